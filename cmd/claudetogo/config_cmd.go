@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+)
+
+// configUsage describes the `claudetogo config` subcommands
+const configUsage = `Usage: claudetogo config <command> [options] [arguments]
+
+Commands:
+  get [key]           Print a setting (or every setting) as currently in effect
+  set key=value       Change a setting in the config file (also: set key value)
+
+Options:
+  --config PATH       Config file to read or edit (default: the auto-discovered file,
+                      or claudetogo-messenger.yaml)
+
+Keys are the YAML paths of the settings, e.g. processing.poll_interval or
+integrations.webhook_url. Values use the same syntax as CLAUDETOGO_ environment
+variables: durations like 2s, true/false, numbers and key=value,key2=value2 maps.
+
+Examples:
+  claudetogo config get processing.poll_interval
+  claudetogo config set integrations.webhook_url=https://example.com/hook
+  claudetogo config set service.components.delivery true
+`
+
+// runConfigSubcommand handles `claudetogo config <command>`
+func runConfigSubcommand(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		fmt.Print(configUsage)
+		return nil
+	}
+
+	// Options may come before or after the command
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Config file to read or edit")
+	fs.StringVar(configPath, "messenger-config", "", "Config file to read or edit (same as --config)")
+	command, rest := args[0], args[1:]
+	if strings.HasPrefix(command, "-") {
+		if err := fs.Parse(args); err != nil {
+			return usageError(err)
+		}
+		if fs.NArg() == 0 {
+			fmt.Print(configUsage)
+			return usageError(fmt.Errorf("missing config command"))
+		}
+		command, rest = fs.Arg(0), fs.Args()[1:]
+	}
+	if err := fs.Parse(rest); err != nil {
+		return usageError(err)
+	}
+
+	switch command {
+	case "get":
+		return handleConfigGet(*configPath, fs.Args())
+	case "set":
+		return handleConfigSet(*configPath, fs.Args())
+	default:
+		fmt.Print(configUsage)
+		return usageError(fmt.Errorf("unknown config command: %s", command))
+	}
+}
+
+// handleConfigGet prints settings from the files and environment, as the other commands see them
+func handleConfigGet(configPath string, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("config get takes at most one key")
+	}
+
+	var msgConfig *messengerConfig.MessengerConfig
+	if configPath != "" {
+		loaded, err := messengerConfig.LoadMessengerConfig(configPath)
+		if err != nil {
+			return err
+		}
+		msgConfig = loaded
+	} else {
+		msgConfig = messengerConfig.GetMessengerConfigWithDefaults("")
+	}
+	if err := msgConfig.ApplyEnvironmentOverrides(); err != nil {
+		return err
+	}
+
+	// A single key prints just its value, for use in scripts
+	if len(args) == 1 {
+		value, err := msgConfig.Get(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	}
+
+	for _, key := range messengerConfig.Keys() {
+		value, _ := msgConfig.Get(key)
+		if messengerConfig.IsSecretKey(key) && value != "" {
+			value = "(set)"
+		}
+		fmt.Printf("%s = %s\n", key, value)
+	}
+	return nil
+}
+
+// handleConfigSet changes one setting in the config file
+func handleConfigSet(configPath string, args []string) error {
+	var key, value string
+	switch len(args) {
+	case 1:
+		var found bool
+		if key, value, found = strings.Cut(args[0], "="); !found {
+			return fmt.Errorf("expected key=value, got %q", args[0])
+		}
+	case 2:
+		key, value = args[0], args[1]
+	default:
+		fmt.Print(configUsage)
+		return usageError(fmt.Errorf("config set takes key=value"))
+	}
+
+	if configPath == "" {
+		if configPath = messengerConfig.FindMessengerConfig(); configPath == "" {
+			configPath = messengerConfig.DefaultConfigFile
+		}
+	}
+
+	if err := messengerConfig.SetValue(configPath, key, value); err != nil {
+		return err
+	}
+
+	shown := value
+	if messengerConfig.IsSecretKey(key) {
+		shown = "(hidden)"
+	}
+	fmt.Printf("✅ Set %s = %s in %s\n", key, shown, configPath)
+	return nil
+}
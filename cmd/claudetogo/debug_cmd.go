@@ -0,0 +1,301 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/extractor"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/jsonl"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/processor"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// debugUsage describes `claudetogo debug`
+const debugUsage = `Usage: claudetogo debug --session ID [options]
+
+Shows how ClaudeToGo reads a session, for when a notification is missing or wrong: the
+session's events in the events file, whether its transcript can be read, and what is
+extracted from the latest event (every event with --all) and the message it turns into.
+
+Options:
+  --session ID          Session to debug (a prefix is enough)
+  --events-file PATH    Events file (default: monitor.log_file)
+  --all                 Extract every event of the session, not only the latest
+  --json                Print the diagnostics as JSON
+  --config PATH         Config file (default: the auto-discovered file)
+`
+
+// debugReport is what `claudetogo debug` found about a session
+type debugReport struct {
+	SessionID    string             `json:"session_id"`
+	EventsFile   string             `json:"events_file"`
+	EventsError  string             `json:"events_error,omitempty"`
+	EventsByType map[string]int     `json:"events_by_type"`
+	Transcript   *transcriptCheck   `json:"transcript,omitempty"`
+	Events       []debugEventReport `json:"events"`
+}
+
+// transcriptCheck describes whether a transcript can be read
+type transcriptCheck struct {
+	Path        string `json:"path"`
+	Error       string `json:"error,omitempty"`
+	Size        int64  `json:"size"`
+	Lines       int    `json:"lines"`
+	Oversized   int    `json:"oversized_lines,omitempty"` // Skipped for exceeding processing.max_line_size_mb
+	Invalid     int    `json:"invalid_lines,omitempty"`   // Not valid JSON
+	LastMessage string `json:"last_message,omitempty"`
+}
+
+// debugEventReport is what was extracted from one event
+type debugEventReport struct {
+	Number    int                     `json:"number"` // Position among the session's events, from 1
+	Event     types.ClaudeHookEvent   `json:"event"`
+	Extracted *types.ExtractedData    `json:"extracted,omitempty"`
+	Message   *types.MessengerMessage `json:"message,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+	Took      string                  `json:"took"`
+}
+
+// runDebugSubcommand handles `claudetogo debug`
+func runDebugSubcommand(args []string) error {
+	fs := flag.NewFlagSet("debug", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(debugUsage) }
+	sessionID := fs.String("session", "", "Session to debug")
+	eventsFile := fs.String("events-file", "", "Events file")
+	all := fs.Bool("all", false, "Extract every event of the session")
+	asJSON := fs.Bool("json", false, "Print as JSON")
+	configPath := fs.String("config", "", "Config file")
+	fs.StringVar(configPath, "messenger-config", "", "Config file (same as --config)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+	if *sessionID == "" {
+		fmt.Print(debugUsage)
+		return usageError(fmt.Errorf("debug requires --session"))
+	}
+
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath))
+	jsonl.SetMaxLineSize(int64(msgConfig.Processing.MaxLineSizeMB) * 1024 * 1024)
+	if *eventsFile == "" {
+		*eventsFile = msgConfig.Monitor.LogFile
+	}
+	report := &debugReport{SessionID: *sessionID, EventsFile: *eventsFile, EventsByType: make(map[string]int)}
+
+	events, err := processor.NewEventProcessor("").SessionEvents(*eventsFile, *sessionID)
+	if err != nil {
+		report.EventsError = err.Error()
+	}
+	for _, event := range events {
+		report.EventsByType[event.HookEventName]++
+	}
+
+	// The transcript named by the newest event, or the session's transcript on disk
+	transcriptPath := ""
+	if len(events) > 0 {
+		transcriptPath = events[len(events)-1].TranscriptPath
+		report.SessionID = events[len(events)-1].SessionID
+	} else if found, err := sessionTranscript(*sessionID, "", *configPath); err == nil {
+		transcriptPath = found
+	}
+	if transcriptPath != "" {
+		report.Transcript = checkTranscript(transcriptPath)
+	}
+
+	dataExtractor := extractor.NewDataExtractor()
+	dataExtractor.SetMaxContentPreview(msgConfig.Formatting.MaxContentPreview)
+	dataExtractor.SetContextMessages(msgConfig.Formatting.ContextMessages)
+	messageFormatter := formatter.NewMessengerFormatter()
+	messageFormatter.SetOptions(formatterOptions(msgConfig))
+	first := len(events) - 1
+	if *all {
+		first = 0
+	}
+	for i := max(first, 0); i < len(events); i++ {
+		report.Events = append(report.Events, debugEvent(dataExtractor, messageFormatter, i+1, events[i]))
+	}
+
+	if *asJSON {
+		if report.Events == nil {
+			report.Events = []debugEventReport{}
+		}
+		return printJSON(report)
+	}
+	printDebugReport(report)
+	if len(events) == 0 && report.Transcript == nil {
+		return fmt.Errorf("no events or transcript found for session %s", *sessionID)
+	}
+	return nil
+}
+
+// debugEvent runs one event through extraction and formatting as the processor would
+func debugEvent(dataExtractor *extractor.DataExtractor, messageFormatter *formatter.MessengerFormatter, number int, event types.ClaudeHookEvent) debugEventReport {
+	eventReport := debugEventReport{Number: number, Event: event}
+	start := time.Now()
+
+	extracted, err := dataExtractor.ProcessEvent(&event)
+	if err != nil {
+		eventReport.Error = "extraction failed: " + err.Error()
+		eventReport.Took = time.Since(start).Round(time.Microsecond).String()
+		return eventReport
+	}
+	eventReport.Extracted = extracted
+	message, err := messageFormatter.CreateActionableMessage(extracted)
+	if err != nil {
+		eventReport.Error = "formatting failed: " + err.Error()
+	}
+	eventReport.Message = message
+	eventReport.Took = time.Since(start).Round(time.Microsecond).String()
+	return eventReport
+}
+
+// checkTranscript reads a whole transcript, counting the lines that can't be used
+func checkTranscript(path string) *transcriptCheck {
+	check := &transcriptCheck{Path: path}
+	file, err := os.Open(path)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil {
+		check.Size = info.Size()
+	}
+
+	var last types.TranscriptMessage
+	reader := jsonl.NewReader(file)
+	for {
+		line, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		check.Lines = reader.Line()
+		if errors.Is(err, jsonl.ErrLineTooLong) {
+			check.Oversized++
+			continue
+		}
+		if err != nil {
+			check.Error = err.Error()
+			break
+		}
+		var message types.TranscriptMessage
+		if err := json.Unmarshal(line, &message); err != nil {
+			check.Invalid++
+			continue
+		}
+		if message.Type == "user" || message.Type == "assistant" {
+			last = message
+		}
+	}
+	if last.Type != "" {
+		check.LastMessage = fmt.Sprintf("%s at %s", last.Type, last.Timestamp)
+		if last.IsSidechain {
+			check.LastMessage += " (subagent)"
+		}
+	}
+	return check
+}
+
+// printDebugReport prints the diagnostics for people
+func printDebugReport(report *debugReport) {
+	fmt.Printf("🔍 Debug: session %s\n", report.SessionID)
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	switch {
+	case report.EventsError != "":
+		fmt.Printf("❌ Events file: %s\n", report.EventsError)
+	case len(report.EventsByType) == 0:
+		fmt.Printf("⚠️  Events file: no events for this session in %s\n", report.EventsFile)
+	default:
+		var counts []string
+		total := 0
+		for eventType, count := range report.EventsByType {
+			counts = append(counts, fmt.Sprintf("%d %s", count, eventType))
+			total += count
+		}
+		sort.Strings(counts)
+		fmt.Printf("✅ Events file: %s (%d events: %s)\n", report.EventsFile, total, strings.Join(counts, ", "))
+	}
+
+	if check := report.Transcript; check == nil {
+		fmt.Println("❌ Transcript:  not found")
+	} else if check.Error != "" {
+		fmt.Printf("❌ Transcript:  %s\n", check.Error)
+	} else {
+		fmt.Printf("✅ Transcript:  %s (%d bytes, %d lines)\n", check.Path, check.Size, check.Lines)
+		if check.Oversized > 0 {
+			fmt.Printf("   ⚠️  %d line(s) over the maximum line size were skipped; raise processing.max_line_size_mb\n", check.Oversized)
+		}
+		if check.Invalid > 0 {
+			fmt.Printf("   ⚠️  %d line(s) are not valid JSON\n", check.Invalid)
+		}
+		if check.LastMessage != "" {
+			fmt.Printf("   Last message: %s\n", check.LastMessage)
+		}
+	}
+
+	for _, eventReport := range report.Events {
+		event := eventReport.Event
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Printf("📥 Event %d: %s at %s (took %s)\n", eventReport.Number, event.HookEventName, event.Timestamp, eventReport.Took)
+		if event.ToolName != "" || event.ToolUseID != "" {
+			fmt.Printf("   Tool: %s  tool_use_id: %s\n", event.ToolName, event.ToolUseID)
+		}
+		if event.Message != "" {
+			fmt.Printf("   Hook message: %s\n", event.Message)
+		}
+		if eventReport.Extracted != nil {
+			printExtracted(eventReport.Extracted)
+		}
+		if eventReport.Error != "" {
+			fmt.Printf("❌ %s\n", eventReport.Error)
+		}
+		if message := eventReport.Message; message != nil {
+			fmt.Printf("📨 %s (%s, %s priority)\n", message.Title, message.Type, message.Priority)
+			for _, line := range strings.Split(strings.TrimRight(message.Message, "\n"), "\n") {
+				fmt.Printf("   │ %s\n", line)
+			}
+		}
+	}
+}
+
+// printExtracted summarizes what the extractor found in an event
+func printExtracted(data *types.ExtractedData) {
+	switch eventData := data.Data.(type) {
+	case *types.StopEventData:
+		fmt.Printf("✅ Extracted stop: task status %s\n", eventData.TaskStatus)
+		fmt.Printf("   Final message: %s\n", formatter.Truncate(singleLogLine(eventData.FinalMessage), 200))
+	case *types.NotificationEventData:
+		fmt.Printf("✅ Extracted notification: %s (%s)\n", eventData.ToolName, eventData.Action)
+		keys := make([]string, 0, len(eventData.Details))
+		for key := range eventData.Details {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("   %s: %s\n", key, formatter.Truncate(singleLogLine(fmt.Sprint(eventData.Details[key])), 200))
+		}
+		if eventData.Subagent {
+			fmt.Printf("   Requested by a subagent: %s %s\n", eventData.Agent, eventData.AgentTask)
+		}
+		if result := eventData.Result; result != nil {
+			fmt.Printf("   Tool result: error=%t %s\n", result.IsError, formatter.Truncate(singleLogLine(result.Output), 200))
+		}
+	default:
+		fmt.Printf("✅ Extracted %s event\n", data.EventType)
+	}
+	if data.Conversation != nil && data.Conversation.LastPrompt != "" {
+		fmt.Printf("   Last prompt: %s\n", formatter.Truncate(singleLogLine(data.Conversation.LastPrompt), 200))
+	}
+}
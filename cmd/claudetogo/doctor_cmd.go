@@ -0,0 +1,440 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/channels"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/claude"
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/service"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/version"
+)
+
+// doctorUsage describes `claudetogo doctor`
+const doctorUsage = `Usage: claudetogo doctor [options]
+
+Checks every link between Claude Code and your phone: the config file, the hooks in
+Claude's settings.json, the events file, Claude's transcripts, the notification channels
+(by sending a test message) and the background service. Each problem comes with a fix.
+
+Options:
+  --config PATH       Config file (default: the auto-discovered file)
+  --output-dir PATH   Output directory the service writes to (default: messenger.output_dir)
+  --no-send           Check channel settings without sending test messages
+`
+
+// doctorStatus is the outcome of one check
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+// doctorResult is one finding of `claudetogo doctor`
+type doctorResult struct {
+	Status doctorStatus
+	Check  string
+	Detail string
+	Fix    string // What to do about a warning or failure
+}
+
+// doctor collects check results
+type doctor struct {
+	results []doctorResult
+	code    int // Exit code of the first failure with a specific one
+}
+
+func (d *doctor) ok(check, format string, args ...interface{}) {
+	d.results = append(d.results, doctorResult{Status: doctorOK, Check: check, Detail: fmt.Sprintf(format, args...)})
+}
+
+func (d *doctor) warn(check, detail, fix string) {
+	d.results = append(d.results, doctorResult{Status: doctorWarn, Check: check, Detail: detail, Fix: fix})
+}
+
+func (d *doctor) fail(check, detail, fix string) {
+	d.results = append(d.results, doctorResult{Status: doctorFail, Check: check, Detail: detail, Fix: fix})
+}
+
+// failWith records a failure that ends doctor with a specific exit code
+func (d *doctor) failWith(code int, check, detail, fix string) {
+	if d.code == 0 {
+		d.code = code
+	}
+	d.fail(check, detail, fix)
+}
+
+// runDoctorSubcommand handles `claudetogo doctor`
+func runDoctorSubcommand(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(doctorUsage) }
+	configPath := fs.String("config", "", "Config file")
+	fs.StringVar(configPath, "messenger-config", "", "Config file (same as --config)")
+	outputDir := fs.String("output-dir", "", "Output directory the service writes to")
+	noSend := fs.Bool("no-send", false, "Don't send test messages")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	fmt.Println("🩺 ClaudeToGo Doctor")
+	fmt.Println(version.Get())
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	d := &doctor{}
+	msgConfig := d.checkConfig(messengerConfigPath(*configPath))
+	if *outputDir == "" {
+		*outputDir = msgConfig.Messenger.OutputDir
+	}
+	d.checkHooks(msgConfig.Monitor.LogFile)
+	d.checkEventsFile(msgConfig.Monitor.LogFile)
+	d.checkTranscripts(msgConfig.Service.TranscriptsDir)
+	d.checkChannels(msgConfig, !*noSend)
+	d.checkService(msgConfig, *outputDir)
+
+	failures, warnings := 0, 0
+	for _, result := range d.results {
+		switch result.Status {
+		case doctorOK:
+			fmt.Printf("✅ %-12s %s\n", result.Check, result.Detail)
+		case doctorWarn:
+			warnings++
+			fmt.Printf("⚠️  %-12s %s\n", result.Check, result.Detail)
+		case doctorFail:
+			failures++
+			fmt.Printf("❌ %-12s %s\n", result.Check, result.Detail)
+		}
+		if result.Fix != "" {
+			fmt.Printf("   💡 %s\n", result.Fix)
+		}
+	}
+
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	if failures > 0 {
+		err := fmt.Errorf("%d check(s) failed, %d warning(s)", failures, warnings)
+		if d.code != 0 {
+			return withExitCode(d.code, err)
+		}
+		return err
+	}
+	if warnings > 0 {
+		fmt.Printf("✅ No failures, %d warning(s)\n", warnings)
+	} else {
+		fmt.Println("✅ Everything looks good")
+	}
+	return nil
+}
+
+// checkConfig loads the config file, returning the configuration the other checks use
+func (d *doctor) checkConfig(configPath string) *messengerConfig.MessengerConfig {
+	msgConfig := messengerConfig.DefaultMessengerConfig()
+	if configPath == "" {
+		d.warn("Config", "no config file found, using built-in defaults",
+			"Run 'claudetogo --setup' to create claudetogo-messenger.yaml")
+	} else if loaded, err := messengerConfig.LoadMessengerConfig(configPath); err != nil {
+		d.failWith(exitConfigError, "Config", fmt.Sprintf("%s: %v", configPath, err),
+			fmt.Sprintf("Run 'claudetogo --config-validate %s' for details and fix the file", configPath))
+	} else {
+		msgConfig = loaded
+		if warnings := loaded.Warnings(); len(warnings) > 0 {
+			d.warn("Config", fmt.Sprintf("%s: %s", configPath, strings.Join(warnings, "; ")),
+				"Remove or rename the keys; 'claudetogo --setup' can remove deprecated ones")
+		} else {
+			d.ok("Config", "%s is valid", configPath)
+		}
+	}
+
+	if _, err := os.Stat(messengerConfig.LegacyConfigFile); err == nil {
+		d.warn("Config", messengerConfig.LegacyConfigFile+" is deprecated",
+			"Run 'claudetogo --config-migrate' to move it into the YAML config")
+		if err := msgConfig.ApplyLegacyConfig(messengerConfig.LegacyConfigFile); err != nil {
+			d.failWith(exitConfigError, "Config", err.Error(), "Fix or remove "+messengerConfig.LegacyConfigFile)
+		}
+	}
+	if err := msgConfig.ApplyEnvironmentOverrides(); err != nil {
+		d.failWith(exitConfigError, "Config", err.Error(), "Fix the CLAUDETOGO_ environment variable")
+	}
+	return msgConfig
+}
+
+// checkHooks looks for ClaudeToGo hooks in Claude's settings files and checks the binary
+// they run and the events file they write
+func (d *doctor) checkHooks(logFile string) {
+	locations, err := claude.AllSettingsLocations()
+	if err != nil {
+		d.fail("Hooks", err.Error(), "")
+		return
+	}
+	cwd, _ := os.Getwd()
+	policy, err := claude.LoadHookPolicy(cwd)
+	if err != nil {
+		d.fail("Hooks", err.Error(), "")
+		return
+	}
+
+	found, blocked := false, false
+	for _, location := range locations {
+		installed, err := claude.InstalledHooks(location.Path)
+		if err != nil {
+			d.fail("Hooks", fmt.Sprintf("%s: %v", location.Path, err), "Fix the JSON syntax of "+location.Path)
+			continue
+		}
+		if disabled, _ := claude.DisabledHookCount(location.Path); disabled > 0 {
+			d.warn("Hooks", fmt.Sprintf("%d ClaudeToGo hook(s) in %s are disabled", disabled, location.Path),
+				"Run 'claudetogo hooks enable' to turn them back on")
+		}
+		if len(installed) == 0 {
+			continue
+		}
+
+		hookTypes := make([]string, 0, len(installed))
+		for hookType := range installed {
+			hookTypes = append(hookTypes, hookType)
+		}
+		sort.Strings(hookTypes)
+		if reason := policy.Blocks(location); reason != "" {
+			fix := "Remove disableAllHooks from " + policy.DisabledBy
+			if policy.ManagedOnly {
+				fix = "Ask an administrator to install the hooks in managed settings ('claudetogo hooks add --scope managed')"
+			}
+			d.warn("Hooks", fmt.Sprintf("%s hooks in %s are ignored: %s", strings.Join(hookTypes, ", "), location.Path, reason), fix)
+			blocked = true
+			continue
+		}
+		found = true
+		d.ok("Hooks", "%s hooks in %s", strings.Join(hookTypes, ", "), location.Path)
+
+		checked := make(map[string]bool)
+		for _, hookType := range hookTypes {
+			for _, command := range installed[hookType] {
+				if checked[command] {
+					continue
+				}
+				checked[command] = true
+				d.checkHookCommand(command, cwd, logFile)
+			}
+		}
+	}
+
+	switch {
+	case !found && blocked:
+		d.failWith(exitPolicyDenied, "Hooks", "Claude Code's hook policy ignores every ClaudeToGo hook", "")
+	case !found:
+		d.fail("Hooks", "no ClaudeToGo hooks in Claude's settings.json",
+			"Run 'claudetogo --setup' and let it configure the hooks")
+	}
+}
+
+// checkHookCommand checks that a hook runs an existing binary and logs where the rest of
+// ClaudeToGo reads events
+func (d *doctor) checkHookCommand(command, dir, logFile string) {
+	binary := claude.HookBinary(command)
+	err := claude.CheckHookBinary(command, dir)
+	switch {
+	case errors.Is(err, claude.ErrHookBinaryMissing):
+		d.fail("Hooks", fmt.Sprintf("hook runs %s, which doesn't exist", binary),
+			"Run 'claudetogo hooks verify' from the installed binary to point the hooks at it")
+	case errors.Is(err, claude.ErrHookBinaryNotExecutable):
+		d.fail("Hooks", fmt.Sprintf("hook runs %s, which isn't executable", binary),
+			"Run 'chmod +x "+binary+"'")
+	}
+
+	if hookLog := hookLogFile(command); hookLog != "" && !samePath(hookLog, logFile) {
+		d.warn("Hooks", fmt.Sprintf("hook logs to %s but monitor.log_file is %s", hookLog, logFile),
+			"Re-run 'claudetogo --setup' or set monitor.log_file to the same file")
+	} else if hookLog == "" && !filepath.IsAbs(logFile) {
+		d.warn("Hooks", fmt.Sprintf("events file %s is relative, so hooks write it in each project's directory", logFile),
+			"Set monitor.log_file to an absolute path and re-run 'claudetogo --setup'")
+	}
+}
+
+// hookLogFile returns the --logfile a hook command passes, if any
+func hookLogFile(command string) string {
+	_, rest, found := strings.Cut(command, "--logfile")
+	if !found {
+		return ""
+	}
+	rest = strings.TrimLeft(rest, " =")
+	if strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "'") {
+		if end := strings.IndexByte(rest[1:], rest[0]); end >= 0 {
+			return rest[1 : end+1]
+		}
+	}
+	if fields := strings.Fields(rest); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// checkEventsFile checks that hooks can append to the events file
+func (d *doctor) checkEventsFile(logFile string) {
+	info, err := os.Stat(logFile)
+	if errors.Is(err, os.ErrNotExist) {
+		// Check the directory by creating and removing a temporary file
+		dir := filepath.Dir(logFile)
+		probe, err := os.CreateTemp(dir, ".claudetogo-doctor-*")
+		if err != nil {
+			d.fail("Events file", fmt.Sprintf("cannot create %s: %v", logFile, err),
+				fmt.Sprintf("Create %s and make it writable, or change monitor.log_file", dir))
+			return
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+		d.warn("Events file", logFile+" doesn't exist yet (no hook events recorded)",
+			"Use Claude Code once with the hooks installed, then run doctor again")
+		return
+	}
+	if err != nil {
+		d.fail("Events file", err.Error(), "Check the permissions of "+logFile)
+		return
+	}
+
+	file, err := os.OpenFile(logFile, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		d.fail("Events file", fmt.Sprintf("%s is not writable: %v", logFile, err),
+			"Run 'chmod u+w "+logFile+"' or change monitor.log_file")
+		return
+	}
+	file.Close()
+	d.ok("Events file", "%s is writable (last event %s ago)", logFile, time.Since(info.ModTime()).Round(time.Second))
+}
+
+// checkTranscripts checks that Claude's session transcripts can be read
+func (d *doctor) checkTranscripts(transcriptsDir string) {
+	dir := service.ExpandHome(transcriptsDir)
+	if _, err := os.ReadDir(dir); err != nil {
+		d.fail("Transcripts", fmt.Sprintf("cannot read %s: %v", dir, err),
+			"Set service.transcripts_dir to where Claude Code keeps transcripts (usually ~/.claude/projects)")
+		return
+	}
+
+	latest, modified := service.LatestTranscript(dir)
+	if latest == "" {
+		d.warn("Transcripts", "no transcripts in "+dir,
+			"Use Claude Code once, or check service.transcripts_dir")
+		return
+	}
+	file, err := os.Open(latest)
+	if err != nil {
+		d.fail("Transcripts", fmt.Sprintf("cannot read %s: %v", latest, err),
+			"Make the transcripts readable by the user running ClaudeToGo")
+		return
+	}
+	file.Close()
+	d.ok("Transcripts", "readable (latest written %s ago)", time.Since(modified).Round(time.Second))
+}
+
+// checkChannels checks the notification channels, sending each a test message
+func (d *doctor) checkChannels(msgConfig *messengerConfig.MessengerConfig, send bool) {
+	_, keyringErr := msgConfig.ApplyKeyringSecrets()
+
+	delivery := deliveryConfig(msgConfig)
+	targets := delivery.Channels()
+	if len(targets) == 0 {
+		// Tokens stored with 'claudetogo secret set' can't be read without the keyring
+		if keyringErr != nil {
+			d.warn("Channels", "OS keyring unavailable: "+keyringErr.Error(),
+				"Unlock the keyring, or put the tokens in the config file or environment")
+		}
+		d.fail("Channels", "no notification channel configured",
+			"Run 'claudetogo --setup' to set up Telegram, Slack or a webhook")
+		return
+	}
+	if !msgConfig.Service.Components.Delivery {
+		d.warn("Channels", "service.components.delivery is off, so nothing is sent",
+			"Run 'claudetogo config set service.components.delivery true'")
+	}
+	if !send {
+		for _, channel := range targets {
+			d.ok("Channels", "%s configured (not tested)", channel.Name())
+		}
+		return
+	}
+
+	message := &types.MessengerMessage{
+		Type:      "completion",
+		Title:     "🩺 ClaudeToGo doctor",
+		Message:   "Test message: notifications reach this channel.",
+		Context:   map[string]interface{}{"test": true},
+		Timestamp: time.Now().Format(time.RFC3339),
+		MessageID: fmt.Sprintf("doctor-%d", time.Now().Unix()),
+	}
+	client := &http.Client{Timeout: delivery.Timeout}
+	if delivery.Timeout <= 0 {
+		client.Timeout = 30 * time.Second
+	}
+	for _, channel := range targets {
+		ctx, cancel := context.WithTimeout(context.Background(), client.Timeout)
+		err := channel.Send(ctx, client, message)
+		cancel()
+		if err != nil {
+			d.failWith(exitDeliveryFailed, "Channels", fmt.Sprintf("%s: %v", channel.Name(), err), channelFix(channel))
+		} else {
+			d.ok("Channels", "%s: test message sent", channel.Name())
+		}
+	}
+}
+
+// channelFix suggests what to check when a channel can't be reached
+func channelFix(channel channels.Channel) string {
+	switch channel.(type) {
+	case *channels.Telegram:
+		return "Check integrations.telegram_token and telegram_chat_id, and that you sent /start to the bot"
+	case *channels.Slack:
+		return "Check integrations.slack_token (chat:write scope) and slack_channel, and invite the bot to the channel"
+	default:
+		return "Check integrations.webhook_url and that the endpoint accepts JSON POST requests"
+	}
+}
+
+// checkService checks that the background service is running and healthy
+func (d *doctor) checkService(msgConfig *messengerConfig.MessengerConfig, outputDir string) {
+	statusFile := service.StatusFilePath(msgConfig.Service.StatusFile, outputDir)
+	status, err := service.ReadStatus(statusFile)
+	if err != nil && !os.IsNotExist(err) {
+		d.fail("Service", err.Error(), "Remove "+statusFile+" and restart the service")
+		return
+	}
+
+	startFix := "Start it with 'claudetogo --service', or 'claudetogo service install --systemd' to run it at login"
+	if state, ok := service.SystemdState(); ok {
+		startFix = "Run 'claudetogo service start' (systemd unit is " + state + ")"
+	}
+	if status == nil || !status.IsRunning() {
+		d.fail("Service", "not running", startFix)
+		return
+	}
+	if status.HeartbeatOverdue() {
+		d.fail("Service", fmt.Sprintf("PID %d is not responding (missed heartbeats)", status.PID),
+			"Restart it: 'claudetogo service restart', or stop and start 'claudetogo --service'")
+		return
+	}
+
+	d.ok("Service", "running (PID %d, up %s)", status.PID, status.Uptime())
+	if status.EventsFile != "" && !samePath(status.EventsFile, msgConfig.Monitor.LogFile) {
+		d.warn("Service", fmt.Sprintf("service watches %s but monitor.log_file is %s", status.EventsFile, msgConfig.Monitor.LogFile),
+			"Restart the service with the same events file the hooks write")
+	}
+	if status.DeliveryFailures > 0 {
+		d.warn("Service", fmt.Sprintf("%d message(s) could not be delivered", status.DeliveryFailures),
+			"Check the service log; the channel check above shows whether delivery works now")
+	}
+}
+
+// samePath reports whether two paths name the same file
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
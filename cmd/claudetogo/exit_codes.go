@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/responder"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/transcript"
+)
+
+// Exit codes, so scripts can branch on the kind of failure without parsing log text
+const (
+	exitOK                = 0
+	exitFailure           = 1 // Any other error
+	exitUsage             = 2 // Invalid flags or arguments, as the flag package uses, or an ambiguous session prefix
+	exitConfigError       = 3 // Config file missing, unreadable or invalid, or a bad environment override
+	exitNoPendingAction   = 4 // No notification for the session
+	exitTranscriptMissing = 5 // The session's transcript can't be found
+	exitDeliveryFailed    = 6 // A messenger channel didn't accept a message
+	exitPolicyDenied      = 7 // Claude Code's hook policy (allowManagedHooksOnly, disableAllHooks) blocks the hooks
+	exitConfirmation      = 8 // A high-risk action was approved without its confirmation code, or with a wrong one
+)
+
+// exitError gives an error the exit code the program ends with
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode makes the program end with code when err is returned to main
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// usageError marks an error in the command-line flags or arguments
+func usageError(err error) error {
+	return withExitCode(exitUsage, err)
+}
+
+// exitCode returns the exit code for the error a command failed with
+func exitCode(err error) int {
+	var coded *exitError
+	var ambiguous *session.AmbiguousError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.As(err, &ambiguous):
+		return exitUsage
+	case errors.Is(err, messengerConfig.ErrInvalidConfig):
+		return exitConfigError
+	case errors.Is(err, responder.ErrNoPendingAction):
+		return exitNoPendingAction
+	case errors.Is(err, transcript.ErrTranscriptMissing):
+		return exitTranscriptMissing
+	case errors.Is(err, responder.ErrConfirmationRequired), errors.Is(err, responder.ErrWrongConfirmationCode):
+		return exitConfirmation
+	}
+	return exitFailure
+}
@@ -0,0 +1,980 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/claude"
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// hooksUsage describes the `claudetogo hooks` subcommands
+const hooksUsage = `Usage: claudetogo hooks <command> [options]
+
+Commands:
+  list                Show the hooks in every settings file, marking ClaudeToGo's
+  add                 Install the ClaudeToGo hook for hook types in one settings file
+  remove              Remove ClaudeToGo hooks from one settings file
+  restore [N|PATH]    Restore a Claude Code settings file from one of its backups
+  verify              Check that ClaudeToGo hooks still run an existing executable and
+                      offer to point broken ones at the current binary
+  export              Print the ClaudeToGo hooks and hook policy of one settings file as
+                      JSON, e.g. claudetogo hooks export > hooks.json
+  import FILE         Replace the ClaudeToGo hooks of one settings file with exported ones
+                      (FILE - reads stdin)
+  disable             Move ClaudeToGo hooks aside, leaving all other settings in place
+  enable              Put hooks moved aside by disable back
+
+The settings files are, highest precedence first: the enterprise managed settings, the
+local and project settings of the current directory, the user's settings.json and
+~/.claude.json, followed by the local and project settings of every project registered in
+the config file. Claude Code runs the hooks of all of them unless a policy
+(allowManagedHooksOnly in managed settings, or disableAllHooks) stops it.
+
+List options:
+  --scope SCOPE       Only show managed, local, project, global or legacy settings
+  --config PATH       Config file with the project registry (default: auto-discovered)
+  --json              Print the hooks as JSON
+
+Add and remove options:
+  --event TYPES       Comma-separated hook types, e.g. PreToolUse or Stop,Notification
+                      (required for add; remove defaults to all types)
+  --matcher PATTERN   Tools the hook fires for, e.g. Bash or Write|Edit (add defaults to *,
+                      remove defaults to any matcher)
+  --scope SCOPE       managed, local, project, global or legacy (~/.claude.json) settings
+                      file (default global; managed needs administrator rights)
+  --dir DIR           Project directory for project and local scope (default: current)
+  --config PATH       Config file with the events file and project registry
+  --dry-run           Show the changes without writing them
+
+Restore options:
+  --scope SCOPE       Only list backups of one kind of settings file
+  --list              List the available backups without restoring
+  --yes               Restore without asking for confirmation
+
+Verify options:
+  --scope SCOPE       Only check managed, local, project, global or legacy settings
+  --config PATH       Config file with the project registry (default: auto-discovered)
+  --fix               Rewrite broken hooks without asking
+  --dry-run           Show the changes without writing them
+
+Export and import options:
+  --scope SCOPE       managed, local, project, global or legacy settings file (default global)
+  --dir DIR           Project directory for project and local scope (default: current)
+  --config PATH       Config file with the events file and project registry (import)
+  --dry-run           Show the changes without writing them (import)
+
+An export holds the hook types, matchers and timeouts, plus disableAllHooks and
+allowManagedHooksOnly when the file sets them. It doesn't hold commands: import builds them
+from the running binary and monitor.log_file, so exports work on any machine.
+
+Disable and enable options:
+  --scope SCOPE       Only managed, local, project, global or legacy settings (default: all)
+  --config PATH       Config file with the project registry (default: auto-discovered)
+  --dry-run           Show the changes without writing them
+
+Disabled hooks are kept in <settings file>.claudetogo-disabled until they are enabled.
+
+Every time ClaudeToGo changes a settings file it keeps a timestamped copy next to it
+(settings.json.<time>.bak). Without N or PATH the backups are listed and you are asked
+which one to restore.
+`
+
+// settingsBackup is a backup together with the settings file it belongs to
+type settingsBackup struct {
+	location types.ConfigLocation
+	claude.SettingsBackup
+}
+
+// runHooksSubcommand handles `claudetogo hooks <command>`
+func runHooksSubcommand(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		fmt.Print(hooksUsage)
+		return nil
+	}
+
+	switch command := args[0]; command {
+	case "list":
+		return handleHooksList(args[1:])
+	case "add":
+		return handleHooksEdit(args[1:], true)
+	case "remove":
+		return handleHooksEdit(args[1:], false)
+	case "restore":
+		return handleHooksRestore(args[1:])
+	case "verify":
+		return handleHooksVerify(args[1:])
+	case "export":
+		return handleHooksExport(args[1:])
+	case "import":
+		return handleHooksImport(args[1:])
+	case "disable":
+		return handleHooksToggle(args[1:], false)
+	case "enable":
+		return handleHooksToggle(args[1:], true)
+	default:
+		fmt.Print(hooksUsage)
+		return usageError(fmt.Errorf("unknown hooks command: %s", command))
+	}
+}
+
+// settingsHooks is the listing of one settings file
+type settingsHooks struct {
+	Path       string             `json:"path"`
+	Scope      string             `json:"scope"`
+	Precedence int                `json:"precedence"`
+	Exists     bool               `json:"exists"`
+	Blocked    string             `json:"blocked,omitempty"` // Why Claude Code doesn't run these hooks
+	Hooks      []claude.HookEntry `json:"hooks"`
+	Disabled   int                `json:"disabled,omitempty"` // ClaudeToGo hooks moved aside by `hooks disable`
+}
+
+// handleHooksList handles `claudetogo hooks list`
+func handleHooksList(args []string) error {
+	fs := flag.NewFlagSet("hooks list", flag.ContinueOnError)
+	scope := fs.String("scope", "", "Only show managed, local, project, global or legacy settings")
+	configPath := fs.String("config", "", "Config file with the project registry")
+	fs.StringVar(configPath, "messenger-config", "", "Config file (same as --config)")
+	asJSON := fs.Bool("json", false, "Print the hooks as JSON")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	locations, err := hookLocations(*scope, messengerConfigPath(*configPath))
+	if err != nil {
+		return err
+	}
+	policies := make(map[string]claude.HookPolicy)
+	var listing []settingsHooks
+	for _, location := range locations {
+		entries, err := claude.ListHooks(location.Path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", location.Path, err)
+		}
+		dir := locationProjectDir(location)
+		policy, seen := policies[dir]
+		if !seen {
+			if policy, err = claude.LoadHookPolicy(dir); err != nil {
+				return err
+			}
+			policies[dir] = policy
+		}
+		disabled, err := claude.DisabledHookCount(location.Path)
+		if err != nil {
+			return err
+		}
+		_, statErr := os.Stat(location.Path)
+		listing = append(listing, settingsHooks{
+			Path:       location.Path,
+			Scope:      location.Scope,
+			Precedence: location.Precedence,
+			Exists:     statErr == nil,
+			Blocked:    policy.Blocks(location),
+			Hooks:      entries,
+			Disabled:   disabled,
+		})
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(listing, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	installed := 0
+	for _, settings := range listing {
+		fmt.Printf("📁 %s (%s, precedence %d)\n", settings.Path, settings.Scope, settings.Precedence)
+		if settings.Blocked != "" && len(settings.Hooks) > 0 {
+			fmt.Printf("   ⛔ Claude Code ignores these hooks: %s\n", settings.Blocked)
+		}
+		switch {
+		case !settings.Exists:
+			fmt.Println("   (no file)")
+		case len(settings.Hooks) == 0:
+			fmt.Println("   (no hooks)")
+		}
+		counted := false
+		for _, hook := range settings.Hooks {
+			marker := "🔧"
+			if hook.ClaudeToGo {
+				marker = "✅"
+				if !counted && settings.Blocked == "" {
+					installed++
+					counted = true
+				}
+			}
+			fmt.Printf("   %s %-17s %-15s %s\n", marker, hook.Event, hook.Matcher, hook.Command)
+		}
+		if settings.Disabled > 0 {
+			fmt.Printf("   ⏸️  %d ClaudeToGo hook(s) disabled ('claudetogo hooks enable' turns them back on)\n", settings.Disabled)
+		}
+	}
+	fmt.Println()
+	fmt.Println("✅ = ClaudeToGo hook, 🔧 = other hook")
+	if installed == 0 {
+		fmt.Println("ℹ️  No active ClaudeToGo hooks; run 'claudetogo --setup' to add them")
+	} else {
+		fmt.Printf("ClaudeToGo hooks are active in %d of %d settings file(s)\n", installed, len(listing))
+	}
+	return nil
+}
+
+// handleHooksEdit handles `claudetogo hooks add` and `claudetogo hooks remove`
+func handleHooksEdit(args []string, add bool) error {
+	name := "hooks remove"
+	if add {
+		name = "hooks add"
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	eventList := fs.String("event", "", "Comma-separated hook types")
+	matcher := fs.String("matcher", "", "Tools the hook fires for")
+	scope := fs.String("scope", "global", "global, project or local settings file")
+	dir := fs.String("dir", "", "Project directory for project and local scope")
+	configPath := fs.String("config", "", "Config file with the events file and project registry")
+	fs.StringVar(configPath, "messenger-config", "", "Config file (same as --config)")
+	dryRun := fs.Bool("dry-run", false, "Show the changes without writing them")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+
+	var events []string
+	for _, event := range strings.Split(*eventList, ",") {
+		if event = strings.TrimSpace(event); event == "" {
+			continue
+		}
+		if !slices.Contains(claude.HookEvents, event) {
+			return fmt.Errorf("unknown hook type %q (use %s)", event, strings.Join(claude.HookEvents, ", "))
+		}
+		events = append(events, event)
+	}
+	if add && len(events) == 0 {
+		return fmt.Errorf("--event is required")
+	}
+	if add && *matcher == "" {
+		*matcher = "*"
+	}
+	if *matcher != "" {
+		if err := claude.ValidateMatcher(*matcher); err != nil {
+			return err
+		}
+	}
+
+	location, projectDir, err := hookLocation(*scope, *dir)
+	if err != nil {
+		return err
+	}
+	if add {
+		if err := checkHookPolicy(location, projectDir); err != nil {
+			return err
+		}
+	}
+	settingsConfig, err := claude.LoadExistingSettings(location.Path)
+	if err != nil {
+		return fmt.Errorf("could not load %s: %w", location.Path, err)
+	}
+	if settingsConfig.Hooks == nil {
+		settingsConfig.Hooks = make(map[string][]types.HookMatcher)
+	}
+
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath))
+	claude.SetBackupRetention(msgConfig.Hooks.BackupRetention)
+	command := hookCommand(msgConfig, projectDir)
+	editHooks := func(settingsConfig *types.ClaudeSettingsConfig) int {
+		if add {
+			for _, event := range events {
+				claude.AddHook(settingsConfig.Hooks, event, *matcher, command, 30)
+			}
+			return len(events)
+		}
+		removed := 0
+		if len(events) == 0 {
+			removed = claude.RemoveHooks(settingsConfig.Hooks, "", *matcher)
+		}
+		for _, event := range events {
+			removed += claude.RemoveHooks(settingsConfig.Hooks, event, *matcher)
+		}
+		return removed
+	}
+	if editHooks(settingsConfig) == 0 {
+		fmt.Printf("✓ No matching ClaudeToGo hooks in %s\n", location.Path)
+		return nil
+	}
+
+	changed, err := previewSettings(location.Path, settingsConfig)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Printf("✓ %s already has these hooks\n", location.Path)
+	} else {
+		if *dryRun {
+			fmt.Println("🔍 Dry run: not applying these changes")
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(location.Path), 0755); err != nil {
+			return permissionHint(fmt.Errorf("could not create directory %s: %w", filepath.Dir(location.Path), err), *location)
+		}
+		edit := func(fresh *types.ClaudeSettingsConfig) { editHooks(fresh) }
+		if err := claude.SaveSettingsEdit(settingsConfig, location.Path, edit); err != nil {
+			return permissionHint(err, *location)
+		}
+		if add {
+			fmt.Printf("✅ Added ClaudeToGo hooks to %s\n", location.Path)
+		} else {
+			fmt.Printf("✅ Removed ClaudeToGo hooks from %s\n", location.Path)
+		}
+	}
+
+	if add && !*dryRun {
+		return registerProject(messengerConfigPath(*configPath), msgConfig, projectDir)
+	}
+	return nil
+}
+
+// previewSettings prints the hooks that saving settings adds and removes, followed by the
+// diff of the file, and reports whether the file would change
+func previewSettings(path string, settingsConfig *types.ClaudeSettingsConfig) (bool, error) {
+	preview, err := claude.SettingsDiff(path, settingsConfig)
+	if err != nil || preview == "" {
+		return false, err
+	}
+	changes, err := claude.HookDiff(path, settingsConfig)
+	if err != nil {
+		return false, err
+	}
+	fmt.Print(changes)
+	fmt.Println(preview)
+	return true, nil
+}
+
+// hookCommand builds the ClaudeToGo hook command from the monitor settings. Hooks run in
+// their project's directory, so a project elsewhere needs an absolute events file to log to
+// the one the service watches.
+func hookCommand(msgConfig *messengerConfig.MessengerConfig, projectDir string) string {
+	configFile := types.ConfigFile{LogFile: msgConfig.Monitor.LogFile, Verbose: msgConfig.Monitor.Verbose}
+	if cwd, _ := os.Getwd(); projectDir != "" && projectDir != cwd && !filepath.IsAbs(configFile.LogFile) {
+		if abs, err := filepath.Abs(configFile.LogFile); err == nil {
+			configFile.LogFile = abs
+		}
+	}
+	return claude.BuildClaudeToGoCommand(configFile)
+}
+
+// registerProject records the project hooks were installed in, as --setup does
+func registerProject(configPath string, msgConfig *messengerConfig.MessengerConfig, projectDir string) error {
+	if projectDir == "" || projectRegistered(msgConfig, projectDir) {
+		return nil
+	}
+	if configPath == "" {
+		configPath = messengerConfig.DefaultConfigFile
+	}
+	project := messengerConfig.ProjectSettings{Name: filepath.Base(projectDir), Path: projectDir}
+	if err := messengerConfig.RegisterProjects(configPath, []messengerConfig.ProjectSettings{project}); err != nil {
+		return fmt.Errorf("failed to register project: %w", err)
+	}
+	fmt.Printf("📋 Registered project %s in %s\n", projectDir, configPath)
+	return nil
+}
+
+// hookLocation returns the settings file of a scope, and for project and local scope the
+// project directory it belongs to
+func hookLocation(scope, dir string) (*types.ConfigLocation, string, error) {
+	if err := checkScope(scope); err != nil {
+		return nil, "", err
+	}
+	if scope != "project" && scope != "local" {
+		if dir != "" {
+			return nil, "", fmt.Errorf("--dir only applies to project and local scope")
+		}
+		locations, err := claude.AllSettingsLocations()
+		if err != nil {
+			return nil, "", err
+		}
+		for i := range locations {
+			if locations[i].Scope == scope {
+				return &locations[i], "", nil
+			}
+		}
+	}
+
+	if dir == "" {
+		dir = "."
+	}
+	projectDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if info, err := os.Stat(projectDir); err != nil || !info.IsDir() {
+		return nil, "", fmt.Errorf("%s is not a directory", projectDir)
+	}
+	locations := claude.ProjectLocations(projectDir)
+	if scope == "local" {
+		return &locations[1], projectDir, nil
+	}
+	return &locations[0], projectDir, nil
+}
+
+// checkHookPolicy refuses to install hooks in a settings file whose hooks Claude Code's hook
+// policy (allowManagedHooksOnly or disableAllHooks) makes it ignore
+func checkHookPolicy(location *types.ConfigLocation, projectDir string) error {
+	if projectDir == "" {
+		projectDir, _ = os.Getwd()
+	}
+	policy, err := claude.LoadHookPolicy(projectDir)
+	if err != nil {
+		return err
+	}
+	if reason := policy.Blocks(*location); reason != "" {
+		return withExitCode(exitPolicyDenied, fmt.Errorf("Claude Code ignores the hooks in %s: %s", location.Path, reason))
+	}
+	return nil
+}
+
+// permissionHint explains a permission error writing managed settings
+func permissionHint(err error, location types.ConfigLocation) error {
+	if errors.Is(err, os.ErrPermission) && location.Scope == "managed" {
+		return fmt.Errorf("%w (managed settings can only be changed by an administrator; re-run with sudo or ask your IT team)", err)
+	}
+	return err
+}
+
+// projectRegistered reports whether a directory is in the project registry
+func projectRegistered(msgConfig *messengerConfig.MessengerConfig, dir string) bool {
+	for _, project := range msgConfig.Projects {
+		if filepath.Clean(project.Path) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// hookLocations returns the settings files Claude Code reads hooks from in the current
+// directory followed by those of the registered projects, optionally limited to one scope
+func hookLocations(scope, configPath string) ([]types.ConfigLocation, error) {
+	if scope != "" {
+		if err := checkScope(scope); err != nil {
+			return nil, err
+		}
+	}
+	locations, err := claude.AllSettingsLocations()
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range messengerConfig.GetMessengerConfigWithDefaults(configPath).Projects {
+		locations = append(locations, claude.ProjectLocations(project.Path)...)
+	}
+
+	var selected []types.ConfigLocation
+	seen := make(map[string]bool)
+	for _, location := range locations {
+		if (scope != "" && location.Scope != scope) || seen[location.Path] {
+			continue
+		}
+		seen[location.Path] = true
+		selected = append(selected, location)
+	}
+	return selected, nil
+}
+
+// checkScope rejects unknown settings scopes
+func checkScope(scope string) error {
+	switch scope {
+	case "managed", "local", "project", "global", "legacy":
+		return nil
+	}
+	return usageError(fmt.Errorf("unknown scope %q (use managed, local, project, global or legacy)", scope))
+}
+
+// locationProjectDir returns the project directory whose policy applies to a settings file:
+// its own for project and local settings, the current directory otherwise
+func locationProjectDir(location types.ConfigLocation) string {
+	if location.Scope == "project" || location.Scope == "local" {
+		return filepath.Dir(filepath.Dir(location.Path))
+	}
+	cwd, _ := os.Getwd()
+	return cwd
+}
+
+// handleHooksRestore handles `claudetogo hooks restore`
+func handleHooksRestore(args []string) error {
+	fs := flag.NewFlagSet("hooks restore", flag.ContinueOnError)
+	scope := fs.String("scope", "", "Only list backups of global, project or local settings")
+	list := fs.Bool("list", false, "List the available backups without restoring")
+	yes := fs.Bool("yes", false, "Restore without asking for confirmation")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return fmt.Errorf("expected at most one backup, got %d", len(positional))
+	}
+
+	backups, err := findSettingsBackups(*scope)
+	if err != nil {
+		return err
+	}
+
+	var chosen *settingsBackup
+	if len(positional) == 1 {
+		if chosen, err = pickBackup(backups, positional[0]); err != nil {
+			return err
+		}
+	} else {
+		if len(backups) == 0 {
+			fmt.Println("ℹ️  No settings backups found")
+			return nil
+		}
+		printBackups(backups)
+		if *list {
+			return nil
+		}
+		fmt.Printf("Restore which backup? [1-%d, Enter to cancel]: ", len(backups))
+		var answer string
+		fmt.Scanln(&answer)
+		if answer == "" {
+			fmt.Println("✓ Nothing restored")
+			return nil
+		}
+		if chosen, err = pickBackup(backups, answer); err != nil {
+			return err
+		}
+	}
+
+	if !*yes {
+		fmt.Printf("Replace %s with %s? [y/N]: ", chosen.location.Path, chosen.Path)
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" && strings.ToLower(answer) != "yes" {
+			fmt.Println("✓ Nothing restored")
+			return nil
+		}
+	}
+
+	claude.SetBackupRetention(messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath("")).Hooks.BackupRetention)
+	previous, err := claude.RestoreBackup(chosen.location.Path, chosen.Path)
+	if err != nil {
+		return permissionHint(err, chosen.location)
+	}
+	fmt.Printf("✅ Restored %s from %s\n", chosen.location.Path, chosen.Path)
+	if previous != "" {
+		fmt.Printf("💾 The replaced settings were saved to %s\n", previous)
+	}
+	fmt.Println("   Restart Claude Code for the change to take effect")
+	return nil
+}
+
+// findSettingsBackups collects the backups of every settings file, optionally limited to
+// one scope
+func findSettingsBackups(scope string) ([]settingsBackup, error) {
+	locations, err := hookLocations(scope, messengerConfigPath(""))
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []settingsBackup
+	for _, location := range locations {
+		locationBackups, err := claude.ListBackups(location.Path)
+		if err != nil {
+			return nil, fmt.Errorf("could not list backups of %s: %w", location.Path, err)
+		}
+		for _, backup := range locationBackups {
+			backups = append(backups, settingsBackup{location: location, SettingsBackup: backup})
+		}
+	}
+	return backups, nil
+}
+
+// printBackups lists backups numbered for selection, grouped by settings file
+func printBackups(backups []settingsBackup) {
+	current := ""
+	for i, backup := range backups {
+		if backup.location.Path != current {
+			current = backup.location.Path
+			fmt.Printf("📁 %s (%s)\n", current, backup.location.Scope)
+		}
+		fmt.Printf("  [%d] %s  %s  (%d bytes)\n", i+1, backup.Created.Format("2006-01-02 15:04:05"), backup.Path, backup.Size)
+	}
+	fmt.Println()
+}
+
+// pickBackup selects a backup by its number in the list or by path. A path that isn't in
+// the list is accepted if it sits next to one of the settings files.
+func pickBackup(backups []settingsBackup, choice string) (*settingsBackup, error) {
+	if n, err := strconv.Atoi(choice); err == nil {
+		if n < 1 || n > len(backups) {
+			return nil, fmt.Errorf("no backup number %d", n)
+		}
+		return &backups[n-1], nil
+	}
+
+	for i := range backups {
+		if samePath(backups[i].Path, choice) {
+			return &backups[i], nil
+		}
+	}
+
+	if _, err := os.Stat(choice); err != nil {
+		return nil, fmt.Errorf("backup not found: %w", err)
+	}
+	path, err := filepath.Abs(choice)
+	if err != nil {
+		return nil, err
+	}
+	locations, err := hookLocations("", messengerConfigPath(""))
+	if err != nil {
+		return nil, err
+	}
+	for _, location := range locations {
+		if strings.HasPrefix(path, location.Path+".") {
+			return &settingsBackup{location: location, SettingsBackup: claude.SettingsBackup{Path: path}}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not a backup of a Claude Code settings file", choice)
+}
+
+// brokenHooks are the ClaudeToGo hooks of one settings file whose executable is gone
+type brokenHooks struct {
+	location types.ConfigLocation
+	dir      string // Where Claude Code runs these hooks
+}
+
+// handleHooksVerify handles `claudetogo hooks verify`
+func handleHooksVerify(args []string) error {
+	fs := flag.NewFlagSet("hooks verify", flag.ContinueOnError)
+	scope := fs.String("scope", "", "Only check managed, local, project, global or legacy settings")
+	configPath := fs.String("config", "", "Config file with the project registry")
+	fs.StringVar(configPath, "messenger-config", "", "Config file (same as --config)")
+	fix := fs.Bool("fix", false, "Rewrite broken hooks without asking")
+	dryRun := fs.Bool("dry-run", false, "Show the changes without writing them")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+
+	path := messengerConfigPath(*configPath)
+	locations, err := hookLocations(*scope, path)
+	if err != nil {
+		return err
+	}
+
+	checked, brokenCount := 0, 0
+	var broken []brokenHooks
+	for _, location := range locations {
+		entries, err := claude.ListHooks(location.Path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", location.Path, err)
+		}
+		dir := locationProjectDir(location)
+		found := false
+		for _, hook := range entries {
+			if !hook.ClaudeToGo {
+				continue
+			}
+			if !found {
+				fmt.Printf("📁 %s (%s)\n", location.Path, location.Scope)
+				found = true
+			}
+			checked++
+			if err := claude.CheckHookBinary(hook.Command, dir); err != nil {
+				fmt.Printf("   ❌ %-17s %-15s %v\n", hook.Event, hook.Matcher, err)
+				brokenCount++
+				if len(broken) == 0 || broken[len(broken)-1].location.Path != location.Path {
+					broken = append(broken, brokenHooks{location: location, dir: dir})
+				}
+				continue
+			}
+			fmt.Printf("   ✅ %-17s %-15s %s\n", hook.Event, hook.Matcher, claude.HookBinary(hook.Command))
+		}
+	}
+
+	fmt.Println()
+	if checked == 0 {
+		fmt.Println("ℹ️  No ClaudeToGo hooks found; run 'claudetogo --setup' to add them")
+		return nil
+	}
+	if brokenCount == 0 {
+		fmt.Printf("✅ All %d ClaudeToGo hook(s) run an existing executable\n", checked)
+		return nil
+	}
+
+	// Point the broken hooks at the binary that is running now
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not find the current executable: %w", err)
+	}
+	if !claude.IsClaudeToGoHook(execPath + " --hook") {
+		return fmt.Errorf("%d hook(s) run a missing executable, and the current one (%s) isn't named claudetogo; run 'claudetogo hooks verify' from the installed binary", brokenCount, execPath)
+	}
+	fmt.Printf("⚠️  %d of %d ClaudeToGo hook(s) run an executable that is gone\n", brokenCount, checked)
+	if !*fix && !*dryRun {
+		fmt.Printf("Rewrite them to run %s? [y/N]: ", execPath)
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" && strings.ToLower(answer) != "yes" {
+			return fmt.Errorf("%d hook(s) run a missing executable", brokenCount)
+		}
+	}
+
+	claude.SetBackupRetention(messengerConfig.GetMessengerConfigWithDefaults(path).Hooks.BackupRetention)
+	for _, settings := range broken {
+		settingsConfig, err := claude.LoadExistingSettings(settings.location.Path)
+		if err != nil {
+			return fmt.Errorf("could not load %s: %w", settings.location.Path, err)
+		}
+		repointed := claude.RepointHooks(settingsConfig.Hooks, execPath, settings.dir)
+		if *dryRun {
+			if _, err := previewSettings(settings.location.Path, settingsConfig); err != nil {
+				return err
+			}
+			fmt.Println("🔍 Dry run: not applying these changes")
+			continue
+		}
+		repoint := func(fresh *types.ClaudeSettingsConfig) { claude.RepointHooks(fresh.Hooks, execPath, settings.dir) }
+		if err := claude.SaveSettingsEdit(settingsConfig, settings.location.Path, repoint); err != nil {
+			return permissionHint(err, settings.location)
+		}
+		fmt.Printf("✅ Rewrote %d hook(s) in %s\n", repointed, settings.location.Path)
+	}
+	return nil
+}
+
+// handleHooksExport handles `claudetogo hooks export`
+func handleHooksExport(args []string) error {
+	fs := flag.NewFlagSet("hooks export", flag.ContinueOnError)
+	scope := fs.String("scope", "global", "managed, local, project, global or legacy settings file")
+	dir := fs.String("dir", "", "Project directory for project and local scope")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+
+	location, _, err := hookLocation(*scope, *dir)
+	if err != nil {
+		return err
+	}
+	export, err := claude.ExportHooks(location.Path)
+	if err != nil {
+		return fmt.Errorf("could not load %s: %w", location.Path, err)
+	}
+	if len(export.Hooks) == 0 && len(export.Policy) == 0 {
+		return fmt.Errorf("no ClaudeToGo hooks in %s", location.Path)
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// handleHooksImport handles `claudetogo hooks import`
+func handleHooksImport(args []string) error {
+	fs := flag.NewFlagSet("hooks import", flag.ContinueOnError)
+	scope := fs.String("scope", "global", "managed, local, project, global or legacy settings file")
+	dir := fs.String("dir", "", "Project directory for project and local scope")
+	configPath := fs.String("config", "", "Config file with the events file and project registry")
+	fs.StringVar(configPath, "messenger-config", "", "Config file (same as --config)")
+	dryRun := fs.Bool("dry-run", false, "Show the changes without writing them")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("expected the file to import (or - for stdin)")
+	}
+	file := positional[0]
+
+	var data []byte
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return fmt.Errorf("could not read hook export: %w", err)
+	}
+	export, err := claude.ParseHookExport(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	location, projectDir, err := hookLocation(*scope, *dir)
+	if err != nil {
+		return err
+	}
+	if _, set := export.Policy["allowManagedHooksOnly"]; set && location.Scope != "managed" {
+		fmt.Println("⚠️  allowManagedHooksOnly only has an effect in managed settings (--scope managed)")
+	}
+	if len(export.Hooks) > 0 {
+		if err := checkHookPolicy(location, projectDir); err != nil {
+			return err
+		}
+	}
+	settingsConfig, err := claude.LoadExistingSettings(location.Path)
+	if err != nil {
+		return fmt.Errorf("could not load %s: %w", location.Path, err)
+	}
+
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath))
+	claude.SetBackupRetention(msgConfig.Hooks.BackupRetention)
+	command := hookCommand(msgConfig, projectDir)
+	export.Apply(settingsConfig, command)
+
+	changed, err := previewSettings(location.Path, settingsConfig)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Printf("✓ %s already has these hooks\n", location.Path)
+		return nil
+	}
+	if *dryRun {
+		fmt.Println("🔍 Dry run: not applying these changes")
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(location.Path), 0755); err != nil {
+		return permissionHint(fmt.Errorf("could not create directory %s: %w", filepath.Dir(location.Path), err), *location)
+	}
+	apply := func(fresh *types.ClaudeSettingsConfig) { export.Apply(fresh, command) }
+	if err := claude.SaveSettingsEdit(settingsConfig, location.Path, apply); err != nil {
+		return permissionHint(err, *location)
+	}
+	fmt.Printf("✅ Imported %d ClaudeToGo hook(s) into %s\n", len(export.Hooks), location.Path)
+
+	if len(export.Hooks) > 0 {
+		return registerProject(messengerConfigPath(*configPath), msgConfig, projectDir)
+	}
+	return nil
+}
+
+// parseInterspersed parses flags given before or after the positional arguments, which it
+// returns, so `hooks import hooks.json --scope project` works as expected
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usageError(err)
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// handleHooksToggle handles `claudetogo hooks disable` and `claudetogo hooks enable`
+func handleHooksToggle(args []string, enable bool) error {
+	name := "hooks disable"
+	if enable {
+		name = "hooks enable"
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	scope := fs.String("scope", "", "Only managed, local, project, global or legacy settings")
+	configPath := fs.String("config", "", "Config file with the project registry")
+	fs.StringVar(configPath, "messenger-config", "", "Config file (same as --config)")
+	dryRun := fs.Bool("dry-run", false, "Show the changes without writing them")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+
+	path := messengerConfigPath(*configPath)
+	locations, err := hookLocations(*scope, path)
+	if err != nil {
+		return err
+	}
+	claude.SetBackupRetention(messengerConfig.GetMessengerConfigWithDefaults(path).Hooks.BackupRetention)
+
+	toggled := 0
+	for _, location := range locations {
+		disabled, err := claude.LoadDisabledHooks(location.Path)
+		if err != nil {
+			return err
+		}
+		if enable && disabled == nil {
+			continue
+		}
+		settingsConfig, err := claude.LoadExistingSettings(location.Path)
+		if err != nil {
+			return fmt.Errorf("could not load %s: %w", location.Path, err)
+		}
+		if settingsConfig.Hooks == nil {
+			settingsConfig.Hooks = make(map[string][]types.HookMatcher)
+		}
+
+		var edit func(*types.ClaudeSettingsConfig)
+		var count int
+		remaining := make(map[string][]types.HookMatcher)
+		if enable {
+			count = claude.EnableHooks(settingsConfig.Hooks, disabled)
+			edit = func(fresh *types.ClaudeSettingsConfig) { claude.EnableHooks(fresh.Hooks, disabled) }
+		} else {
+			if disabled != nil {
+				remaining = maps.Clone(disabled)
+			}
+			if count = claude.DisableHooks(settingsConfig.Hooks, remaining); count == 0 {
+				continue
+			}
+			edit = func(fresh *types.ClaudeSettingsConfig) { claude.CleanupAllClaudeToGoHooks(fresh.Hooks) }
+		}
+
+		fmt.Printf("📁 %s\n", location.Path)
+		if _, err := previewSettings(location.Path, settingsConfig); err != nil {
+			return err
+		}
+		toggled += count
+		if *dryRun {
+			continue
+		}
+
+		// Disabled hooks are saved before they leave settings.json so they can't get lost
+		if err := claude.SaveDisabledHooks(location.Path, remaining); err != nil {
+			return permissionHint(err, location)
+		}
+		if err := claude.SaveSettingsEdit(settingsConfig, location.Path, edit); err != nil {
+			claude.SaveDisabledHooks(location.Path, disabled)
+			return permissionHint(err, location)
+		}
+		if enable {
+			fmt.Printf("✅ Enabled %d ClaudeToGo hook(s) in %s\n", count, location.Path)
+		} else {
+			fmt.Printf("⏸️  Disabled %d ClaudeToGo hook(s) in %s\n", count, location.Path)
+		}
+	}
+
+	switch {
+	case toggled == 0 && enable:
+		fmt.Println("ℹ️  No disabled ClaudeToGo hooks found")
+	case toggled == 0:
+		fmt.Println("ℹ️  No ClaudeToGo hooks to disable")
+	case *dryRun:
+		fmt.Println("🔍 Dry run: not applying these changes")
+	case !enable:
+		fmt.Println("Run 'claudetogo hooks enable' to turn them back on")
+	}
+	return nil
+}
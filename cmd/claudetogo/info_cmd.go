@@ -0,0 +1,242 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/responder"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/risk"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/service"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/timeline"
+)
+
+// infoUsage describes `claudetogo info`
+const infoUsage = `Usage: claudetogo info --session ID [options]
+
+Shows the whole messenger message of a session: its title, message, suggested actions,
+context and attachments, as the notification channels received it.
+
+Options:
+  --session ID        Session to show (a prefix is enough)
+  --json              Print the message as JSON
+  --output-dir DIR    Directory with the messenger files (default: messenger.output_dir)
+  --config PATH       Config file (default: the auto-discovered file)
+`
+
+// statusUsage describes `claudetogo status`
+const statusUsage = `Usage: claudetogo status --session ID [options]
+
+Shows the status of a session's pending action, the same as 'claudetogo --status'.
+
+Options:
+  --session ID          Session to show (a prefix is enough)
+  --timeline            Show the session's activity instead: when it started and ended, its
+                        prompts, tool calls, permission requests, approvals and completions
+  --json                Print as JSON
+  --events-file PATH    timeline: hook events to read (default: monitor.log_file)
+  --output-dir DIR      timeline: directory with the messenger files (default: messenger.output_dir)
+  --config PATH         Config file (default: the auto-discovered file)
+`
+
+// runInfoSubcommand handles `claudetogo info`
+func runInfoSubcommand(args []string) error {
+	fs := flag.NewFlagSet("info", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(infoUsage) }
+	sessionID := fs.String("session", "", "Session to show")
+	asJSON := fs.Bool("json", false, "Print as JSON")
+	outputDir := fs.String("output-dir", "", "Directory with the messenger files")
+	configPath := fs.String("config", "", "Config file")
+	fs.StringVar(configPath, "messenger-config", "", "Config file (same as --config)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+	if *sessionID == "" {
+		fmt.Print(infoUsage)
+		return usageError(fmt.Errorf("info requires --session"))
+	}
+
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath))
+	if *outputDir == "" {
+		*outputDir = msgConfig.Messenger.OutputDir
+	}
+	rh := responder.NewResponseHandler(*outputDir, logger.New(false))
+	fullID, err := resolveSessionID(rh, *sessionID, !*asJSON)
+	if err != nil {
+		return err
+	}
+	message, messengerFile, err := rh.FindMessage(fullID)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(message)
+	}
+
+	display := formatterOptions(msgConfig)
+	fmt.Printf("📖 %s\n", message.Title)
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Printf("Session:   %s\n", message.SessionID)
+	fmt.Printf("Type:      %s\n", message.Type)
+	if message.Priority != "" {
+		fmt.Printf("Priority:  %s\n", message.Priority)
+	}
+	fmt.Printf("Time:      %s\n", display.FormatTimestamp(message.Timestamp))
+	if message.MessageID != "" {
+		fmt.Printf("Message:   %s\n", message.MessageID)
+	}
+	fmt.Printf("File:      %s\n", messengerFile)
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println(strings.TrimRight(message.Message, "\n"))
+
+	if len(message.Actions) > 0 {
+		fmt.Println("\nActions:")
+		for _, action := range message.Actions {
+			fmt.Printf("  %s\n", action.Label)
+			if action.Command != "" {
+				fmt.Printf("     %s\n", action.Command)
+			}
+		}
+	}
+	if context := risk.WithoutCodes(message.Context); len(context) > 0 {
+		fmt.Println("\nContext:")
+		keys := make([]string, 0, len(context))
+		for key := range context {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("  %s: %v\n", key, context[key])
+		}
+	}
+	if len(message.Attachments) > 0 {
+		fmt.Println("\nAttachments:")
+		for _, attachment := range message.Attachments {
+			fmt.Printf("  📎 %s (%d bytes) %s\n", attachment.Name, attachment.Size, attachment.Path)
+		}
+	}
+	return nil
+}
+
+// runStatusSubcommand handles `claudetogo status`, the form suggested in messenger messages
+func runStatusSubcommand(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(statusUsage) }
+	sessionID := fs.String("session", "", "Session to show")
+	showTimeline := fs.Bool("timeline", false, "Show the session's activity timeline")
+	asJSON := fs.Bool("json", false, "Print as JSON")
+	eventsFile := fs.String("events-file", "", "Hook events file")
+	outputDir := fs.String("output-dir", "", "Directory with the messenger files")
+	configPath := fs.String("config", "", "Config file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+	if *sessionID == "" {
+		fmt.Print(statusUsage)
+		return usageError(fmt.Errorf("status requires --session"))
+	}
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath))
+	if *showTimeline {
+		if *outputDir == "" {
+			*outputDir = msgConfig.Messenger.OutputDir
+		}
+		if *eventsFile == "" {
+			*eventsFile = msgConfig.Monitor.LogFile
+		}
+		return handleTimeline(*sessionID, msgConfig, *outputDir, *eventsFile, *asJSON)
+	}
+	return handleStatusCommand(*sessionID, formatterOptions(msgConfig), *asJSON, logger.New(false))
+}
+
+// handleTimeline prints a session's activity timeline
+func handleTimeline(sessionID string, msgConfig *messengerConfig.MessengerConfig, outputDir, eventsFile string, asJSON bool) error {
+	rh := responder.NewResponseHandler(outputDir, logger.New(false))
+	fullID, err := resolveSessionID(rh, sessionID, !asJSON)
+	var ambiguous *session.AmbiguousError
+	if errors.As(err, &ambiguous) {
+		return err
+	}
+	if err == nil {
+		sessionID = fullID
+	}
+
+	tl, err := timeline.Build(sessionID, timeline.Sources{
+		OutputDir:      outputDir,
+		EventsFile:     eventsFile,
+		TranscriptsDir: service.ExpandHome(msgConfig.Service.TranscriptsDir),
+	})
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return printJSON(tl)
+	}
+
+	display := formatterOptions(msgConfig)
+	fmt.Printf("🕒 Session Timeline: %s\n", tl.SessionID)
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	if tl.Project != "" {
+		fmt.Printf("📁 Project:  %s\n", tl.Project)
+	}
+	if tl.Status != "" {
+		fmt.Printf("🔍 Status:   %s\n", tl.Status)
+	}
+	if len(tl.Tags) > 0 {
+		fmt.Printf("🏷️  Tags:     %s\n", strings.Join(tl.Tags, ", "))
+	}
+	fmt.Printf("▶️  Started:  %s\n", display.FormatTime(tl.Started))
+	if tl.Ended != nil {
+		fmt.Printf("⏹️  Ended:    %s\n", display.FormatTime(*tl.Ended))
+	}
+	fmt.Printf("⏱️  Duration: %s\n\n", tl.Duration())
+
+	for _, entry := range tl.Entries {
+		text := entry.Text
+		if entry.Tool != "" {
+			text = strings.TrimSpace(entry.Tool + " " + text)
+		}
+		if entry.Subagent {
+			text = "(subagent) " + text
+		}
+		fmt.Printf("%s  %s %-18s %s\n", display.FormatTime(entry.At), timelineIcon(entry.Kind), entry.Kind, formatter.Truncate(text, 80))
+	}
+	return nil
+}
+
+// timelineIcon marks an entry kind in the timeline
+func timelineIcon(kind string) string {
+	switch kind {
+	case timeline.KindStart:
+		return "▶️ "
+	case timeline.KindPrompt:
+		return "💬"
+	case timeline.KindToolCall:
+		return "🔧"
+	case timeline.KindPermissionRequest:
+		return "🔐"
+	case timeline.KindApproval:
+		return "✅"
+	case timeline.KindRejection:
+		return "❌"
+	case timeline.KindCompletion:
+		return "🏁"
+	case timeline.KindNote:
+		return "🗒️ "
+	case timeline.KindEnd:
+		return "⏹️ "
+	default:
+		return "•"
+	}
+}
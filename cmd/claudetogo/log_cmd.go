@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/transcript"
+)
+
+// logResultLines is how many lines of a tool result are shown without --full
+const logResultLines = 5
+
+// logUsage describes `claudetogo log`
+const logUsage = `Usage: claudetogo log --session ID [options]
+
+Prints the end of a session's transcript, oldest first: the prompts, Claude's replies, and
+the tool calls with their results. Subagent steps are marked.
+
+Options:
+  --session ID        Session to show (a unique prefix is enough)
+  --transcript PATH   Read this transcript file instead of looking up --session
+  --lines N           Number of steps to show (default 20)
+  --full              Show tool results in full instead of their first lines
+  --json              Print the steps as JSON
+  --config PATH       Config file (default: the auto-discovered file); its
+                      service.transcripts_dir is searched for the session
+`
+
+// runLogSubcommand handles `claudetogo log`
+func runLogSubcommand(args []string) error {
+	fs := flag.NewFlagSet("log", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(logUsage) }
+	sessionID := fs.String("session", "", "Session to show")
+	transcriptPath := fs.String("transcript", "", "Transcript file to read")
+	lines := fs.Int("lines", 20, "Number of steps to show")
+	full := fs.Bool("full", false, "Show tool results in full")
+	asJSON := fs.Bool("json", false, "Print as JSON")
+	configPath := fs.String("config", "", "Config file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+	if *transcriptPath == "" && *sessionID == "" {
+		fmt.Print(logUsage)
+		return usageError(fmt.Errorf("log requires --session or --transcript"))
+	}
+	if *lines < 1 {
+		return usageError(fmt.Errorf("--lines must be at least 1"))
+	}
+
+	path, err := sessionTranscript(*sessionID, *transcriptPath, *configPath)
+	if err != nil {
+		return err
+	}
+	entries, err := transcript.NewReader().Tail(path, *lines)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if entries == nil {
+			entries = []transcript.LogEntry{}
+		}
+		return printJSON(entries)
+	}
+
+	fmt.Printf("📋 %s\n", path)
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	for _, entry := range entries {
+		printLogEntry(entry, *full)
+	}
+	return nil
+}
+
+// printLogEntry prints one transcript step with its time, kind and text
+func printLogEntry(entry transcript.LogEntry, full bool) {
+	prefix := ""
+	if entry.Subagent {
+		prefix = "[subagent] "
+	}
+	clock := "        "
+	if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+		clock = t.Local().Format("15:04:05")
+	}
+
+	text := entry.Text
+	switch entry.Kind {
+	case "user":
+		fmt.Printf("%s 👤 %sUser:\n", clock, prefix)
+	case "assistant":
+		fmt.Printf("%s 🤖 %sClaude:\n", clock, prefix)
+	case "tool_use":
+		fmt.Printf("%s 🔧 %s%s %s\n", clock, prefix, entry.Tool, formatter.Truncate(singleLogLine(text), 200))
+		return
+	case "tool_result":
+		icon := "✅"
+		if entry.Failed {
+			icon = "❌"
+		}
+		fmt.Printf("%s %s %sResult:\n", clock, icon, prefix)
+		if !full {
+			text = firstLines(text, logResultLines)
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Printf("         │ %s\n", line)
+	}
+}
+
+// singleLogLine joins the lines of a text with spaces
+func singleLogLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// firstLines returns the first n lines of a text, noting how many were left out
+func firstLines(text string, n int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) <= n {
+		return text
+	}
+	return strings.Join(lines[:n], "\n") + fmt.Sprintf("\n… %d more line(s), use --full to see them", len(lines)-n)
+}
@@ -2,27 +2,71 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
 
+	"golang.org/x/term"
+
 	"github.com/riaanpieterse81/ClaudeToGo/internal/config"
 	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/hooks"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/jsonl"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/monitor"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/processor"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/redact"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/risk"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/responder"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/service"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/setup"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/version"
 )
 
+// logFileList collects --logfile values; the flag may be repeated or given a comma-separated list
+type logFileList struct {
+	values []string
+	isSet  bool
+}
+
+// String returns the log files as a comma-separated list
+func (l *logFileList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(l.values, ",")
+}
+
+// Set adds log files, replacing the default on first use
+func (l *logFileList) Set(value string) error {
+	if !l.isSet {
+		l.values = nil
+		l.isSet = true
+	}
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			l.values = append(l.values, path)
+		}
+	}
+	if len(l.values) == 0 {
+		return fmt.Errorf("log file path cannot be empty")
+	}
+	return nil
+}
+
 func showHelp() {
 	fmt.Printf("Usage: %s [options]\n\n", os.Args[0])
 	fmt.Println("Description:")
@@ -33,39 +77,89 @@ func showHelp() {
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  claudetogo --help                           Show this help")
+	fmt.Println("  claudetogo --version                        Show version, commit, build date and Go version")
+	fmt.Println("  claudetogo version --json                   Show build information as JSON (for bug reports)")
 	fmt.Println("  claudetogo --setup                          Run interactive setup wizard (recommended for first use)")
+	fmt.Println("  claudetogo --setup --dry-run                Preview the setup changes (settings.json diff) without writing")
 	fmt.Println("  claudetogo --hook                           Process hook event from stdin (logs and allows all events)")
-	fmt.Println("  claudetogo --config myconfig.json           Use custom configuration file")
+	fmt.Println("  claudetogo --hook --forward https://host:8787  Also send hook events to a remote ClaudeToGo service")
+	fmt.Println("  claudetogo --config myconfig.yaml           Use custom configuration file")
 	fmt.Println("  claudetogo --monitor                        Monitor events in real-time")
 	fmt.Println("  claudetogo --monitor --verbose              Monitor with debug output")
+	fmt.Println("  claudetogo --monitor --logfile 'a/*.jsonl'  Monitor several logs (repeat --logfile or use a glob)")
+	fmt.Println("  claudetogo --monitor --poll                 Monitor by polling (for network filesystems)")
+	fmt.Println("  claudetogo --monitor --since 8h             Replay the last 8 hours, then follow new events")
+	fmt.Println("  claudetogo --monitor --from-start           Replay the whole log, then follow new events")
+	fmt.Println("  claudetogo --monitor --show-context 3       Show recent transcript excerpts under each event")
+	fmt.Println("  claudetogo --monitor --no-color             Monitor without colors (or set NO_COLOR)")
+	fmt.Println("  claudetogo --service --log-format json      Log as JSON lines for Loki/ELK (or set service.log_format)")
+	fmt.Println("  claudetogo --monitor --dashboard            Interactive dashboard (approve/reject/inspect)")
 	fmt.Println()
 	fmt.Println("Processing Commands:")
 	fmt.Println("  claudetogo --process                        Process all events and generate messenger JSON files")
 	fmt.Println("  claudetogo --process --latest 5             Process latest 5 events only")
 	fmt.Println("  claudetogo --process --generate-samples     Generate test samples from real data")
 	fmt.Println("  claudetogo --process --stats               Get processing statistics")
+	fmt.Println("  claudetogo --process --stats --json        Get processing statistics as JSON")
 	fmt.Println("  claudetogo --process --watch --interval 5s  Watch for new events and process them")
 	fmt.Println("  claudetogo --process --output-dir custom/   Use custom output directory")
+	fmt.Println("  claudetogo --process --resume               Resume an interrupted run from its checkpoint")
+	fmt.Println("  claudetogo retry-failed                     Retry events that failed and were quarantined")
 	fmt.Println()
 	fmt.Println("Response Commands:")
 	fmt.Println("  claudetogo --respond --session 1fa8811f --action approve   Approve a pending action")
 	fmt.Println("  claudetogo --respond --session 1fa8811f --action reject    Reject a pending action")
+	fmt.Println("  claudetogo --respond --session 1fa8811f --action approve --code 482913  Approve a high-risk action with its code")
 	fmt.Println("  claudetogo --status --session 1fa8811f                     Get session status")
 	fmt.Println("  claudetogo --pending                                       List pending actions")
+	fmt.Println("  claudetogo --pending --json                                List pending actions as JSON (also --status, --respond)")
+	fmt.Println("  claudetogo review                                          Pick pending actions one by one to approve or reject")
+	fmt.Println("  claudetogo respond --session 1fa8811f --action reject --reason \"Not now\"  Reject with a reason")
+	fmt.Println("  claudetogo respond --stdin < decisions.jsonl               Answer many actions from JSON lines {session, action, reason}")
+	fmt.Println()
+	fmt.Println("Session Commands:")
+	fmt.Println("  claudetogo summarize --session 1fa8811f > session.md      Summarize a session in Markdown for a PR or ticket")
+	fmt.Println("  claudetogo summarize --session 1fa8811f --format json     Summarize a session as JSON")
+	fmt.Println("  claudetogo report --since 7d                              Tool usage, approval rates and tokens across sessions")
+	fmt.Println("  claudetogo info --session 1fa8811f                        Show the whole messenger message of a session")
+	fmt.Println("  claudetogo log --session 1fa8811f --lines 50              Show the end of a session's transcript")
+	fmt.Println("  claudetogo status --session 1fa8811f --timeline           Show a session's duration and activity timeline")
+	fmt.Println("  claudetogo debug --session 1fa8811f                       Show how the session's latest event is extracted")
+	fmt.Println("  claudetogo sessions list                                  List known sessions with status and approval counts")
+	fmt.Println("  claudetogo sessions tag ID release-hotfix                 Tag a session for triage (sessions note ID TEXT adds a note)")
 	fmt.Println()
 	fmt.Println("Service Commands:")
 	fmt.Println("  claudetogo --service                                       Run as background service")
 	fmt.Println("  claudetogo --service --daemon                              Run as daemon (background)")
+	fmt.Println("  claudetogo service install --systemd                      Install as a user-level systemd service")
+	fmt.Println("  claudetogo service enable|start|stop|status|uninstall     Manage the installed service")
 	fmt.Println("  claudetogo --service --interval 10s                       Custom service poll interval")
 	fmt.Println()
 	fmt.Println("Configuration Commands:")
 	fmt.Println("  claudetogo --config-init                                   Create example messenger config file")
 	fmt.Println("  claudetogo --config-show                                   Show current configuration")
-	fmt.Println("  claudetogo --config-validate claudetogo-messenger.yaml    Validate configuration file")
-	fmt.Println("  claudetogo --messenger-config myconfig.yaml               Use custom messenger config")
+	fmt.Println("  claudetogo --config-validate claudetogo-messenger.yaml    Validate configuration file (unknown keys, types, ranges)")
+	fmt.Println("  claudetogo --config-schema messenger                      Print the JSON Schema (messenger or runtime)")
+	fmt.Println("  claudetogo --strict --config-validate config.yaml         Treat unknown and deprecated keys as errors")
+	fmt.Println("  claudetogo --config-migrate                               Move claudetogo-config.json into the YAML config")
+	fmt.Println("  claudetogo config get processing.poll_interval            Print a setting (or all settings without a key)")
+	fmt.Println("  claudetogo config set integrations.webhook_url=URL        Change a setting in the config file (validated)")
+	fmt.Println("  claudetogo secret set telegram_token                      Store a token in the OS keyring instead of the YAML file")
+	fmt.Println("  claudetogo secret list                                    Show which tokens are stored in the keyring")
+	fmt.Println("  claudetogo redact-check claude-events.jsonl               Show the secrets redaction would mask in a file")
+	fmt.Println("  claudetogo payload keygen                                 Create a key pair for encrypted webhook payloads")
+	fmt.Println("  claudetogo hooks list                                     Show the hooks in every Claude Code settings file")
+	fmt.Println("  claudetogo hooks add --event PreToolUse --matcher Bash    Install one ClaudeToGo hook without the wizard")
+	fmt.Println("  claudetogo hooks remove --event PreToolUse                Remove ClaudeToGo hooks of a hook type")
+	fmt.Println("  claudetogo hooks restore                                  Restore Claude Code settings from a backup")
+	fmt.Println("  claudetogo hooks verify                                   Check hooks still run an existing claudetogo")
+	fmt.Println("  claudetogo hooks export > hooks.json                      Save the hook configuration to share it")
+	fmt.Println("  claudetogo hooks import hooks.json                        Install a shared hook configuration")
+	fmt.Println("  claudetogo hooks disable                                  Silence ClaudeToGo hooks until 'hooks enable'")
 	fmt.Println()
 	fmt.Println("Getting Started:")
 	fmt.Println("  For first-time users, run 'claudetogo --setup' to configure the application")
+	fmt.Println("  Run 'claudetogo doctor' to check hooks, events, transcripts, channels and the service")
 }
 
 // setupGracefulShutdown sets up graceful shutdown handling
@@ -77,23 +171,165 @@ func setupGracefulShutdown() (context.Context, context.CancelFunc) {
 
 	go func() {
 		<-c
-		log.Println("\nReceived shutdown signal, stopping gracefully...")
+		cliLogger.Info("Received shutdown signal, stopping gracefully...")
 		cancel()
 	}()
 
 	return ctx, cancel
 }
 
+// cliLogger reports command failures in the configured log format
+var cliLogger = logger.New(false)
+
 func main() {
+	// Subcommands parse their own flags
+	if len(os.Args) > 1 && os.Args[1] == "service" {
+		if err := runServiceSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Service command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfigSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Config command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		if err := runDoctorSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Doctor found problems: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "hooks" {
+		if err := runHooksSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Hooks command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "summarize" {
+		if err := runSummarizeSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Summarize command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		if err := runReportSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Report command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "respond" {
+		if err := runRespondSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Respond command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "review" {
+		if err := runReviewSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Review command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "info" {
+		if err := runInfoSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Info command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "status" {
+		if err := runStatusSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Status command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "log" {
+		if err := runLogSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Log command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "sessions" {
+		if err := runSessionsSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Sessions command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "debug" {
+		if err := runDebugSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Debug command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "version" {
+		if err := runVersionSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Version command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "redact-check" {
+		if err := runRedactCheckSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Redact check failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "payload" {
+		if err := runPayloadSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Payload command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "retry-failed" {
+		if err := runRetryFailedSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Retry failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "secret" {
+		if err := runSecretSubcommand(os.Args[2:]); err != nil {
+			cliLogger.Error("Secret command failed: %v", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
 	// Command line flags
 	helpFlag := flag.Bool("help", false, "Show help information")
+	versionFlag := flag.Bool("version", false, "Show version and build information")
 	setupFlag := flag.Bool("setup", false, "Run interactive setup wizard to configure the application")
-	configFlag := flag.String("config", "", "Path to configuration file (JSON format)")
+	dryRunFlag := flag.Bool("dry-run", false, "Preview the changes --setup would make without writing any files")
+	configFlag := flag.String("config", "", "Path to configuration file (YAML; a legacy claudetogo-config.json is still read)")
 	hookFlag := flag.Bool("hook", false, "Process hook event from stdin (for Claude Code hooks)")
+	forwardFlag := flag.String("forward", "", "With --hook, also send the event to a remote ClaudeToGo service (default: hooks.forward_url)")
 	monitorFlag := flag.Bool("monitor", false, "Monitor events in real-time")
-	logFileFlag := flag.String("logfile", "claude-events.jsonl", "Path to log file")
+	dashboardFlag := flag.Bool("dashboard", false, "Show an interactive dashboard (use with --monitor)")
+	logFileFlag := &logFileList{values: []string{"claude-events.jsonl"}}
+	flag.Var(logFileFlag, "logfile", "Path to log file (repeat, comma-separate or use a glob to monitor several)")
 	verboseFlag := flag.Bool("verbose", false, "Enable verbose debug output")
+	logFormatFlag := flag.String("log-format", "", "Log format: text or json (default: service.log_format)")
 	pollIntervalFlag := flag.Duration("poll-interval", 100*time.Millisecond, "Polling interval for monitoring")
+	noColorFlag := flag.Bool("no-color", false, "Disable colored monitor output (also honors NO_COLOR)")
+	showContextFlag := flag.Int("show-context", 0, "Show the last N transcript messages/tool calls under each monitored event")
+	fromStartFlag := flag.Bool("from-start", false, "Replay all existing events before following new ones (use with --monitor)")
+	sinceFlag := flag.String("since", "", "Replay events since a duration ago (e.g. 2h) or a timestamp (use with --monitor)")
+	pollFlag := flag.Bool("poll", false, "Poll the log file instead of using file system notifications (e.g. on network filesystems)")
 
 	// Processing command flags
 	processFlag := flag.Bool("process", false, "Process Claude events and generate messenger JSON files")
@@ -101,14 +337,18 @@ func main() {
 	outputDirFlag := flag.String("output-dir", "messenger-output", "Output directory for messenger JSON files")
 	latestFlag := flag.Int("latest", 0, "Process only the latest N events (0 = all events)")
 	generateSamplesFlag := flag.Bool("generate-samples", false, "Generate test samples from real data")
+	retryFailedFlag := flag.Bool("retry-failed", false, "Retry events that previously failed processing (same as 'claudetogo retry-failed')")
 	statsFlag := flag.Bool("stats", false, "Show processing statistics")
+	jsonFlag := flag.Bool("json", false, "Print results of --pending, --status, --stats and --respond as JSON on stdout")
 	processWatchFlag := flag.Bool("watch", false, "Watch for new events and process them continuously")
 	intervalFlag := flag.Duration("interval", 5*time.Second, "Interval for watch mode processing")
+	resumeFlag := flag.Bool("resume", false, "Resume an interrupted --process run from its last checkpoint")
 
 	// Response command flags
 	respondFlag := flag.Bool("respond", false, "Respond to a notification event")
 	sessionFlag := flag.String("session", "", "Session ID for response or status commands")
-	actionFlag := flag.String("action", "", "Action to take (approve, reject)")
+	actionFlag := flag.String("action", "", "Action to take (approve, reject, info or modify)")
+	codeFlag := flag.String("code", "", "Confirmation code for approving a high-risk action")
 	statusFlag := flag.Bool("status", false, "Get session status")
 	pendingFlag := flag.Bool("pending", false, "List pending actions")
 
@@ -120,10 +360,17 @@ func main() {
 	// Configuration command flags
 	configInitFlag := flag.Bool("config-init", false, "Create example messenger configuration file")
 	configShowFlag := flag.Bool("config-show", false, "Show current configuration")
-	configValidateFlag := flag.String("config-validate", "", "Validate messenger configuration file")
-	messengerConfigFlag := flag.String("messenger-config", "", "Path to messenger configuration file")
+	configValidateFlag := flag.String("config-validate", "", "Validate a messenger (YAML) or runtime (JSON) configuration file")
+	configSchemaFlag := flag.String("config-schema", "", "Print the JSON Schema of the messenger or runtime configuration")
+	strictFlag := flag.Bool("strict", false, "Fail on unknown or deprecated config keys instead of warning about them")
+	configMigrateFlag := flag.Bool("config-migrate", false, "Move claudetogo-config.json settings into the YAML configuration")
+	messengerConfigFlag := flag.String("messenger-config", "", "Path to configuration file (same as --config)")
 
 	flag.Parse()
+	if err := logger.SetFormat(*logFormatFlag); err != nil {
+		cliLogger.Error("%v", err)
+		os.Exit(exitUsage)
+	}
 
 	// Show help and exit
 	if *helpFlag {
@@ -131,51 +378,112 @@ func main() {
 		return
 	}
 
+	// Show version and exit
+	if *versionFlag {
+		fmt.Println(version.Get())
+		return
+	}
+
 	// Run setup wizard
 	if *setupFlag {
-		if err := setup.RunWizard(); err != nil {
-			log.Printf("[ERROR] Setup failed: %v", err)
-			os.Exit(1)
+		if err := setup.RunWizard(*dryRunFlag); err != nil {
+			cliLogger.Error("Setup failed: %v", err)
+			os.Exit(exitCode(err))
 		}
 		return
 	}
 
-	// Initialize configuration with defaults
-	runtimeConfig := types.Config{
-		LogFile:      "claude-events.jsonl",
-		PollInterval: 100 * time.Millisecond,
-		Verbose:      false,
+	// One configuration document: --config (or the older --messenger-config), else the
+	// auto-discovered file. A JSON --config is a legacy runtime config.
+	configPath, legacyPath := *configFlag, ""
+	if strings.EqualFold(filepath.Ext(configPath), ".json") {
+		configPath, legacyPath = *messengerConfigFlag, *configFlag
+	} else if configPath == "" {
+		configPath = *messengerConfigFlag
+	}
+	if legacyPath == "" {
+		if _, err := os.Stat(messengerConfig.LegacyConfigFile); err == nil {
+			legacyPath = messengerConfig.LegacyConfigFile
+		}
 	}
 
-	// Load configuration file if specified or default exists
-	var configPath string
-	if *configFlag != "" {
-		configPath = *configFlag
-	} else {
-		// Check for default config file
-		if _, err := os.Stat("claudetogo-config.json"); err == nil {
-			configPath = "claudetogo-config.json"
+	// Unknown and deprecated keys are warnings, or errors with --strict.
+	// --config-validate reports problems itself.
+	messengerConfig.SetStrict(*strictFlag)
+	reportConfigProblems := *configValidateFlag == ""
+	msgConfig := messengerConfig.DefaultMessengerConfig()
+	if path := messengerConfigPath(configPath); path != "" {
+		loaded, err := messengerConfig.LoadMessengerConfig(path)
+		switch {
+		case err == nil:
+			msgConfig = loaded
+		case !reportConfigProblems:
+		case *configFlag != "" || *strictFlag:
+			cliLogger.Error("Failed to load config file '%s': %v", path, err)
+			os.Exit(exitCode(err))
+		default:
+			cliLogger.Warn("Ignoring config file '%s', using defaults: %v", path, err)
+		}
+	}
+	if reportConfigProblems {
+		for _, warning := range msgConfig.Warnings() {
+			cliLogger.Warn("%s (use --strict to make this an error)", warning)
 		}
 	}
 
-	if configPath != "" {
-		configFile, err := config.Load(configPath)
-		if err != nil {
-			log.Printf("[ERROR] Failed to load config file '%s': %v", configPath, err)
-			os.Exit(1)
+	// Settings from an old claudetogo-config.json apply over the monitor section until migrated
+	if legacyPath != "" && !*configMigrateFlag {
+		if err := msgConfig.ApplyLegacyConfig(legacyPath); err != nil {
+			cliLogger.Error("%v", err)
+			os.Exit(exitConfigError)
 		}
+		cliLogger.Info("Loaded configuration from: %s (deprecated, run 'claudetogo --config-migrate' to move it into the YAML config)", legacyPath)
+	}
 
-		if err := config.Apply(configFile, &runtimeConfig); err != nil {
-			log.Printf("[ERROR] Failed to apply config file: %v", err)
-			os.Exit(1)
+	if err := msgConfig.ApplyEnvironmentOverrides(); err != nil {
+		cliLogger.Error("Environment configuration error: %v", err)
+		os.Exit(exitConfigError)
+	}
+	if *logFormatFlag == "" {
+		if err := logger.SetFormat(msgConfig.Service.LogFormat); err != nil {
+			cliLogger.Error("%v", err)
+			os.Exit(exitConfigError)
 		}
+	}
+	if err := logger.SetLevel(msgConfig.Service.LogLevel); err != nil {
+		cliLogger.Error("%v", err)
+		os.Exit(exitConfigError)
+	}
+	if err := logger.SetComponentLevels(msgConfig.Service.LogLevels); err != nil {
+		cliLogger.Error("service.log_levels: %v", err)
+		os.Exit(exitConfigError)
+	}
+	jsonl.SetMaxLineSize(int64(msgConfig.Processing.MaxLineSizeMB) * 1024 * 1024)
+	if err := redact.Configure(msgConfig.Processing.Redact, msgConfig.Processing.RedactPatterns); err != nil {
+		cliLogger.Error("processing.redact_patterns: %v", err)
+		os.Exit(exitConfigError)
+	}
+	if err := risk.Configure(msgConfig.Approvals.ConfirmDangerous, msgConfig.Approvals.DangerousCommands, msgConfig.Approvals.ProtectedPaths); err != nil {
+		cliLogger.Error("approvals: %v", err)
+		os.Exit(exitConfigError)
+	}
 
-		log.Printf("[INFO] Loaded configuration from: %s", configPath)
+	// Initialize the runtime configuration from the monitor section
+	runtimeConfig := types.Config{
+		LogFile:      msgConfig.Monitor.LogFile,
+		PollInterval: msgConfig.Monitor.PollInterval,
+		Verbose:      msgConfig.Monitor.Verbose,
+		UsePolling:   msgConfig.Monitor.UsePolling,
 	}
 
 	// Command line flags override config file settings
-	if flag.Lookup("logfile").Value.String() != flag.Lookup("logfile").DefValue {
-		runtimeConfig.LogFile = *logFileFlag
+	if logFileFlag.isSet {
+		runtimeConfig.LogFile = logFileFlag.values[0]
+		runtimeConfig.LogFiles = logFileFlag.values
+	}
+	// An unquoted glob is expanded by the shell, leaving the extra matches as arguments
+	if *monitorFlag && flag.NArg() > 0 {
+		runtimeConfig.LogFiles = append([]string{runtimeConfig.LogFile}, flag.Args()...)
 	}
 	if flag.Lookup("poll-interval").Value.String() != flag.Lookup("poll-interval").DefValue {
 		runtimeConfig.PollInterval = *pollIntervalFlag
@@ -183,9 +491,41 @@ func main() {
 	if *verboseFlag {
 		runtimeConfig.Verbose = true
 	}
+	if *pollFlag {
+		runtimeConfig.UsePolling = true
+		msgConfig.Monitor.UsePolling = true
+	}
+	if *noColorFlag {
+		runtimeConfig.NoColor = true
+	}
+	runtimeConfig.ShowContext = *showContextFlag
+	runtimeConfig.FromStart = *fromStartFlag
+	if *sinceFlag != "" {
+		since, err := monitor.ParseSince(*sinceFlag, time.Now())
+		if err != nil {
+			cliLogger.Error("%v", err)
+			os.Exit(exitUsage)
+		}
+		runtimeConfig.Since = since
+	}
 
 	// Initialize logger
 	appLogger := logger.New(runtimeConfig.Verbose)
+	// --verbose shows everything, whatever service.log_level says
+	if runtimeConfig.Verbose {
+		logger.SetLevel("debug")
+	}
+
+	// Flags left at their defaults fall back to the configuration (file or environment)
+	if flag.Lookup("events-file").Value.String() == flag.Lookup("events-file").DefValue {
+		*eventsFileFlag = msgConfig.Monitor.LogFile
+	}
+	if flag.Lookup("output-dir").Value.String() == flag.Lookup("output-dir").DefValue && msgConfig.Messenger.OutputDir != "" {
+		*outputDirFlag = msgConfig.Messenger.OutputDir
+	}
+	if flag.Lookup("service-interval").Value.String() == flag.Lookup("service-interval").DefValue && msgConfig.Service.ServiceInterval > 0 {
+		*serviceIntervalFlag = msgConfig.Service.ServiceInterval
+	}
 
 	// Set up graceful shutdown
 	ctx, cancel := setupGracefulShutdown()
@@ -195,80 +535,126 @@ func main() {
 	if *configInitFlag {
 		if err := handleConfigInitCommand(appLogger); err != nil {
 			appLogger.Error("Config init command error: %v", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
+	if *configMigrateFlag {
+		if err := handleConfigMigrateCommand(legacyPath, configPath, appLogger); err != nil {
+			appLogger.Error("Config migrate command error: %v", err)
+			os.Exit(exitCode(err))
 		}
 		return
 	}
 
 	if *configShowFlag {
-		if err := handleConfigShowCommand(*messengerConfigFlag, appLogger); err != nil {
+		if err := handleConfigShowCommand(configPath, legacyPath, appLogger); err != nil {
 			appLogger.Error("Config show command error: %v", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
+	if *configSchemaFlag != "" {
+		if err := handleConfigSchemaCommand(*configSchemaFlag); err != nil {
+			appLogger.Error("Config schema command error: %v", err)
+			os.Exit(exitCode(err))
 		}
 		return
 	}
 
 	if *configValidateFlag != "" {
-		if err := handleConfigValidateCommand(*configValidateFlag, appLogger); err != nil {
+		if err := handleConfigValidateCommand(*configValidateFlag, *strictFlag, appLogger); err != nil {
 			appLogger.Error("Config validate command error: %v", err)
-			os.Exit(1)
+			os.Exit(exitConfigError)
 		}
 		return
 	}
 
 	if *serviceFlag {
-		if err := handleServiceCommand(ctx, *eventsFileFlag, *outputDirFlag, *daemonFlag, *serviceIntervalFlag, appLogger); err != nil {
+		if err := handleServiceCommand(ctx, *eventsFileFlag, *outputDirFlag, *daemonFlag, *serviceIntervalFlag, msgConfig, messengerConfigPath(configPath), appLogger); err != nil {
 			appLogger.Error("Service command error: %v", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 		return
 	}
 
 	if *processFlag {
-		if err := handleProcessCommand(ctx, *eventsFileFlag, *outputDirFlag, *latestFlag, *generateSamplesFlag, *statsFlag, *processWatchFlag, *intervalFlag, appLogger); err != nil {
+		opts := processOptions{
+			EventsFile:      *eventsFileFlag,
+			OutputDir:       *outputDirFlag,
+			Latest:          *latestFlag,
+			GenerateSamples: *generateSamplesFlag,
+			RetryFailed:     *retryFailedFlag,
+			Stats:           *statsFlag,
+			JSON:            *jsonFlag,
+			Watch:           *processWatchFlag,
+			Resume:          *resumeFlag,
+			Interval:        *intervalFlag,
+		}
+		if err := handleProcessCommand(ctx, opts, msgConfig, appLogger); err != nil {
 			appLogger.Error("Process command error: %v", err)
-			os.Exit(1)
+			printJSONError(*jsonFlag, err)
+			os.Exit(exitCode(err))
 		}
 		return
 	}
 
 	if *respondFlag {
-		if err := handleRespondCommand(*sessionFlag, *actionFlag, appLogger); err != nil {
+		if err := handleRespondCommand("messenger-output", *sessionFlag, *actionFlag, "", *codeFlag, formatterOptions(msgConfig), *jsonFlag, appLogger); err != nil {
 			appLogger.Error("Respond command error: %v", err)
-			os.Exit(1)
+			printJSONError(*jsonFlag, err)
+			os.Exit(exitCode(err))
 		}
 		return
 	}
 
 	if *statusFlag {
-		if err := handleStatusCommand(*sessionFlag, appLogger); err != nil {
+		if err := handleStatusCommand(*sessionFlag, formatterOptions(msgConfig), *jsonFlag, appLogger); err != nil {
 			appLogger.Error("Status command error: %v", err)
-			os.Exit(1)
+			printJSONError(*jsonFlag, err)
+			os.Exit(exitCode(err))
 		}
 		return
 	}
 
 	if *pendingFlag {
-		if err := handlePendingCommand(appLogger); err != nil {
+		if err := handlePendingCommand(formatterOptions(msgConfig), *jsonFlag, appLogger); err != nil {
 			appLogger.Error("Pending command error: %v", err)
-			os.Exit(1)
+			printJSONError(*jsonFlag, err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
+	if *monitorFlag && *dashboardFlag {
+		if err := handleDashboardCommand(ctx, runtimeConfig, *outputDirFlag, formatterOptions(msgConfig), appLogger); err != nil && !errors.Is(err, context.Canceled) {
+			appLogger.Error("Dashboard error: %v", err)
+			os.Exit(exitCode(err))
 		}
 		return
 	}
 
 	if *monitorFlag {
 		appLogger.Info("Monitoring Claude events... (Press Ctrl+C to stop)")
-		if err := monitor.Start(ctx, runtimeConfig, appLogger); err != nil && err != context.Canceled {
+		if err := monitor.Start(ctx, runtimeConfig, appLogger); err != nil && !errors.Is(err, context.Canceled) {
 			appLogger.Error("Monitor error: %v", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 		return
 	}
 
 	if *hookFlag {
-		if err := hooks.ProcessFromStdin(runtimeConfig, appLogger); err != nil {
+		forwarder, err := hookForwarder(msgConfig, *forwardFlag, appLogger.Component("hooks"))
+		if err != nil {
+			appLogger.Error("Hook forwarding error: %v", err)
+			os.Exit(exitConfigError)
+		}
+		if err := hooks.ProcessFromStdin(runtimeConfig, forwarder, appLogger.Component("hooks")); err != nil {
 			appLogger.Error("Hook processing error: %v", err)
-			os.Exit(1)
+			// Claude Code reads exit code 2 from a hook as blocking the action; 1 never blocks
+			os.Exit(exitFailure)
 		}
 		return
 	}
@@ -277,32 +663,117 @@ func main() {
 	showHelp()
 }
 
+// hookForwarder returns the forwarder --hook sends events to a remote service with, or nil
+// when neither --forward nor hooks.forward_url is set
+func hookForwarder(msgConfig *messengerConfig.MessengerConfig, forwardURL string, logger *logger.Logger) (*hooks.Forwarder, error) {
+	if forwardURL == "" {
+		forwardURL = msgConfig.Hooks.ForwardURL
+	}
+	if forwardURL == "" {
+		return nil, nil
+	}
+	// The signing secret may live in the keyring
+	if _, err := msgConfig.ApplyKeyringSecrets(); err != nil {
+		logger.Debug("Keyring unavailable: %v", err)
+	}
+	return hooks.NewForwarder(forwardURL, msgConfig.Integration.ResponseAPISecret, msgConfig.Hooks.ForwardToken, msgConfig.Hooks.SpoolDir, logger)
+}
+
+// processOptions holds the --process command's sub-options
+type processOptions struct {
+	EventsFile      string
+	OutputDir       string
+	Latest          int
+	GenerateSamples bool
+	RetryFailed     bool
+	Stats           bool
+	JSON            bool
+	Watch           bool
+	Resume          bool
+	Interval        time.Duration
+}
+
 // handleProcessCommand handles the --process command with all its sub-options
-func handleProcessCommand(ctx context.Context, eventsFile, outputDir string, latest int, generateSamples, stats, watch bool, interval time.Duration, logger *logger.Logger) error {
-	// Create processor
-	eventProcessor := processor.NewEventProcessor(outputDir)
+func handleProcessCommand(ctx context.Context, opts processOptions, msgConfig *messengerConfig.MessengerConfig, logger *logger.Logger) error {
+	eventProcessor, err := newEventProcessor(opts.OutputDir, msgConfig)
+	if err != nil {
+		return err
+	}
 
 	// Handle stats command
-	if stats {
-		return handleStatsCommand(eventsFile, eventProcessor, logger)
+	if opts.Stats {
+		statusFile := service.StatusFilePath(msgConfig.Service.StatusFile, opts.OutputDir)
+		return handleStatsCommand(opts.EventsFile, eventProcessor, statusFile, opts.JSON, logger)
 	}
 
 	// Handle generate samples command
-	if generateSamples {
-		return handleGenerateSamplesCommand(eventsFile, eventProcessor, logger)
+	if opts.GenerateSamples {
+		return handleGenerateSamplesCommand(opts.EventsFile, eventProcessor, logger)
+	}
+
+	// Handle retry of quarantined events
+	if opts.RetryFailed {
+		return handleRetryFailedCommand(eventProcessor, logger)
 	}
 
 	// Handle watch mode
-	if watch {
-		return handleWatchCommand(ctx, eventsFile, eventProcessor, interval, logger)
+	if opts.Watch {
+		return handleWatchCommand(ctx, opts.EventsFile, eventProcessor, opts.Interval, logger)
 	}
 
 	// Handle regular processing (all events or latest N)
-	return handleRegularProcessing(eventsFile, eventProcessor, latest, logger)
+	return handleRegularProcessing(ctx, opts.EventsFile, eventProcessor, opts.Latest, opts.Resume, logger)
+}
+
+// newEventProcessor creates a processor writing to outputDir with the messenger settings
+func newEventProcessor(outputDir string, msgConfig *messengerConfig.MessengerConfig) (*processor.EventProcessor, error) {
+	eventProcessor := processor.NewEventProcessor(outputDir)
+	eventProcessor.SetFileFormat(msgConfig.Messenger.FileFormat, int64(msgConfig.Messenger.RotateSizeMB)*1024*1024)
+	if err := eventProcessor.SetLayout(msgConfig.Messenger.Layout); err != nil {
+		return nil, err
+	}
+	eventProcessor.SetFormatterOptions(formatterOptions(msgConfig))
+	eventProcessor.SetDebounce(msgConfig.Processing.Debounce)
+	return eventProcessor, nil
+}
+
+// formatterOptions maps the messenger formatting settings onto formatter options
+func formatterOptions(msgConfig *messengerConfig.MessengerConfig) formatter.Options {
+	return formatter.Options{
+		TemplatesDir:      msgConfig.Formatting.TemplatesDir,
+		IncludeEmojis:     msgConfig.Formatting.IncludeEmojis,
+		MaxMessageLength:  msgConfig.Formatting.MaxMessageLength,
+		MaxContentPreview: msgConfig.Formatting.MaxContentPreview,
+		TimestampFormat:   msgConfig.Formatting.TimestampFormat,
+		UseRelativeTime:   msgConfig.Formatting.UseRelativeTime,
+		ContextMessages:   msgConfig.Formatting.ContextMessages,
+		Projects:          projectNames(msgConfig),
+		ProjectInTitle:    msgConfig.Formatting.ProjectInTitle,
+		Users:             formatterUsers(msgConfig),
+	}
+}
+
+// projectNames maps the registered project directories to their names
+func projectNames(msgConfig *messengerConfig.MessengerConfig) map[string]string {
+	names := make(map[string]string, len(msgConfig.Projects))
+	for _, project := range msgConfig.Projects {
+		names[project.Path] = project.Name
+	}
+	return names
+}
+
+// formatterUsers lists the users whose sessions are recognized by git author or directory
+func formatterUsers(msgConfig *messengerConfig.MessengerConfig) []formatter.User {
+	users := make([]formatter.User, 0, len(msgConfig.Users))
+	for _, user := range msgConfig.Users {
+		users = append(users, formatter.User{Name: user.Name, GitAuthors: user.GitAuthors, Paths: user.Paths})
+	}
+	return users
 }
 
-// handleStatsCommand shows processing statistics
-func handleStatsCommand(eventsFile string, eventProcessor *processor.EventProcessor, logger *logger.Logger) error {
+// handleStatsCommand shows processing statistics, and the running service's metrics when
+// there is one
+func handleStatsCommand(eventsFile string, eventProcessor *processor.EventProcessor, statusFile string, asJSON bool, logger *logger.Logger) error {
 	logger.Info("Getting processing statistics...")
 	
 	stats, err := eventProcessor.GetProcessingStats(eventsFile)
@@ -310,6 +781,18 @@ func handleStatsCommand(eventsFile string, eventProcessor *processor.EventProces
 		return fmt.Errorf("failed to get processing stats: %w", err)
 	}
 
+	var metrics *service.MetricsSnapshot
+	if status, err := service.ReadStatus(statusFile); err == nil && status.IsRunning() {
+		metrics = status.Metrics
+	}
+
+	if asJSON {
+		return printJSON(struct {
+			*processor.ProcessingStats
+			ServiceMetrics *service.MetricsSnapshot `json:"service_metrics,omitempty"`
+		}{stats, metrics})
+	}
+
 	fmt.Printf("\n📊 Processing Statistics for %s\n", eventsFile)
 	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 	fmt.Printf("Total Events:         %d\n", stats.TotalEvents)
@@ -317,6 +800,14 @@ func handleStatsCommand(eventsFile string, eventProcessor *processor.EventProces
 	fmt.Printf("Notification Events:  %d\n", stats.NotificationEvents)
 	fmt.Printf("Processable Events:   %d\n", stats.ProcessableEvents)
 	fmt.Printf("Missing Transcripts:  %d\n", stats.MissingTranscripts)
+	printStatsBreakdown("By Tool", stats.EventsByTool)
+	printStatsBreakdown("By Task Status", stats.TaskStatuses)
+	printStatsBreakdown("By Day", stats.EventsByDay)
+	printStatsBreakdown("By Session", stats.EventsBySession)
+	if metrics != nil {
+		fmt.Printf("\nService metrics:\n")
+		printServiceMetrics(metrics)
+	}
 	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 
 	if stats.ProcessableEvents > 0 {
@@ -328,6 +819,67 @@ func handleStatsCommand(eventsFile string, eventProcessor *processor.EventProces
 	return nil
 }
 
+// printStatsBreakdown prints one stats breakdown section with keys in sorted order
+func printStatsBreakdown(title string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Printf("\n%s:\n", title)
+	for _, key := range keys {
+		fmt.Printf("  %-20s %d\n", key, counts[key])
+	}
+}
+
+// handleRetryFailedCommand reprocesses quarantined events
+func handleRetryFailedCommand(eventProcessor *processor.EventProcessor, logger *logger.Logger) error {
+	logger.Info("Retrying quarantined events...")
+
+	result, err := eventProcessor.RetryFailedEvents()
+	if err != nil {
+		return fmt.Errorf("failed to retry quarantined events: %w", err)
+	}
+
+	if result.TotalAttempted == 0 {
+		fmt.Printf("✅ No quarantined events to retry\n")
+		return nil
+	}
+
+	fmt.Printf("\n🔄 Retried %d quarantined event(s)\n", result.TotalAttempted)
+	fmt.Printf("✅ Recovered: %d\n", len(result.OutputFiles))
+	for _, file := range result.OutputFiles {
+		fmt.Printf("  - %s\n", file)
+	}
+
+	if len(result.StillFailing) > 0 {
+		fmt.Printf("❌ Still failing: %d\n", len(result.StillFailing))
+		for _, entry := range result.StillFailing {
+			fmt.Printf("  - %s %s (session %s, attempts %d): %s\n",
+				entry.Event.HookEventName, entry.Event.Timestamp, entry.Event.SessionID, entry.Attempts, entry.Error)
+		}
+		fmt.Printf("📁 Quarantine file: %s\n", eventProcessor.QuarantinePath())
+	}
+
+	return nil
+}
+
+// printQuarantineNotice points the user at quarantined events, if there are any
+func printQuarantineNotice(eventProcessor *processor.EventProcessor) {
+	entries, err := eventProcessor.LoadQuarantinedEvents()
+	if err != nil || len(entries) == 0 {
+		return
+	}
+
+	fmt.Printf("\n⚠️  %d event(s) failed and were quarantined: %s\n", len(entries), eventProcessor.QuarantinePath())
+	fmt.Printf("🔄 Retry with: claudetogo retry-failed\n")
+}
+
 // handleGenerateSamplesCommand generates test samples
 func handleGenerateSamplesCommand(eventsFile string, eventProcessor *processor.EventProcessor, logger *logger.Logger) error {
 	logger.Info("Generating test samples from real data...")
@@ -350,13 +902,16 @@ func handleWatchCommand(ctx context.Context, eventsFile string, eventProcessor *
 	fmt.Printf("⏱️  Interval: %v\n", interval)
 	fmt.Println()
 
-	// Keep track of last processed event count
-	lastEventCount := 0
-	
-	// Get initial event count
-	if stats, err := eventProcessor.GetProcessingStats(eventsFile); err == nil {
-		lastEventCount = stats.TotalEvents
-		logger.Debug("Initial event count: %d", lastEventCount)
+	// Only what is appended since the last tick is read on each one
+	// Events logged since watch mode last ran are handled first
+	tail := processor.NewTail(eventsFile)
+	count, outputFiles, err := eventProcessor.CatchUp(tail)
+	if err != nil {
+		logger.Warn("Could not catch up with the events file: %v", err)
+	}
+	logger.Debug("Initial event count: %d", count)
+	for _, file := range outputFiles {
+		fmt.Printf("📝 Generated: %s\n", file)
 	}
 
 	ticker := time.NewTicker(interval)
@@ -369,35 +924,30 @@ func handleWatchCommand(ctx context.Context, eventsFile string, eventProcessor *
 			return nil
 		case <-ticker.C:
 			// Check for new events
-			stats, err := eventProcessor.GetProcessingStats(eventsFile)
+			events, reset, err := eventProcessor.ReadTail(tail)
+			if reset {
+				logger.Warn("Events file was truncated, rotated or replaced, reading it from the start")
+			}
 			if err != nil {
-				logger.Debug("Failed to get stats during watch: %v", err)
+				logger.Debug("Failed to read new events during watch: %v", err)
 				continue
 			}
 
-			if stats.TotalEvents > lastEventCount {
-				newEvents := stats.TotalEvents - lastEventCount
-				logger.Info("Found %d new event(s), processing...", newEvents)
-				
-				// Process the latest new events
-				outputFiles, err := eventProcessor.ProcessLatestEvents(eventsFile, newEvents)
-				if err != nil {
-					logger.Error("Failed to process new events: %v", err)
-					continue
-				}
-
-				for _, file := range outputFiles {
+			if len(events) > 0 {
+				logger.Info("Found %d new event(s), processing...", len(events))
+				for _, file := range eventProcessor.ProcessNewEvents(events) {
 					fmt.Printf("📝 Generated: %s\n", file)
 				}
-				
-				lastEventCount = stats.TotalEvents
+				if err := eventProcessor.SaveTail(tail); err != nil {
+					logger.Debug("Could not save events file position: %v", err)
+				}
 			}
 		}
 	}
 }
 
 // handleRegularProcessing handles regular event processing (all or latest N)
-func handleRegularProcessing(eventsFile string, eventProcessor *processor.EventProcessor, latest int, logger *logger.Logger) error {
+func handleRegularProcessing(ctx context.Context, eventsFile string, eventProcessor *processor.EventProcessor, latest int, resume bool, logger *logger.Logger) error {
 	var outputFiles []string
 	var err error
 
@@ -405,8 +955,22 @@ func handleRegularProcessing(eventsFile string, eventProcessor *processor.EventP
 		logger.Info("Processing latest %d events...", latest)
 		outputFiles, err = eventProcessor.ProcessLatestEvents(eventsFile, latest)
 	} else {
+		if !resume {
+			if checkpoint, _ := eventProcessor.LoadCheckpoint(eventsFile); checkpoint != nil {
+				fmt.Printf("⚠️  Found checkpoint at event %d of %d, starting over (use --resume to continue)\n", checkpoint.ProcessedCount, checkpoint.TotalEvents)
+			}
+		}
 		logger.Info("Processing all events...")
-		outputFiles, err = eventProcessor.ProcessEventsFromFile(eventsFile)
+		outputFiles, err = eventProcessor.ProcessEventsWithCheckpoint(ctx, eventsFile, resume)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		fmt.Printf("\n🛑 Processing interrupted after %d file(s)\n", len(outputFiles))
+		if checkpoint, _ := eventProcessor.LoadCheckpoint(eventsFile); checkpoint != nil {
+			fmt.Printf("📍 Checkpoint saved at event %d of %d\n", checkpoint.ProcessedCount, checkpoint.TotalEvents)
+		}
+		fmt.Printf("🔄 Continue with: claudetogo --process --resume\n")
+		return nil
 	}
 
 	if err != nil {
@@ -424,11 +988,13 @@ func handleRegularProcessing(eventsFile string, eventProcessor *processor.EventP
 		}
 	}
 
+	printQuarantineNotice(eventProcessor)
+
 	return nil
 }
 
 // handleRespondCommand handles user responses to notification events
-func handleRespondCommand(sessionID, action string, logger *logger.Logger) error {
+func handleRespondCommand(outputDir, sessionID, action, reason, code string, displayOptions formatter.Options, asJSON bool, logger *logger.Logger) error {
 	if sessionID == "" {
 		return fmt.Errorf("session ID is required for respond command")
 	}
@@ -439,14 +1005,36 @@ func handleRespondCommand(sessionID, action string, logger *logger.Logger) error
 	logger.Info("Processing response for session %s with action: %s", sessionID, action)
 	
 	// Create response handler
-	responseHandler := responder.NewResponseHandler("messenger-output", logger)
-	
+	responseHandler := responder.NewResponseHandler(outputDir, logger)
+	responseHandler.SetDisplayOptions(displayOptions)
+	responseHandler.SetQuiet(asJSON)
+
+	sessionID, err := resolveSessionID(responseHandler, sessionID, !asJSON)
+	if err != nil {
+		return fmt.Errorf("failed to handle response: %w", err)
+	}
+
+	if asJSON {
+		if err := responseHandler.HandleConfirmedResponse(sessionID, action, reason, code); err != nil {
+			return fmt.Errorf("failed to handle response: %w", err)
+		}
+		result := respondResult{Status: "ok", SessionID: sessionID, Action: action}
+		if action == "info" {
+			status, err := responseHandler.GetSessionStatus(sessionID)
+			if err != nil {
+				return fmt.Errorf("failed to get session status: %w", err)
+			}
+			result.Session = status
+		}
+		return printJSON(result)
+	}
+
 	// Process the response
 	fmt.Printf("🔄 Processing response...\n")
 	fmt.Printf("📋 Session:  %s\n", sessionID)
 	fmt.Printf("⚡ Action:   %s\n", action)
 	
-	if err := responseHandler.HandleResponse(sessionID, action); err != nil {
+	if err := responseHandler.HandleConfirmedResponse(sessionID, action, reason, code); err != nil {
 		return fmt.Errorf("failed to handle response: %w", err)
 	}
 
@@ -465,8 +1053,31 @@ func handleRespondCommand(sessionID, action string, logger *logger.Logger) error
 	return nil
 }
 
+// respondResult is the --json output of --respond
+type respondResult struct {
+	Status    string                   `json:"status"`
+	SessionID string                   `json:"session_id"`
+	Action    string                   `json:"action"`
+	Session   *responder.SessionStatus `json:"session,omitempty"` // For the info action
+}
+
+// printJSON writes a command result to stdout as indented JSON
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// printJSONError reports a failed command on stdout in --json mode, so a caller parsing
+// the output gets an answer either way
+func printJSONError(asJSON bool, err error) {
+	if asJSON {
+		printJSON(map[string]string{"error": err.Error()})
+	}
+}
+
 // handleStatusCommand shows status for a specific session
-func handleStatusCommand(sessionID string, logger *logger.Logger) error {
+func handleStatusCommand(sessionID string, displayOptions formatter.Options, asJSON bool, logger *logger.Logger) error {
 	if sessionID == "" {
 		return fmt.Errorf("session ID is required for status command")
 	}
@@ -475,21 +1086,38 @@ func handleStatusCommand(sessionID string, logger *logger.Logger) error {
 	
 	// Create response handler
 	responseHandler := responder.NewResponseHandler("messenger-output", logger)
-	
+
+	sessionID, err := resolveSessionID(responseHandler, sessionID, !asJSON)
+	if err != nil {
+		return fmt.Errorf("failed to get session status: %w", err)
+	}
+
 	// Get session status
 	status, err := responseHandler.GetSessionStatus(sessionID)
 	if err != nil {
 		return fmt.Errorf("failed to get session status: %w", err)
 	}
+	if asJSON {
+		return printJSON(status)
+	}
 
 	fmt.Printf("📋 Session Status: %s\n", sessionID)
 	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 	fmt.Printf("🔍 Status:      %s\n", status.Status)
-	fmt.Printf("📅 Created:     %s\n", status.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("📅 Created:     %s\n", displayOptions.FormatTime(status.CreatedAt))
 	
 	if status.LastAction != "" {
 		fmt.Printf("⚡ Last Action: %s\n", status.LastAction)
 	}
+	if status.LastReason != "" {
+		fmt.Printf("💬 Reason:      %s\n", status.LastReason)
+	}
+	if len(status.Tags) > 0 {
+		fmt.Printf("🏷️  Tags:        %s\n", strings.Join(status.Tags, ", "))
+	}
+	for _, note := range status.Notes {
+		fmt.Printf("🗒️  Note:        %s (%s)\n", note.Text, displayOptions.FormatTime(note.At))
+	}
 	
 	if status.Context != nil && len(status.Context) > 0 {
 		fmt.Printf("📝 Context:\n")
@@ -504,7 +1132,7 @@ func handleStatusCommand(sessionID string, logger *logger.Logger) error {
 }
 
 // handlePendingCommand lists all pending actions
-func handlePendingCommand(logger *logger.Logger) error {
+func handlePendingCommand(displayOptions formatter.Options, asJSON bool, logger *logger.Logger) error {
 	logger.Info("Listing pending actions...")
 	
 	// Create response handler
@@ -515,6 +1143,12 @@ func handlePendingCommand(logger *logger.Logger) error {
 	if err != nil {
 		return fmt.Errorf("failed to get pending actions: %w", err)
 	}
+	if asJSON {
+		if pendingActions == nil {
+			pendingActions = []*responder.PendingAction{}
+		}
+		return printJSON(pendingActions)
+	}
 
 	fmt.Printf("📋 Pending Actions\n")
 	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
@@ -526,11 +1160,24 @@ func handlePendingCommand(logger *logger.Logger) error {
 
 	for i, action := range pendingActions {
 		fmt.Printf("%d. 📝 %s\n", i+1, action.Title)
-		fmt.Printf("   Session: %s\n", action.SessionID)
-		fmt.Printf("   Created: %s\n", action.CreatedAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("   Session: %s\n", session.WithLabel(action.SessionID, action.SessionLabel))
+		if len(action.SessionTags) > 0 {
+			fmt.Printf("   Tags:    %s\n", strings.Join(action.SessionTags, ", "))
+		}
+		if action.SessionNote != "" {
+			fmt.Printf("   Note:    %s\n", action.SessionNote)
+		}
+		fmt.Printf("   Created: %s\n", displayOptions.FormatTime(action.CreatedAt))
 		fmt.Printf("   Message: %s\n", action.Message)
+		if action.Risk != "" {
+			fmt.Printf("   ⚠️  High risk: %s\n", action.Risk)
+		}
 		fmt.Printf("   Commands:\n")
-		fmt.Printf("     Approve: claudetogo --respond --session %s --action approve\n", action.SessionID)
+		if action.Risk != "" {
+			fmt.Printf("     Approve: claudetogo --respond --session %s --action approve --code CODE\n", action.SessionID)
+		} else {
+			fmt.Printf("     Approve: claudetogo --respond --session %s --action approve\n", action.SessionID)
+		}
 		fmt.Printf("     Reject:  claudetogo --respond --session %s --action reject\n", action.SessionID)
 		fmt.Printf("     Info:    claudetogo --status --session %s\n", action.SessionID)
 		
@@ -545,8 +1192,17 @@ func handlePendingCommand(logger *logger.Logger) error {
 	return nil
 }
 
+// handleDashboardCommand runs the interactive monitor dashboard
+func handleDashboardCommand(ctx context.Context, runtimeConfig types.Config, outputDir string, displayOptions formatter.Options, logger *logger.Logger) error {
+	rh := responder.NewResponseHandler(outputDir, logger)
+	rh.SetDisplayOptions(displayOptions)
+
+	dashboard := monitor.NewDashboard(runtimeConfig, rh, displayOptions, logger)
+	return dashboard.Run(ctx)
+}
+
 // handleServiceCommand runs the background service mode
-func handleServiceCommand(ctx context.Context, eventsFile, outputDir string, daemon bool, interval time.Duration, logger *logger.Logger) error {
+func handleServiceCommand(ctx context.Context, eventsFile, outputDir string, daemon bool, interval time.Duration, msgConfig *messengerConfig.MessengerConfig, configPath string, logger *logger.Logger) error {
 	logger.Info("Starting ClaudeToGo service mode...")
 	
 	if daemon {
@@ -558,20 +1214,279 @@ func handleServiceCommand(ctx context.Context, eventsFile, outputDir string, dae
 
 	fmt.Printf("📁 Events file: %s\n", eventsFile)
 	fmt.Printf("📂 Output dir:  %s\n", outputDir)
-	fmt.Printf("⏱️  Interval:   %v\n", interval)
+	if msgConfig.Monitor.UsePolling {
+		fmt.Printf("⏱️  Interval:   %v\n", interval)
+	} else {
+		fmt.Printf("⏱️  Interval:   file system notifications (poll fallback: %v)\n", interval)
+	}
+
+	// On a server, the log may belong with the system's own in syslog or the journal
+	if target := msgConfig.Service.LogTarget; target != "" && target != "stderr" {
+		closeTarget, err := openLogTarget(msgConfig.Service)
+		if err != nil {
+			return err
+		}
+		defer closeTarget()
+		fmt.Printf("📝 Logging to:  %s\n", target)
+	}
+
+	// Detached from a terminal, stderr goes nowhere, so keep the log in a file as well
+	if msgConfig.Service.LogFile != "" {
+		closeLog, err := openServiceLog(msgConfig.Service)
+		if err != nil {
+			return err
+		}
+		defer closeLog()
+		fmt.Printf("📝 Log file:    %s\n", msgConfig.Service.LogFile)
+	}
+
+	// Tokens left out of the config files may be stored in the OS keyring
+	if applied, err := msgConfig.ApplyKeyringSecrets(); err != nil {
+		logger.Error("Failed to read secrets from the OS keyring: %v", err)
+	} else if len(applied) > 0 {
+		fmt.Printf("🔐 Keyring:     %s\n", strings.Join(applied, ", "))
+	}
+
+	// Create service config
+	serviceConfig := buildServiceConfig(eventsFile, outputDir, interval, msgConfig, logger)
+
+	// Apply edits to the messenger config (and the files it layers) without a restart
+	if configPath != "" {
+		current := msgConfig
+		serviceConfig.ConfigFiles = watchedConfigFiles(configPath)
+		serviceConfig.Reload = func() (service.WatcherConfig, []string, error) {
+			next, err := messengerConfig.LoadMessengerConfig(configPath)
+			if err != nil {
+				return service.WatcherConfig{}, nil, err
+			}
+			for _, warning := range next.Warnings() {
+				logger.Warn("Config warning: %s", warning)
+			}
+			if err := next.ApplyEnvironmentOverrides(); err != nil {
+				return service.WatcherConfig{}, nil, err
+			}
+			if _, err := next.ApplyKeyringSecrets(); err != nil {
+				logger.Error("Failed to read secrets from the OS keyring: %v", err)
+			}
+			if err := next.Validate(); err != nil {
+				return service.WatcherConfig{}, nil, fmt.Errorf("invalid configuration: %w", err)
+			}
+			if err := setComponentLogLevels(next.Service); err != nil {
+				return service.WatcherConfig{}, nil, err
+			}
+			if err := redact.Configure(next.Processing.Redact, next.Processing.RedactPatterns); err != nil {
+				return service.WatcherConfig{}, nil, err
+			}
+			if err := risk.Configure(next.Approvals.ConfirmDangerous, next.Approvals.DangerousCommands, next.Approvals.ProtectedPaths); err != nil {
+				return service.WatcherConfig{}, nil, err
+			}
+
+			changes := messengerConfig.Diff(current, next)
+			current = next
+			reloaded := buildServiceConfig(eventsFile, outputDir, interval, next, logger)
+			reloaded.ConfigFiles = watchedConfigFiles(configPath)
+			return reloaded, changes, nil
+		}
+		fmt.Printf("♻️  Config:      %s (reloaded on change)\n", strings.Join(serviceConfig.ConfigFiles, ", "))
+	}
+
+	fmt.Printf("🧩 Components:  %s\n", strings.Join(serviceConfig.Components.Names(), ", "))
+	if reporting := serviceConfig.ErrorReporting; reporting.SentryDSN != "" || reporting.WebhookURL != "" {
+		var destinations []string
+		if reporting.SentryDSN != "" {
+			destinations = append(destinations, "Sentry")
+		}
+		if reporting.WebhookURL != "" {
+			destinations = append(destinations, "error webhook")
+		}
+		fmt.Printf("🚨 Errors:      reported to %s\n", strings.Join(destinations, " and "))
+	}
 	fmt.Printf("🔄 Press Ctrl+C to stop\n")
 	fmt.Println()
 
-	// Create service config
-	serviceConfig := service.WatcherConfig{
+	// Run the service
+	return service.ServiceMode(ctx, serviceConfig)
+}
+
+// openServiceLog sends log output to the service log file, rotated at its maximum size, and
+// to stderr as well when that is a terminal. The returned function restores stderr.
+func openServiceLog(settings messengerConfig.ServiceSettings) (func(), error) {
+	logFile, err := logger.OpenFile(settings.LogFile, int64(settings.LogMaxSizeMB)*1024*1024, settings.LogMaxBackups)
+	if err != nil {
+		return nil, err
+	}
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		log.SetOutput(io.MultiWriter(os.Stderr, logFile))
+	} else {
+		log.SetOutput(logFile)
+	}
+	return func() {
+		log.SetOutput(os.Stderr)
+		logFile.Close()
+	}, nil
+}
+
+// openLogTarget sends log output to syslog or the journal, tagged "claudetogo"
+func openLogTarget(settings messengerConfig.ServiceSettings) (func(), error) {
+	return logger.OpenTarget(settings.LogTarget, "claudetogo")
+}
+
+// setComponentLogLevels applies service.log_levels; the service command's logger parameter
+// hides the logger package, as with openServiceLog
+func setComponentLogLevels(settings messengerConfig.ServiceSettings) error {
+	return logger.SetComponentLevels(settings.LogLevels)
+}
+
+// buildServiceConfig maps the messenger configuration onto the service
+func buildServiceConfig(eventsFile, outputDir string, interval time.Duration, msgConfig *messengerConfig.MessengerConfig, logger *logger.Logger) service.WatcherConfig {
+	return service.WatcherConfig{
 		EventsFile:   eventsFile,
 		OutputDir:    outputDir,
+		FileFormat:   msgConfig.Messenger.FileFormat,
+		RotateSize:   int64(msgConfig.Messenger.RotateSizeMB) * 1024 * 1024,
+		Layout:       msgConfig.Messenger.Layout,
+		MaxLineSize:  int64(msgConfig.Processing.MaxLineSizeMB) * 1024 * 1024,
+		Debounce:     msgConfig.Processing.Debounce,
+		Formatting:   formatterOptions(msgConfig),
 		PollInterval: interval,
-		Logger:       logger,
+		UsePolling:   msgConfig.Monitor.UsePolling,
+		StatusFile:   msgConfig.Service.StatusFile,
+		PidFile:      msgConfig.Service.PidFile,
+		LogFile:      msgConfig.Service.LogFile,
+		LogTarget:    msgConfig.Service.LogTarget,
+		AutoRestart:  msgConfig.Service.AutoRestart,
+		Components:   serviceComponents(msgConfig),
+		ErrorReporting: service.ErrorReportingConfig{
+			SentryDSN:        msgConfig.Service.ErrorReporting.SentryDSN,
+			WebhookURL:       msgConfig.Service.ErrorReporting.WebhookURL,
+			FailureThreshold: msgConfig.Service.ErrorReporting.FailureThreshold,
+		},
+		ResponseAuth: service.ResponseAuth{
+			Secret:              msgConfig.Integration.ResponseAPISecret,
+			SlackSigningSecret:  msgConfig.Integration.SlackSigningSecret,
+			TelegramSecretToken: msgConfig.Integration.TelegramSecretToken,
+			APITokens:           msgConfig.Service.APITokens,
+			ChatRoles:           msgConfig.Service.ChatRoles,
+		},
+		ChatAllowlist: service.ChatAllowlist{
+			Telegram:   msgConfig.Integration.TelegramAllowedUsers,
+			Slack:      msgConfig.Integration.SlackAllowedUsers,
+			SlackToken: msgConfig.Integration.SlackToken,
+		},
+		Logger: logger,
 	}
+}
 
-	// Run the service
-	return service.ServiceMode(ctx, serviceConfig)
+// watchedConfigFiles lists the layers of a messenger config, plus its local override even
+// before that file exists, so creating it is picked up too
+func watchedConfigFiles(configPath string) []string {
+	files, err := messengerConfig.MessengerConfigLayers(configPath)
+	if err != nil {
+		files = []string{configPath}
+	}
+	local := messengerConfig.LocalConfigPath(configPath)
+	for _, file := range files {
+		if file == local {
+			return files
+		}
+	}
+	return append(files, local)
+}
+
+// messengerConfigPath returns the config file in use: the --config (or --messenger-config)
+// path, or the auto-discovered file; empty when running on defaults
+func messengerConfigPath(flagPath string) string {
+	if flagPath != "" {
+		return flagPath
+	}
+	return messengerConfig.FindMessengerConfig()
+}
+
+// deliveryConfig maps the integration settings onto message delivery
+func deliveryConfig(msgConfig *messengerConfig.MessengerConfig) service.DeliveryConfig {
+	return service.DeliveryConfig{
+		WebhookURL:     msgConfig.Integration.WebhookURL,
+		Headers:        msgConfig.Integration.CustomHeaders,
+		WebhookKey:     service.ExpandHome(msgConfig.Integration.WebhookPublicKey),
+		TelegramToken:  msgConfig.Integration.TelegramToken,
+		TelegramChatID: msgConfig.Integration.TelegramChatID,
+		SlackToken:     msgConfig.Integration.SlackToken,
+		SlackChannel:   msgConfig.Integration.SlackChannel,
+		RetryAttempts:  msgConfig.Integration.RetryAttempts,
+		RetryInterval:  msgConfig.Integration.RetryInterval,
+		Timeout:        msgConfig.Integration.TimeoutDuration,
+		RateLimits: map[string]int{
+			"telegram": msgConfig.Integration.TelegramRateLimit,
+			"slack":    msgConfig.Integration.SlackRateLimit,
+			"webhook":  msgConfig.Integration.WebhookRateLimit,
+		},
+		FloodThreshold: msgConfig.Integration.FloodThreshold,
+		FloodWindow:    msgConfig.Integration.FloodWindow,
+		BatchWindow:    msgConfig.Integration.BatchWindow,
+		BatchMaxSize:   msgConfig.Integration.BatchMaxSize,
+		Projects:       projectRoutes(msgConfig),
+		Users:          userRoutes(msgConfig),
+		Escalation: service.UserRoute{
+			TelegramChatID: msgConfig.Integration.EscalationTelegramChatID,
+			SlackChannel:   msgConfig.Integration.EscalationSlackChannel,
+		},
+	}
+}
+
+// projectRoutes maps the registered projects to their delivery routes
+func projectRoutes(msgConfig *messengerConfig.MessengerConfig) map[string]service.ProjectRoute {
+	routes := make(map[string]service.ProjectRoute)
+	for _, project := range msgConfig.Projects {
+		if len(project.Channels) == 0 && project.WebhookURL == "" && project.TelegramChatID == "" && project.SlackChannel == "" {
+			continue
+		}
+		routes[project.Name] = service.ProjectRoute{
+			Channels:       project.Channels,
+			WebhookURL:     project.WebhookURL,
+			TelegramChatID: project.TelegramChatID,
+			SlackChannel:   project.SlackChannel,
+		}
+	}
+	return routes
+}
+
+// userRoutes maps the users to the chats their sessions' messages go to
+func userRoutes(msgConfig *messengerConfig.MessengerConfig) map[string]service.UserRoute {
+	routes := make(map[string]service.UserRoute, len(msgConfig.Users))
+	for _, user := range msgConfig.Users {
+		routes[user.Name] = service.UserRoute{TelegramChatID: user.TelegramChatID, SlackChannel: user.SlackChannel}
+	}
+	return routes
+}
+
+// serviceComponents maps the configured pipeline components onto the service
+func serviceComponents(msgConfig *messengerConfig.MessengerConfig) service.Components {
+	settings := msgConfig.Service.Components
+	components := service.Components{
+		Watcher:      settings.Watcher,
+		IngestSocket: settings.IngestSocket,
+		ResponseAPI:  settings.ResponseAPI,
+		Profiling:    settings.Pprof,
+		ResponseAPITLS: service.TLSConfig{
+			CertFile:     msgConfig.Service.APITLS.CertFile,
+			KeyFile:      msgConfig.Service.APITLS.KeyFile,
+			ClientCAFile: msgConfig.Service.APITLS.ClientCAFile,
+			SelfSigned:   msgConfig.Service.APITLS.SelfSigned,
+		},
+	}
+	if settings.Delivery {
+		delivery := deliveryConfig(msgConfig)
+		components.Delivery = &delivery
+	}
+	if msgConfig.Service.HeartbeatInterval > 0 {
+		components.Heartbeat = &service.HeartbeatConfig{
+			Interval:       msgConfig.Service.HeartbeatInterval,
+			PingURL:        msgConfig.Service.HeartbeatURL,
+			StallThreshold: msgConfig.Service.StallThreshold,
+			TranscriptsDir: msgConfig.Service.TranscriptsDir,
+		}
+	}
+	return components
 }
 
 // handleConfigInitCommand creates an example messenger configuration file
@@ -606,7 +1521,7 @@ func handleConfigInitCommand(logger *logger.Logger) error {
 }
 
 // handleConfigShowCommand shows the current configuration
-func handleConfigShowCommand(messengerConfigPath string, logger *logger.Logger) error {
+func handleConfigShowCommand(messengerConfigPath, legacyPath string, logger *logger.Logger) error {
 	logger.Info("Loading and displaying current configuration...")
 
 	var config *messengerConfig.MessengerConfig
@@ -631,8 +1546,22 @@ func handleConfigShowCommand(messengerConfigPath string, logger *logger.Logger)
 		}
 	}
 
+	if legacyPath != "" {
+		if err := config.ApplyLegacyConfig(legacyPath); err != nil {
+			return err
+		}
+		fmt.Printf("📁 Legacy settings from: %s (run 'claudetogo --config-migrate')\n\n", legacyPath)
+	}
+
 	// Apply environment overrides
-	config.ApplyEnvironmentOverrides()
+	if err := config.ApplyEnvironmentOverrides(); err != nil {
+		return err
+	}
+	if applied, err := config.ApplyKeyringSecrets(); err != nil {
+		fmt.Printf("⚠️  Could not read the OS keyring: %v\n\n", err)
+	} else if len(applied) > 0 {
+		fmt.Printf("🔐 From the OS keyring: %s\n\n", strings.Join(applied, ", "))
+	}
 
 	// Show configuration summary
 	fmt.Println(config.Summary())
@@ -640,8 +1569,32 @@ func handleConfigShowCommand(messengerConfigPath string, logger *logger.Logger)
 	return nil
 }
 
+// handleConfigMigrateCommand moves a legacy claudetogo-config.json into the YAML configuration
+func handleConfigMigrateCommand(legacyPath, configPath string, logger *logger.Logger) error {
+	if legacyPath == "" {
+		fmt.Printf("✅ Nothing to migrate: no %s found\n", messengerConfig.LegacyConfigFile)
+		return nil
+	}
+	if configPath == "" {
+		if configPath = messengerConfig.FindMessengerConfig(); configPath == "" {
+			configPath = messengerConfig.DefaultConfigFile
+		}
+	}
+
+	logger.Info("Migrating %s into %s", legacyPath, configPath)
+	backupPath, err := messengerConfig.MigrateLegacyConfig(legacyPath, configPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Moved the settings of %s into the monitor section of %s\n", legacyPath, configPath)
+	fmt.Printf("💾 The old file was kept as %s\n", backupPath)
+	fmt.Printf("🔍 Validate with: claudetogo --config-validate %s\n", configPath)
+	return nil
+}
+
 // handleConfigValidateCommand validates a messenger configuration file
-func handleConfigValidateCommand(configPath string, logger *logger.Logger) error {
+func handleConfigValidateCommand(configPath string, strict bool, logger *logger.Logger) error {
 	logger.Info("Validating configuration file: %s", configPath)
 
 	fmt.Printf("🔍 Validating configuration file: %s\n", configPath)
@@ -652,8 +1605,76 @@ func handleConfigValidateCommand(configPath string, logger *logger.Logger) error
 		return fmt.Errorf("config file not found: %s", configPath)
 	}
 
+	// JSON files are runtime configs, everything else is messenger YAML with optional layers
+	runtimeFile := strings.EqualFold(filepath.Ext(configPath), ".json")
+	schema := messengerConfig.MessengerSchema()
+	files := []string{configPath}
+	if runtimeFile {
+		schema = messengerConfig.RuntimeSchema()
+	} else {
+		layers, err := messengerConfig.MessengerConfigLayers(configPath)
+		if err != nil {
+			fmt.Printf("❌ Configuration validation failed:\n")
+			fmt.Printf("   %v\n", err)
+			return err
+		}
+		files = layers
+		if len(layers) > 1 {
+			fmt.Printf("📚 Layers: %s\n", strings.Join(layers, " → "))
+		}
+	}
+
+	// Check structure first: unknown keys and wrong types, with line numbers.
+	// Unknown and deprecated keys only fail validation with --strict.
+	issueCount, warningCount := 0, 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		issues, err := messengerConfig.ValidateDocument(data, schema)
+		if err != nil {
+			fmt.Printf("❌ Configuration validation failed:\n")
+			fmt.Printf("   %s: %v\n", file, err)
+			return err
+		}
+		for _, issue := range issues {
+			if issue.Warning && !strict {
+				fmt.Printf("⚠️  %s:%d:%d: %s: %s\n", file, issue.Line, issue.Column, issue.Path, issue.Message)
+				warningCount++
+				continue
+			}
+			if issueCount == 0 {
+				fmt.Printf("❌ Configuration validation failed:\n")
+			}
+			fmt.Printf("   %s:%d:%d: %s: %s\n", file, issue.Line, issue.Column, issue.Path, issue.Message)
+			issueCount++
+		}
+	}
+	if issueCount > 0 {
+		return fmt.Errorf("%d validation issue(s) in %s", issueCount, configPath)
+	}
+	if warningCount > 0 {
+		fmt.Printf("   %d warning(s); run with --strict to treat them as errors\n", warningCount)
+	}
+
+	if runtimeFile {
+		configFile, err := config.Load(configPath)
+		if err == nil {
+			err = config.Apply(configFile, &types.Config{})
+		}
+		if err != nil {
+			fmt.Printf("❌ Configuration validation failed:\n")
+			fmt.Printf("   %v\n", err)
+			return err
+		}
+		fmt.Printf("✅ Configuration file is valid!\n")
+		return nil
+	}
+
 	// Load and validate the configuration
-	config, err := messengerConfig.LoadMessengerConfig(configPath)
+	loaded, err := messengerConfig.LoadMessengerConfig(configPath)
 	if err != nil {
 		fmt.Printf("❌ Configuration validation failed:\n")
 		fmt.Printf("   %v\n", err)
@@ -663,7 +1684,27 @@ func handleConfigValidateCommand(configPath string, logger *logger.Logger) error
 	fmt.Printf("✅ Configuration file is valid!\n\n")
 	
 	// Show summary of loaded config
-	fmt.Println(config.Summary())
+	fmt.Println(loaded.Summary())
 	
 	return nil
 }
+
+// handleConfigSchemaCommand prints the JSON Schema of the messenger or runtime configuration
+func handleConfigSchemaCommand(kind string) error {
+	var schema *messengerConfig.Schema
+	switch kind {
+	case "messenger":
+		schema = messengerConfig.MessengerSchema()
+	case "runtime":
+		schema = messengerConfig.RuntimeSchema()
+	default:
+		return fmt.Errorf("unknown schema %q (use messenger or runtime)", kind)
+	}
+
+	data, err := messengerConfig.MarshalSchema(schema)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
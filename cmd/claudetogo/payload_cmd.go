@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/channels"
+)
+
+// payloadUsage describes the `claudetogo payload` subcommands
+const payloadUsage = `Usage: claudetogo payload <command> [options]
+
+Commands:
+  keygen [--out DIR]           Create an X25519 key pair for encrypted webhook payloads:
+                               DIR/webhook-key.pem (private, keep it with the receiver) and
+                               DIR/webhook-key.pub.pem (set as integrations.webhook_public_key)
+  decrypt --key FILE [FILE|-]  Decrypt an encrypted webhook payload (default: stdin) and
+                               print the message JSON
+
+With integrations.webhook_public_key set, webhook_url receives
+{"encrypted": "claudetogo-v1", "alg": ..., "key_id": ..., "nonce": ..., "ciphertext": ...}
+instead of the message, so relays in between can't read tool inputs or transcript text.
+`
+
+// runPayloadSubcommand handles `claudetogo payload <command>`
+func runPayloadSubcommand(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		fmt.Print(payloadUsage)
+		return nil
+	}
+
+	switch args[0] {
+	case "keygen":
+		return handlePayloadKeygen(args[1:])
+	case "decrypt":
+		return handlePayloadDecrypt(args[1:])
+	default:
+		fmt.Print(payloadUsage)
+		return usageError(fmt.Errorf("unknown payload command: %s", args[0]))
+	}
+}
+
+// handlePayloadKeygen writes a new key pair, refusing to overwrite an existing one
+func handlePayloadKeygen(args []string) error {
+	fs := flag.NewFlagSet("payload keygen", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(payloadUsage) }
+	outDir := fs.String("out", ".", "Directory for the key files")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	privateFile := filepath.Join(*outDir, "webhook-key.pem")
+	publicFile := filepath.Join(*outDir, "webhook-key.pub.pem")
+	for _, path := range []string{privateFile, publicFile} {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+
+	private, public, err := channels.GenerateKeyPair()
+	if err != nil {
+		return fmt.Errorf("failed to generate key pair: %w", err)
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(privateFile, private, 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(publicFile, public, 0644); err != nil {
+		return err
+	}
+
+	key, err := channels.LoadPublicKey(publicFile)
+	if err != nil {
+		return err
+	}
+	keyID, err := channels.KeyID(key)
+	if err != nil {
+		return err
+	}
+	absPublic, _ := filepath.Abs(publicFile)
+	fmt.Printf("🔑 Private key: %s (keep it with the webhook receiver)\n", privateFile)
+	fmt.Printf("📤 Public key:  %s (key ID %s)\n", publicFile, keyID)
+	fmt.Println("\nAdd to the messenger config:")
+	fmt.Printf("  integrations:\n    webhook_public_key: %q\n", absPublic)
+	return nil
+}
+
+// handlePayloadDecrypt prints the message inside an encrypted payload
+func handlePayloadDecrypt(args []string) error {
+	fs := flag.NewFlagSet("payload decrypt", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(payloadUsage) }
+	keyFile := fs.String("key", "", "Private key file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if *keyFile == "" || fs.NArg() > 1 {
+		fmt.Print(payloadUsage)
+		return usageError(fmt.Errorf("payload decrypt requires --key and at most one file"))
+	}
+
+	key, err := channels.LoadPrivateKey(*keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load private key: %w", err)
+	}
+
+	var input io.Reader = os.Stdin
+	if path := fs.Arg(0); path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+	var payload channels.EncryptedPayload
+	if err := json.NewDecoder(input).Decode(&payload); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+
+	plaintext, err := channels.Decrypt(&payload, key)
+	if err != nil {
+		return err
+	}
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, plaintext, "", "  ") != nil {
+		pretty.Reset()
+		pretty.Write(plaintext)
+	}
+	fmt.Println(pretty.String())
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/redact"
+)
+
+// redactCheckUsage describes `claudetogo redact-check`
+const redactCheckUsage = `Usage: claudetogo redact-check [options] FILE...
+
+Scans files (or stdin, as -) with the redaction patterns — the built-in ones and
+processing.redact_patterns — and lists every secret they would mask, to check the
+patterns before relying on them. Exits with 1 when a secret is found (except with --show).
+
+Options:
+  --config PATH   Config file whose processing.redact_patterns are added (default:
+                  the auto-discovered file)
+  --show          Print the files with the secrets masked instead of listing them
+
+Examples:
+  claudetogo redact-check claude-events.jsonl
+  claudetogo redact-check --show messenger-output/messenger-notification-1fa8811f.json
+  echo "password=hunter22" | claudetogo redact-check -
+`
+
+// runRedactCheckSubcommand handles `claudetogo redact-check`
+func runRedactCheckSubcommand(args []string) error {
+	fs := flag.NewFlagSet("redact-check", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(redactCheckUsage) }
+	configPath := fs.String("config", "", "Config file")
+	show := fs.Bool("show", false, "Print the files with secrets masked")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() == 0 {
+		fmt.Print(redactCheckUsage)
+		return usageError(fmt.Errorf("redact-check requires at least one file"))
+	}
+
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath))
+	redactor, err := redact.New(msgConfig.Processing.RedactPatterns)
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("processing.redact_patterns: %w", err))
+	}
+
+	found := 0
+	for _, path := range fs.Args() {
+		n, err := checkRedaction(redactor, path, *show)
+		if err != nil {
+			return err
+		}
+		found += n
+	}
+
+	if !*show {
+		if found == 0 {
+			fmt.Println("✅ No secrets found")
+		} else {
+			fmt.Printf("🔒 %d secret(s) would be redacted\n", found)
+		}
+		if !msgConfig.Processing.Redact {
+			fmt.Println("⚠️  processing.redact is off: these secrets are currently logged and stored as they are")
+		}
+	}
+	if found > 0 && !*show {
+		return withExitCode(exitFailure, fmt.Errorf("%d secret(s) found", found))
+	}
+	return nil
+}
+
+// checkRedaction lists (or with show, prints masked) the secrets in one file, line by line,
+// returning how many were found
+func checkRedaction(redactor *redact.Redactor, path string, show bool) (int, error) {
+	var input io.Reader = os.Stdin
+	name := "stdin"
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer file.Close()
+		input, name = file, path
+	}
+
+	// Private keys span lines, so the whole file is scanned at once and matches mapped to lines
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	text := string(data)
+	matches := redactor.Find(text)
+
+	if show {
+		fmt.Print(redactor.String(text))
+		return len(matches), nil
+	}
+
+	for _, match := range matches {
+		before := text[:match.Start]
+		line, column := strings.Count(before, "\n")+1, match.Start-strings.LastIndex(before, "\n")
+		fmt.Printf("%s:%d:%d  %-15s %s\n", name, line, column, match.Pattern, preview(text[match.Start:match.End]))
+	}
+	return len(matches), nil
+}
+
+// preview shows enough of a secret to recognise it without printing it
+func preview(secret string) string {
+	secret = strings.SplitN(secret, "\n", 2)[0]
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", 8) + fmt.Sprintf(" (%d chars)", len(secret))
+}
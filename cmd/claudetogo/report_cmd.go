@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/monitor"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/report"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/service"
+)
+
+// reportUsage describes `claudetogo report`
+const reportUsage = `Usage: claudetogo report [options]
+
+Aggregates the sessions active over a period: how often each tool was called, how many
+permission requests were approved and rejected, how long requests waited for a response,
+the busiest projects and the tokens used. Sessions come from the session registry, events
+and requests from the events file, tools and tokens from the sessions' transcripts.
+
+Options:
+  --since VALUE       Start of the period: a duration like 7d or 12h, an RFC3339 timestamp
+                      or YYYY-MM-DD [HH:MM] (default: 7d)
+  --format FORMAT     text (default), json or md
+  --output PATH       Write the report to a file instead of stdout
+  --events-file PATH  Hook events file (default: monitor.log_file)
+  --output-dir DIR    Directory with the messenger files (default: messenger.output_dir)
+  --config PATH       Config file (default: the auto-discovered file); its
+                      service.transcripts_dir is searched for transcripts
+
+Examples:
+  claudetogo report --since 7d
+  claudetogo report --since 2025-01-01 --format md --output report.md
+`
+
+// runReportSubcommand handles `claudetogo report`
+func runReportSubcommand(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(reportUsage) }
+	since := fs.String("since", "7d", "Start of the period")
+	format := fs.String("format", "text", "Output format: text, json or md")
+	outputPath := fs.String("output", "", "File to write the report to")
+	eventsFile := fs.String("events-file", "", "Hook events file")
+	outputDir := fs.String("output-dir", "", "Directory with the messenger files")
+	configPath := fs.String("config", "", "Config file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+	if *format != "text" && *format != "json" && *format != "md" {
+		return usageError(fmt.Errorf("unknown format %q (use text, json or md)", *format))
+	}
+	now := time.Now()
+	start, err := monitor.ParseSince(*since, now)
+	if err != nil {
+		return usageError(err)
+	}
+
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath))
+	if *outputDir == "" {
+		*outputDir = msgConfig.Messenger.OutputDir
+	}
+	if *eventsFile == "" {
+		*eventsFile = msgConfig.Monitor.LogFile
+	}
+	rep, err := report.Build(report.Sources{
+		OutputDir:      *outputDir,
+		EventsFile:     *eventsFile,
+		TranscriptsDir: service.ExpandHome(msgConfig.Service.TranscriptsDir),
+	}, start, now)
+	if err != nil {
+		return err
+	}
+
+	var output []byte
+	switch *format {
+	case "json":
+		if output, err = json.MarshalIndent(rep, "", "  "); err != nil {
+			return err
+		}
+		output = append(output, '\n')
+	case "md":
+		output = []byte(rep.Markdown())
+	default:
+		output = []byte(reportText(rep, formatterOptions(msgConfig).FormatTime))
+	}
+
+	if *outputPath == "" {
+		_, err = os.Stdout.Write(output)
+		return err
+	}
+	if err := os.WriteFile(*outputPath, output, 0644); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "📝 Wrote report of %d session(s) to %s\n", rep.Sessions, *outputPath)
+	return nil
+}
+
+// reportText renders a report for the terminal
+func reportText(rep *report.Report, formatTime func(time.Time) string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📊 ClaudeToGo Report: %s to %s\n", formatTime(rep.Since), formatTime(rep.Until))
+	fmt.Fprintln(&b, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	if rep.Sessions == 0 {
+		fmt.Fprintln(&b, "📭 No session activity in this period")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "🗂️  Sessions:  %d   Events: %d   Permission requests: %d\n", rep.Sessions, rep.Events, rep.Requests)
+	fmt.Fprintf(&b, "✅ Approved:  %d (%s)\n", rep.Approvals, report.Percent(rep.ApprovalRate))
+	fmt.Fprintf(&b, "❌ Rejected:  %d (%s)\n", rep.Rejections, report.Percent(rep.RejectionRate))
+	if rep.ApprovalLatencyAvg != "" {
+		fmt.Fprintf(&b, "⏱️  Latency:   %s average, %s longest\n", rep.ApprovalLatencyAvg, rep.ApprovalLatencyMax)
+	}
+	fmt.Fprintf(&b, "🔢 Tokens:    %d in, %d cache, %d out (from %d transcript(s))\n",
+		rep.Tokens.Input, rep.Tokens.Cache, rep.Tokens.Output, rep.Transcripts)
+
+	fmt.Fprintf(&b, "\n📁 Busiest projects\n")
+	for _, p := range rep.Projects {
+		fmt.Fprintf(&b, "   %-24s %3d session(s)  %4d events  %3d requests  %3d ✅ %3d ❌  %d tokens\n",
+			p.Name, p.Sessions, p.Events, p.Requests, p.Approvals, p.Rejections, p.Tokens)
+	}
+	if len(rep.Tools) > 0 {
+		fmt.Fprintf(&b, "\n🔧 Tool usage\n")
+		for _, tool := range rep.Tools {
+			fmt.Fprintf(&b, "   %-24s %5d\n", tool.Name, tool.Calls)
+		}
+	}
+	return b.String()
+}
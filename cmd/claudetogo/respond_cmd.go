@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/jsonl"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/responder"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+)
+
+// respondUsage describes `claudetogo respond`
+const respondUsage = `Usage: claudetogo respond --session ID --action ACTION [--reason TEXT] [--code CODE] [options]
+       claudetogo respond --stdin [options]
+
+Answers a pending action, like 'claudetogo --respond'. With --stdin, reads one decision per
+line as JSON and answers each as it arrives, so a chat bridge or script can submit many
+decisions through one process:
+
+  {"session": "6f48ee8a", "action": "approve"}
+  {"session": "ffc70d26", "action": "reject", "reason": "Don't touch the lock file"}
+  {"session": "0b3c9e51", "action": "approve", "code": "482913"}
+
+One result is printed per decision. The command fails when any decision failed.
+
+Options:
+  --session ID        Session to answer (a prefix is enough)
+  --action ACTION     approve, reject, info or modify (review a Write or Edit)
+  --reason TEXT       Why, recorded with the response
+  --code CODE         Confirmation code from the notification, to approve a high-risk action
+  --stdin             Read decisions from stdin as JSON lines
+  --json              Print results as JSON (one object per line with --stdin)
+  --output-dir DIR    Directory with the messenger files (default: messenger.output_dir)
+  --config PATH       Config file (default: the auto-discovered file)
+`
+
+// batchDecision is one line read by `claudetogo respond --stdin`
+type batchDecision struct {
+	Session string `json:"session"`
+	Action  string `json:"action"`
+	Reason  string `json:"reason,omitempty"`
+	Code    string `json:"code,omitempty"` // Confirmation code of a high-risk action
+}
+
+// batchResult is the outcome of one decision read by `claudetogo respond --stdin`
+type batchResult struct {
+	Line      int    `json:"line"`
+	Status    string `json:"status"` // "ok" or "error"
+	SessionID string `json:"session_id,omitempty"`
+	Action    string `json:"action,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// runRespondSubcommand handles `claudetogo respond`
+func runRespondSubcommand(args []string) error {
+	fs := flag.NewFlagSet("respond", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(respondUsage) }
+	sessionID := fs.String("session", "", "Session to answer")
+	action := fs.String("action", "", "Action: approve, reject, info or modify")
+	reason := fs.String("reason", "", "Why, recorded with the response")
+	code := fs.String("code", "", "Confirmation code of a high-risk action")
+	fromStdin := fs.Bool("stdin", false, "Read decisions from stdin as JSON lines")
+	asJSON := fs.Bool("json", false, "Print as JSON")
+	outputDir := fs.String("output-dir", "", "Directory with the messenger files")
+	configPath := fs.String("config", "", "Config file")
+	fs.StringVar(configPath, "messenger-config", "", "Config file (same as --config)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+	if *fromStdin && (*sessionID != "" || *action != "" || *reason != "" || *code != "") {
+		return usageError(fmt.Errorf("--stdin reads the session, action, reason and code from stdin; don't combine it with --session, --action, --reason or --code"))
+	}
+	if !*fromStdin && (*sessionID == "" || *action == "") {
+		fmt.Print(respondUsage)
+		return usageError(fmt.Errorf("respond requires --session and --action, or --stdin"))
+	}
+
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath))
+	if *outputDir == "" {
+		*outputDir = msgConfig.Messenger.OutputDir
+	}
+	if !*fromStdin {
+		err := handleRespondCommand(*outputDir, *sessionID, *action, *reason, *code, formatterOptions(msgConfig), *asJSON, logger.New(false))
+		if err != nil {
+			printJSONError(*asJSON, err)
+		}
+		return err
+	}
+
+	rh := responder.NewResponseHandler(*outputDir, logger.New(false))
+	rh.SetDisplayOptions(formatterOptions(msgConfig))
+	rh.SetQuiet(true)
+	return respondBatch(rh, os.Stdin, *asJSON)
+}
+
+// respondBatch answers the decisions read from r, one JSON object per line, reporting each
+// as soon as it is handled
+func respondBatch(rh *responder.ResponseHandler, r io.Reader, asJSON bool) error {
+	reader := jsonl.NewReader(r)
+	total, failed := 0, 0
+	for {
+		line, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		var lineTooLong *jsonl.LineTooLongError
+		if err != nil && !errors.As(err, &lineTooLong) {
+			return fmt.Errorf("failed to read decisions: %w", err)
+		}
+
+		total++
+		result := batchResult{Line: reader.Line()}
+		if err == nil {
+			result = respondDecision(rh, result.Line, line)
+		} else {
+			result.Status, result.Error = "error", err.Error()
+		}
+		if result.Status != "ok" {
+			failed++
+		}
+		printBatchResult(result, asJSON)
+	}
+
+	if !asJSON {
+		fmt.Printf("📊 %d decision(s), %d failed\n", total, failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d decisions failed", failed, total)
+	}
+	return nil
+}
+
+// respondDecision parses and answers one decision
+func respondDecision(rh *responder.ResponseHandler, lineNumber int, line []byte) batchResult {
+	result := batchResult{Line: lineNumber, Status: "error"}
+	var decision batchDecision
+	if err := json.Unmarshal(line, &decision); err != nil {
+		result.Error = fmt.Sprintf("invalid decision: %v", err)
+		return result
+	}
+	result.SessionID, result.Action = decision.Session, decision.Action
+	if decision.Session == "" || decision.Action == "" {
+		result.Error = `a decision needs "session" and "action"`
+		return result
+	}
+
+	// Stdin carries the decisions, so an ambiguous prefix can't be asked about
+	sessionID, err := resolveSessionID(rh, decision.Session, false)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.SessionID = sessionID
+	if err := rh.HandleConfirmedResponse(sessionID, decision.Action, decision.Reason, decision.Code); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = "ok"
+	return result
+}
+
+// printBatchResult reports the outcome of one decision
+func printBatchResult(result batchResult, asJSON bool) {
+	if asJSON {
+		data, _ := json.Marshal(result)
+		fmt.Println(string(data))
+		return
+	}
+	if result.Status == "ok" {
+		fmt.Printf("✅ Line %d: %s %s\n", result.Line, result.Action, session.Short(result.SessionID))
+		return
+	}
+	fmt.Printf("❌ Line %d: %s\n", result.Line, result.Error)
+}
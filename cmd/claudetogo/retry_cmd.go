@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+)
+
+// retryFailedUsage describes `claudetogo retry-failed`
+const retryFailedUsage = `Usage: claudetogo retry-failed [options]
+
+Processes the events that failed and were quarantined again. Events that succeed now are
+written out and leave the quarantine; the rest stay there with their latest error and
+attempt count. The same as 'claudetogo --process --retry-failed'.
+
+Options:
+  --output-dir DIR    Directory with the quarantine (default: messenger.output_dir)
+  --config PATH       Config file (default: the auto-discovered file)
+`
+
+// runRetryFailedSubcommand handles `claudetogo retry-failed`
+func runRetryFailedSubcommand(args []string) error {
+	fs := flag.NewFlagSet("retry-failed", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(retryFailedUsage) }
+	outputDir := fs.String("output-dir", "", "Directory with the quarantine")
+	configPath := fs.String("config", "", "Config file")
+	fs.StringVar(configPath, "messenger-config", "", "Config file (same as --config)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath))
+	if *outputDir == "" {
+		*outputDir = msgConfig.Messenger.OutputDir
+	}
+	eventProcessor, err := newEventProcessor(*outputDir, msgConfig)
+	if err != nil {
+		return err
+	}
+	return handleRetryFailedCommand(eventProcessor, logger.New(false))
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/responder"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/risk"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+)
+
+// reviewPreviewLines is how many lines of a message are shown before asking what to do
+const reviewPreviewLines = 25
+
+// reviewUsage describes `claudetogo review`
+const reviewUsage = `Usage: claudetogo review [options]
+
+Lists the pending actions and lets you pick one by number. The picked action is shown
+with its message, including the command or diff Claude wants to run or apply, and can
+be approved, rejected or shown in full with its context and attachments. The list is
+shown again until nothing is pending or you press Enter.
+
+Options:
+  --output-dir DIR    Directory with the messenger files (default: messenger.output_dir)
+  --config PATH       Config file (default: the auto-discovered file)
+
+Keys:
+  a  approve    r  reject    d  details (full message, context, attachments)
+  s  skip back to the list   q  quit
+`
+
+// runReviewSubcommand handles `claudetogo review`
+func runReviewSubcommand(args []string) error {
+	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(reviewUsage) }
+	outputDir := fs.String("output-dir", "", "Directory with the messenger files")
+	configPath := fs.String("config", "", "Config file")
+	fs.StringVar(configPath, "messenger-config", "", "Config file (same as --config)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath))
+	if *outputDir == "" {
+		*outputDir = msgConfig.Messenger.OutputDir
+	}
+	rh := responder.NewResponseHandler(*outputDir, logger.New(false))
+	rh.SetDisplayOptions(formatterOptions(msgConfig))
+	review := &reviewSession{responder: rh, display: formatterOptions(msgConfig), input: bufio.NewScanner(os.Stdin)}
+	return review.run()
+}
+
+// reviewSession walks the user through the pending actions
+type reviewSession struct {
+	responder *responder.ResponseHandler
+	display   formatter.Options
+	input     *bufio.Scanner
+	approved  int
+	rejected  int
+}
+
+// run lists the pending actions until none are left or the user quits
+func (r *reviewSession) run() error {
+	defer r.printTally()
+	for {
+		pending, err := r.responder.ListPendingActions()
+		if err != nil {
+			return fmt.Errorf("failed to get pending actions: %w", err)
+		}
+		if len(pending) == 0 {
+			fmt.Println("✅ No pending actions")
+			return nil
+		}
+		sort.Slice(pending, func(i, j int) bool { return pending[i].CreatedAt.Before(pending[j].CreatedAt) })
+
+		fmt.Printf("📋 Pending Actions (%d)\n", len(pending))
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		for i, action := range pending {
+			fmt.Printf("  [%d] %s  %s  %s\n", i+1, session.WithLabel(session.Short(action.SessionID), action.SessionLabel),
+				r.display.FormatTime(action.CreatedAt), action.Title)
+			fmt.Printf("      %s\n", firstMessageLine(action.Message))
+		}
+		fmt.Println()
+
+		answer, ok := r.ask(fmt.Sprintf("Review which action? [1-%d, Enter to quit]: ", len(pending)))
+		if !ok || answer == "" || answer == "q" {
+			return nil
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(pending) {
+			fmt.Printf("⚠️  No action number %s\n\n", answer)
+			continue
+		}
+		if quit := r.reviewAction(pending[n-1]); quit {
+			return nil
+		}
+		fmt.Println()
+	}
+}
+
+// reviewAction shows one pending action and carries out what the user chooses for it.
+// It reports whether the user asked to quit.
+func (r *reviewSession) reviewAction(action *responder.PendingAction) bool {
+	fmt.Println()
+	fmt.Printf("📝 %s\n", action.Title)
+	fmt.Printf("   Session: %s\n", session.WithLabel(action.SessionID, action.SessionLabel))
+	if len(action.SessionTags) > 0 {
+		fmt.Printf("   Tags:    %s\n", strings.Join(action.SessionTags, ", "))
+	}
+	if action.SessionNote != "" {
+		fmt.Printf("   Note:    %s\n", action.SessionNote)
+	}
+	fmt.Printf("   Created: %s\n", r.display.FormatTime(action.CreatedAt))
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	lines := strings.Split(strings.TrimRight(action.Message, "\n"), "\n")
+	for i, line := range lines {
+		if i == reviewPreviewLines {
+			fmt.Printf("… %d more line(s), press d for details\n", len(lines)-i)
+			break
+		}
+		fmt.Println(line)
+	}
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	for {
+		answer, ok := r.ask("[a]pprove, [r]eject, [d]etails, [s]kip, [q]uit: ")
+		if !ok {
+			return true
+		}
+		switch strings.ToLower(answer) {
+		case "a", "approve":
+			r.respond(action, "approve")
+			return false
+		case "r", "reject":
+			r.respond(action, "reject")
+			return false
+		case "d", "details":
+			r.printDetails(action)
+		case "s", "skip", "":
+			return false
+		case "q", "quit":
+			return true
+		default:
+			fmt.Printf("⚠️  Unknown choice %q\n", answer)
+		}
+	}
+}
+
+// respond records the user's decision for an action
+func (r *reviewSession) respond(action *responder.PendingAction, decision string) {
+	if err := r.responder.HandleResponse(action.SessionID, decision); err != nil {
+		fmt.Printf("❌ Failed to %s %s: %v\n", decision, session.Short(action.SessionID), err)
+		return
+	}
+	if decision == "approve" {
+		r.approved++
+		fmt.Printf("✅ Approved %s\n", session.Short(action.SessionID))
+	} else {
+		r.rejected++
+		fmt.Printf("❌ Rejected %s\n", session.Short(action.SessionID))
+	}
+}
+
+// printDetails shows the whole message of an action with its context and attachments
+func (r *reviewSession) printDetails(action *responder.PendingAction) {
+	message, err := r.responder.PendingMessage(action)
+	if err != nil {
+		fmt.Printf("❌ Failed to load %s: %v\n", action.MessengerFile, err)
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(message.Message)
+	if context := risk.WithoutCodes(message.Context); len(context) > 0 {
+		fmt.Println("\nContext:")
+		keys := make([]string, 0, len(context))
+		for key := range context {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("  %s: %v\n", key, context[key])
+		}
+	}
+	for _, attachment := range message.Attachments {
+		fmt.Printf("\n📎 %s (%s, %d bytes) %s\n", attachment.Name, attachment.Description, attachment.Size, attachment.Path)
+		// Diffs are the point of reviewing an edit, so they are shown in full
+		if attachment.MimeType != "text/x-diff" {
+			continue
+		}
+		content, err := os.ReadFile(attachment.Path)
+		if err != nil {
+			fmt.Printf("   ⚠️  %v\n", err)
+			continue
+		}
+		fmt.Println(strings.TrimRight(string(content), "\n"))
+	}
+	fmt.Printf("\n📁 File: %s\n\n", action.MessengerFile)
+}
+
+// ask prompts for one line of input, returning false at the end of input
+func (r *reviewSession) ask(prompt string) (string, bool) {
+	fmt.Print(prompt)
+	if !r.input.Scan() {
+		fmt.Println()
+		return "", false
+	}
+	return strings.TrimSpace(r.input.Text()), true
+}
+
+// printTally reports what was decided in this review
+func (r *reviewSession) printTally() {
+	if r.approved+r.rejected > 0 {
+		fmt.Printf("📊 Approved %d, rejected %d\n", r.approved, r.rejected)
+	}
+}
+
+// firstMessageLine returns the first non-empty line of a message, shortened for a list
+func firstMessageLine(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if len([]rune(line)) > 100 {
+				line = string([]rune(line)[:100]) + "..."
+			}
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/secrets"
+)
+
+// secretUsage describes the `claudetogo secret` subcommands
+const secretUsage = `Usage: claudetogo secret <command> [name]
+
+Commands:
+  set <name>      Store a secret in the OS keyring (prompts, or reads one line from stdin)
+  get <name>      Print a stored secret
+  delete <name>   Remove a secret from the keyring
+  list            Show which secrets are stored
+
+Names:
+  slack_token, telegram_token, webhook_url,
+  response_api_secret, slack_signing_secret, telegram_secret_token,
+  forward_token (hooks), sentry_dsn (service.error_reporting)
+
+Secrets are kept in the macOS Keychain, the Secret Service (GNOME Keyring/KWallet) on
+Linux, or the Windows Credential Manager. They fill the matching setting when it is left
+empty in the messenger config and environment.
+`
+
+// runSecretSubcommand handles `claudetogo secret <command>`
+func runSecretSubcommand(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		fmt.Print(secretUsage)
+		return nil
+	}
+
+	command := args[0]
+	if command == "list" {
+		return handleSecretList()
+	}
+	if len(args) != 2 {
+		fmt.Print(secretUsage)
+		return usageError(fmt.Errorf("secret %s requires exactly one name", command))
+	}
+	name := args[1]
+
+	switch command {
+	case "set":
+		value, err := readSecretValue(name)
+		if err != nil {
+			return err
+		}
+		if err := secrets.Set(name, value); err != nil {
+			return err
+		}
+		fmt.Printf("🔐 Stored %s in the OS keyring\n", name)
+		return nil
+	case "get":
+		value, err := secrets.Get(name)
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	case "delete":
+		if err := secrets.Delete(name); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Removed %s from the OS keyring\n", name)
+		return nil
+	default:
+		fmt.Print(secretUsage)
+		return usageError(fmt.Errorf("unknown secret command: %s", command))
+	}
+}
+
+// handleSecretList shows which secrets are stored without printing them
+func handleSecretList() error {
+	fmt.Println("🔐 Secrets in the OS keyring:")
+	for _, name := range secrets.Names {
+		_, err := secrets.Get(name)
+		switch {
+		case err == nil:
+			fmt.Printf("   ✅ %s\n", name)
+		case errors.Is(err, secrets.ErrNotFound):
+			fmt.Printf("   ➖ %s (not set)\n", name)
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// readSecretValue prompts for a secret without echoing it, or reads it from piped stdin
+func readSecretValue(name string) (string, error) {
+	stdinFd := int(os.Stdin.Fd())
+	if term.IsTerminal(stdinFd) {
+		fmt.Printf("Enter %s: ", name)
+		value, err := term.ReadPassword(stdinFd)
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret: %w", err)
+		}
+		return strings.TrimSpace(string(value)), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read secret from stdin: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/responder"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/service"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+)
+
+// serviceUsage describes the `claudetogo service` subcommands
+const serviceUsage = `Usage: claudetogo service <command> [options]
+
+Commands:
+  install --systemd   Install a user-level systemd unit running claudetogo --service
+  uninstall           Stop, disable and remove the systemd unit
+  enable              Start the service automatically at login/boot
+  start               Start the service now
+  stop                Stop the service
+  restart             Restart the service
+  status              Show uptime, last processed event, backlog, failures and pending actions
+
+Install options:
+  --events-file PATH        Events file to watch (default "claude-events.jsonl")
+  --output-dir PATH         Output directory for messenger files (default "messenger-output")
+  --service-interval DUR    Service poll interval (default 2s)
+  --messenger-config PATH   Messenger configuration file
+
+Status options:
+  --output-dir PATH         Output directory the service writes to (default "messenger-output")
+  --messenger-config PATH   Messenger configuration file (for service.status_file)
+  --json                    Print the status as JSON
+`
+
+// runServiceSubcommand handles `claudetogo service <command>`
+func runServiceSubcommand(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		fmt.Print(serviceUsage)
+		return nil
+	}
+
+	switch command := args[0]; command {
+	case "install":
+		return handleServiceInstall(args[1:])
+	case "uninstall":
+		if err := service.UninstallSystemdUnit(); err != nil {
+			return err
+		}
+		fmt.Println("✅ ClaudeToGo service uninstalled")
+		return nil
+	case "enable":
+		return service.Systemctl("enable", service.SystemdUnitName)
+	case "start", "stop", "restart":
+		return service.Systemctl(command, service.SystemdUnitName)
+	case "status":
+		return handleServiceStatus(args[1:])
+	default:
+		fmt.Print(serviceUsage)
+		return usageError(fmt.Errorf("unknown service command: %s", command))
+	}
+}
+
+// handleServiceInstall handles `claudetogo service install`
+func handleServiceInstall(args []string) error {
+	fs := flag.NewFlagSet("service install", flag.ContinueOnError)
+	systemd := fs.Bool("systemd", true, "Install as a user-level systemd unit")
+	eventsFile := fs.String("events-file", "claude-events.jsonl", "Events file to watch")
+	outputDir := fs.String("output-dir", "messenger-output", "Output directory for messenger files")
+	interval := fs.Duration("service-interval", 2*time.Second, "Service poll interval")
+	messengerConfigPath := fs.String("messenger-config", "", "Messenger configuration file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	if !*systemd {
+		return fmt.Errorf("systemd is currently the only supported service manager")
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate claudetogo binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(execPath); err == nil {
+		execPath = resolved
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	// The unit runs from workingDir, but absolute paths keep it correct if that ever changes
+	opts := service.SystemdUnitOptions{
+		ExecPath:    execPath,
+		WorkingDir:  workingDir,
+		EventsFile:  absPath(*eventsFile),
+		OutputDir:   absPath(*outputDir),
+		Interval:    *interval,
+		Environment: claudeToGoEnvironment(),
+	}
+	if *messengerConfigPath != "" {
+		opts.MessengerConfig = absPath(*messengerConfigPath)
+	}
+
+	unitPath, err := service.InstallSystemdUnit(opts)
+	if err != nil {
+		if unitPath == "" {
+			return err
+		}
+		// The unit was written; only reloading systemd failed (e.g. no user session bus)
+		fmt.Printf("⚠️  %v\n", err)
+		fmt.Println("   Run 'systemctl --user daemon-reload' once a user session is available.")
+	}
+
+	fmt.Printf("✅ Installed %s\n", unitPath)
+	fmt.Printf("📁 Events file: %s\n", opts.EventsFile)
+	fmt.Printf("📂 Output dir:  %s\n", opts.OutputDir)
+	if len(opts.Environment) > 0 {
+		envPath, _ := service.SystemdEnvironmentPath()
+		fmt.Printf("🔐 Environment: %d CLAUDETOGO_* variable(s) saved to %s\n", len(opts.Environment), envPath)
+	}
+	fmt.Println()
+	fmt.Println("Next steps:")
+	fmt.Println("  claudetogo service enable    # start automatically at login/boot")
+	fmt.Println("  claudetogo service start     # start now")
+	fmt.Println("  loginctl enable-linger $USER # keep running when logged out (optional)")
+
+	return nil
+}
+
+// serviceStatusReport is the output of `claudetogo service status --json`
+type serviceStatusReport struct {
+	Running        bool                   `json:"running"`
+	StatusFile     string                 `json:"status_file"`
+	Systemd        string                 `json:"systemd,omitempty"`
+	UptimeSeconds  int64                  `json:"uptime_seconds,omitempty"`
+	PendingActions int                    `json:"pending_actions"`
+	Status         *service.ServiceStatus `json:"status,omitempty"`
+}
+
+// handleServiceStatus handles `claudetogo service status`
+func handleServiceStatus(args []string) error {
+	fs := flag.NewFlagSet("service status", flag.ContinueOnError)
+	outputDir := fs.String("output-dir", "messenger-output", "Output directory the service writes to")
+	messengerConfigPath := fs.String("messenger-config", "", "Messenger configuration file")
+	asJSON := fs.Bool("json", false, "Print the status as JSON")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(*messengerConfigPath)
+	report := serviceStatusReport{
+		StatusFile: service.StatusFilePath(msgConfig.Service.StatusFile, *outputDir),
+	}
+
+	status, err := service.ReadStatus(report.StatusFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if status != nil {
+		report.Status = status
+		report.Running = status.IsRunning()
+		if report.Running {
+			report.UptimeSeconds = int64(status.Uptime().Seconds())
+		}
+	}
+	if state, ok := service.SystemdState(); ok {
+		report.Systemd = state
+	}
+
+	rh := responder.NewResponseHandler(*outputDir, logger.New(false))
+	if pending, err := rh.ListPendingActions(); err == nil {
+		report.PendingActions = len(pending)
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal status: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printServiceStatus(report)
+	return nil
+}
+
+// printServiceStatus prints a human-readable service status report
+func printServiceStatus(report serviceStatusReport) {
+	fmt.Println("🛰️  ClaudeToGo Service Status")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	status := report.Status
+	switch {
+	case status == nil:
+		fmt.Printf("State:            ⏹️  not running (no status file at %s)\n", report.StatusFile)
+	case report.Running && status.HeartbeatOverdue():
+		fmt.Printf("State:            ⚠️  unresponsive (PID %d, last heartbeat %s ago)\n", status.PID, time.Since(*status.HeartbeatAt).Round(time.Second))
+		fmt.Printf("Uptime:           %s\n", status.Uptime())
+	case report.Running:
+		fmt.Printf("State:            ✅ running (PID %d)\n", status.PID)
+		fmt.Printf("Uptime:           %s\n", status.Uptime())
+	default:
+		fmt.Printf("State:            ⚠️  not running (stale status file from PID %d)\n", status.PID)
+	}
+	if report.Systemd != "" {
+		fmt.Printf("systemd:          %s\n", report.Systemd)
+	}
+
+	if status != nil {
+		fmt.Printf("Events file:      %s\n", status.EventsFile)
+		fmt.Printf("Output dir:       %s\n", status.OutputDir)
+		if len(status.Components) > 0 {
+			fmt.Printf("Components:       %s\n", strings.Join(status.Components, ", "))
+		}
+		fmt.Printf("Last update:      %s\n", status.UpdatedAt.Format(time.RFC3339))
+		fmt.Printf("Events processed: %d\n", status.EventsProcessed)
+		if status.LastEvent != nil {
+			fmt.Printf("Last event:       %s for session %s at %s\n",
+				status.LastEvent.Type, session.Short(status.LastEvent.SessionID), status.LastEvent.ProcessedAt.Format(time.RFC3339))
+		} else {
+			fmt.Printf("Last event:       none since start\n")
+		}
+		if status.LastHookEvent != nil {
+			fmt.Printf("Last hook event:  %s\n", status.LastHookEvent.Format(time.RFC3339))
+		}
+		if status.StalledSince != nil {
+			fmt.Printf("Stall:            ⚠️  sessions active without hook events since %s\n", status.StalledSince.Format(time.RFC3339))
+		}
+		fmt.Printf("Backlog:          %d event(s)\n", status.Backlog)
+		fmt.Printf("Failed events:    %d (quarantined)\n", status.FailedEvents)
+		if status.MessagesDelivered > 0 || status.DeliveryFailures > 0 || status.DeliveryQueue > 0 {
+			fmt.Printf("Delivered:        %d message(s), %d failed, %d queued\n",
+				status.MessagesDelivered, status.DeliveryFailures, status.DeliveryQueue)
+		}
+		if len(status.Restarts) > 0 {
+			names := make([]string, 0, len(status.Restarts))
+			for name := range status.Restarts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			parts := make([]string, 0, len(names))
+			for _, name := range names {
+				parts = append(parts, fmt.Sprintf("%s=%d", name, status.Restarts[name]))
+			}
+			fmt.Printf("Restarts:         %s\n", strings.Join(parts, ", "))
+		}
+		if status.LastError != "" {
+			fmt.Printf("Last error:       %s\n", status.LastError)
+		}
+		if status.Metrics != nil {
+			printServiceMetrics(status.Metrics)
+		}
+	}
+
+	fmt.Printf("Pending actions:  %d\n", report.PendingActions)
+}
+
+// printServiceMetrics prints the metrics the service publishes in its status file
+func printServiceMetrics(metrics *service.MetricsSnapshot) {
+	fmt.Printf("Event rate:       %.2f/s over the last minute (%d since start)\n", metrics.EventsPerSecond, metrics.EventsTotal)
+	fmt.Printf("Queue depth:      %d\n", metrics.QueueDepth)
+	fmt.Printf("Delivery errors:  %d\n", metrics.DeliveryErrors)
+	if metrics.Responses > 0 {
+		fmt.Printf("Approval latency: avg %s, max %s, last %s (%d response(s))\n",
+			metrics.ApprovalLatencyAvg, metrics.ApprovalLatencyMax, metrics.ApprovalLatencyLast, metrics.Responses)
+	} else {
+		fmt.Printf("Approval latency: no responses since start\n")
+	}
+	if len(metrics.Stages) > 0 {
+		fmt.Printf("Stage latency:    (avg / max per event)\n")
+		for _, stage := range service.Stages {
+			if latency, ok := metrics.Stages[stage]; ok {
+				fmt.Printf("  %-16s%s / %s (%d event(s))\n", stage, latency.Avg, latency.Max, latency.Events)
+			}
+		}
+	}
+}
+
+// claudeToGoEnvironment returns the CLAUDETOGO_* variables set in the current environment
+func claudeToGoEnvironment() map[string]string {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		key, value, found := strings.Cut(entry, "=")
+		if found && strings.HasPrefix(key, "CLAUDETOGO_") {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// absPath returns path made absolute, or unchanged if that fails
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
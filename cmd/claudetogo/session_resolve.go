@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/responder"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+)
+
+// resolveSessionID turns the session given on the command line, usually a prefix, into a
+// full session ID. When the prefix matches several sessions and interactive is set, a user at
+// a terminal picks one; otherwise the error lists the candidates.
+func resolveSessionID(rh *responder.ResponseHandler, sessionID string, interactive bool) (string, error) {
+	fullID, err := rh.ResolveSession(sessionID)
+	var ambiguous *session.AmbiguousError
+	if !errors.As(err, &ambiguous) || !interactive ||
+		!term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fullID, err
+	}
+
+	fmt.Printf("🔀 Session %q matches %d sessions:\n", ambiguous.Prefix, len(ambiguous.Candidates))
+	for i, candidate := range ambiguous.Candidates {
+		title := ""
+		if message, _, err := rh.FindMessage(candidate); err == nil {
+			title = message.Title
+		}
+		fmt.Printf("  [%d] %s  %s\n", i+1, candidate, title)
+	}
+	fmt.Printf("Which session? [1-%d, Enter to cancel]: ", len(ambiguous.Candidates))
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	n, convErr := strconv.Atoi(answer)
+	if convErr != nil || n < 1 || n > len(ambiguous.Candidates) {
+		return "", err
+	}
+	return ambiguous.Candidates[n-1], nil
+}
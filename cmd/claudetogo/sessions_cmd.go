@@ -0,0 +1,356 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/layout"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/responder"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// sessionsUsage describes the `claudetogo sessions` subcommands
+const sessionsUsage = `Usage: claudetogo sessions <command> [options]
+
+Every Claude session ClaudeToGo has seen is kept in a registry (.sessions.json in the
+output directory) with its project, status, when it was first and last seen, and how many
+permission requests it made and how many were approved or rejected. Each session has a label,
+its name or else its branch and start time, which notifications carry while another session
+of the same project is running. Tags and notes help triage: they appear in notifications,
+pending listings, session status and the API, and sessions can be listed by tag.
+
+Commands:
+  list     List the registered sessions, most recent first
+  import   Register the sessions of messenger files written before the registry existed
+  name     Name a session, e.g. sessions name --session 1fa8811f "db migration"
+  tag      Tag a session, e.g. sessions tag 1fa8811f release-hotfix
+  note     Leave a note on a session, e.g. sessions note 1fa8811f "waiting on QA"
+
+Options:
+  --status STATUS     list: only sessions with this status (active, waiting, completed, failed, ended)
+  --project NAME      list: only sessions of this project
+  --tag TAG           list: only sessions with this tag
+  --json              list: print the sessions as JSON
+  --session ID        name, tag, note: the session (a prefix is enough); tag and note also
+                      take it as their first argument
+  --clear             name: remove the name, going back to branch and start time;
+                      note: remove all notes
+  --remove            tag: remove the tags instead of adding them
+  --output-dir DIR    Directory with the messenger files (default: messenger.output_dir)
+  --config PATH       Config file (default: the auto-discovered file)
+`
+
+// sessionStatuses are the statuses `sessions list --status` accepts
+var sessionStatuses = []string{session.StatusActive, session.StatusWaiting, session.StatusCompleted, session.StatusFailed, session.StatusEnded}
+
+// runSessionsSubcommand handles `claudetogo sessions <command>`
+func runSessionsSubcommand(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		fmt.Print(sessionsUsage)
+		return nil
+	}
+
+	switch args[0] {
+	case "list":
+		return handleSessionsList(args[1:])
+	case "import":
+		return handleSessionsImport(args[1:])
+	case "name":
+		return handleSessionsName(args[1:])
+	case "tag":
+		return handleSessionsTag(args[1:])
+	case "note":
+		return handleSessionsNote(args[1:])
+	default:
+		fmt.Print(sessionsUsage)
+		return usageError(fmt.Errorf("unknown sessions command: %s", args[0]))
+	}
+}
+
+// handleSessionsList prints the registered sessions
+func handleSessionsList(args []string) error {
+	fs := flag.NewFlagSet("sessions list", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(sessionsUsage) }
+	status := fs.String("status", "", "Only sessions with this status")
+	project := fs.String("project", "", "Only sessions of this project")
+	tag := fs.String("tag", "", "Only sessions with this tag")
+	asJSON := fs.Bool("json", false, "Print as JSON")
+	outputDir := fs.String("output-dir", "", "Directory with the messenger files")
+	configPath := fs.String("config", "", "Config file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+	if *status != "" && !slices.Contains(sessionStatuses, *status) {
+		return usageError(fmt.Errorf("invalid --status %q (use %s)", *status, strings.Join(sessionStatuses, ", ")))
+	}
+
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath))
+	if *outputDir == "" {
+		*outputDir = msgConfig.Messenger.OutputDir
+	}
+	records, err := session.NewRegistry(*outputDir).List()
+	if err != nil {
+		return err
+	}
+
+	filtered := make([]session.Record, 0, len(records))
+	for _, record := range records {
+		if (*status == "" || record.Status == *status) && (*project == "" || strings.EqualFold(record.Project, *project)) &&
+			(*tag == "" || record.HasTag(*tag)) {
+			filtered = append(filtered, record)
+		}
+	}
+	if *asJSON {
+		return printJSON(filtered)
+	}
+
+	if len(filtered) == 0 {
+		fmt.Println("📭 No sessions registered")
+		if len(records) == 0 {
+			fmt.Println("Sessions are registered as their events are processed; run 'claudetogo sessions import' to add older ones")
+		}
+		return nil
+	}
+
+	display := formatterOptions(msgConfig)
+	fmt.Printf("🗂️  %d session(s)\n", len(filtered))
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	for _, record := range filtered {
+		project := record.Project
+		if project == "" {
+			project = "-"
+		}
+		fmt.Printf("%s %s  %-9s  %s  (%s)\n", sessionStatusIcon(record.Status), session.Short(record.ID), record.Status, project, record.Label())
+		fmt.Printf("   Started: %s   Last event: %s (%s)   Duration: %s\n",
+			display.FormatTime(record.Start()), display.FormatTime(record.LastEvent), record.LastEventType, record.Duration().Round(time.Second))
+		fmt.Printf("   Events: %d   Requests: %d   Approved: %d   Rejected: %d\n",
+			record.Events, record.Requests, record.Approvals, record.Rejections)
+		if len(record.Tags) > 0 {
+			fmt.Printf("   🏷️  %s\n", strings.Join(record.Tags, ", "))
+		}
+		if note := record.LastNote(); note != "" {
+			fmt.Printf("   🗒️  %s\n", formatter.Truncate(note, 80))
+		}
+		if record.LastTitle != "" {
+			fmt.Printf("   %s\n", formatter.Truncate(record.LastTitle, 80))
+		}
+	}
+	return nil
+}
+
+// sessionStatusIcon marks a session status in `sessions list`
+func sessionStatusIcon(status string) string {
+	switch status {
+	case session.StatusWaiting:
+		return "⏳"
+	case session.StatusCompleted:
+		return "✅"
+	case session.StatusFailed:
+		return "❌"
+	case session.StatusEnded:
+		return "⏹️ "
+	default:
+		return "🔄"
+	}
+}
+
+// handleSessionsName names a session, so its notifications are labelled with the name
+func handleSessionsName(args []string) error {
+	fs := flag.NewFlagSet("sessions name", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(sessionsUsage) }
+	sessionID := fs.String("session", "", "Session to name")
+	clearName := fs.Bool("clear", false, "Remove the session's name")
+	outputDir := fs.String("output-dir", "", "Directory with the messenger files")
+	configPath := fs.String("config", "", "Config file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if *sessionID == "" {
+		return usageError(fmt.Errorf("sessions name requires --session"))
+	}
+	name := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if name == "" && !*clearName {
+		return usageError(fmt.Errorf("give the session a name, or --clear to remove it"))
+	}
+	if name != "" && *clearName {
+		return usageError(fmt.Errorf("--clear takes no name"))
+	}
+
+	fullID, registry, err := registrySession(*sessionID, *outputDir, *configPath)
+	if err != nil {
+		return err
+	}
+	if err := registry.SetName(fullID, name); err != nil {
+		return err
+	}
+	if *clearName {
+		fmt.Printf("✅ Removed the name of session %s\n", session.Short(fullID))
+	} else {
+		fmt.Printf("✅ Named session %s %q\n", session.Short(fullID), name)
+	}
+	return nil
+}
+
+// handleSessionsTag adds tags to a session, or removes them with --remove
+func handleSessionsTag(args []string) error {
+	fs := flag.NewFlagSet("sessions tag", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(sessionsUsage) }
+	sessionID := fs.String("session", "", "Session to tag")
+	remove := fs.Bool("remove", false, "Remove the tags")
+	outputDir := fs.String("output-dir", "", "Directory with the messenger files")
+	configPath := fs.String("config", "", "Config file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	tags := fs.Args()
+	if *sessionID == "" && len(tags) > 0 {
+		*sessionID, tags = tags[0], tags[1:]
+	}
+	if *sessionID == "" || len(tags) == 0 {
+		return usageError(fmt.Errorf("usage: claudetogo sessions tag [--remove] ID TAG..."))
+	}
+	for _, tag := range tags {
+		if tag == "" || strings.ContainsAny(tag, " \t,") {
+			return usageError(fmt.Errorf("invalid tag %q: tags can't be empty or contain spaces or commas", tag))
+		}
+	}
+
+	fullID, registry, err := registrySession(*sessionID, *outputDir, *configPath)
+	if err != nil {
+		return err
+	}
+	result, err := registry.Tag(fullID, tags, *remove)
+	if err != nil {
+		return err
+	}
+	if len(result) == 0 {
+		fmt.Printf("✅ Session %s has no tags\n", session.Short(fullID))
+	} else {
+		fmt.Printf("✅ Session %s tagged %s\n", session.Short(fullID), strings.Join(result, ", "))
+	}
+	return nil
+}
+
+// handleSessionsNote leaves a note on a session, or removes its notes with --clear
+func handleSessionsNote(args []string) error {
+	fs := flag.NewFlagSet("sessions note", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(sessionsUsage) }
+	sessionID := fs.String("session", "", "Session to leave a note on")
+	clearNotes := fs.Bool("clear", false, "Remove the session's notes")
+	outputDir := fs.String("output-dir", "", "Directory with the messenger files")
+	configPath := fs.String("config", "", "Config file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	words := fs.Args()
+	if *sessionID == "" && len(words) > 0 {
+		*sessionID, words = words[0], words[1:]
+	}
+	if *sessionID == "" {
+		return usageError(fmt.Errorf("usage: claudetogo sessions note ID TEXT, or --clear ID"))
+	}
+	text := strings.TrimSpace(strings.Join(words, " "))
+	if text == "" && !*clearNotes {
+		return usageError(fmt.Errorf("give the note's text, or --clear to remove the session's notes"))
+	}
+	if text != "" && *clearNotes {
+		return usageError(fmt.Errorf("--clear takes no text"))
+	}
+
+	fullID, registry, err := registrySession(*sessionID, *outputDir, *configPath)
+	if err != nil {
+		return err
+	}
+	if err := registry.AddNote(fullID, text); err != nil {
+		return err
+	}
+	if *clearNotes {
+		fmt.Printf("✅ Removed the notes of session %s\n", session.Short(fullID))
+	} else {
+		fmt.Printf("✅ Added a note to session %s\n", session.Short(fullID))
+	}
+	return nil
+}
+
+// registrySession resolves a session ID, which may be a prefix, and returns the registry of
+// the output directory it is in
+func registrySession(sessionID, outputDir, configPath string) (string, *session.Registry, error) {
+	if outputDir == "" {
+		outputDir = messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(configPath)).Messenger.OutputDir
+	}
+	fullID, err := resolveSessionID(responder.NewResponseHandler(outputDir, logger.New(false)), sessionID, true)
+	if err != nil {
+		return "", nil, err
+	}
+	return fullID, session.NewRegistry(outputDir), nil
+}
+
+// handleSessionsImport registers the sessions found in existing messenger files. Messages
+// are recorded oldest first, and the registry ignores any older than what it already knows.
+func handleSessionsImport(args []string) error {
+	fs := flag.NewFlagSet("sessions import", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(sessionsUsage) }
+	outputDir := fs.String("output-dir", "", "Directory with the messenger files")
+	configPath := fs.String("config", "", "Config file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+
+	if *outputDir == "" {
+		*outputDir = messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(*configPath)).Messenger.OutputDir
+	}
+	files, err := layout.Find(*outputDir, "messenger-*.json")
+	if err != nil {
+		return err
+	}
+
+	var messages []*types.MessengerMessage
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var message types.MessengerMessage
+		if err := json.Unmarshal(data, &message); err != nil || message.SessionID == "" {
+			fmt.Printf("⚠️  Skipping %s: not a messenger message\n", file)
+			continue
+		}
+		messages = append(messages, &message)
+	}
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].Timestamp < messages[j].Timestamp })
+
+	registry := session.NewRegistry(*outputDir)
+	before, err := registry.IDs()
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		if err := registry.RecordMessage(message); err != nil {
+			return err
+		}
+	}
+	after, err := registry.IDs()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Read %d messenger file(s), registered %d new session(s) in %s\n",
+		len(messages), len(after)-len(before), registry.Path())
+	return nil
+}
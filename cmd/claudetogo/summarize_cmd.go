@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	messengerConfig "github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/service"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/transcript"
+)
+
+// summarizeUsage describes `claudetogo summarize`
+const summarizeUsage = `Usage: claudetogo summarize --session ID [options]
+
+Walks the transcript of a Claude Code session and writes a readable summary: the
+prompts, the tool calls with the files, commands and URLs they worked on, Claude's
+replies and the final result. The Markdown format suits a pull request or ticket.
+
+Options:
+  --session ID        Session to summarize (a unique prefix is enough)
+  --transcript PATH   Summarize this transcript file instead of looking up --session
+  --format FORMAT     md (default) or json
+  --output PATH       Write the summary to a file instead of stdout
+  --config PATH       Config file (default: the auto-discovered file); its
+                      service.transcripts_dir is searched for the session
+
+Examples:
+  claudetogo summarize --session 1fa8811f > session.md
+  claudetogo summarize --session 1fa8811f --format json
+`
+
+// sessionTranscript returns the transcript to read: transcriptPath when given, otherwise
+// the session's transcript in the configured transcripts directory
+func sessionTranscript(sessionID, transcriptPath, configPath string) (string, error) {
+	if transcriptPath != "" {
+		return transcriptPath, nil
+	}
+	msgConfig := messengerConfig.GetMessengerConfigWithDefaults(messengerConfigPath(configPath))
+	return transcript.FindSessionTranscript(service.ExpandHome(msgConfig.Service.TranscriptsDir), sessionID)
+}
+
+// runSummarizeSubcommand handles `claudetogo summarize`
+func runSummarizeSubcommand(args []string) error {
+	fs := flag.NewFlagSet("summarize", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(summarizeUsage) }
+	sessionID := fs.String("session", "", "Session to summarize")
+	transcriptPath := fs.String("transcript", "", "Transcript file to summarize")
+	format := fs.String("format", "md", "Output format: md or json")
+	outputPath := fs.String("output", "", "File to write the summary to")
+	configPath := fs.String("config", "", "Config file")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+	if *format != "md" && *format != "json" {
+		return usageError(fmt.Errorf("unknown format %q (use md or json)", *format))
+	}
+
+	if *transcriptPath == "" && *sessionID == "" {
+		fmt.Print(summarizeUsage)
+		return usageError(fmt.Errorf("summarize requires --session or --transcript"))
+	}
+	path, err := sessionTranscript(*sessionID, *transcriptPath, *configPath)
+	if err != nil {
+		return err
+	}
+
+	summary, err := transcript.NewReader().Summarize(path)
+	if err != nil {
+		return err
+	}
+	var output []byte
+	if *format == "json" {
+		if output, err = json.MarshalIndent(summary, "", "  "); err != nil {
+			return err
+		}
+		output = append(output, '\n')
+	} else {
+		output = []byte(summary.Markdown())
+	}
+
+	if *outputPath == "" {
+		_, err = os.Stdout.Write(output)
+		return err
+	}
+	if err := os.WriteFile(*outputPath, output, 0644); err != nil {
+		return fmt.Errorf("could not write summary: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "📝 Wrote summary of session %s to %s\n", summary.SessionID, *outputPath)
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/version"
+)
+
+// versionUsage describes `claudetogo version`
+const versionUsage = `Usage: claudetogo version [--json]
+
+Prints the version, git commit, build date, Go version and platform of this build.
+Include it in bug reports. The same line is printed by 'claudetogo --version'.
+
+Options:
+  --json   Print the build metadata as JSON
+`
+
+// runVersionSubcommand handles `claudetogo version`
+func runVersionSubcommand(args []string) error {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(versionUsage) }
+	asJSON := fs.Bool("json", false, "Print as JSON")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+
+	info := version.Get()
+	if !*asJSON {
+		fmt.Println(info)
+		return nil
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode version: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,160 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/extractor"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/transcript"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// transcriptTurns is how many prompt/tool use/result/response turns a generated transcript has
+const transcriptTurns = 2000
+
+// sessionID is the session of the generated transcripts and events
+const sessionID = "bench-0000-0000-0000-000000000000"
+
+// writeTranscript writes a transcript of turns turns, each a prompt, a tool use with a
+// sizeable file write, its result and a response, ending with a pending Write. It returns
+// the transcript's path.
+func writeTranscript(b *testing.B, turns int) string {
+	b.Helper()
+	path := filepath.Join(b.TempDir(), sessionID+".jsonl")
+	file, err := os.Create(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer file.Close()
+
+	content := strings.Repeat("func example() { return }\n", 200)
+	encoder := json.NewEncoder(file)
+	write := func(kind string, message types.ClaudeMessage, n int) {
+		err := encoder.Encode(types.TranscriptMessage{
+			SessionID: sessionID,
+			Type:      kind,
+			Message:   message,
+			UUID:      fmt.Sprintf("uuid-%d-%s", n, kind),
+			Timestamp: "2025-01-02T10:00:00Z",
+			CWD:       "/home/dev/project",
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	toolUse := func(n int) types.ClaudeMessage {
+		return types.ClaudeMessage{Role: "assistant", Content: []types.ContentItem{{
+			Type:  "tool_use",
+			ID:    fmt.Sprintf("toolu_%d", n),
+			Name:  "Write",
+			Input: map[string]interface{}{"file_path": fmt.Sprintf("/home/dev/project/file%d.go", n), "content": content},
+		}}}
+	}
+
+	for n := 0; n < turns; n++ {
+		write("user", types.ClaudeMessage{Role: "user", Content: fmt.Sprintf("Please write file %d", n)}, n)
+		write("assistant", toolUse(n), n)
+		write("user", types.ClaudeMessage{Role: "user", Content: []types.ContentItem{{
+			Type:      "tool_result",
+			ToolUseID: fmt.Sprintf("toolu_%d", n),
+			Content:   "File created successfully",
+		}}}, n)
+		write("assistant", types.ClaudeMessage{Role: "assistant", Content: []types.ContentItem{{
+			Type: "text",
+			Text: fmt.Sprintf("I wrote file %d. The task is complete.", n),
+		}}}, n)
+	}
+	write("assistant", toolUse(turns), turns)
+	return path
+}
+
+// events returns a Stop and a Notification event for a transcript
+func events(transcriptPath string) (stop, notification *types.ClaudeHookEvent) {
+	stop = &types.ClaudeHookEvent{
+		SessionID:      sessionID,
+		TranscriptPath: transcriptPath,
+		CWD:            "/home/dev/project",
+		HookEventName:  "Stop",
+		Timestamp:      "2025-01-02T10:00:00Z",
+	}
+	notification = &types.ClaudeHookEvent{
+		SessionID:      sessionID,
+		TranscriptPath: transcriptPath,
+		CWD:            "/home/dev/project",
+		HookEventName:  "Notification",
+		Message:        "Claude needs your permission to use Write",
+		Timestamp:      "2025-01-02T10:00:00Z",
+	}
+	return stop, notification
+}
+
+func BenchmarkParseTranscript(b *testing.B) {
+	path := writeTranscript(b, transcriptTurns)
+	reader := transcript.NewReader()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := reader.ParseTranscriptFile(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLastAssistantMessage(b *testing.B) {
+	path := writeTranscript(b, transcriptTurns)
+	reader := transcript.NewReader()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := reader.GetLastAssistantMessage(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExtractStop(b *testing.B) {
+	stop, _ := events(writeTranscript(b, transcriptTurns))
+	de := extractor.NewDataExtractor()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := de.ProcessEvent(stop); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExtractNotification(b *testing.B) {
+	_, notification := events(writeTranscript(b, transcriptTurns))
+	de := extractor.NewDataExtractor()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := de.ProcessEvent(notification); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFormat(b *testing.B) {
+	stop, notification := events(writeTranscript(b, transcriptTurns))
+	de := extractor.NewDataExtractor()
+	var extracted []*types.ExtractedData
+	for _, event := range []*types.ClaudeHookEvent{stop, notification} {
+		data, err := de.ProcessEvent(event)
+		if err != nil {
+			b.Fatal(err)
+		}
+		extracted = append(extracted, data)
+	}
+	mf := formatter.NewMessengerFormatter()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, data := range extracted {
+			if _, err := mf.CreateActionableMessage(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
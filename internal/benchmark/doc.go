@@ -0,0 +1,4 @@
+// Package benchmark holds benchmarks of the event pipeline (transcript parsing, extraction
+// and formatting) against large generated transcripts, so performance regressions show up
+// in `go test -bench`
+package benchmark
@@ -0,0 +1,363 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// API endpoints of the chat services
+var (
+	telegramAPI = "https://api.telegram.org"
+	slackAPI    = "https://slack.com/api"
+)
+
+// telegramDetailsLimit is how many characters of a batch's details a Telegram message quotes, keeping
+// it within Telegram's 4096 character limit
+const telegramDetailsLimit = 3000
+
+// Names are the names of the channel types, as returned by Name
+var Names = []string{"telegram", "slack", "webhook"}
+
+// Channel sends messenger messages to one destination
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, client *http.Client, message *types.MessengerMessage) error
+}
+
+// Webhook posts each message as JSON to a URL
+type Webhook struct {
+	URL       string
+	Headers   map[string]string
+	PublicKey string // PEM public key file; when set, messages are posted as an EncryptedPayload
+}
+
+// Name identifies the channel in logs
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// Send posts the message
+func (w *Webhook) Send(ctx context.Context, client *http.Client, message *types.MessengerMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	if w.PublicKey != "" {
+		// The key is read for every message, so a replaced key takes effect without a restart
+		key, err := LoadPublicKey(w.PublicKey)
+		if err != nil {
+			return fmt.Errorf("failed to load webhook public key: %w", err)
+		}
+		payload, err := Encrypt(body, key)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt message: %w", err)
+		}
+		if body, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to marshal encrypted message: %w", err)
+		}
+	}
+	_, err = postJSON(ctx, client, w.URL, body, w.Headers)
+	return err
+}
+
+// Telegram sends messages through a Telegram bot to one chat
+type Telegram struct {
+	Token   string
+	ChatID  string
+	Threads *Threads // Messages of a session reply to its first one (nil = not threaded)
+}
+
+// Name identifies the channel in logs
+func (t *Telegram) Name() string {
+	return "telegram"
+}
+
+// Send delivers the message as a chat message from the bot, in reply to the first message
+// of its thread
+func (t *Telegram) Send(ctx context.Context, client *http.Client, message *types.MessengerMessage) error {
+	request := map[string]interface{}{
+		"chat_id": t.ChatID,
+		"text":    Text(message),
+	}
+	key := threadKey("telegram", t.ChatID, message.ThreadID)
+	if root, err := strconv.ParseInt(t.Threads.root(key), 10, 64); err == nil && message.ThreadID != "" {
+		request["reply_to_message_id"] = root
+		// A deleted first message doesn't stop the rest of the thread
+		request["allow_sending_without_reply"] = true
+	}
+	// Details of a batch are shown collapsed, in an expandable quote
+	if details := contextStrings(message.Context["details"]); len(details) > 0 {
+		quote := formatter.Truncate(strings.Join(details, "\n"), telegramDetailsLimit)
+		request["text"] = html.EscapeString(summaryText(message)) +
+			"\n\n<blockquote expandable>" + html.EscapeString(quote) + "</blockquote>"
+		request["parse_mode"] = "HTML"
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	result, err := telegramCall(ctx, client, t.Token, "sendMessage", body)
+	if err != nil {
+		return err
+	}
+
+	var sent struct {
+		MessageID int64 `json:"message_id"`
+	}
+	if message.ThreadID != "" && json.Unmarshal(result, &sent) == nil && sent.MessageID != 0 {
+		// The message was delivered; failing to remember it only leaves later ones unthreaded
+		_ = t.Threads.start(key, strconv.FormatInt(sent.MessageID, 10))
+	}
+	return nil
+}
+
+// DiscoverTelegramChatID waits for someone to send /start to the bot and returns the ID of
+// that chat. It long-polls the bot's updates until a /start arrives or ctx is done.
+func DiscoverTelegramChatID(ctx context.Context, client *http.Client, token string) (string, error) {
+	offset := 0
+	for {
+		body, err := json.Marshal(map[string]interface{}{
+			"offset":          offset,
+			"timeout":         10,
+			"allowed_updates": []string{"message"},
+		})
+		if err != nil {
+			return "", err
+		}
+		result, err := telegramCall(ctx, client, token, "getUpdates", body)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", fmt.Errorf("no /start message received: %w", ctx.Err())
+			}
+			return "", err
+		}
+
+		var updates []struct {
+			UpdateID int `json:"update_id"`
+			Message  *struct {
+				Text string `json:"text"`
+				Chat struct {
+					ID int64 `json:"id"`
+				} `json:"chat"`
+			} `json:"message"`
+		}
+		if err := json.Unmarshal(result, &updates); err != nil {
+			return "", fmt.Errorf("unexpected Telegram response: %w", err)
+		}
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message != nil && strings.HasPrefix(update.Message.Text, "/start") {
+				return fmt.Sprint(update.Message.Chat.ID), nil
+			}
+		}
+	}
+}
+
+// telegramCall calls a Bot API method and returns its result
+func telegramCall(ctx context.Context, client *http.Client, token, method string, body []byte) (json.RawMessage, error) {
+	url := fmt.Sprintf("%s/bot%s/%s", telegramAPI, token, method)
+	data, err := postJSON(ctx, client, url, body, nil)
+	if err != nil && data == nil {
+		// Keep the token, which is part of the URL, out of error messages
+		return nil, fmt.Errorf("telegram %s failed: %s", method, strings.ReplaceAll(err.Error(), token, "***"))
+	}
+
+	var response struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if jsonErr := json.Unmarshal(data, &response); jsonErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("telegram %s failed: %w", method, err)
+		}
+		return nil, fmt.Errorf("unexpected Telegram response: %w", jsonErr)
+	}
+	if !response.OK {
+		return nil, fmt.Errorf("telegram %s failed: %s", method, response.Description)
+	}
+	return response.Result, nil
+}
+
+// Slack posts messages to a Slack channel as a bot
+type Slack struct {
+	Token   string
+	Channel string
+	Threads *Threads // Messages of a session are posted in the thread of its first one (nil = not threaded)
+}
+
+// Name identifies the channel in logs
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+// Send posts the message with chat.postMessage, in the thread of the first message of its
+// thread
+func (s *Slack) Send(ctx context.Context, client *http.Client, message *types.MessengerMessage) error {
+	request := map[string]string{
+		"channel": s.Channel,
+		"text":    Text(message),
+	}
+	key := threadKey("slack", s.Channel, message.ThreadID)
+	if root := s.Threads.root(key); root != "" && message.ThreadID != "" {
+		request["thread_ts"] = root
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	data, err := postJSON(ctx, client, slackAPI+"/chat.postMessage", body, map[string]string{
+		"Authorization": "Bearer " + s.Token,
+	})
+	if err != nil {
+		return fmt.Errorf("slack chat.postMessage failed: %w", err)
+	}
+
+	// Slack reports most errors with a 200 status and ok=false
+	var response struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("unexpected Slack response: %w", err)
+	}
+	if !response.OK {
+		return fmt.Errorf("slack chat.postMessage failed: %s", response.Error)
+	}
+	if message.ThreadID != "" {
+		// The message was delivered; failing to remember it only leaves later ones unthreaded
+		_ = s.Threads.start(key, response.TS)
+	}
+	return nil
+}
+
+// SlackUserEmail returns the email address of a Slack user with users.info, which needs the
+// users:read.email scope
+func SlackUserEmail(ctx context.Context, client *http.Client, token, userID string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, slackAPI+"/users.info?user="+url.QueryEscape(userID), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("User-Agent", "claudetogo")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("slack users.info failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		User  struct {
+			Profile struct {
+				Email string `json:"email"`
+			} `json:"profile"`
+		} `json:"user"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&response); err != nil {
+		return "", fmt.Errorf("unexpected Slack response: %w", err)
+	}
+	if !response.OK {
+		return "", fmt.Errorf("slack users.info failed: %s", response.Error)
+	}
+	if response.User.Profile.Email == "" {
+		return "", fmt.Errorf("slack users.info returned no email for %s (is the users:read.email scope granted?)", userID)
+	}
+	return response.User.Profile.Email, nil
+}
+
+// Text renders a message as plain text for chat services, ending with the detail lines of
+// a batch of messages
+func Text(message *types.MessengerMessage) string {
+	text := summaryText(message)
+	if details := contextStrings(message.Context["details"]); len(details) > 0 {
+		text += "\n\n" + strings.Join(details, "\n")
+	}
+	return text
+}
+
+// summaryText renders a message as plain text without batch details
+func summaryText(message *types.MessengerMessage) string {
+	var text strings.Builder
+	text.WriteString(message.Title)
+	if message.Message != "" {
+		text.WriteString("\n\n" + message.Message)
+	}
+	// Tags and notes of the session, for triage
+	separator := "\n\n"
+	if tags := contextStrings(message.Context["session_tags"]); len(tags) > 0 {
+		text.WriteString(separator + "🏷️ " + strings.Join(tags, ", "))
+		separator = "\n"
+	}
+	if note, _ := message.Context["session_note"].(string); note != "" {
+		text.WriteString(separator + "📝 " + note)
+	}
+	for _, action := range message.Actions {
+		text.WriteString("\n• " + action.Label)
+		if action.Command != "" {
+			text.WriteString(": " + action.Command)
+		}
+	}
+	return text.String()
+}
+
+// contextStrings reads a list of strings from a message's context, whether it was set in
+// this process or read back from JSON
+func contextStrings(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		var values []string
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// postJSON sends a JSON request and returns the response body. On an error status the body
+// is returned along with the error.
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "claudetogo")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return data, fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return data, nil
+}
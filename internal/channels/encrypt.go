@@ -0,0 +1,269 @@
+package channels
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// Encrypted webhook payloads: the message JSON is sealed with AES-256-GCM under a fresh key,
+// which is wrapped for the recipient with RSA-OAEP (SHA-256) or derived with ephemeral ECDH
+// (X25519 or P-256) as SHA-256(version || shared secret || ephemeral key || recipient key).
+// The additional data is "<version>.<alg>".
+const (
+	payloadVersion = "claudetogo-v1"
+	algRSA         = "RSA-OAEP-256+A256GCM"
+	algECDH        = "ECDH-ES+A256GCM"
+	minRSABits     = 2048
+)
+
+// EncryptedPayload is posted to a webhook instead of the message when it has a public key
+type EncryptedPayload struct {
+	Encrypted          string `json:"encrypted"` // Format version, "claudetogo-v1"
+	Algorithm          string `json:"alg"`
+	KeyID              string `json:"key_id"`                         // Identifies the recipient key
+	EncryptedKey       string `json:"encrypted_key,omitempty"`        // RSA-wrapped content key (base64)
+	EphemeralPublicKey string `json:"ephemeral_public_key,omitempty"` // Sender's ECDH key (base64, raw encoding)
+	Nonce              string `json:"nonce"`                          // AES-GCM nonce (base64)
+	Ciphertext         string `json:"ciphertext"`                     // Sealed message JSON (base64)
+}
+
+// LoadPublicKey reads a PEM "PUBLIC KEY" (RSA, X25519 or P-256) to encrypt payloads for
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("%s is not a PEM public key", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA key in %s has %d bits, at least %d are needed", path, key.N.BitLen(), minRSABits)
+		}
+		return key, nil
+	case *ecdsa.PublicKey:
+		return key.ECDH()
+	case *ecdh.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T in %s (use RSA, X25519 or P-256)", key, path)
+}
+
+// LoadPrivateKey reads the PEM private key (PKCS#8, PKCS#1 or SEC 1) payloads were encrypted for
+func LoadPrivateKey(path string) (crypto.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type == "PUBLIC KEY" {
+		return nil, fmt.Errorf("%s is not a PEM private key", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key.ECDH()
+	case *ecdh.PrivateKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T in %s", key, path)
+}
+
+// GenerateKeyPair creates an X25519 key pair as PEM, private key first
+func GenerateKeyPair() ([]byte, []byte, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	private, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	public, err := x509.MarshalPKIXPublicKey(key.PublicKey())
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}), nil
+}
+
+// KeyID returns the identifier payloads carry for a public key: the first 16 hex digits of
+// the SHA-256 of its PKIX encoding
+func KeyID(key crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// Encrypt seals plaintext for the holder of key's private key
+func Encrypt(plaintext []byte, key crypto.PublicKey) (*EncryptedPayload, error) {
+	keyID, err := KeyID(key)
+	if err != nil {
+		return nil, err
+	}
+	payload := &EncryptedPayload{Encrypted: payloadVersion, KeyID: keyID}
+
+	var contentKey []byte
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		payload.Algorithm = algRSA
+		contentKey = make([]byte, 32)
+		if _, err := rand.Read(contentKey); err != nil {
+			return nil, err
+		}
+		wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, contentKey, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap key: %w", err)
+		}
+		payload.EncryptedKey = base64.StdEncoding.EncodeToString(wrapped)
+	case *ecdh.PublicKey:
+		payload.Algorithm = algECDH
+		ephemeral, err := key.Curve().GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		shared, err := ephemeral.ECDH(key)
+		if err != nil {
+			return nil, err
+		}
+		contentKey = deriveKey(shared, ephemeral.PublicKey().Bytes(), key.Bytes())
+		payload.EphemeralPublicKey = base64.StdEncoding.EncodeToString(ephemeral.PublicKey().Bytes())
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+
+	aead, err := newGCM(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	payload.Nonce = base64.StdEncoding.EncodeToString(nonce)
+	payload.Ciphertext = base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plaintext, additionalData(payload.Algorithm)))
+	return payload, nil
+}
+
+// Decrypt opens a payload with the recipient's private key
+func Decrypt(payload *EncryptedPayload, key crypto.PrivateKey) ([]byte, error) {
+	if payload.Encrypted != payloadVersion {
+		return nil, fmt.Errorf("unsupported payload version %q", payload.Encrypted)
+	}
+
+	var contentKey []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if payload.Algorithm != algRSA {
+			return nil, fmt.Errorf("payload uses %s, not an RSA key", payload.Algorithm)
+		}
+		wrapped, err := base64.StdEncoding.DecodeString(payload.EncryptedKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encrypted_key: %w", err)
+		}
+		if contentKey, err = rsa.DecryptOAEP(sha256.New(), nil, key, wrapped, nil); err != nil {
+			return nil, fmt.Errorf("failed to unwrap key: %w", err)
+		}
+	case *ecdh.PrivateKey:
+		if payload.Algorithm != algECDH {
+			return nil, fmt.Errorf("payload uses %s, not an ECDH key", payload.Algorithm)
+		}
+		raw, err := base64.StdEncoding.DecodeString(payload.EphemeralPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ephemeral_public_key: %w", err)
+		}
+		ephemeral, err := key.Curve().NewPublicKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ephemeral_public_key: %w", err)
+		}
+		shared, err := key.ECDH(ephemeral)
+		if err != nil {
+			return nil, err
+		}
+		contentKey = deriveKey(shared, raw, key.PublicKey().Bytes())
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(payload.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(payload.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	aead, err := newGCM(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(nonce))
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(payload.Algorithm))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload (wrong key?): %w", err)
+	}
+	return plaintext, nil
+}
+
+// deriveKey turns an ECDH shared secret into the AES-256 content key
+func deriveKey(shared, ephemeral, recipient []byte) []byte {
+	hash := sha256.New()
+	hash.Write([]byte(payloadVersion))
+	hash.Write(shared)
+	hash.Write(ephemeral)
+	hash.Write(recipient)
+	return hash.Sum(nil)
+}
+
+// additionalData binds the ciphertext to the format version and algorithm
+func additionalData(algorithm string) []byte {
+	return []byte(payloadVersion + "." + algorithm)
+}
+
+// newGCM creates an AES-256-GCM cipher
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
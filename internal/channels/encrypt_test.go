@@ -0,0 +1,206 @@
+package channels
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testKeyPair is a recipient key pair, loaded from PEM files as a webhook and its receiver do
+type testKeyPair struct {
+	name    string
+	public  crypto.PublicKey
+	private crypto.PrivateKey
+}
+
+// writePEM writes a PEM block to a file in dir and returns its path
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testKeyPairs returns an RSA, an X25519 and a P-256 key pair
+func testKeyPairs(t *testing.T) []testKeyPair {
+	t.Helper()
+	dir := t.TempDir()
+	var pairs []testKeyPair
+	load := func(name, privatePath, publicPath string) {
+		public, err := LoadPublicKey(publicPath)
+		if err != nil {
+			t.Fatalf("LoadPublicKey(%s) error = %v", name, err)
+		}
+		private, err := LoadPrivateKey(privatePath)
+		if err != nil {
+			t.Fatalf("LoadPrivateKey(%s) error = %v", name, err)
+		}
+		pairs = append(pairs, testKeyPair{name: name, public: public, private: private})
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, minRSABits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPublic, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	load("rsa",
+		writePEM(t, dir, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+		writePEM(t, dir, "rsa.pub", "PUBLIC KEY", rsaPublic))
+
+	privatePEM, publicPEM, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	x25519Private, x25519Public := filepath.Join(dir, "x25519.pem"), filepath.Join(dir, "x25519.pub")
+	if err := os.WriteFile(x25519Private, privatePEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(x25519Public, publicPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	load("x25519", x25519Private, x25519Public)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPrivate, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPublic, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	load("p256",
+		writePEM(t, dir, "p256.pem", "EC PRIVATE KEY", ecPrivate),
+		writePEM(t, dir, "p256.pub", "PUBLIC KEY", ecPublic))
+
+	return pairs
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	plaintext := []byte(`{"type":"action_needed","session_id":"abc","title":"Approve?"}`)
+
+	for _, pair := range testKeyPairs(t) {
+		t.Run(pair.name, func(t *testing.T) {
+			payload, err := Encrypt(plaintext, pair.public)
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+			wantAlg := algECDH
+			if _, ok := pair.public.(*rsa.PublicKey); ok {
+				wantAlg = algRSA
+			}
+			if payload.Algorithm != wantAlg {
+				t.Errorf("Algorithm = %q, want %q", payload.Algorithm, wantAlg)
+			}
+			if keyID, _ := KeyID(pair.public); payload.KeyID != keyID {
+				t.Errorf("KeyID = %q, want %q", payload.KeyID, keyID)
+			}
+			if bytes.Contains([]byte(payload.Ciphertext), []byte("Approve")) {
+				t.Error("ciphertext contains the plaintext")
+			}
+
+			got, err := Decrypt(payload, pair.private)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("Decrypt() = %q, want %q", got, plaintext)
+			}
+		})
+	}
+}
+
+// flipByte returns base64 data with one decoded byte changed; a negative index counts from
+// the end
+func flipByte(t *testing.T, encoded string, index int) string {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index < 0 {
+		index += len(data)
+	}
+	data[index] ^= 0x01
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	plaintext := []byte(`{"type":"action_needed","session_id":"abc"}`)
+	pairs := testKeyPairs(t)
+
+	tests := []struct {
+		name   string
+		tamper func(t *testing.T, payload *EncryptedPayload)
+	}{
+		{name: "ciphertext", tamper: func(t *testing.T, p *EncryptedPayload) { p.Ciphertext = flipByte(t, p.Ciphertext, 0) }},
+		{name: "tag", tamper: func(t *testing.T, p *EncryptedPayload) { p.Ciphertext = flipByte(t, p.Ciphertext, -1) }},
+		{name: "nonce", tamper: func(t *testing.T, p *EncryptedPayload) { p.Nonce = flipByte(t, p.Nonce, 0) }},
+		{name: "truncated", tamper: func(t *testing.T, p *EncryptedPayload) {
+			data, _ := base64.StdEncoding.DecodeString(p.Ciphertext)
+			p.Ciphertext = base64.StdEncoding.EncodeToString(data[:len(data)-1])
+		}},
+		{name: "version", tamper: func(t *testing.T, p *EncryptedPayload) { p.Encrypted = "claudetogo-v0" }},
+		{name: "wrapped key", tamper: func(t *testing.T, p *EncryptedPayload) {
+			if p.EncryptedKey != "" {
+				p.EncryptedKey = flipByte(t, p.EncryptedKey, 0)
+			} else {
+				p.EphemeralPublicKey = flipByte(t, p.EphemeralPublicKey, 0)
+			}
+		}},
+	}
+
+	for _, pair := range pairs {
+		for _, tt := range tests {
+			t.Run(pair.name+"/"+tt.name, func(t *testing.T) {
+				payload, err := Encrypt(plaintext, pair.public)
+				if err != nil {
+					t.Fatalf("Encrypt() error = %v", err)
+				}
+				tt.tamper(t, payload)
+				if got, err := Decrypt(payload, pair.private); err == nil {
+					t.Fatalf("Decrypt() of a tampered payload = %q, want an error", got)
+				}
+			})
+		}
+	}
+}
+
+func TestDecryptRejectsOtherKey(t *testing.T) {
+	plaintext := []byte(`{"type":"completion"}`)
+	payload, err := Encrypt(plaintext, mustX25519(t).PublicKey())
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if _, err := Decrypt(payload, mustX25519(t)); err == nil {
+		t.Fatal("Decrypt() with another key succeeded, want an error")
+	}
+}
+
+// mustX25519 generates an X25519 private key
+func mustX25519(t *testing.T) *ecdh.PrivateKey {
+	t.Helper()
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
@@ -0,0 +1,95 @@
+package channels
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
+)
+
+// ThreadsFileName is the file (inside the output directory) the service keeps thread roots in
+const ThreadsFileName = ".channel-threads.json"
+
+// maxThreads is how many thread roots are kept; the oldest are dropped beyond it
+const maxThreads = 1000
+
+// threadRoot is the message a chat service gave the first message of a thread
+type threadRoot struct {
+	ID        string    `json:"id"` // Telegram message_id or Slack ts
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Threads remembers, for each thread (ThreadID) in each chat, the message the chat service
+// gave its first message, so Telegram and Slack post the rest of a session's messages as
+// replies to it. It is shared by all deliveries and saved to a file, so threads carry on
+// after a restart. A nil *Threads leaves messages unthreaded.
+type Threads struct {
+	mu    sync.Mutex
+	path  string
+	roots map[string]threadRoot
+}
+
+// NewThreads returns the thread roots saved in path, starting with none when the file
+// doesn't exist or can't be read
+func NewThreads(path string) *Threads {
+	t := &Threads{path: path, roots: make(map[string]threadRoot)}
+	if data, err := os.ReadFile(path); err == nil {
+		if json.Unmarshal(data, &t.roots) != nil {
+			t.roots = make(map[string]threadRoot)
+		}
+	}
+	return t
+}
+
+// threadKey identifies a thread in one chat of a chat service
+func threadKey(service, chat, threadID string) string {
+	return service + ":" + chat + ":" + threadID
+}
+
+// root returns the ID of the first message of a thread, or "" for a thread not started yet
+func (t *Threads) root(key string) string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.roots[key].ID
+}
+
+// start records the first message of a thread, unless it already has one, dropping the
+// oldest threads beyond maxThreads
+func (t *Threads) start(key, id string) error {
+	if t == nil || id == "" {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.roots[key]; ok {
+		return nil
+	}
+	t.roots[key] = threadRoot{ID: id, CreatedAt: time.Now()}
+
+	if len(t.roots) > maxThreads {
+		keys := make([]string, 0, len(t.roots))
+		for k := range t.roots {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return t.roots[keys[i]].CreatedAt.Before(t.roots[keys[j]].CreatedAt) })
+		for _, k := range keys[:len(keys)-maxThreads] {
+			delete(t.roots, k)
+		}
+	}
+
+	data, err := json.Marshal(t.roots)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(t.path, data, 0644)
+}
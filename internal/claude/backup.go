@@ -0,0 +1,154 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimeFormat names timestamped backups, e.g. settings.json.2025-01-02T10-00.bak
+const backupTimeFormat = "2006-01-02T15-04"
+
+// legacyBackupSuffix is the single backup older versions overwrote on every save
+const legacyBackupSuffix = ".backup"
+
+// backupRetention is how many backups of each settings file are kept (0 = all)
+var backupRetention = 10
+
+// SetBackupRetention sets how many backups of each settings file are kept; older ones are
+// deleted when a new backup is made. 0 keeps all backups.
+func SetBackupRetention(count int) {
+	backupRetention = count
+}
+
+// SettingsBackup is a saved copy of a settings file
+type SettingsBackup struct {
+	Path    string
+	Created time.Time
+	Size    int64
+}
+
+// BackupSettings copies a settings file to a timestamped <name>.<time>.bak next to it and
+// returns the backup path. Backups beyond the retention count are deleted, oldest first.
+func BackupSettings(path string) (string, error) {
+	// Give the single backup of older versions a timestamp so it is rotated like the others
+	if info, err := os.Stat(path + legacyBackupSuffix); err == nil {
+		if err := os.Rename(path+legacyBackupSuffix, timestampedBackupPath(path, info.ModTime())); err != nil {
+			return "", fmt.Errorf("could not rename %s: %w", path+legacyBackupSuffix, err)
+		}
+	}
+
+	backupPath := timestampedBackupPath(path, time.Now())
+	if err := copyFile(path, backupPath); err != nil {
+		return "", err
+	}
+	if err := pruneBackups(path, backupPath); err != nil {
+		return backupPath, fmt.Errorf("could not delete old backups: %w", err)
+	}
+	return backupPath, nil
+}
+
+// timestampedBackupPath returns an unused backup name for a settings file
+func timestampedBackupPath(path string, at time.Time) string {
+	stamp := at.Format(backupTimeFormat)
+	backupPath := fmt.Sprintf("%s.%s.bak", path, stamp)
+	for i := 2; fileExists(backupPath); i++ {
+		backupPath = fmt.Sprintf("%s.%s-%d.bak", path, stamp, i)
+	}
+	return backupPath
+}
+
+// pruneBackups deletes the oldest backups of a settings file beyond the retention count,
+// never the one just made
+func pruneBackups(path, keep string) error {
+	if backupRetention <= 0 {
+		return nil
+	}
+	backups, err := ListBackups(path)
+	if err != nil {
+		return err
+	}
+	kept := 0
+	for _, backup := range backups {
+		if backup.Path == keep || kept < backupRetention-1 {
+			if backup.Path != keep {
+				kept++
+			}
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListBackups returns the backups of a settings file, newest first, including the
+// <name>.backup written by older versions
+func ListBackups(path string) ([]SettingsBackup, error) {
+	matches, err := filepath.Glob(globEscape(path) + ".*.bak")
+	if err != nil {
+		return nil, err
+	}
+	if fileExists(path + legacyBackupSuffix) {
+		matches = append(matches, path+legacyBackupSuffix)
+	}
+
+	var backups []SettingsBackup
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		backups = append(backups, SettingsBackup{Path: match, Created: info.ModTime(), Size: info.Size()})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Created.After(backups[j].Created)
+	})
+	return backups, nil
+}
+
+// RestoreBackup replaces a settings file with one of its backups. The current file is
+// backed up first so the restore can be undone; that backup's path is returned.
+func RestoreBackup(path, backupPath string) (string, error) {
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return "", fmt.Errorf("could not read backup: %w", err)
+	}
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return "", fmt.Errorf("backup %s is not a valid settings file: %w", backupPath, err)
+	}
+
+	var current string
+	if fileExists(path) {
+		if current, err = BackupSettings(path); err != nil {
+			return "", fmt.Errorf("could not back up current settings: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return current, fmt.Errorf("could not write %s: %w", path, err)
+	}
+	return current, nil
+}
+
+// globEscape escapes glob metacharacters in a literal path
+func globEscape(path string) string {
+	replacer := strings.NewReplacer("*", `\*`, "?", `\?`, "[", `\[`, `\`, `\\`)
+	if filepath.Separator == '\\' {
+		// Backslashes are path separators on Windows and can't be escaped
+		replacer = strings.NewReplacer("*", "[*]", "?", "[?]", "[", "[[]")
+	}
+	return replacer.Replace(path)
+}
+
+// fileExists reports whether a file exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
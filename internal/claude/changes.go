@@ -0,0 +1,70 @@
+package claude
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// HookChanges is what saving settings would do to the hooks of a settings file, hook
+// command by hook command
+type HookChanges struct {
+	Added   []HookEntry `json:"added"`
+	Removed []HookEntry `json:"removed"`
+	Kept    []HookEntry `json:"kept"`
+}
+
+// Changed reports whether any hook is added or removed
+func (c HookChanges) Changed() bool {
+	return len(c.Added) > 0 || len(c.Removed) > 0
+}
+
+// String renders the changes for the console, one line per added or removed hook
+func (c HookChanges) String() string {
+	var b strings.Builder
+	for _, hook := range c.Added {
+		fmt.Fprintf(&b, "  ➕ %-17s %-15s %s\n", hook.Event, hook.Matcher, hook.Command)
+	}
+	for _, hook := range c.Removed {
+		fmt.Fprintf(&b, "  ➖ %-17s %-15s %s\n", hook.Event, hook.Matcher, hook.Command)
+	}
+	if len(c.Kept) > 0 {
+		fmt.Fprintf(&b, "  %d other hook(s) unchanged\n", len(c.Kept))
+	}
+	return b.String()
+}
+
+// DiffHooks compares two hooks maps. A hook counts as kept when the same command stays
+// under the same hook type and matcher.
+func DiffHooks(before, after map[string][]types.HookMatcher) HookChanges {
+	var changes HookChanges
+	remaining := make(map[HookEntry]int)
+	for _, hook := range hookEntries(before) {
+		remaining[hook]++
+	}
+	for _, hook := range hookEntries(after) {
+		if remaining[hook] > 0 {
+			remaining[hook]--
+			changes.Kept = append(changes.Kept, hook)
+		} else {
+			changes.Added = append(changes.Added, hook)
+		}
+	}
+	for _, hook := range hookEntries(before) {
+		if remaining[hook] > 0 {
+			remaining[hook]--
+			changes.Removed = append(changes.Removed, hook)
+		}
+	}
+	return changes
+}
+
+// HookDiff returns the hook changes from a settings.json on disk to the given settings
+func HookDiff(path string, settingsConfig *types.ClaudeSettingsConfig) (HookChanges, error) {
+	current, err := LoadExistingSettings(path)
+	if err != nil {
+		return HookChanges{}, err
+	}
+	return DiffHooks(current.Hooks, settingsConfig.Hooks), nil
+}
@@ -1,22 +1,202 @@
 package claude
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
+	"github.com/riaanpieterse81/ClaudeToGo/internal/diff"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
 )
 
+// HookEvents are the Claude Code hook types ClaudeToGo can be installed for
+var HookEvents = []string{"Stop", "Notification", "PreToolUse", "PostToolUse", "UserPromptSubmit", "SessionStart", "SessionEnd"}
+
+// toolHookEvents are the hook types that fire per tool call, where matchers select tools
+var toolHookEvents = map[string]bool{"PreToolUse": true, "PostToolUse": true}
+
+// EditToolsMatcher matches the tools that run commands or change files
+const EditToolsMatcher = "Bash|Write|Edit"
+
+// HookSelection chooses the hook types to install and the tool pattern each is matched
+// against, so hooks only run for the tools you care about
+type HookSelection struct {
+	Events   []string
+	Matchers map[string]string // Matcher by hook type, e.g. "Bash|Write|Edit"; "*" (all tools) when unset
+}
+
+// DefaultHookSelection installs the Stop and Notification hooks for all tools
+func DefaultHookSelection() HookSelection {
+	return HookSelection{Events: []string{"Stop", "Notification"}, Matchers: make(map[string]string)}
+}
+
+// Matcher returns the matcher a hook type is installed under
+func (s HookSelection) Matcher(event string) string {
+	if matcher := s.Matchers[event]; matcher != "" {
+		return matcher
+	}
+	return "*"
+}
+
+// SetMatcher sets the matcher of one hook type
+func (s *HookSelection) SetMatcher(event, matcher string) {
+	if s.Matchers == nil {
+		s.Matchers = make(map[string]string)
+	}
+	s.Matchers[event] = matcher
+}
+
+// SetToolMatcher sets the matcher of the per-tool hook types (PreToolUse, PostToolUse)
+func (s *HookSelection) SetToolMatcher(matcher string) {
+	for event := range toolHookEvents {
+		s.SetMatcher(event, matcher)
+	}
+}
+
+// ValidateMatcher checks that a matcher is "*" or a valid tool name pattern (a regular
+// expression such as "Bash|Write|Edit")
+func ValidateMatcher(matcher string) error {
+	if matcher == "" || matcher == "*" {
+		return nil
+	}
+	if _, err := regexp.Compile(matcher); err != nil {
+		return fmt.Errorf("invalid matcher %q: %w", matcher, err)
+	}
+	return nil
+}
+
 // IsClaudeToGoHook identifies if a command is a ClaudeToGo hook
 func IsClaudeToGoHook(command string) bool {
 	return strings.Contains(command, "claudetogo") && strings.Contains(command, "--hook")
 }
 
+// HookBinary returns the executable a hook command runs
+func HookBinary(command string) string {
+	command = strings.TrimSpace(command)
+	if strings.HasPrefix(command, `"`) || strings.HasPrefix(command, "'") {
+		if end := strings.IndexByte(command[1:], command[0]); end >= 0 {
+			return command[1 : end+1]
+		}
+	}
+	if fields := strings.Fields(command); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// InstalledHooks returns the ClaudeToGo hook commands in a settings.json by hook type
+func InstalledHooks(path string) (map[string][]string, error) {
+	entries, err := ListHooks(path)
+	if err != nil {
+		return nil, err
+	}
+
+	installed := make(map[string][]string)
+	for _, entry := range entries {
+		if entry.ClaudeToGo {
+			installed[entry.Event] = append(installed[entry.Event], entry.Command)
+		}
+	}
+	return installed, nil
+}
+
+// HookEntry is one hook command in a settings.json
+type HookEntry struct {
+	Event      string `json:"event"`
+	Matcher    string `json:"matcher"`
+	Command    string `json:"command"`
+	ClaudeToGo bool   `json:"claudetogo"`
+}
+
+// ListHooks returns every hook command in a settings.json, with the hook types in
+// HookEvents order followed by any others alphabetically
+func ListHooks(path string) ([]HookEntry, error) {
+	settingsConfig, err := LoadExistingSettings(path)
+	if err != nil {
+		return nil, err
+	}
+	return hookEntries(settingsConfig.Hooks), nil
+}
+
+// hookEntries flattens a hooks map into its commands, in ListHooks order
+func hookEntries(hooks map[string][]types.HookMatcher) []HookEntry {
+	var entries []HookEntry
+	for _, event := range sortedHookTypes(hooks) {
+		for _, matcher := range hooks[event] {
+			for _, hook := range matcher.Hooks {
+				entries = append(entries, HookEntry{
+					Event:      event,
+					Matcher:    matcher.Matcher,
+					Command:    hook.Command,
+					ClaudeToGo: IsClaudeToGoHook(hook.Command),
+				})
+			}
+		}
+	}
+	return entries
+}
+
+// sortedHookTypes returns the hook types in HookEvents order followed by any others
+// alphabetically
+func sortedHookTypes(hooks map[string][]types.HookMatcher) []string {
+	var events []string
+	for event := range hooks {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		a, b := slices.Index(HookEvents, events[i]), slices.Index(HookEvents, events[j])
+		if a < 0 || b < 0 {
+			if a != b {
+				return b < 0
+			}
+			return events[i] < events[j]
+		}
+		return a < b
+	})
+	return events
+}
+
+// SettingsLocations returns the global, project and local settings.json locations
+func SettingsLocations() ([]types.ConfigLocation, error) {
+	// Detect current working directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("could not get current directory: %w", err)
+	}
+
+	locations, err := ProjectSettingsLocations(cwd)
+	if err != nil {
+		return nil, err
+	}
+	return []types.ConfigLocation{locations[3], locations[2], locations[1]}, nil
+}
+
+// ProjectLocations returns the project and local settings files of a project directory
+func ProjectLocations(dir string) []types.ConfigLocation {
+	return []types.ConfigLocation{
+		{
+			Path:        filepath.Join(dir, ".claude", "settings.json"),
+			Description: "Project configuration (shared with team, committed to repo)",
+			Scope:       "project",
+			Precedence:  3,
+		},
+		{
+			Path:        filepath.Join(dir, ".claude", "settings.local.json"),
+			Description: "Local project configuration (personal, not committed)",
+			Scope:       "local",
+			Precedence:  2,
+		},
+	}
+}
+
 // CleanupAllClaudeToGoHooks removes ClaudeToGo hooks from all hook types
 // If a hook type contains only ClaudeToGo hooks, the entire type is removed
 // If it contains mixed hooks, only ClaudeToGo hooks are filtered out
@@ -83,16 +263,16 @@ func BuildClaudeToGoCommand(config types.ConfigFile) string {
 	return cmd.String()
 }
 
-// UpdateHookType adds our ClaudeToGo hook to existing matchers (ClaudeToGo hooks already cleaned up)
-func UpdateHookType(existingMatchers []types.HookMatcher, newCommand string, timeout int) []types.HookMatcher {
+// UpdateHookType adds our ClaudeToGo hook under the given matcher (ClaudeToGo hooks already cleaned up)
+func UpdateHookType(existingMatchers []types.HookMatcher, matcherPattern, newCommand string, timeout int) []types.HookMatcher {
 	var updatedMatchers []types.HookMatcher
-	hasWildcardMatcher := false
+	hasMatcher := false
 
-	// Preserve all existing matchers and add our hook to wildcard matcher if it exists
+	// Preserve all existing matchers and add our hook to the same matcher if it exists
 	for _, matcher := range existingMatchers {
-		if matcher.Matcher == "*" {
-			hasWildcardMatcher = true
-			// Add our hook to existing wildcard matcher
+		if matcher.Matcher == matcherPattern {
+			hasMatcher = true
+			// Add our hook to the existing matcher
 			updatedHooks := append(matcher.Hooks, types.HookConfig{
 				Type:    "command",
 				Command: newCommand,
@@ -103,15 +283,15 @@ func UpdateHookType(existingMatchers []types.HookMatcher, newCommand string, tim
 				Hooks:   updatedHooks,
 			})
 		} else {
-			// Preserve non-wildcard matchers as-is
+			// Preserve other matchers as-is
 			updatedMatchers = append(updatedMatchers, matcher)
 		}
 	}
 
-	// If no wildcard matcher exists, create one with our hook
-	if !hasWildcardMatcher {
+	// If no such matcher exists, create one with our hook
+	if !hasMatcher {
 		updatedMatchers = append(updatedMatchers, types.HookMatcher{
-			Matcher: "*",
+			Matcher: matcherPattern,
 			Hooks: []types.HookConfig{
 				{
 					Type:    "command",
@@ -125,6 +305,63 @@ func UpdateHookType(existingMatchers []types.HookMatcher, newCommand string, tim
 	return updatedMatchers
 }
 
+// AddHook adds the ClaudeToGo hook command to one hook type under a matcher. A ClaudeToGo hook
+// already under that matcher is replaced, so adding twice changes nothing; ClaudeToGo hooks
+// under other matchers are kept.
+func AddHook(hooks map[string][]types.HookMatcher, event, matcher, command string, timeout int) {
+	matchers, _ := withoutClaudeToGoHooks(hooks[event], matcher)
+	hooks[event] = UpdateHookType(matchers, matcher, command, timeout)
+}
+
+// RemoveHooks removes the ClaudeToGo hooks of one hook type, or of every type when event is
+// empty, and only those under matcher when it isn't empty. Matchers and hook types left
+// without hooks are dropped. It returns the number of hooks removed.
+func RemoveHooks(hooks map[string][]types.HookMatcher, event, matcher string) int {
+	removed := 0
+	for hookType, matchers := range hooks {
+		if event != "" && hookType != event {
+			continue
+		}
+		kept, n := withoutClaudeToGoHooks(matchers, matcher)
+		if n == 0 {
+			continue
+		}
+		removed += n
+		if len(kept) == 0 {
+			delete(hooks, hookType)
+		} else {
+			hooks[hookType] = kept
+		}
+	}
+	return removed
+}
+
+// withoutClaudeToGoHooks filters the ClaudeToGo hooks out of the matchers with the given
+// pattern (all matchers when it is empty), dropping matchers left empty. It also returns the
+// number of hooks removed.
+func withoutClaudeToGoHooks(matchers []types.HookMatcher, pattern string) ([]types.HookMatcher, int) {
+	var kept []types.HookMatcher
+	removed := 0
+	for _, matcher := range matchers {
+		if pattern != "" && matcher.Matcher != pattern {
+			kept = append(kept, matcher)
+			continue
+		}
+		var hooks []types.HookConfig
+		for _, hook := range matcher.Hooks {
+			if IsClaudeToGoHook(hook.Command) {
+				removed++
+			} else {
+				hooks = append(hooks, hook)
+			}
+		}
+		if len(hooks) > 0 {
+			kept = append(kept, types.HookMatcher{Matcher: matcher.Matcher, Hooks: hooks})
+		}
+	}
+	return kept, removed
+}
+
 // LoadExistingSettings safely loads existing settings.json while preserving unknown fields
 func LoadExistingSettings(path string) (*types.ClaudeSettingsConfig, error) {
 	var settingsConfig types.ClaudeSettingsConfig
@@ -136,16 +373,15 @@ func LoadExistingSettings(path string) (*types.ClaudeSettingsConfig, error) {
 	}
 
 	// File exists, load it with full preservation
-	file, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("could not open existing settings.json: %w", err)
 	}
-	defer file.Close()
+	settingsConfig.Loaded = fingerprint(data)
 
 	// First, read into a generic map to capture all fields
 	var rawConfig map[string]json.RawMessage
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&rawConfig); err != nil {
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&rawConfig); err != nil {
 		return nil, fmt.Errorf("could not parse existing settings.json: %w", err)
 	}
 
@@ -186,8 +422,8 @@ func copyFile(src, dst string) error {
 	return err
 }
 
-// SaveSettingsWithPreservation safely saves settings while preserving unknown fields
-func SaveSettingsWithPreservation(settingsConfig *types.ClaudeSettingsConfig, path string) error {
+// RenderSettings returns the settings.json content for a configuration, preserving unknown fields
+func RenderSettings(settingsConfig *types.ClaudeSettingsConfig) ([]byte, error) {
 	// Create a map to hold the final JSON structure
 	finalConfig := make(map[string]any)
 
@@ -195,7 +431,7 @@ func SaveSettingsWithPreservation(settingsConfig *types.ClaudeSettingsConfig, pa
 	for key, value := range settingsConfig.Extra {
 		var unmarshaled any
 		if err := json.Unmarshal(value, &unmarshaled); err != nil {
-			return fmt.Errorf("could not unmarshal preserved field %s: %w", key, err)
+			return nil, fmt.Errorf("could not unmarshal preserved field %s: %w", key, err)
 		}
 		finalConfig[key] = unmarshaled
 	}
@@ -205,48 +441,84 @@ func SaveSettingsWithPreservation(settingsConfig *types.ClaudeSettingsConfig, pa
 		finalConfig["hooks"] = settingsConfig.Hooks
 	}
 
-	// Create backup of existing file
+	var buf strings.Builder
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(finalConfig); err != nil {
+		return nil, err
+	}
+	return []byte(buf.String()), nil
+}
+
+// SaveSettingsWithPreservation safely saves settings while preserving unknown fields. It
+// returns ErrSettingsChanged instead of overwriting changes made to the file since it was
+// loaded.
+func SaveSettingsWithPreservation(settingsConfig *types.ClaudeSettingsConfig, path string) error {
+	data, err := RenderSettings(settingsConfig)
+	if err != nil {
+		return err
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not read %s: %w", path, err)
+	}
+	if (err == nil && fingerprint(current) != settingsConfig.Loaded) || (err != nil && settingsConfig.Loaded != "") {
+		return fmt.Errorf("%s: %w", path, ErrSettingsChanged)
+	}
+
+	// Create a timestamped backup of the existing file
 	if _, err := os.Stat(path); err == nil {
-		backupPath := path + ".backup"
-		if err := copyFile(path, backupPath); err != nil {
+		if _, err := BackupSettings(path); err != nil {
 			// Log warning but don't fail
-			log.Printf("[WARNING] Could not create backup at %s: %v", backupPath, err)
+			logger.New(false).Warn("Could not back up %s: %v", path, err)
 		}
 	}
 
 	// Write the merged configuration
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("could not create settings.json: %w", err)
-	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(finalConfig); err != nil {
+	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("could not write settings.json: %w", err)
 	}
 
 	return nil
 }
 
-// ConfigureHooksAtLocation configures Claude Code hooks at specified location
-func ConfigureHooksAtLocation(config types.ConfigFile, location *types.ConfigLocation) error {
-	// Ensure directory exists
-	claudeDir := filepath.Dir(location.Path)
-	if err := os.MkdirAll(claudeDir, 0755); err != nil {
-		return fmt.Errorf("could not create directory %s: %w", claudeDir, err)
+// PlanHooksAtLocation returns the settings at a location with the selected ClaudeToGo hooks
+// installed, without writing them
+func PlanHooksAtLocation(config types.ConfigFile, location *types.ConfigLocation, selection HookSelection) (*types.ClaudeSettingsConfig, error) {
+	for _, hookType := range selection.Events {
+		if err := ValidateMatcher(selection.Matcher(hookType)); err != nil {
+			return nil, fmt.Errorf("%s: %w", hookType, err)
+		}
 	}
 
-	// Build the command from config
-	newCommand := BuildClaudeToGoCommand(config)
-	timeout := 30
-
 	// Load existing settings.json safely while preserving unknown fields
 	settingsConfig, err := LoadExistingSettings(location.Path)
 	if err != nil {
-		return fmt.Errorf("could not load existing settings: %w", err)
+		return nil, fmt.Errorf("could not load existing settings: %w", err)
+	}
+	installHooks(settingsConfig, BuildClaudeToGoCommand(config), selection)
+	return settingsConfig, nil
+}
+
+// PreviewHooksAtLocation plans hook installation like PlanHooksAtLocation and also returns
+// the hooks it adds, removes and keeps, so callers can show them before saving the plan with
+// ConfigureHooksAtLocation
+func PreviewHooksAtLocation(config types.ConfigFile, location *types.ConfigLocation, selection HookSelection) (*types.ClaudeSettingsConfig, HookChanges, error) {
+	settingsConfig, err := PlanHooksAtLocation(config, location, selection)
+	if err != nil {
+		return nil, HookChanges{}, err
+	}
+	changes, err := HookDiff(location.Path, settingsConfig)
+	if err != nil {
+		return nil, HookChanges{}, err
 	}
+	return settingsConfig, changes, nil
+}
+
+// installHooks replaces the ClaudeToGo hooks in settings with the selected ones
+func installHooks(settingsConfig *types.ClaudeSettingsConfig, command string, selection HookSelection) {
+	timeout := 30
 
 	// Initialize hooks if nil
 	if settingsConfig.Hooks == nil {
@@ -256,14 +528,45 @@ func ConfigureHooksAtLocation(config types.ConfigFile, location *types.ConfigLoc
 	// Clean up all ClaudeToGo hooks from all hook types before adding new ones
 	CleanupAllClaudeToGoHooks(settingsConfig.Hooks)
 
-	// Add our new ClaudeToGo hooks to target hook types
-	targetHooks := []string{"Stop", "Notification"}
-	for _, hookType := range targetHooks {
-		settingsConfig.Hooks[hookType] = UpdateHookType(settingsConfig.Hooks[hookType], newCommand, timeout)
+	// Add our new ClaudeToGo hooks to the selected hook types
+	for _, hookType := range selection.Events {
+		settingsConfig.Hooks[hookType] = UpdateHookType(settingsConfig.Hooks[hookType], selection.Matcher(hookType), command, timeout)
+	}
+}
+
+// SettingsDiff returns a unified diff from a settings.json on disk to the given settings; it
+// is empty when saving them would not change the file
+func SettingsDiff(path string, settingsConfig *types.ClaudeSettingsConfig) (string, error) {
+	after, err := RenderSettings(settingsConfig)
+	if err != nil {
+		return "", err
+	}
+
+	before, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("could not read %s: %w", path, err)
+	}
+	oldName := path
+	if os.IsNotExist(err) {
+		oldName = "/dev/null"
+	}
+
+	return diff.Unified(oldName, path, string(before), string(after), 3), nil
+}
+
+// ConfigureHooksAtLocation saves settings planned by PlanHooksAtLocation. If the file changed
+// since it was planned, the hooks are installed into its new contents once confirmed.
+func ConfigureHooksAtLocation(config types.ConfigFile, location *types.ConfigLocation, selection HookSelection, settingsConfig *types.ClaudeSettingsConfig) error {
+	// Ensure directory exists
+	claudeDir := filepath.Dir(location.Path)
+	if err := os.MkdirAll(claudeDir, 0755); err != nil {
+		return fmt.Errorf("could not create directory %s: %w", claudeDir, err)
 	}
 
 	// Save the updated settings.json while preserving existing configuration
-	if err := SaveSettingsWithPreservation(settingsConfig, location.Path); err != nil {
+	command := BuildClaudeToGoCommand(config)
+	install := func(fresh *types.ClaudeSettingsConfig) { installHooks(fresh, command, selection) }
+	if err := SaveSettingsEdit(settingsConfig, location.Path, install); err != nil {
 		return fmt.Errorf("could not save settings.json: %w", err)
 	}
 
@@ -0,0 +1,61 @@
+package claude
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// ErrSettingsChanged is returned when saving a settings file another program changed after
+// it was loaded
+var ErrSettingsChanged = errors.New("settings file was changed by another program since it was read")
+
+// fingerprint identifies the contents of a settings file
+func fingerprint(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveSettingsEdit saves settings that edit produced from a loaded settings file. If another
+// program changed the file in the meantime, edit is applied again to the new contents and
+// the result is shown and only saved once confirmed, so neither side's changes are lost.
+func SaveSettingsEdit(settingsConfig *types.ClaudeSettingsConfig, path string, edit func(*types.ClaudeSettingsConfig)) error {
+	for {
+		err := SaveSettingsWithPreservation(settingsConfig, path)
+		if !errors.Is(err, ErrSettingsChanged) {
+			return err
+		}
+
+		fresh, err := LoadExistingSettings(path)
+		if err != nil {
+			return fmt.Errorf("%s changed since it was read and can't be loaded again: %w", path, err)
+		}
+		if fresh.Hooks == nil {
+			fresh.Hooks = make(map[string][]types.HookMatcher)
+		}
+		edit(fresh)
+		preview, err := SettingsDiff(path, fresh)
+		if err != nil {
+			return err
+		}
+		if preview == "" {
+			fmt.Printf("✓ %s was changed by another program and already has these changes\n", path)
+			return nil
+		}
+
+		fmt.Printf("⚠️  %s was changed by another program since it was read.\n", path)
+		fmt.Println("The same changes applied to its new contents:")
+		fmt.Println(preview)
+		fmt.Print("Save these changes instead? [y/N]: ")
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" && strings.ToLower(answer) != "yes" {
+			return fmt.Errorf("%s: %w; nothing saved", path, ErrSettingsChanged)
+		}
+		settingsConfig = fresh
+	}
+}
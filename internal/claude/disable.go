@@ -0,0 +1,121 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// disabledHooksSuffix names the file next to a settings file that holds its disabled
+// ClaudeToGo hooks
+const disabledHooksSuffix = ".claudetogo-disabled"
+
+// DisabledHooksPath returns the file the disabled ClaudeToGo hooks of a settings file are
+// moved to
+func DisabledHooksPath(path string) string {
+	return path + disabledHooksSuffix
+}
+
+// LoadDisabledHooks returns the ClaudeToGo hooks disabled in a settings file, or nil if
+// there are none
+func LoadDisabledHooks(path string) (map[string][]types.HookMatcher, error) {
+	data, err := os.ReadFile(DisabledHooksPath(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read disabled hooks: %w", err)
+	}
+	var disabled map[string][]types.HookMatcher
+	if err := json.Unmarshal(data, &disabled); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", DisabledHooksPath(path), err)
+	}
+	return disabled, nil
+}
+
+// SaveDisabledHooks writes the disabled ClaudeToGo hooks of a settings file, removing the
+// file when there are none
+func SaveDisabledHooks(path string, disabled map[string][]types.HookMatcher) error {
+	if len(disabled) == 0 {
+		if err := os.Remove(DisabledHooksPath(path)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not remove %s: %w", DisabledHooksPath(path), err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(disabled, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(DisabledHooksPath(path), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("could not write %s: %w", DisabledHooksPath(path), err)
+	}
+	return nil
+}
+
+// DisableHooks moves the ClaudeToGo hooks out of a hooks map into disabled, keeping their
+// hook types and matchers, and returns how many it moved. Other hooks stay in place.
+func DisableHooks(hooks, disabled map[string][]types.HookMatcher) int {
+	moved := 0
+	for event, matchers := range hooks {
+		for _, matcher := range matchers {
+			var ours []types.HookConfig
+			for _, hook := range matcher.Hooks {
+				if IsClaudeToGoHook(hook.Command) {
+					ours = append(ours, hook)
+				}
+			}
+			if len(ours) > 0 {
+				disabled[event] = append(disabled[event], types.HookMatcher{Matcher: matcher.Matcher, Hooks: ours})
+				moved += len(ours)
+			}
+		}
+	}
+	CleanupAllClaudeToGoHooks(hooks)
+	return moved
+}
+
+// EnableHooks puts disabled ClaudeToGo hooks back into a hooks map as they were, and returns
+// how many it restored. ClaudeToGo hooks added under the same hook type and matcher in the
+// meantime make way for them.
+func EnableHooks(hooks, disabled map[string][]types.HookMatcher) int {
+	for event, matchers := range disabled {
+		for _, matcher := range matchers {
+			hooks[event], _ = withoutClaudeToGoHooks(hooks[event], matcher.Matcher)
+		}
+	}
+
+	enabled := 0
+	for event, matchers := range disabled {
+		for _, matcher := range matchers {
+			hooks[event] = appendToMatcher(hooks[event], matcher)
+			enabled += len(matcher.Hooks)
+		}
+	}
+	return enabled
+}
+
+// appendToMatcher adds hooks to the matcher with the same pattern, or as a new matcher
+func appendToMatcher(matchers []types.HookMatcher, add types.HookMatcher) []types.HookMatcher {
+	for i := range matchers {
+		if matchers[i].Matcher == add.Matcher {
+			matchers[i].Hooks = append(matchers[i].Hooks, add.Hooks...)
+			return matchers
+		}
+	}
+	return append(matchers, types.HookMatcher{Matcher: add.Matcher, Hooks: slices.Clone(add.Hooks)})
+}
+
+// DisabledHookCount returns how many ClaudeToGo hooks of a settings file are disabled
+func DisabledHookCount(path string) (int, error) {
+	disabled, err := LoadDisabledHooks(path)
+	count := 0
+	for _, matchers := range disabled {
+		for _, matcher := range matchers {
+			count += len(matcher.Hooks)
+		}
+	}
+	return count, err
+}
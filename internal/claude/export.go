@@ -0,0 +1,120 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// hookExportVersion is the format version written by ExportHooks
+const hookExportVersion = 1
+
+// hookPolicyKeys are the settings that decide whether Claude Code runs hooks
+var hookPolicyKeys = []string{"disableAllHooks", "allowManagedHooksOnly"}
+
+// HookExport is a portable ClaudeToGo hook configuration. Commands aren't included: they
+// hold the binary path and events file of one machine, so importing builds them anew.
+type HookExport struct {
+	Version int             `json:"version"`
+	Hooks   []ExportedHook  `json:"hooks"`
+	Policy  map[string]bool `json:"policy,omitempty"` // disableAllHooks and allowManagedHooksOnly, when set
+}
+
+// ExportedHook is one ClaudeToGo hook in a HookExport
+type ExportedHook struct {
+	Event   string `json:"event"`
+	Matcher string `json:"matcher"`
+	Timeout int    `json:"timeout,omitempty"`
+}
+
+// ExportHooks returns the ClaudeToGo hooks and hook policy of a settings file
+func ExportHooks(path string) (*HookExport, error) {
+	settingsConfig, err := LoadExistingSettings(path)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &HookExport{Version: hookExportVersion, Hooks: []ExportedHook{}}
+	for _, event := range sortedHookTypes(settingsConfig.Hooks) {
+		for _, matcher := range settingsConfig.Hooks[event] {
+			for _, hook := range matcher.Hooks {
+				if !IsClaudeToGoHook(hook.Command) {
+					continue
+				}
+				exported := ExportedHook{Event: event, Matcher: matcher.Matcher}
+				if hook.Timeout != nil {
+					exported.Timeout = *hook.Timeout
+				}
+				export.Hooks = append(export.Hooks, exported)
+			}
+		}
+	}
+	for _, key := range hookPolicyKeys {
+		if _, ok := settingsConfig.Extra[key]; ok {
+			if export.Policy == nil {
+				export.Policy = make(map[string]bool)
+			}
+			export.Policy[key] = settingFlag(settingsConfig, key)
+		}
+	}
+	return export, nil
+}
+
+// ParseHookExport reads and checks an exported hook configuration
+func ParseHookExport(data []byte) (*HookExport, error) {
+	var export HookExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("not a hook export: %w", err)
+	}
+	if export.Version < 1 || export.Version > hookExportVersion {
+		return nil, fmt.Errorf("unsupported hook export version %d (this version reads %d)", export.Version, hookExportVersion)
+	}
+	for i, hook := range export.Hooks {
+		if !slices.Contains(HookEvents, hook.Event) {
+			return nil, fmt.Errorf("hooks[%d]: unknown hook type %q (use %s)", i, hook.Event, strings.Join(HookEvents, ", "))
+		}
+		if err := ValidateMatcher(hook.Matcher); err != nil {
+			return nil, fmt.Errorf("hooks[%d]: %w", i, err)
+		}
+		if hook.Timeout < 0 {
+			return nil, fmt.Errorf("hooks[%d]: timeout must be non-negative", i)
+		}
+	}
+	for key := range export.Policy {
+		if !slices.Contains(hookPolicyKeys, key) {
+			return nil, fmt.Errorf("unknown policy setting %q (use %s)", key, strings.Join(hookPolicyKeys, ", "))
+		}
+	}
+	return &export, nil
+}
+
+// Apply replaces the ClaudeToGo hooks in settings with the exported ones, all running
+// command, and sets the exported policy
+func (e *HookExport) Apply(settingsConfig *types.ClaudeSettingsConfig, command string) {
+	if settingsConfig.Hooks == nil {
+		settingsConfig.Hooks = make(map[string][]types.HookMatcher)
+	}
+	CleanupAllClaudeToGoHooks(settingsConfig.Hooks)
+	for _, hook := range e.Hooks {
+		timeout := hook.Timeout
+		if timeout == 0 {
+			timeout = 30
+		}
+		matcher := hook.Matcher
+		if matcher == "" {
+			matcher = "*"
+		}
+		AddHook(settingsConfig.Hooks, hook.Event, matcher, command, timeout)
+	}
+
+	if settingsConfig.Extra == nil {
+		settingsConfig.Extra = make(map[string]json.RawMessage)
+	}
+	for key, value := range e.Policy {
+		raw, _ := json.Marshal(value)
+		settingsConfig.Extra[key] = raw
+	}
+}
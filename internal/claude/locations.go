@@ -0,0 +1,176 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// ManagedSettingsPath returns where administrators deploy Claude Code's managed-settings.json
+// on this OS. Its settings take precedence over all others.
+func ManagedSettingsPath() string {
+	var candidates []string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = []string{"/Library/Application Support/ClaudeCode/managed-settings.json"}
+	case "windows":
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		programFiles := os.Getenv("ProgramFiles")
+		if programFiles == "" {
+			programFiles = `C:\Program Files`
+		}
+		// Older releases read it from Program Files
+		candidates = []string{
+			filepath.Join(programData, "ClaudeCode", "managed-settings.json"),
+			filepath.Join(programFiles, "ClaudeCode", "managed-settings.json"),
+		}
+	default:
+		candidates = []string{"/etc/claude-code/managed-settings.json"}
+	}
+
+	for _, path := range candidates {
+		if fileExists(path) {
+			return path
+		}
+	}
+	return candidates[0]
+}
+
+// UserConfigPath returns Claude Code's ~/.claude.json, which holds user state and, in older
+// releases, settings such as hooks
+func UserConfigPath() (string, error) {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, ".claude.json"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".claude.json"), nil
+}
+
+// userClaudeDir returns the directory of the user's settings.json, ~/.claude unless
+// CLAUDE_CONFIG_DIR points elsewhere
+func userClaudeDir() (string, error) {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".claude"), nil
+}
+
+// AllSettingsLocations returns every file Claude Code reads hooks from, highest precedence
+// first: managed settings, local and project settings of the current directory, the user's
+// settings.json and ~/.claude.json
+func AllSettingsLocations() ([]types.ConfigLocation, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("could not get current directory: %w", err)
+	}
+	return ProjectSettingsLocations(cwd)
+}
+
+// ProjectSettingsLocations returns every file Claude Code reads hooks from when it runs in a
+// project directory, highest precedence first
+func ProjectSettingsLocations(dir string) ([]types.ConfigLocation, error) {
+	userDir, err := userClaudeDir()
+	if err != nil {
+		return nil, err
+	}
+	userConfig, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	project := ProjectLocations(dir)
+	return []types.ConfigLocation{
+		{
+			Path:        ManagedSettingsPath(),
+			Description: "Enterprise managed settings (set by administrators, overrides all others)",
+			Scope:       "managed",
+			Precedence:  1,
+		},
+		project[1],
+		project[0],
+		{
+			Path:        filepath.Join(userDir, "settings.json"),
+			Description: "Global configuration (affects all projects)",
+			Scope:       "global",
+			Precedence:  4,
+		},
+		{
+			Path:        userConfig,
+			Description: "User state file (~/.claude.json, where older releases kept settings)",
+			Scope:       "legacy",
+			Precedence:  5,
+		},
+	}, nil
+}
+
+// HookPolicy holds the settings that stop hooks in some files from running
+type HookPolicy struct {
+	ManagedPath string // Managed settings file
+	ManagedOnly bool   // allowManagedHooksOnly is set in managed settings
+	DisabledBy  string // Highest-precedence file setting disableAllHooks to true
+}
+
+// LoadHookPolicy reads allowManagedHooksOnly and disableAllHooks from the settings files that
+// apply in a project directory. For disableAllHooks the highest-precedence file that sets it
+// decides.
+func LoadHookPolicy(projectDir string) (HookPolicy, error) {
+	locations, err := ProjectSettingsLocations(projectDir)
+	if err != nil {
+		return HookPolicy{}, err
+	}
+
+	policy := HookPolicy{ManagedPath: locations[0].Path}
+	disableDecided := false
+	for _, location := range locations {
+		settingsConfig, err := LoadExistingSettings(location.Path)
+		if err != nil {
+			continue
+		}
+		if location.Scope == "managed" {
+			policy.ManagedOnly = settingFlag(settingsConfig, "allowManagedHooksOnly")
+		}
+		if raw, ok := settingsConfig.Extra["disableAllHooks"]; ok && !disableDecided {
+			disableDecided = true
+			var disabled bool
+			if json.Unmarshal(raw, &disabled) == nil && disabled {
+				policy.DisabledBy = location.Path
+			}
+		}
+	}
+	return policy, nil
+}
+
+// Blocks explains why hooks in a settings file don't run, or returns "" if they do.
+// Managed hooks can only be disabled from managed settings.
+func (p HookPolicy) Blocks(location types.ConfigLocation) string {
+	if p.ManagedOnly && location.Scope != "managed" {
+		return "allowManagedHooksOnly is set in " + p.ManagedPath
+	}
+	if p.DisabledBy != "" && (location.Scope != "managed" || p.DisabledBy == location.Path) {
+		return "disableAllHooks is set in " + p.DisabledBy
+	}
+	return ""
+}
+
+// settingFlag reads a boolean top-level setting
+func settingFlag(settingsConfig *types.ClaudeSettingsConfig, key string) bool {
+	var value bool
+	if raw, ok := settingsConfig.Extra[key]; ok {
+		json.Unmarshal(raw, &value)
+	}
+	return value
+}
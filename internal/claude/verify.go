@@ -0,0 +1,79 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// Errors returned by CheckHookBinary
+var (
+	ErrHookBinaryMissing       = errors.New("hook executable not found")
+	ErrHookBinaryNotExecutable = errors.New("hook executable is not executable")
+)
+
+// CheckHookBinary checks that the executable a hook command runs exists and can be run.
+// Relative paths are resolved against dir, where Claude Code runs the hook; bare names are
+// looked up in PATH.
+func CheckHookBinary(command, dir string) error {
+	binary := HookBinary(command)
+	path := binary
+	switch {
+	case !strings.ContainsAny(binary, `/\`):
+		found, err := exec.LookPath(binary)
+		if err != nil {
+			return fmt.Errorf("%w: %s is not in PATH", ErrHookBinaryMissing, binary)
+		}
+		path = found
+	case !filepath.IsAbs(binary):
+		path = filepath.Join(dir, binary)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrHookBinaryMissing, binary)
+	}
+	if info.IsDir() || (runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0) {
+		return fmt.Errorf("%w: %s", ErrHookBinaryNotExecutable, binary)
+	}
+	return nil
+}
+
+// ReplaceHookBinary returns a hook command that runs execPath with the same arguments
+func ReplaceHookBinary(command, execPath string) string {
+	command = strings.TrimSpace(command)
+	binary := HookBinary(command)
+	rest := command[len(binary):]
+	if strings.HasPrefix(command, `"`) || strings.HasPrefix(command, "'") {
+		rest = command[len(binary)+2:]
+	}
+	if strings.ContainsAny(execPath, " \t") {
+		execPath = `"` + execPath + `"`
+	}
+	return execPath + rest
+}
+
+// RepointHooks rewrites the ClaudeToGo hooks whose executable fails CheckHookBinary to run
+// execPath instead, and returns how many it rewrote
+func RepointHooks(hooks map[string][]types.HookMatcher, execPath, dir string) int {
+	repointed := 0
+	for _, matchers := range hooks {
+		for i := range matchers {
+			for j := range matchers[i].Hooks {
+				hook := &matchers[i].Hooks[j]
+				if !IsClaudeToGoHook(hook.Command) || CheckHookBinary(hook.Command, dir) == nil {
+					continue
+				}
+				hook.Command = ReplaceHookBinary(hook.Command, execPath)
+				repointed++
+			}
+		}
+	}
+	return repointed
+}
@@ -52,6 +52,7 @@ func Apply(configFile *types.ConfigFile, config *types.Config) error {
 	// Apply other settings (command line flags will override these later)
 	config.LogFile = configFile.LogFile
 	config.Verbose = configFile.Verbose
+	config.UsePolling = configFile.UsePolling
 
 	return nil
 }
\ No newline at end of file
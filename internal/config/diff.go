@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// secretKeys are config keys whose values are never shown in diffs
+var secretKeys = map[string]bool{
+	"slack_token":           true,
+	"telegram_token":        true,
+	"custom_headers":        true,
+	"sentry_dsn":            true,
+	"response_api_secret":   true,
+	"slack_signing_secret":  true,
+	"telegram_secret_token": true,
+	"api_tokens":            true,
+	"forward_token":         true,
+	"webhook_url":           true, // Webhook URLs carry their credentials in the path
+	"heartbeat_url":         true,
+}
+
+// Diff describes every setting that differs between two configurations,
+// one "section.key: old -> new" line per setting, with secrets masked
+func Diff(old, new *MessengerConfig) []string {
+	oldValues := make(map[string]interface{})
+	walkFields(reflect.ValueOf(old).Elem(), nil, func(keys []string, field reflect.Value) error {
+		oldValues[strings.Join(keys, ".")] = field.Interface()
+		return nil
+	})
+
+	var changes []string
+	walkFields(reflect.ValueOf(new).Elem(), nil, func(keys []string, field reflect.Value) error {
+		name := strings.Join(keys, ".")
+		before, after := oldValues[name], field.Interface()
+		if reflect.DeepEqual(before, after) {
+			return nil
+		}
+		if secretKeys[keys[len(keys)-1]] {
+			changes = append(changes, fmt.Sprintf("%s: changed", name))
+		} else {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, displayValue(before), displayValue(after)))
+		}
+		return nil
+	})
+	return changes
+}
+
+// displayValue formats a setting for a diff, making empty strings visible
+func displayValue(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return value
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Keys lists the dotted key of every setting, in file order
+func Keys() []string {
+	var keys []string
+	walkFields(reflect.ValueOf(DefaultMessengerConfig()).Elem(), nil, func(path []string, _ reflect.Value) error {
+		keys = append(keys, strings.Join(path, "."))
+		return nil
+	})
+	return keys
+}
+
+// IsSecretKey reports whether a setting holds a token or credentials
+func IsSecretKey(key string) bool {
+	keys := strings.Split(key, ".")
+	return secretKeys[keys[len(keys)-1]]
+}
+
+// Get returns the value of a setting such as "processing.poll_interval", formatted as it
+// would be written in the config file
+func (mc *MessengerConfig) Get(key string) (string, error) {
+	field, err := mc.field(key)
+	if err != nil {
+		return "", err
+	}
+	return formatField(field), nil
+}
+
+// SetValue changes one setting in a YAML config file. The value is parsed like the
+// matching CLAUDETOGO_ environment variable, and the file is only written when the
+// resulting configuration (with all its layers) is valid. The rest of the file, including
+// comments, is kept; a missing file is created from the example config.
+func SetValue(configPath, key, value string) error {
+	field, err := DefaultMessengerConfig().field(key)
+	if err != nil {
+		return err
+	}
+	if err := setEnvField(field, value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	created := false
+	if !fileExists(configPath) {
+		if err := GenerateExampleConfig(configPath); err != nil {
+			return err
+		}
+		created = true
+	}
+
+	err = writeSetting(configPath, strings.Split(key, "."), yamlValue(field))
+	if err != nil && created {
+		os.Remove(configPath)
+	}
+	return err
+}
+
+// writeSetting validates and writes an edited config file
+func writeSetting(configPath string, keys []string, value interface{}) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	edited, err := setDocumentValue(data, keys, value)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", configPath, err)
+	}
+
+	// Check the edited file merged with the other layers
+	_, err = loadMessengerConfig(configPath, func(path string) ([]byte, error) {
+		if sameFile(path, configPath) {
+			return edited, nil
+		}
+		return os.ReadFile(path)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Replace the file atomically so a crash never leaves it half written
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(configPath), "."+filepath.Base(configPath)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(edited); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), configPath); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// field finds the struct field of a dotted key
+func (mc *MessengerConfig) field(key string) (reflect.Value, error) {
+	var found reflect.Value
+	walkFields(reflect.ValueOf(mc).Elem(), nil, func(path []string, field reflect.Value) error {
+		if strings.Join(path, ".") == key {
+			found = field
+		}
+		return nil
+	})
+	if !found.IsValid() {
+		message := fmt.Sprintf("unknown setting %q", key)
+		if suggestion := closestSetting(key); suggestion != "" {
+			message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		return found, fmt.Errorf("%s", message)
+	}
+	return found, nil
+}
+
+// closestSetting suggests a known key for a misspelled one
+func closestSetting(key string) string {
+	best, bestDistance := "", 4
+	for _, known := range Keys() {
+		if d := editDistance(strings.ToLower(key), known); d < bestDistance {
+			best, bestDistance = known, d
+		}
+	}
+	return best
+}
+
+// formatField renders a setting the way it is written in YAML or an environment variable
+func formatField(field reflect.Value) string {
+	switch value := field.Interface().(type) {
+	case time.Duration:
+		return value.String()
+	case map[string]string:
+		pairs := make([]string, 0, len(value))
+		for k, v := range value {
+			pairs = append(pairs, k+"="+v)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	case []ProjectSettings:
+		pairs := make([]string, 0, len(value))
+		for _, project := range value {
+			pairs = append(pairs, project.Name+"="+project.Path)
+		}
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// yamlValue returns the value to encode for a parsed setting
+func yamlValue(field reflect.Value) interface{} {
+	if d, ok := field.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	return field.Interface()
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts every environment variable that overrides a messenger config field
+const EnvPrefix = "CLAUDETOGO_"
+
+// envAliases are the short variable names supported before every field had one.
+// The full names take precedence when both are set.
+var envAliases = map[string]string{
+	"CLAUDETOGO_OUTPUT_DIR":     "CLAUDETOGO_MESSENGER_OUTPUT_DIR",
+	"CLAUDETOGO_LOG_LEVEL":      "CLAUDETOGO_SERVICE_LOG_LEVEL",
+	"CLAUDETOGO_WEBHOOK_URL":    "CLAUDETOGO_INTEGRATIONS_WEBHOOK_URL",
+	"CLAUDETOGO_SLACK_TOKEN":    "CLAUDETOGO_INTEGRATIONS_SLACK_TOKEN",
+	"CLAUDETOGO_TELEGRAM_TOKEN": "CLAUDETOGO_INTEGRATIONS_TELEGRAM_TOKEN",
+}
+
+// EnvironmentVariables lists the variable name of every config field, in file order
+func EnvironmentVariables() []string {
+	var names []string
+	walkFields(reflect.ValueOf(DefaultMessengerConfig()).Elem(), nil, func(keys []string, _ reflect.Value) error {
+		names = append(names, envName(keys))
+		return nil
+	})
+	return names
+}
+
+// ApplyEnvironmentOverrides applies environment variable overrides to config.
+// Every field can be set with CLAUDETOGO_<SECTION>_<FIELD>, named after its YAML keys
+// (e.g. CLAUDETOGO_PROCESSING_POLL_INTERVAL); environment variables override the config file.
+func (mc *MessengerConfig) ApplyEnvironmentOverrides() error {
+	lookup := func(name string) (string, bool) {
+		if value, ok := os.LookupEnv(name); ok && value != "" {
+			return value, true
+		}
+		for alias, target := range envAliases {
+			if target == name {
+				if value, ok := os.LookupEnv(alias); ok && value != "" {
+					return value, true
+				}
+			}
+		}
+		return "", false
+	}
+
+	return walkFields(reflect.ValueOf(mc).Elem(), nil, func(keys []string, field reflect.Value) error {
+		name := envName(keys)
+		value, ok := lookup(name)
+		if !ok {
+			return nil
+		}
+		if err := setEnvField(field, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// envName returns the environment variable for a field's YAML key path
+func envName(keys []string) string {
+	return EnvPrefix + strings.ToUpper(strings.Join(keys, "_"))
+}
+
+// walkFields calls fn for every leaf field of a config struct with its YAML key path
+func walkFields(v reflect.Value, keys []string, fn func(keys []string, field reflect.Value) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		path := append(append([]string(nil), keys...), key)
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := walkFields(field, path, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(path, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setEnvField parses value into a config field according to its type
+func setEnvField(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	case map[string]string:
+		// Maps are written as key=value pairs separated by commas
+		m := make(map[string]string)
+		for _, pair := range strings.Split(value, ",") {
+			key, val, found := strings.Cut(pair, "=")
+			if !found || strings.TrimSpace(key) == "" {
+				return fmt.Errorf("expected key=value pairs, got %q", pair)
+			}
+			m[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+		field.Set(reflect.ValueOf(m))
+		return nil
+	case []string:
+		// Lists are written as values separated by commas
+		var values []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+		field.Set(reflect.ValueOf(values))
+		return nil
+	case []ProjectSettings:
+		// Projects are written as name=path pairs separated by commas
+		var projects []ProjectSettings
+		for _, pair := range strings.Split(value, ",") {
+			name, path, found := strings.Cut(pair, "=")
+			if !found || strings.TrimSpace(name) == "" {
+				return fmt.Errorf("expected name=path pairs, got %q", pair)
+			}
+			projects = append(projects, ProjectSettings{Name: strings.TrimSpace(name), Path: strings.TrimSpace(path)})
+		}
+		field.Set(reflect.ValueOf(projects))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/secrets"
+)
+
+// keyringFields maps the secrets that can live in the OS keyring to their config fields
+func (mc *MessengerConfig) keyringFields() map[string]*string {
+	return map[string]*string{
+		"slack_token":           &mc.Integration.SlackToken,
+		"telegram_token":        &mc.Integration.TelegramToken,
+		"webhook_url":           &mc.Integration.WebhookURL,
+		"response_api_secret":   &mc.Integration.ResponseAPISecret,
+		"slack_signing_secret":  &mc.Integration.SlackSigningSecret,
+		"telegram_secret_token": &mc.Integration.TelegramSecretToken,
+		"forward_token":         &mc.Hooks.ForwardToken,
+		"sentry_dsn":            &mc.Service.ErrorReporting.SentryDSN,
+	}
+}
+
+// ApplyKeyringSecrets fills secret settings that are empty in the config files and
+// environment from the OS keyring, returning the names that were filled. Values set in a
+// file or environment variable always win over the keyring.
+func (mc *MessengerConfig) ApplyKeyringSecrets() ([]string, error) {
+	var applied []string
+	fields := mc.keyringFields()
+	for _, name := range secrets.Names {
+		field := fields[name]
+		if *field != "" {
+			continue
+		}
+		value, err := secrets.Get(name)
+		if errors.Is(err, secrets.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return applied, err
+		}
+		*field = value
+		applied = append(applied, name)
+	}
+	return applied, nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeHeader reads the include list of a messenger config file
+type includeHeader struct {
+	Include []string `yaml:"include"`
+}
+
+// MessengerConfigLayers returns the files that make up a messenger configuration, in the
+// order they are applied: each file's includes (recursively, in list order) before the file
+// itself, then the optional local override next to the top-level file
+// (claudetogo-messenger.local.yaml for claudetogo-messenger.yaml).
+func MessengerConfigLayers(configPath string) ([]string, error) {
+	var layers []string
+	if err := collectLayers(configPath, nil, &layers); err != nil {
+		return nil, err
+	}
+
+	if local := LocalConfigPath(configPath); fileExists(local) {
+		layers = append(layers, local)
+	}
+	return layers, nil
+}
+
+// LocalConfigPath returns the personal override file for a messenger config
+func LocalConfigPath(configPath string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + ".local" + ext
+}
+
+// collectLayers appends the includes of path and then path itself, rejecting include cycles
+func collectLayers(path string, stack []string, layers *[]string) error {
+	for _, parent := range stack {
+		if sameFile(parent, path) {
+			return fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), path)
+		}
+	}
+	if !fileExists(path) {
+		if len(stack) > 0 {
+			return fmt.Errorf("included config file not found: %s (included from %s)", path, stack[len(stack)-1])
+		}
+		return fmt.Errorf("config file not found: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var header includeHeader
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return fmt.Errorf("failed to parse YAML config %s: %w", path, err)
+	}
+
+	stack = append(stack, path)
+	for _, include := range header.Include {
+		if err := collectLayers(resolveInclude(path, include), stack, layers); err != nil {
+			return err
+		}
+	}
+
+	*layers = append(*layers, path)
+	return nil
+}
+
+// resolveInclude resolves an include relative to the directory of the file naming it
+func resolveInclude(from, include string) string {
+	if include == "~" || strings.HasPrefix(include, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(include, "~"))
+		}
+	}
+	if filepath.IsAbs(include) {
+		return include
+	}
+	return filepath.Join(filepath.Dir(from), include)
+}
+
+// sameFile reports whether two paths name the same file
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return os.SameFile(infoA, infoB)
+}
+
+// applyLayers decodes each layer onto config in order. Later layers override earlier ones
+// key by key: nested sections and maps (such as custom_headers) are merged, while scalar
+// values and lists are replaced. It returns a "file:line:col: key: message" warning for
+// every unknown or deprecated key.
+func applyLayers(config *MessengerConfig, layers []string, readFile func(string) ([]byte, error)) ([]string, error) {
+	schema := MessengerSchema()
+	var warnings []string
+	for _, layer := range layers {
+		data, err := readFile(layer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		// Report type errors with their position before decoding
+		issues, err := ValidateDocument(data, schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse YAML config %s: %w", layer, err)
+		}
+		for _, issue := range issues {
+			position := fmt.Sprintf("%s:%d:%d: %s: %s", layer, issue.Line, issue.Column, issue.Path, issue.Message)
+			if !issue.Warning {
+				return nil, fmt.Errorf("%s", position)
+			}
+			warnings = append(warnings, position)
+		}
+
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML config %s: %w", layer, err)
+		}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// LegacyConfigFile is the runtime JSON config written by older versions of the setup wizard
+const LegacyConfigFile = "claudetogo-config.json"
+
+// DefaultConfigFile is the configuration document written by --setup and --config-migrate
+const DefaultConfigFile = "claudetogo-messenger.yaml"
+
+// ApplyLegacyConfig applies a claudetogo-config.json file to the monitor section
+func (mc *MessengerConfig) ApplyLegacyConfig(path string) error {
+	configFile, err := Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", path, err)
+	}
+
+	monitor, err := legacyMonitorSettings(configFile, mc.Monitor)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", path, err)
+	}
+	mc.Monitor = monitor
+	return nil
+}
+
+// legacyMonitorSettings maps the JSON config fields onto monitor settings. Older wizards
+// wrote every field, so values left at their defaults don't override the YAML settings.
+func legacyMonitorSettings(configFile *types.ConfigFile, monitor MonitorSettings) (MonitorSettings, error) {
+	defaults := DefaultMessengerConfig().Monitor
+	if configFile.LogFile != "" && configFile.LogFile != defaults.LogFile {
+		monitor.LogFile = configFile.LogFile
+	}
+	if configFile.PollInterval != "" {
+		interval, err := time.ParseDuration(configFile.PollInterval)
+		if err != nil {
+			return monitor, fmt.Errorf("pollInterval: %w", err)
+		}
+		if interval != defaults.PollInterval {
+			monitor.PollInterval = interval
+		}
+	}
+	if configFile.Verbose {
+		monitor.Verbose = true
+	}
+	if configFile.UsePolling {
+		monitor.UsePolling = true
+	}
+	return monitor, nil
+}
+
+// MigrateLegacyConfig moves the settings of a claudetogo-config.json file into the monitor
+// section of a YAML config (creating it from the example if needed) and renames the JSON
+// file to <name>.bak. It returns the backup path.
+func MigrateLegacyConfig(jsonPath, yamlPath string) (string, error) {
+	configFile, err := Load(jsonPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to load %s: %w", jsonPath, err)
+	}
+	// Start from the YAML settings in effect so values only set there are kept
+	base := DefaultMessengerConfig().Monitor
+	if fileExists(yamlPath) {
+		if current, err := LoadMessengerConfig(yamlPath); err == nil {
+			base = current.Monitor
+		}
+	}
+	monitor, err := legacyMonitorSettings(configFile, base)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", jsonPath, err)
+	}
+
+	if err := SaveMonitorSettings(yamlPath, monitor); err != nil {
+		return "", err
+	}
+
+	backupPath := jsonPath + ".bak"
+	if err := os.Rename(jsonPath, backupPath); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", jsonPath, err)
+	}
+	return backupPath, nil
+}
+
+// SaveMonitorSettings writes the monitor section of a YAML config, keeping the rest of the
+// file (including comments) intact. A missing file is created from the example config.
+func SaveMonitorSettings(yamlPath string, monitor MonitorSettings) error {
+	if !fileExists(yamlPath) {
+		if err := GenerateExampleConfig(yamlPath); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(yamlPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := map[string]interface{}{
+		"log_file":      monitor.LogFile,
+		"poll_interval": monitor.PollInterval.String(),
+		"verbose":       monitor.Verbose,
+		"use_polling":   monitor.UsePolling,
+	}
+	for _, key := range []string{"log_file", "poll_interval", "verbose", "use_polling"} {
+		data, err = setDocumentValue(data, []string{"monitor", key}, values[key])
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", yamlPath, err)
+		}
+	}
+
+	if err := os.WriteFile(yamlPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// DeprecatedKeys lists the deprecated keys set in a YAML config file
+func DeprecatedKeys(yamlPath string) ([]string, error) {
+	data, err := os.ReadFile(yamlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	issues, err := ValidateDocument(data, MessengerSchema())
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, issue := range issues {
+		if issue.Warning && strings.HasPrefix(issue.Message, "deprecated key") {
+			keys = append(keys, issue.Path)
+		}
+	}
+	return keys, nil
+}
+
+// RemoveDeprecatedKeys deletes the deprecated keys from a YAML config file, keeping the rest
+// of the file, and returns the keys removed. Deprecated keys have no effect, so the
+// configuration itself doesn't change.
+func RemoveDeprecatedKeys(yamlPath string) ([]string, error) {
+	keys, err := DeprecatedKeys(yamlPath)
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(yamlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	for _, key := range keys {
+		data, err = deleteDocumentKey(data, strings.Split(key, "."))
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", yamlPath, err)
+		}
+	}
+
+	if err := os.WriteFile(yamlPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	return keys, nil
+}
+
+// BackupFile copies a file to <name>.bak and returns the backup path
+func BackupFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	backupPath := path + ".bak"
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write backup %s: %w", backupPath, err)
+	}
+	return backupPath, nil
+}
@@ -1,21 +1,80 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/channels"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/claude"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/layout"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/redact"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/risk"
 )
 
 // MessengerConfig represents the configuration for messenger integration
 type MessengerConfig struct {
+	Monitor     MonitorSettings     `yaml:"monitor"`
 	Messenger   MessengerSettings   `yaml:"messenger"`
 	Processing  ProcessingSettings  `yaml:"processing"`
 	Service     ServiceSettings     `yaml:"service"`
 	Formatting  FormattingSettings  `yaml:"formatting"`
 	Integration IntegrationSettings `yaml:"integrations"`
+	Hooks       HookSettings        `yaml:"hooks"`
+	Approvals   ApprovalSettings    `yaml:"approvals"`
+	Projects    []ProjectSettings   `yaml:"projects"`
+	Users       []UserSettings      `yaml:"users"`
+
+	warnings []string // Unknown and deprecated keys found while loading
+}
+
+// ErrInvalidConfig is matched by errors loading a configuration that can't be read, parsed
+// or validated
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// configError marks an error as ErrInvalidConfig without changing its message
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap lets errors.Is match both ErrInvalidConfig and the underlying error
+func (e *configError) Unwrap() []error {
+	return []error{ErrInvalidConfig, e.err}
+}
+
+// strict makes unknown and deprecated keys errors instead of warnings
+var strict bool
+
+// SetStrict turns strict validation on or off for configurations loaded afterwards
+func SetStrict(enabled bool) {
+	strict = enabled
+}
+
+// Warnings returns the unknown and deprecated keys found while loading the configuration
+func (mc *MessengerConfig) Warnings() []string {
+	return mc.warnings
+}
+
+// MonitorSettings contains hook logging and monitoring configuration
+// (formerly claudetogo-config.json)
+type MonitorSettings struct {
+	LogFile      string        `yaml:"log_file"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	Verbose      bool          `yaml:"verbose"`
+	UsePolling   bool          `yaml:"use_polling"`
 }
 
 // MessengerSettings contains messenger-specific configuration
@@ -23,26 +82,71 @@ type MessengerSettings struct {
 	OutputDir     string `yaml:"output_dir"`
 	FileFormat    string `yaml:"file_format"`
 	IncludeSamples bool  `yaml:"include_samples"`
+	RotateSizeMB   int   `yaml:"rotate_size_mb"`
+	Layout         string `yaml:"layout"` // Subdirectory template for json files, e.g. "{{.Project}}/{{.Session}}" (empty = flat)
 }
 
 // ProcessingSettings contains event processing configuration
 type ProcessingSettings struct {
-	WatchMode          bool          `yaml:"watch_mode"`
-	PollInterval       time.Duration `yaml:"poll_interval"`
-	MaxEventsPerBatch  int           `yaml:"max_events_per_batch"`
-	AutoProcess        bool          `yaml:"auto_process"`
-	ProcessLatestOnly  int           `yaml:"process_latest_only"`
+	WatchMode         bool                     `yaml:"watch_mode"` // Deprecated: has no effect, use --process --watch
+	PollInterval      time.Duration            `yaml:"poll_interval"`
+	MaxEventsPerBatch int                      `yaml:"max_events_per_batch"`
+	AutoProcess       bool                     `yaml:"auto_process"` // Deprecated: has no effect, run the service
+	ProcessLatestOnly int                      `yaml:"process_latest_only"`
+	MaxLineSizeMB     int                      `yaml:"max_line_size_mb"` // Longer event and transcript lines are skipped
+	Redact            bool                     `yaml:"redact"`           // Mask secrets in everything that is logged or stored
+	RedactPatterns    map[string]string        `yaml:"redact_patterns"`  // Extra secret patterns: name -> regular expression
+	Debounce          map[string]time.Duration `yaml:"debounce"`         // Hook event name -> window in which repeats of an event are dropped
 }
 
 // ServiceSettings contains background service configuration
 type ServiceSettings struct {
-	Enabled        bool          `yaml:"enabled"`
-	DaemonMode     bool          `yaml:"daemon_mode"`
+	Enabled        bool          `yaml:"enabled"`     // Deprecated: has no effect, use --service
+	DaemonMode     bool          `yaml:"daemon_mode"` // Deprecated: has no effect, use --service --daemon
 	PidFile        string        `yaml:"pid_file"`
 	LogLevel       string        `yaml:"log_level"`
+	LogLevels      map[string]string `yaml:"log_levels"` // Per-component overrides of log_level
+	LogFormat      string        `yaml:"log_format"` // text or json
+	LogTarget      string        `yaml:"log_target"` // stderr, syslog or journald
+	LogFile        string        `yaml:"log_file"`   // Service log file; empty logs to stderr only
+	LogMaxSizeMB   int           `yaml:"log_max_size_mb"`
+	LogMaxBackups  int           `yaml:"log_max_backups"`
 	ServiceInterval time.Duration `yaml:"service_interval"`
 	StatusFile     string        `yaml:"status_file"`
 	AutoRestart    bool          `yaml:"auto_restart"`
+	Components     ComponentSettings `yaml:"components"`
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+	HeartbeatURL      string        `yaml:"heartbeat_url"`
+	StallThreshold    time.Duration `yaml:"stall_threshold"`
+	TranscriptsDir    string        `yaml:"transcripts_dir"`
+	ErrorReporting    ErrorReportingSettings `yaml:"error_reporting"`
+	APITokens         map[string]string `yaml:"api_tokens"` // Response API bearer token -> role
+	ChatRoles         map[string]string `yaml:"chat_roles"` // "slack:<user ID>" or "telegram:<user ID>" -> role
+	APITLS            APITLSSettings    `yaml:"api_tls"`
+}
+
+// APITLSSettings configures HTTPS for the response API
+type APITLSSettings struct {
+	CertFile     string `yaml:"cert_file"`      // PEM certificate (chain)
+	KeyFile      string `yaml:"key_file"`       // PEM private key of cert_file
+	ClientCAFile string `yaml:"client_ca_file"` // Require client certificates signed by this CA
+	SelfSigned   bool   `yaml:"self_signed"`    // Generate a self-signed certificate when cert_file is empty
+}
+
+// ErrorReportingSettings configures where the service reports panics and repeated failures
+type ErrorReportingSettings struct {
+	SentryDSN        string `yaml:"sentry_dsn"`        // Sentry project DSN (empty = disabled)
+	WebhookURL       string `yaml:"webhook_url"`       // URL reports are posted to as JSON (empty = disabled)
+	FailureThreshold int    `yaml:"failure_threshold"` // Consecutive failures before they are reported
+}
+
+// ComponentSettings selects the pipeline components the service runs
+type ComponentSettings struct {
+	Watcher      bool   `yaml:"watcher"`       // Turn new events into messenger messages
+	IngestSocket string `yaml:"ingest_socket"` // Unix socket for hook events (empty = disabled)
+	Delivery     bool   `yaml:"delivery"`      // Send new messages to the configured integrations
+	ResponseAPI  string `yaml:"response_api"`  // Listen address for the HTTP response API (empty = disabled)
+	Pprof        bool   `yaml:"pprof"`         // Serve /debug/pprof on the response API
 }
 
 // FormattingSettings contains message formatting configuration
@@ -52,26 +156,96 @@ type FormattingSettings struct {
 	MaxContentPreview  int  `yaml:"max_content_preview"`
 	TimestampFormat    string `yaml:"timestamp_format"`
 	UseRelativeTime    bool `yaml:"use_relative_time"`
+	TemplatesDir       string `yaml:"templates_dir"`
+	ContextMessages    int    `yaml:"context_messages"` // Recent transcript messages behind the last-prompt context (0 = off)
+	ProjectInTitle     bool   `yaml:"project_in_title"` // Start message titles with the project name
 }
 
 // IntegrationSettings contains external integration configuration
 type IntegrationSettings struct {
-	WebhookURL      string            `yaml:"webhook_url"`
-	SlackToken      string            `yaml:"slack_token"`
-	TelegramToken   string            `yaml:"telegram_token"`
-	CustomHeaders   map[string]string `yaml:"custom_headers"`
-	RetryAttempts   int               `yaml:"retry_attempts"`
-	RetryInterval   time.Duration     `yaml:"retry_interval"`
-	TimeoutDuration time.Duration     `yaml:"timeout_duration"`
+	WebhookURL           string            `yaml:"webhook_url"`
+	WebhookPublicKey     string            `yaml:"webhook_public_key"` // Encrypt webhook payloads for this PEM public key
+	SlackToken           string            `yaml:"slack_token"`
+	SlackChannel         string            `yaml:"slack_channel"`
+	TelegramToken        string            `yaml:"telegram_token"`
+	TelegramChatID       string            `yaml:"telegram_chat_id"`
+	ResponseAPISecret    string            `yaml:"response_api_secret"`    // HMAC key for signed response API requests
+	SlackSigningSecret   string            `yaml:"slack_signing_secret"`   // Verifies Slack button clicks sent to the response API
+	TelegramSecretToken  string            `yaml:"telegram_secret_token"`  // Verifies Telegram webhook updates sent to the response API
+	TelegramAllowedUsers []string          `yaml:"telegram_allowed_users"` // Telegram user IDs allowed to respond (empty = anyone)
+	SlackAllowedUsers    []string          `yaml:"slack_allowed_users"`    // Slack user IDs or email addresses allowed to respond
+	CustomHeaders        map[string]string `yaml:"custom_headers"`
+	RetryAttempts        int               `yaml:"retry_attempts"`
+	RetryInterval        time.Duration     `yaml:"retry_interval"`
+	TimeoutDuration      time.Duration     `yaml:"timeout_duration"`
+	TelegramRateLimit    int               `yaml:"telegram_rate_limit"` // Messages per minute sent to Telegram (0 = unlimited)
+	SlackRateLimit       int               `yaml:"slack_rate_limit"`    // Messages per minute sent to Slack (0 = unlimited)
+	WebhookRateLimit     int               `yaml:"webhook_rate_limit"`  // Messages per minute posted to webhook_url (0 = unlimited)
+	FloodThreshold       int               `yaml:"flood_threshold"`     // Messages within flood_window before the rest are sent as one summary (0 = off)
+	FloodWindow          time.Duration     `yaml:"flood_window"`
+	BatchWindow          time.Duration     `yaml:"batch_window"`   // Messages within this window go out as one notification (0 = off)
+	BatchMaxSize         int               `yaml:"batch_max_size"` // Messages in a batch before it is sent early (0 = no limit)
+	// Destinations of messages no user in users claims (empty = telegram_chat_id, slack_channel)
+	EscalationTelegramChatID string `yaml:"escalation_telegram_chat_id"`
+	EscalationSlackChannel   string `yaml:"escalation_slack_channel"`
+}
+
+// HookSettings contains Claude Code hook installation configuration
+type HookSettings struct {
+	BackupRetention int    `yaml:"backup_retention"` // Backups kept per settings file (0 = all)
+	ForwardURL      string `yaml:"forward_url"`      // Remote service --hook also sends events to (empty = local only)
+	ForwardToken    string `yaml:"forward_token"`    // API token for the remote service (empty = none)
+	SpoolDir        string `yaml:"spool_dir"`        // Events waiting for the remote service (empty = user cache dir)
+}
+
+// ApprovalSettings decides which requests take a confirmation code to approve
+type ApprovalSettings struct {
+	ConfirmDangerous  bool              `yaml:"confirm_dangerous"`  // Require a one-time code to approve dangerous requests
+	DangerousCommands map[string]string `yaml:"dangerous_commands"` // Extra Bash command patterns: name -> regular expression
+	ProtectedPaths    []string          `yaml:"protected_paths"`    // Extra file patterns whose writes are dangerous
+}
+
+// ProjectSettings registers a project directory whose Claude Code hooks report to ClaudeToGo
+type ProjectSettings struct {
+	Name           string   `yaml:"name"`
+	Path           string   `yaml:"path"`
+	OutputDir      string   `yaml:"output_dir,omitempty"`       // Messenger output for this project (empty = messenger.output_dir)
+	Channels       []string `yaml:"channels,omitempty"`         // Destinations for this project's notifications: telegram, slack, webhook (empty = all)
+	TelegramChatID string   `yaml:"telegram_chat_id,omitempty"` // Telegram chat for this project (empty = integrations.telegram_chat_id)
+	SlackChannel   string   `yaml:"slack_channel,omitempty"`    // Slack channel for this project (empty = integrations.slack_channel)
+	WebhookURL     string   `yaml:"webhook_url,omitempty"`      // Webhook for this project (empty = integrations.webhook_url)
+}
+
+// UserSettings sends the notifications of one developer's sessions to their own chat
+type UserSettings struct {
+	Name           string   `yaml:"name"`
+	GitAuthors     []string `yaml:"git_authors,omitempty"`      // git user.email or user.name values of the developer
+	Paths          []string `yaml:"paths,omitempty"`            // Directories the developer's sessions run in
+	TelegramChatID string   `yaml:"telegram_chat_id,omitempty"` // The developer's Telegram chat
+	SlackChannel   string   `yaml:"slack_channel,omitempty"`    // The developer's Slack channel or user ID, for a direct message
+}
+
+// HasChannel reports whether a delivery destination is fully configured
+func (is IntegrationSettings) HasChannel() bool {
+	return is.WebhookURL != "" ||
+		(is.TelegramToken != "" && is.TelegramChatID != "") ||
+		(is.SlackToken != "" && is.SlackChannel != "")
 }
 
 // DefaultMessengerConfig returns a configuration with sensible defaults
 func DefaultMessengerConfig() *MessengerConfig {
 	return &MessengerConfig{
+		Monitor: MonitorSettings{
+			LogFile:      "claude-events.jsonl",
+			PollInterval: 100 * time.Millisecond,
+			Verbose:      false,
+			UsePolling:   false,
+		},
 		Messenger: MessengerSettings{
 			OutputDir:      "messenger-output",
 			FileFormat:     "json",
 			IncludeSamples: true,
+			RotateSizeMB:   10,
 		},
 		Processing: ProcessingSettings{
 			WatchMode:         false,
@@ -79,15 +253,33 @@ func DefaultMessengerConfig() *MessengerConfig {
 			MaxEventsPerBatch: 10,
 			AutoProcess:       false,
 			ProcessLatestOnly: 0,
+			MaxLineSizeMB:     64,
+			Redact:            true,
+			Debounce:          map[string]time.Duration{"Notification": 10 * time.Second},
 		},
 		Service: ServiceSettings{
 			Enabled:         false,
 			DaemonMode:      false,
 			PidFile:         "",
 			LogLevel:        "info",
+			LogFormat:       "text",
+			LogTarget:       "stderr",
+			LogFile:         "",
+			LogMaxSizeMB:    10,
+			LogMaxBackups:   3,
 			ServiceInterval: 2 * time.Second,
 			StatusFile:      "",
 			AutoRestart:     false,
+			Components: ComponentSettings{
+				Watcher: true,
+			},
+			HeartbeatInterval: 30 * time.Second,
+			HeartbeatURL:      "",
+			StallThreshold:    30 * time.Minute,
+			TranscriptsDir:    "~/.claude/projects",
+			ErrorReporting: ErrorReportingSettings{
+				FailureThreshold: 3,
+			},
 		},
 		Formatting: FormattingSettings{
 			IncludeEmojis:     true,
@@ -95,38 +287,70 @@ func DefaultMessengerConfig() *MessengerConfig {
 			MaxContentPreview: 200,
 			TimestampFormat:   "2006-01-02 15:04:05",
 			UseRelativeTime:   false,
+			TemplatesDir:      "",
+			ContextMessages:   0,
+			ProjectInTitle:    true,
 		},
 		Integration: IntegrationSettings{
-			WebhookURL:      "",
-			SlackToken:      "",
-			TelegramToken:   "",
-			CustomHeaders:   make(map[string]string),
-			RetryAttempts:   3,
-			RetryInterval:   1 * time.Second,
-			TimeoutDuration: 30 * time.Second,
+			WebhookURL:        "",
+			SlackToken:        "",
+			SlackChannel:      "",
+			TelegramToken:     "",
+			TelegramChatID:    "",
+			CustomHeaders:     make(map[string]string),
+			RetryAttempts:     3,
+			RetryInterval:     1 * time.Second,
+			TimeoutDuration:   30 * time.Second,
+			TelegramRateLimit: 20,
+			SlackRateLimit:    50,
+			FloodThreshold:    10,
+			FloodWindow:       10 * time.Second,
+		},
+		Hooks: HookSettings{
+			BackupRetention: 10,
+		},
+		Approvals: ApprovalSettings{
+			ConfirmDangerous:  true,
+			DangerousCommands: make(map[string]string),
 		},
 	}
 }
 
-// LoadMessengerConfig loads messenger configuration from a YAML file
+// LoadMessengerConfig loads messenger configuration from a YAML file, with its includes and local override
 func LoadMessengerConfig(configPath string) (*MessengerConfig, error) {
-	// Check if file exists
-	if !fileExists(configPath) {
-		return nil, fmt.Errorf("config file not found: %s", configPath)
-	}
+	return loadMessengerConfig(configPath, os.ReadFile)
+}
+
+// loadMessengerConfig loads a messenger configuration, reading each layer with readFile
+func loadMessengerConfig(configPath string, readFile func(string) ([]byte, error)) (_ *MessengerConfig, err error) {
+	defer func() {
+		if err != nil {
+			err = &configError{err: err}
+		}
+	}()
 
-	// Read the file
-	data, err := os.ReadFile(configPath)
+	// Resolve includes and the local override
+	layers, err := MessengerConfigLayers(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
 	}
 
 	// Start with defaults
 	config := DefaultMessengerConfig()
 
-	// Parse YAML and merge with defaults
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML config: %w", err)
+	// Parse YAML and merge each layer over the defaults
+	warnings, err := applyLayers(config, layers, readFile)
+	if err != nil {
+		return nil, err
+	}
+	if strict && len(warnings) > 0 {
+		return nil, fmt.Errorf("strict mode: %s", strings.Join(warnings, "; "))
+	}
+	config.warnings = warnings
+
+	// Delivery may take its webhook URL and tokens from the OS keyring rather than the file
+	if config.Service.Components.Delivery && !config.Integration.HasChannel() {
+		config.ApplyKeyringSecrets()
 	}
 
 	// Validate the configuration
@@ -166,6 +390,15 @@ func SaveMessengerConfig(config *MessengerConfig, configPath string) error {
 
 // Validate checks if the configuration is valid
 func (mc *MessengerConfig) Validate() error {
+	// Validate monitor settings
+	if mc.Monitor.LogFile == "" {
+		return fmt.Errorf("monitor.log_file cannot be empty")
+	}
+
+	if mc.Monitor.PollInterval <= 0 {
+		return fmt.Errorf("monitor.poll_interval must be positive")
+	}
+
 	// Validate messenger settings
 	if mc.Messenger.OutputDir == "" {
 		return fmt.Errorf("messenger.output_dir cannot be empty")
@@ -175,6 +408,14 @@ func (mc *MessengerConfig) Validate() error {
 		return fmt.Errorf("messenger.file_format must be 'json' or 'jsonl'")
 	}
 
+	if mc.Messenger.RotateSizeMB < 0 {
+		return fmt.Errorf("messenger.rotate_size_mb must be non-negative")
+	}
+
+	if _, err := layout.Parse(mc.Messenger.Layout); err != nil {
+		return fmt.Errorf("messenger.layout: %w", err)
+	}
+
 	// Validate processing settings
 	if mc.Processing.PollInterval < 100*time.Millisecond {
 		return fmt.Errorf("processing.poll_interval must be at least 100ms")
@@ -184,6 +425,19 @@ func (mc *MessengerConfig) Validate() error {
 		return fmt.Errorf("processing.max_events_per_batch must be at least 1")
 	}
 
+	if mc.Processing.MaxLineSizeMB < 1 {
+		return fmt.Errorf("processing.max_line_size_mb must be at least 1")
+	}
+
+	for event, window := range mc.Processing.Debounce {
+		if !slices.Contains(claude.HookEvents, event) {
+			return fmt.Errorf("processing.debounce: unknown hook event %q, must be one of: %s", event, strings.Join(claude.HookEvents, ", "))
+		}
+		if window < 0 {
+			return fmt.Errorf("processing.debounce.%s must be non-negative", event)
+		}
+	}
+
 	// Validate service settings
 	if mc.Service.ServiceInterval < 100*time.Millisecond {
 		return fmt.Errorf("service.service_interval must be at least 100ms")
@@ -200,6 +454,104 @@ func (mc *MessengerConfig) Validate() error {
 	if !validLogLevel {
 		return fmt.Errorf("service.log_level must be one of: debug, info, warn, error")
 	}
+	for component, level := range mc.Service.LogLevels {
+		if !isLogComponent(component) {
+			return fmt.Errorf("service.log_levels: unknown component %q, must be one of: %s", component, strings.Join(logger.Components, ", "))
+		}
+		if level != "debug" && level != "info" && level != "warn" && level != "error" {
+			return fmt.Errorf("service.log_levels.%s must be one of: debug, info, warn, error", component)
+		}
+	}
+	if mc.Service.LogFormat != "" && mc.Service.LogFormat != "text" && mc.Service.LogFormat != "json" {
+		return fmt.Errorf("service.log_format must be text or json")
+	}
+	switch mc.Service.LogTarget {
+	case "", "stderr":
+	case "syslog", "journald":
+		if mc.Service.LogFile != "" {
+			return fmt.Errorf("service.log_file can't be combined with service.log_target %s", mc.Service.LogTarget)
+		}
+	default:
+		return fmt.Errorf("service.log_target must be stderr, syslog or journald")
+	}
+	if _, err := redact.New(mc.Processing.RedactPatterns); err != nil {
+		return fmt.Errorf("processing.redact_patterns: %w", err)
+	}
+	if _, err := risk.New(mc.Approvals.DangerousCommands, mc.Approvals.ProtectedPaths); err != nil {
+		return fmt.Errorf("approvals: %w", err)
+	}
+
+	if mc.Service.LogMaxSizeMB < 0 {
+		return fmt.Errorf("service.log_max_size_mb must be non-negative")
+	}
+	if mc.Service.LogMaxBackups < 0 {
+		return fmt.Errorf("service.log_max_backups must be non-negative")
+	}
+
+	if mc.Service.HeartbeatInterval < 0 {
+		return fmt.Errorf("service.heartbeat_interval must be non-negative")
+	}
+
+	if mc.Service.StallThreshold < 0 {
+		return fmt.Errorf("service.stall_threshold must be non-negative")
+	}
+	if mc.Service.StallThreshold > 0 && mc.Service.StallThreshold <= mc.Service.HeartbeatInterval {
+		return fmt.Errorf("service.stall_threshold must be longer than service.heartbeat_interval")
+	}
+
+	if mc.Service.ErrorReporting.FailureThreshold < 1 {
+		return fmt.Errorf("service.error_reporting.failure_threshold must be at least 1")
+	}
+	if dsn := mc.Service.ErrorReporting.SentryDSN; dsn != "" {
+		if u, err := url.Parse(dsn); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User == nil || strings.Trim(u.Path, "/") == "" {
+			return fmt.Errorf("service.error_reporting.sentry_dsn must look like https://<key>@<host>/<project>")
+		}
+	}
+
+	for token, role := range mc.Service.APITokens {
+		if token == "" {
+			return fmt.Errorf("service.api_tokens: tokens must not be empty")
+		}
+		if !containsString(apiRoles, role) {
+			return fmt.Errorf("service.api_tokens: role %q must be one of: %s", role, strings.Join(apiRoles, ", "))
+		}
+	}
+	for user, role := range mc.Service.ChatRoles {
+		if !strings.HasPrefix(user, "slack:") && !strings.HasPrefix(user, "telegram:") {
+			return fmt.Errorf("service.chat_roles: %q must be \"slack:<user ID>\" or \"telegram:<user ID>\"", user)
+		}
+		if !containsString(apiRoles, role) {
+			return fmt.Errorf("service.chat_roles.%s must be one of: %s", user, strings.Join(apiRoles, ", "))
+		}
+	}
+
+	for _, user := range mc.Integration.TelegramAllowedUsers {
+		if _, err := strconv.ParseInt(user, 10, 64); err != nil {
+			return fmt.Errorf("integrations.telegram_allowed_users: %q is not a Telegram user ID", user)
+		}
+	}
+	for _, user := range mc.Integration.SlackAllowedUsers {
+		if strings.Contains(user, "@") && mc.Integration.SlackToken == "" {
+			return fmt.Errorf("integrations.slack_allowed_users: email addresses such as %q need integrations.slack_token to look up users", user)
+		}
+	}
+
+	if tlsSettings := mc.Service.APITLS; (tlsSettings.CertFile == "") != (tlsSettings.KeyFile == "") {
+		return fmt.Errorf("service.api_tls.cert_file and key_file must be set together")
+	} else if tlsSettings.CertFile != "" && tlsSettings.SelfSigned {
+		return fmt.Errorf("service.api_tls.self_signed can't be combined with cert_file")
+	} else if tlsSettings.ClientCAFile != "" && tlsSettings.CertFile == "" && !tlsSettings.SelfSigned {
+		return fmt.Errorf("service.api_tls.client_ca_file requires cert_file and key_file, or self_signed")
+	}
+
+	if mc.Service.Components.Delivery {
+		if !mc.Integration.HasChannel() {
+			return fmt.Errorf("service.components.delivery requires integrations.webhook_url, telegram_token and telegram_chat_id, or slack_token and slack_channel (run 'claudetogo --setup' to configure one)")
+		}
+		if !mc.Service.Components.Watcher {
+			return fmt.Errorf("service.components.delivery requires service.components.watcher")
+		}
+	}
 
 	// Validate formatting settings
 	if mc.Formatting.MaxMessageLength < 100 {
@@ -210,6 +562,16 @@ func (mc *MessengerConfig) Validate() error {
 		return fmt.Errorf("formatting.max_content_preview must be at least 50")
 	}
 
+	if mc.Formatting.ContextMessages < 0 {
+		return fmt.Errorf("formatting.context_messages must be non-negative")
+	}
+
+	if mc.Formatting.TemplatesDir != "" {
+		if info, err := os.Stat(mc.Formatting.TemplatesDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("formatting.templates_dir must be an existing directory: %s", mc.Formatting.TemplatesDir)
+		}
+	}
+
 	// Validate integration settings
 	if mc.Integration.RetryAttempts < 0 {
 		return fmt.Errorf("integrations.retry_attempts must be non-negative")
@@ -223,37 +585,169 @@ func (mc *MessengerConfig) Validate() error {
 		return fmt.Errorf("integrations.timeout_duration must be at least 1 second")
 	}
 
+	if mc.Integration.TelegramRateLimit < 0 || mc.Integration.SlackRateLimit < 0 || mc.Integration.WebhookRateLimit < 0 {
+		return fmt.Errorf("integrations rate limits must be non-negative")
+	}
+
+	if key := mc.Integration.WebhookPublicKey; key != "" {
+		if strings.HasPrefix(key, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				key = filepath.Join(home, key[2:])
+			}
+		}
+		if _, err := channels.LoadPublicKey(key); err != nil {
+			return fmt.Errorf("integrations.webhook_public_key: %w", err)
+		}
+	}
+
+	if mc.Integration.FloodThreshold < 0 {
+		return fmt.Errorf("integrations.flood_threshold must be non-negative")
+	}
+
+	if mc.Integration.FloodThreshold > 0 && mc.Integration.FloodWindow < time.Second {
+		return fmt.Errorf("integrations.flood_window must be at least 1 second when flood_threshold is set")
+	}
+
+	if mc.Integration.BatchWindow < 0 {
+		return fmt.Errorf("integrations.batch_window must be non-negative")
+	}
+
+	if mc.Integration.BatchMaxSize < 0 {
+		return fmt.Errorf("integrations.batch_max_size must be non-negative")
+	}
+
+	if mc.Hooks.BackupRetention < 0 {
+		return fmt.Errorf("hooks.backup_retention must be non-negative")
+	}
+
+	if mc.Hooks.ForwardURL != "" {
+		u, err := url.Parse(mc.Hooks.ForwardURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("hooks.forward_url must be an http:// or https:// URL with a host")
+		}
+	}
+
+	// Validate the project registry
+	names := make(map[string]bool)
+	paths := make(map[string]bool)
+	for i, project := range mc.Projects {
+		if project.Name == "" {
+			return fmt.Errorf("projects[%d].name cannot be empty", i)
+		}
+		if !filepath.IsAbs(project.Path) {
+			return fmt.Errorf("projects[%d].path must be an absolute path: %q", i, project.Path)
+		}
+		if names[project.Name] {
+			return fmt.Errorf("projects: duplicate name %q", project.Name)
+		}
+		if paths[filepath.Clean(project.Path)] {
+			return fmt.Errorf("projects: %s is registered twice", project.Path)
+		}
+		names[project.Name] = true
+		paths[filepath.Clean(project.Path)] = true
+		for _, channel := range project.Channels {
+			if !containsString(channels.Names, channel) {
+				return fmt.Errorf("projects[%d].channels: unknown channel %q (use %s)", i, channel, strings.Join(channels.Names, ", "))
+			}
+		}
+	}
+
+	// Validate the users notifications are routed to
+	users := make(map[string]bool)
+	for i, user := range mc.Users {
+		if user.Name == "" {
+			return fmt.Errorf("users[%d].name cannot be empty", i)
+		}
+		if users[user.Name] {
+			return fmt.Errorf("users: duplicate name %q", user.Name)
+		}
+		users[user.Name] = true
+		if len(user.GitAuthors) == 0 && len(user.Paths) == 0 {
+			return fmt.Errorf("users[%d]: %s needs git_authors or paths to recognize their sessions", i, user.Name)
+		}
+		for _, path := range user.Paths {
+			if !filepath.IsAbs(path) {
+				return fmt.Errorf("users[%d].paths must be absolute paths: %q", i, path)
+			}
+		}
+		if user.TelegramChatID == "" && user.SlackChannel == "" {
+			return fmt.Errorf("users[%d]: %s needs a telegram_chat_id or slack_channel", i, user.Name)
+		}
+	}
+
 	return nil
 }
 
 // GenerateExampleConfig creates an example configuration file with comments
 func GenerateExampleConfig(configPath string) error {
-	exampleYAML := `# ClaudeToGo Messenger Configuration
-# This file configures the messenger integration features of ClaudeToGo
+	exampleYAML := `# ClaudeToGo Configuration
+# This file configures hook logging, monitoring and the messenger integration of ClaudeToGo
+
+# Other config files merged before this one (relative to this file), e.g. a shared team policy.
+# claudetogo-messenger.local.yaml next to this file, if present, is merged after it.
+# include:
+#   - team/claudetogo-messenger.yaml
+
+# Hook logging and monitoring settings (formerly claudetogo-config.json)
+monitor:
+  log_file: "claude-events.jsonl"    # Where --hook logs events and --monitor reads them
+  poll_interval: "100ms"             # Polling interval for monitoring
+  verbose: false                     # Enable verbose debug output
+  use_polling: false                 # Poll instead of file system notifications (network filesystems)
 
 # Messenger output settings
 messenger:
   output_dir: "messenger-output"     # Directory for generated JSON files
   file_format: "json"                # Output format: "json" or "jsonl"
   include_samples: true              # Generate sample files for testing
+  rotate_size_mb: 10                 # Rotate messages.jsonl at this size in MB (0 = never)
+  layout: ""                         # Subdirectories for json files, e.g. "{{.Project}}/{{.Session}}" (fields: Project, Session, SessionID, Event, Date; empty = flat)
 
 # Event processing settings
 processing:
-  watch_mode: false                  # Enable automatic file watching
   poll_interval: "2s"                # How often to check for new events
   max_events_per_batch: 10           # Maximum events to process at once
-  auto_process: false                # Automatically process new events
   process_latest_only: 0             # Only process N latest events (0 = all)
+  max_line_size_mb: 64               # Skip event/transcript lines longer than this (in MB)
+  redact: true                       # Mask API keys, tokens, passwords and private keys in logs, events and messages
+  redact_patterns: {}                # Extra secrets to mask, name: regular expression, e.g. {ticket_token: "TT-[0-9a-f]{32}"}
+  debounce:                          # Drop repeats of an event (same session, tool and target) within this window
+    Notification: "10s"              # e.g. the approval request Claude Code sends again when it retries a tool
 
 # Background service settings
 service:
-  enabled: false                     # Enable background service mode
-  daemon_mode: false                 # Run as daemon (background process)
   pid_file: ""                       # PID file location (empty = auto)
   log_level: "info"                  # Log level: debug, info, warn, error
+  log_levels: {}                     # Per-component levels, e.g. {watcher: debug, hooks: error}
+  log_format: "text"                 # Log format: text (key=value) or json (for Loki, ELK)
+  log_target: "stderr"               # Where service logs go: stderr, syslog or journald
+  log_file: ""                       # Service log file (empty = stderr only)
+  log_max_size_mb: 10                # Rotate the log file at this size in MB (0 = never)
+  log_max_backups: 3                 # Rotated log files kept (log_file.1, .2, ...)
   service_interval: "2s"             # Service check interval
   status_file: ""                    # Status file location (empty = auto)
-  auto_restart: false                # Automatically restart on failure
+  auto_restart: false                # Recover panics and restart components with backoff
+  components:                        # Pipeline components run by --service
+    watcher: true                    # Turn new events into messenger messages
+    ingest_socket: ""                # Unix socket hooks can send events to (empty = disabled)
+    delivery: false                  # Send new messages to the configured integrations
+    response_api: ""                 # HTTP response API address, e.g. "127.0.0.1:8787" (empty = disabled)
+    pprof: false                     # Serve Go profiles at /debug/pprof/ on the response API (admin role when roles are set)
+  heartbeat_interval: "30s"          # Refresh the status file and ping heartbeat_url (0 = off)
+  heartbeat_url: ""                  # URL requested on every heartbeat, e.g. an uptime monitor
+  stall_threshold: "30m"             # Alert when sessions are active this long without hook events (0 = off)
+  transcripts_dir: "~/.claude/projects"  # Where Claude Code writes session transcripts
+  error_reporting:                   # Report panics and repeated failures (both empty = off)
+    sentry_dsn: ""                   # Sentry project DSN, e.g. "https://<key>@o0.ingest.sentry.io/<project>"
+    webhook_url: ""                  # URL error reports are posted to as JSON
+    failure_threshold: 3             # Report processing or delivery failures after this many in a row
+  api_tokens: {}                     # Response API bearer tokens and their role: viewer, approver or admin
+  chat_roles: {}                     # Roles of chat users pressing buttons, e.g. {"slack:U0123ABC": approver, "telegram:123456789": admin}
+  api_tls:                           # Serve the response API over HTTPS
+    cert_file: ""                    # PEM certificate (chain)
+    key_file: ""                     # PEM private key of cert_file
+    client_ca_file: ""               # Require client certificates signed by this PEM CA (mTLS)
+    self_signed: false               # Without cert_file, generate a self-signed certificate for LAN use
 
 # Message formatting settings
 formatting:
@@ -262,16 +756,65 @@ formatting:
   max_content_preview: 200           # Maximum content preview length
   timestamp_format: "2006-01-02 15:04:05"  # Timestamp format
   use_relative_time: false           # Use relative timestamps (e.g., "2 hours ago")
+  templates_dir: ""                  # Directory of message templates, e.g. notification-bash.tmpl (empty = built-in)
+  context_messages: 0                # Add the user's last prompt and recent exchanges from the last N transcript messages (0 = off)
+  project_in_title: true             # Start titles with the project name, e.g. "[api] ⚡ Command Execution Request"
 
 # External integration settings
 integrations:
   webhook_url: ""                    # HTTP webhook URL for notifications
-  slack_token: ""                    # Slack bot token
-  telegram_token: ""                 # Telegram bot token
+  webhook_public_key: ""             # PEM public key (RSA, X25519 or P-256) to encrypt webhook payloads for (claudetogo payload keygen)
+  slack_token: ""                    # Slack bot token (or: claudetogo secret set slack_token)
+  slack_channel: ""                  # Slack channel to post to, e.g. "#claude" or a channel ID
+  telegram_token: ""                 # Telegram bot token (or: claudetogo secret set telegram_token)
+  telegram_chat_id: ""               # Telegram chat to message (found by --setup from a /start)
+  response_api_secret: ""            # Require response API requests signed with this HMAC key
+  slack_signing_secret: ""           # Slack app signing secret, to accept Slack button clicks on the response API
+  telegram_secret_token: ""          # secret_token set with Telegram's setWebhook, to accept Telegram button presses
+  telegram_allowed_users: []         # Telegram user IDs allowed to respond from Telegram (empty = anyone in the chat)
+  slack_allowed_users: []            # Slack user IDs or email addresses allowed to respond from Slack (empty = anyone)
   custom_headers: {}                 # Custom HTTP headers for webhooks
   retry_attempts: 3                  # Number of retry attempts
   retry_interval: "1s"               # Interval between retries
   timeout_duration: "30s"            # Request timeout duration
+  telegram_rate_limit: 20            # Messages per minute sent to Telegram, in bursts of up to 3 (0 = unlimited)
+  slack_rate_limit: 50               # Messages per minute sent to Slack (0 = unlimited)
+  webhook_rate_limit: 0              # Messages per minute posted to webhook_url (0 = unlimited)
+  flood_threshold: 10                # After this many messages within flood_window, send the rest as one summary (0 = off)
+  flood_window: "10s"                # Window for flood_threshold; also how often a summary is sent during a flood
+  batch_window: "0s"                 # Send messages arriving within this window as one notification per chat (0 = off)
+  batch_max_size: 0                  # Send a batch early once it holds this many messages (0 = no limit)
+  escalation_telegram_chat_id: ""    # With users set: Telegram chat for messages no user claims (empty = telegram_chat_id)
+  escalation_slack_channel: ""       # With users set: Slack channel for messages no user claims (empty = slack_channel)
+
+# Claude Code hook installation settings
+hooks:
+  backup_retention: 10               # Backups kept per settings file, e.g. settings.json.2025-01-02T10-00.bak (0 = all)
+  forward_url: ""                    # Also send hook events to a remote service, e.g. https://host:8787 (or --forward)
+  forward_token: ""                  # API token for the remote service (signed with integrations.response_api_secret when set)
+  spool_dir: ""                      # Events waiting for the remote service (empty = user cache dir)
+
+# Confirmation codes for high-risk approvals
+approvals:
+  confirm_dangerous: true            # Approving dangerous commands or writes to protected paths takes a one-time code from the notification
+  dangerous_commands: {}             # Extra Bash commands, name: regular expression, e.g. {deploy: "make (deploy|release)"}
+  protected_paths: []                # Extra files, e.g. ["*.lock", "migrations/**", "~/.aws/**"]
+
+# Project directories whose project-scoped hooks report here (registered by --setup), e.g.
+#   - name: "api"
+#     path: "/home/me/src/api"
+#     output_dir: "messenger-output/api"   # Optional, defaults to messenger.output_dir
+#     channels: ["slack"]                  # Optional: where its notifications go (telegram, slack, webhook; default all)
+#     slack_channel: "#api"                # Optional: also telegram_chat_id and webhook_url, defaulting to integrations
+projects: []
+
+# Developers who get their own sessions' notifications in their own chat, e.g.
+#   - name: "alice"
+#     git_authors: ["alice@example.com"]   # Matched against git user.email or user.name in the session's repository
+#     paths: ["/home/alice"]               # And/or the directories their sessions run in
+#     telegram_chat_id: "123456789"        # Their chat; also slack_channel, e.g. their Slack user ID
+# Messages no user claims go to integrations.escalation_telegram_chat_id / escalation_slack_channel.
+users: []
 `
 
 	// Ensure directory exists
@@ -333,39 +876,14 @@ func fileExists(path string) bool {
 	return !os.IsNotExist(err)
 }
 
-// ApplyEnvironmentOverrides applies environment variable overrides to config
-func (mc *MessengerConfig) ApplyEnvironmentOverrides() {
-	// Apply environment variable overrides
-	if outputDir := os.Getenv("CLAUDETOGO_OUTPUT_DIR"); outputDir != "" {
-		mc.Messenger.OutputDir = outputDir
-	}
-
-	if logLevel := os.Getenv("CLAUDETOGO_LOG_LEVEL"); logLevel != "" {
-		mc.Service.LogLevel = logLevel
-	}
-
-	if webhookURL := os.Getenv("CLAUDETOGO_WEBHOOK_URL"); webhookURL != "" {
-		mc.Integration.WebhookURL = webhookURL
-	}
-
-	if slackToken := os.Getenv("CLAUDETOGO_SLACK_TOKEN"); slackToken != "" {
-		mc.Integration.SlackToken = slackToken
-	}
-
-	if telegramToken := os.Getenv("CLAUDETOGO_TELEGRAM_TOKEN"); telegramToken != "" {
-		mc.Integration.TelegramToken = telegramToken
-	}
-}
-
 // Summary returns a human-readable summary of the configuration
 func (mc *MessengerConfig) Summary() string {
 	summary := fmt.Sprintf(`ClaudeToGo Messenger Configuration Summary:
 ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+📝 Events Log File:     %s
 📁 Output Directory:    %s
 📋 File Format:         %s
 ⏱️  Poll Interval:       %v
-🔄 Watch Mode:          %t
-🚀 Service Mode:        %t
 📊 Max Events/Batch:    %d
 🎨 Include Emojis:      %t
 📏 Max Message Length:  %d
@@ -373,18 +891,27 @@ func (mc *MessengerConfig) Summary() string {
 🤖 Slack Integration:   %t
 📱 Telegram Integration: %t
 ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━`,
+		mc.Monitor.LogFile,
 		mc.Messenger.OutputDir,
 		mc.Messenger.FileFormat,
 		mc.Processing.PollInterval,
-		mc.Processing.WatchMode,
-		mc.Service.Enabled,
 		mc.Processing.MaxEventsPerBatch,
 		mc.Formatting.IncludeEmojis,
 		mc.Formatting.MaxMessageLength,
 		mc.Integration.WebhookURL,
-		mc.Integration.SlackToken != "",
-		mc.Integration.TelegramToken != "",
+		mc.Integration.SlackToken != "" && mc.Integration.SlackChannel != "",
+		mc.Integration.TelegramToken != "" && mc.Integration.TelegramChatID != "",
 	)
 
 	return summary
-}
\ No newline at end of file
+}
+
+// isLogComponent reports whether name is a component with its own log level
+func isLogComponent(name string) bool {
+	for _, component := range logger.Components {
+		if component == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RegisterProjects adds project directories to the registry in a YAML config file. A project
+// already registered under the same path is updated in place; a name already taken by another
+// path gets a numeric suffix. A missing file is created from the example config.
+func RegisterProjects(configPath string, projects []ProjectSettings) error {
+	if !fileExists(configPath) {
+		if err := GenerateExampleConfig(configPath); err != nil {
+			return err
+		}
+	}
+
+	// Only this file's registry is rewritten, not projects from included files
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var document struct {
+		Projects []ProjectSettings `yaml:"projects"`
+	}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+
+	registry := document.Projects
+	for _, project := range projects {
+		registry = addProject(registry, project)
+	}
+	return writeSetting(configPath, []string{"projects"}, registry)
+}
+
+// addProject adds or updates one project in a registry
+func addProject(registry []ProjectSettings, project ProjectSettings) []ProjectSettings {
+	project.Path = filepath.Clean(project.Path)
+	if project.Name == "" {
+		project.Name = filepath.Base(project.Path)
+	}
+
+	for i, existing := range registry {
+		if filepath.Clean(existing.Path) == project.Path {
+			// Keep the name and routing the user gave the project
+			existing.Path = project.Path
+			if project.OutputDir != "" {
+				existing.OutputDir = project.OutputDir
+			}
+			registry[i] = existing
+			return registry
+		}
+	}
+
+	name := project.Name
+	for n := 2; projectNamed(registry, project.Name); n++ {
+		project.Name = fmt.Sprintf("%s-%d", name, n)
+	}
+	return append(registry, project)
+}
+
+// projectNamed reports whether a registry has a project with the given name
+func projectNamed(registry []ProjectSettings, name string) bool {
+	for _, project := range registry {
+		if project.Name == name {
+			return true
+		}
+	}
+	return false
+}
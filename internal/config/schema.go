@@ -0,0 +1,375 @@
+package config
+
+//go:generate sh -c "go run ../../cmd/claudetogo --config-schema messenger > ../../schemas/claudetogo-messenger.schema.json"
+//go:generate sh -c "go run ../../cmd/claudetogo --config-schema runtime > ../../schemas/claudetogo-config.schema.json"
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/channels"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// durationPattern matches the duration strings accepted by time.ParseDuration
+const durationPattern = `^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// Schema is the subset of JSON Schema used to describe configuration files
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false or a *Schema for map values
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Format               string             `json:"format,omitempty"`
+}
+
+// ValidationIssue is a problem found in a configuration file. Warnings (unknown and
+// deprecated keys) don't stop the file from loading unless strict mode is on.
+type ValidationIssue struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	Warning bool   `json:"warning,omitempty"`
+}
+
+// messengerConstraints adds the limits enforced by Validate to the generated messenger schema
+var messengerConstraints = map[string]func(*Schema){
+	"messenger.file_format":                     enum("json", "jsonl"),
+	"messenger.rotate_size_mb":                  minimum(0),
+	"processing.max_events_per_batch":           minimum(1),
+	"processing.process_latest_only":            minimum(0),
+	"processing.max_line_size_mb":               minimum(1),
+	"service.log_level":                         enum("debug", "info", "warn", "error"),
+	"service.log_format":                        enum("text", "json"),
+	"service.log_levels":                        logLevels,
+	"service.log_target":                        enum("stderr", "syslog", "journald"),
+	"service.log_max_size_mb":                   minimum(0),
+	"service.log_max_backups":                   minimum(0),
+	"formatting.max_message_length":             minimum(100),
+	"formatting.max_content_preview":            minimum(50),
+	"formatting.context_messages":               minimum(0),
+	"integrations.retry_attempts":               minimum(0),
+	"integrations.telegram_rate_limit":          minimum(0),
+	"integrations.slack_rate_limit":             minimum(0),
+	"integrations.webhook_rate_limit":           minimum(0),
+	"integrations.flood_threshold":              minimum(0),
+	"integrations.batch_max_size":               minimum(0),
+	"hooks.backup_retention":                    minimum(0),
+	"integrations.webhook_url":                  format("uri"),
+	"service.heartbeat_url":                     format("uri"),
+	"hooks.forward_url":                         format("uri"),
+	"service.error_reporting.sentry_dsn":        format("uri"),
+	"service.error_reporting.webhook_url":       format("uri"),
+	"service.error_reporting.failure_threshold": minimum(1),
+	"projects.channels":                         channelNames,
+	"projects.webhook_url":                      format("uri"),
+	"service.api_tokens":                        roles,
+	"service.chat_roles":                        roles,
+	"service.enabled":                           deprecated("it has no effect; run 'claudetogo --service' or 'claudetogo service install'"),
+	"service.daemon_mode":                       deprecated("it has no effect; use 'claudetogo --service --daemon'"),
+	"processing.watch_mode":                     deprecated("it has no effect; use 'claudetogo --process --watch'"),
+	"processing.auto_process":                   deprecated("it has no effect; run the service to process new events"),
+}
+
+// MessengerSchema returns the JSON Schema of the messenger YAML configuration
+func MessengerSchema() *Schema {
+	schema := schemaFor(reflect.TypeOf(MessengerConfig{}), "yaml", nil, messengerConstraints)
+	schema.Properties["include"] = &Schema{Type: "array", Items: &Schema{Type: "string"}}
+	schema.SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+	schema.Title = "ClaudeToGo messenger configuration"
+	return schema
+}
+
+// RuntimeSchema returns the JSON Schema of the runtime JSON configuration (claudetogo-config.json)
+func RuntimeSchema() *Schema {
+	schema := schemaFor(reflect.TypeOf(types.ConfigFile{}), "json", nil, map[string]func(*Schema){
+		"pollInterval": func(s *Schema) { s.Pattern = durationPattern },
+	})
+	schema.SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+	schema.Title = "ClaudeToGo runtime configuration"
+	return schema
+}
+
+// MarshalSchema renders a schema as indented JSON
+func MarshalSchema(schema *Schema) ([]byte, error) {
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaFor builds the schema of a Go type from its struct tags
+func schemaFor(t reflect.Type, tagName string, keys []string, constraints map[string]func(*Schema)) *Schema {
+	var schema *Schema
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		schema = &Schema{Type: "string", Pattern: durationPattern}
+	case t.Kind() == reflect.Struct:
+		schema = &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			key := strings.Split(t.Field(i).Tag.Get(tagName), ",")[0]
+			if key == "" || key == "-" {
+				continue
+			}
+			path := append(append([]string(nil), keys...), key)
+			schema.Properties[key] = schemaFor(t.Field(i).Type, tagName, path, constraints)
+		}
+	case t.Kind() == reflect.Slice:
+		schema = &Schema{Type: "array", Items: schemaFor(t.Elem(), tagName, keys, constraints)}
+	case t.Kind() == reflect.Map:
+		schema = &Schema{Type: "object", AdditionalProperties: schemaFor(t.Elem(), tagName, nil, nil)}
+	case t.Kind() == reflect.String:
+		schema = &Schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		schema = &Schema{Type: "boolean"}
+	case t.Kind() == reflect.Int || t.Kind() == reflect.Int64:
+		schema = &Schema{Type: "integer"}
+	default:
+		schema = &Schema{}
+	}
+
+	if constrain, ok := constraints[strings.Join(keys, ".")]; ok {
+		constrain(schema)
+	}
+	return schema
+}
+
+// logLevels limits service.log_levels to the components with a log level of their own
+func logLevels(s *Schema) {
+	s.Properties = make(map[string]*Schema, len(logger.Components))
+	for _, component := range logger.Components {
+		s.Properties[component] = &Schema{Type: "string", Enum: []string{"debug", "info", "warn", "error"}}
+	}
+	s.AdditionalProperties = false
+}
+
+// apiRoles are the roles API tokens and chat users can have
+var apiRoles = []string{"viewer", "approver", "admin"}
+
+// roles limits the values of a token or user map to the API roles
+func roles(s *Schema) {
+	s.AdditionalProperties = &Schema{Type: "string", Enum: apiRoles}
+}
+
+// channelNames limits project channels to the channel types. The constraint reaches both
+// the list and its items, and only the items take the enum.
+func channelNames(s *Schema) {
+	if s.Type == "string" {
+		s.Enum = channels.Names
+	}
+}
+
+func enum(values ...string) func(*Schema) {
+	return func(s *Schema) { s.Enum = values }
+}
+
+func minimum(value float64) func(*Schema) {
+	return func(s *Schema) { s.Minimum = &value }
+}
+
+func format(name string) func(*Schema) {
+	return func(s *Schema) { s.Format = name }
+}
+
+func deprecated(reason string) func(*Schema) {
+	return func(s *Schema) {
+		s.Deprecated = true
+		s.Description = "Deprecated: " + reason
+	}
+}
+
+// ValidateDocument checks a YAML or JSON document against a schema, reporting unknown
+// keys and type errors with their line numbers
+func ValidateDocument(data []byte, schema *Schema) ([]ValidationIssue, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("syntax error: %w", err)
+	}
+	if root.Kind == 0 || len(root.Content) == 0 {
+		return nil, nil // Empty file: everything defaults
+	}
+
+	var issues []ValidationIssue
+	validateNode(root.Content[0], schema, "", &issues)
+	return issues, nil
+}
+
+// validateNode checks a node and its children against a schema
+func validateNode(node *yaml.Node, schema *Schema, path string, issues *[]ValidationIssue) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	report := func(format string, args ...interface{}) {
+		*issues = append(*issues, ValidationIssue{Line: node.Line, Column: node.Column, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// An explicit null leaves the default in place
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			report("expected a mapping, got %s", describeNode(node))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			childPath := joinPath(path, keyNode.Value)
+
+			child, known := schema.Properties[keyNode.Value]
+			if !known {
+				if additional, ok := schema.AdditionalProperties.(*Schema); ok {
+					child = additional
+				} else {
+					message := fmt.Sprintf("unknown key %q", keyNode.Value)
+					if suggestion := closestKey(keyNode.Value, schema.Properties); suggestion != "" {
+						message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+					}
+					*issues = append(*issues, ValidationIssue{Line: keyNode.Line, Column: keyNode.Column, Path: childPath, Message: message, Warning: true})
+					continue
+				}
+			}
+			if child.Deprecated {
+				*issues = append(*issues, ValidationIssue{Line: keyNode.Line, Column: keyNode.Column, Path: childPath,
+					Message: "deprecated key: " + strings.TrimPrefix(child.Description, "Deprecated: "), Warning: true})
+			}
+			validateNode(valueNode, child, childPath, issues)
+		}
+
+	case "string":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+			if schema.Pattern == durationPattern {
+				report("expected a duration such as \"2s\" or \"500ms\", got %s", describeNode(node))
+			} else {
+				report("expected a string, got %s", describeNode(node))
+			}
+			return
+		}
+		if schema.Pattern != "" && !regexp.MustCompile(schema.Pattern).MatchString(node.Value) {
+			if schema.Pattern == durationPattern {
+				report("invalid duration %q (use e.g. \"2s\", \"500ms\", \"1m30s\")", node.Value)
+			} else {
+				report("%q does not match %s", node.Value, schema.Pattern)
+			}
+		}
+		if len(schema.Enum) > 0 && !containsString(schema.Enum, node.Value) {
+			report("%q is not one of: %s", node.Value, strings.Join(schema.Enum, ", "))
+		}
+
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			report("expected a list, got %s", describeNode(node))
+			return
+		}
+		for i, item := range node.Content {
+			validateNode(item, schema.Items, fmt.Sprintf("%s[%d]", path, i), issues)
+		}
+
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			report("expected true or false, got %s", describeNode(node))
+		}
+
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			report("expected an integer, got %s", describeNode(node))
+			return
+		}
+		if schema.Minimum != nil {
+			if value, err := strconv.ParseInt(node.Value, 0, 64); err == nil && float64(value) < *schema.Minimum {
+				report("must be at least %v, got %d", *schema.Minimum, value)
+			}
+		}
+	}
+}
+
+// describeNode names the kind of value a node holds, for error messages
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch node.Tag {
+	case "!!str":
+		return fmt.Sprintf("string %q", node.Value)
+	case "!!int":
+		return fmt.Sprintf("integer %s", node.Value)
+	case "!!float":
+		return fmt.Sprintf("number %s", node.Value)
+	case "!!bool":
+		return fmt.Sprintf("boolean %s", node.Value)
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
+
+// closestKey suggests a known key for a misspelled one
+func closestKey(key string, properties map[string]*Schema) string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3 // Only suggest reasonably close matches
+	for _, name := range names {
+		if d := editDistance(strings.ToLower(key), name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
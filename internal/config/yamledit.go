@@ -0,0 +1,357 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setDocumentValue sets the value at a key path in a YAML document. Existing single-line
+// values are replaced and missing keys are added as text, keeping the file's formatting
+// and comments; anything else falls back to re-encoding the document.
+func setDocumentValue(data []byte, keys []string, value interface{}) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if root.Kind == 0 {
+		root.Kind = yaml.DocumentNode
+	}
+	if len(root.Content) == 0 {
+		root.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	node := root.Content[0]
+
+	created := false
+	var keyNode *yaml.Node
+	for i, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s is not a section", strings.Join(keys[:i], "."))
+		}
+		var child *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key {
+				keyNode, child = node.Content[j], node.Content[j+1]
+				break
+			}
+		}
+		if child == nil {
+			if edited, ok := insertKeys(data, node, node == root.Content[0], keys[i:], value); ok {
+				return edited, nil
+			}
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+			created = true
+		}
+		node = child
+	}
+
+	if !created {
+		if edited, ok := replaceBlock(data, keyNode, node, value); ok {
+			return edited, nil
+		}
+		if edited, ok := replaceValue(data, node, value); ok {
+			return edited, nil
+		}
+	}
+
+	// Keep the existing line comment when replacing the value
+	comment := node.LineComment
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	node.LineComment = comment
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deleteDocumentKey removes the key at a key path from a YAML document, along with a section
+// the removal leaves empty. Block mappings are edited as text, keeping the rest of the file.
+func deleteDocumentKey(data []byte, keys []string) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return data, nil
+	}
+
+	// Find the mapping holding the key, and the index of the key in it
+	parent := root.Content[0]
+	index := -1
+	for i, key := range keys {
+		if parent.Kind != yaml.MappingNode {
+			return data, nil
+		}
+		index = -1
+		for j := 0; j+1 < len(parent.Content); j += 2 {
+			if parent.Content[j].Value == key {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			return data, nil
+		}
+		if i < len(keys)-1 {
+			parent = parent.Content[index+1]
+		}
+	}
+
+	// Drop the enclosing section instead when this is its only key
+	if len(parent.Content) == 2 && len(keys) > 1 {
+		return deleteDocumentKey(data, keys[:len(keys)-1])
+	}
+
+	keyNode, valueNode := parent.Content[index], parent.Content[index+1]
+	last, ok := lastLine(valueNode)
+	lines := strings.SplitAfter(string(data), "\n")
+	if ok && parent.Style&yaml.FlowStyle == 0 && keyNode.Line >= 1 && last <= len(lines) &&
+		keyNode.Column-1 <= len(lines[keyNode.Line-1]) &&
+		strings.TrimSpace(lines[keyNode.Line-1][:keyNode.Column-1]) == "" {
+		edited := strings.Join(lines[:keyNode.Line-1], "") + strings.Join(lines[last:], "")
+		return []byte(edited), nil
+	}
+
+	parent.Content = append(parent.Content[:index], parent.Content[index+2:]...)
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// replaceValue rewrites a single-line scalar or flow collection where it stands in the
+// source, keeping the column of a trailing comment where possible
+func replaceValue(data []byte, node *yaml.Node, value interface{}) ([]byte, bool) {
+	flow := node.Style&yaml.FlowStyle != 0
+	if node.Kind != yaml.ScalarNode && !flow {
+		return nil, false
+	}
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return nil, false
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	if node.Line < 1 || node.Line > len(lines) {
+		return nil, false
+	}
+	line := lines[node.Line-1]
+	start := node.Column - 1
+	if start < 0 || start > len(line) {
+		return nil, false
+	}
+
+	// Find the end of the old value: the closing quote or bracket, or a comment/end of line
+	rest := strings.TrimRight(line[start:], "\r\n")
+	var end int
+	switch {
+	case flow:
+		end = closingBracket(rest)
+	case node.Style == yaml.DoubleQuotedStyle:
+		end = closingQuote(rest, '"')
+	case node.Style == yaml.SingleQuotedStyle:
+		end = closingQuote(rest, '\'')
+	default:
+		end = len(rest)
+		if i := strings.Index(rest, " #"); i >= 0 {
+			end = i
+		}
+		end = len(strings.TrimRight(rest[:end], " \t"))
+	}
+	if end <= 0 {
+		return nil, false
+	}
+
+	text, ok := inlineText(value, node.Style)
+	if !ok {
+		return nil, false
+	}
+
+	// Pad or shrink the gap before a trailing comment so comments stay aligned
+	after := rest[end:]
+	if strings.TrimSpace(after) != "" {
+		gap := len(after) - len(strings.TrimLeft(after, " \t"))
+		gap += end - len(text)
+		if gap < 1 {
+			gap = 1
+		}
+		after = strings.Repeat(" ", gap) + strings.TrimLeft(after, " \t")
+	}
+
+	lines[node.Line-1] = line[:start] + text + after + line[start+len(rest):]
+	return []byte(strings.Join(lines, "")), true
+}
+
+// replaceBlock rewrites a key whose new value is a list of mappings as a block, which stays
+// readable where a flow collection would not
+func replaceBlock(data []byte, keyNode, node *yaml.Node, value interface{}) ([]byte, bool) {
+	var encoded yaml.Node
+	if err := encoded.Encode(value); err != nil || encoded.Kind != yaml.SequenceNode ||
+		len(encoded.Content) == 0 || encoded.Content[0].Kind != yaml.MappingNode {
+		return nil, false
+	}
+	last, ok := lastLine(node)
+	lines := strings.SplitAfter(string(data), "\n")
+	if !ok || keyNode == nil || keyNode.Line < 1 || last > len(lines) || keyNode.Column-1 > len(lines[keyNode.Line-1]) ||
+		strings.TrimSpace(lines[keyNode.Line-1][:keyNode.Column-1]) != "" {
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string]interface{}{keyNode.Value: value}); err != nil {
+		return nil, false
+	}
+	encoder.Close()
+
+	var block strings.Builder
+	for _, text := range strings.SplitAfter(buf.String(), "\n") {
+		if text != "" {
+			block.WriteString(strings.Repeat(" ", keyNode.Column-1) + text)
+		}
+	}
+	edited := strings.Join(lines[:keyNode.Line-1], "") + block.String() + strings.Join(lines[last:], "")
+	return []byte(edited), true
+}
+
+// insertKeys adds keys (and the sections leading to them) at the end of a block mapping
+func insertKeys(data []byte, parent *yaml.Node, isRoot bool, keys []string, value interface{}) ([]byte, bool) {
+	if parent.Style&yaml.FlowStyle != 0 || (len(parent.Content) == 0 && !isRoot) {
+		return nil, false
+	}
+	indent := 0
+	if len(parent.Content) > 0 {
+		indent = parent.Content[0].Column - 1
+	}
+	last, ok := lastLine(parent)
+	if !ok {
+		return nil, false
+	}
+
+	// Render the new keys as a nested block, indented to the parent's level
+	var nested interface{} = value
+	for i := len(keys) - 1; i >= 0; i-- {
+		nested = map[string]interface{}{keys[i]: nested}
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(nested); err != nil {
+		return nil, false
+	}
+	encoder.Close()
+
+	var block strings.Builder
+	if isRoot && len(parent.Content) > 0 {
+		block.WriteString("\n") // Separate a new section from the previous one
+	}
+	for _, text := range strings.SplitAfter(buf.String(), "\n") {
+		if text != "" {
+			block.WriteString(strings.Repeat(" ", indent) + text)
+		}
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	if last > len(lines) {
+		return nil, false
+	}
+	if last > 0 && !strings.HasSuffix(lines[last-1], "\n") {
+		lines[last-1] += "\n"
+	}
+	edited := strings.Join(lines[:last], "") + block.String() + strings.Join(lines[last:], "")
+	return []byte(edited), true
+}
+
+// lastLine returns the last source line of a node, or false if it ends in a multi-line scalar
+func lastLine(node *yaml.Node) (int, bool) {
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle|yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 &&
+		strings.Contains(node.Value, "\n") {
+		return 0, false
+	}
+	if len(node.Content) == 0 || node.Style&yaml.FlowStyle != 0 {
+		return node.Line, true
+	}
+	return lastLine(node.Content[len(node.Content)-1])
+}
+
+// closingQuote returns the index just past the quote closing a quoted scalar
+func closingQuote(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// closingBracket returns the index just past the bracket closing a flow collection
+func closingBracket(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			end := closingQuote(s[i:], s[i])
+			if end < 0 {
+				return -1
+			}
+			i += end - 1
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// inlineText renders a value on one line: a scalar quoted like the value it replaces, or a
+// flow collection
+func inlineText(value interface{}, style yaml.Style) (string, bool) {
+	if text, isString := value.(string); isString && style == yaml.DoubleQuotedStyle {
+		quoted, err := json.Marshal(text)
+		return string(quoted), err == nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return "", false
+	}
+	if node.Kind != yaml.ScalarNode {
+		node.Style = yaml.FlowStyle
+	}
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return "", false
+	}
+	text := strings.TrimSuffix(string(out), "\n")
+	if strings.Contains(text, "\n") {
+		return "", false
+	}
+	return text, true
+}
@@ -0,0 +1,252 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxLCSCells bounds the LCS table size; larger changes fall back to a single replace hunk
+const maxLCSCells = 4_000_000
+
+// opKind identifies the kind of a diff line
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is a single line of an edit script
+type op struct {
+	kind    opKind
+	text    string
+	oldLine int // 1-based line in the old text (equal/delete)
+	newLine int // 1-based line in the new text (equal/insert)
+}
+
+// Unified returns a unified diff between oldText and newText with the given number
+// of context lines. An empty string is returned when the texts are identical.
+func Unified(oldName, newName, oldText, newText string, context int) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := lineOps(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n", oldName)
+	fmt.Fprintf(&b, "+++ %s\n", newName)
+
+	for _, hunk := range groupHunks(ops, context) {
+		writeHunk(&b, hunk)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Stats returns the number of added and removed lines between two texts
+func Stats(oldText, newText string) (added, removed int) {
+	for _, o := range lineOps(splitLines(oldText), splitLines(newText)) {
+		switch o.kind {
+		case opInsert:
+			added++
+		case opDelete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// splitLines splits text into lines without their trailing newlines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// lineOps computes a line edit script turning a into b
+func lineOps(a, b []string) []op {
+	// Trim the common prefix and suffix so the LCS only covers the changed region
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{kind: opEqual, text: a[i], oldLine: i + 1, newLine: i + 1})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	ops = append(ops, middleOps(midA, midB, prefix, prefix)...)
+
+	for i := 0; i < suffix; i++ {
+		ai := len(a) - suffix + i
+		bi := len(b) - suffix + i
+		ops = append(ops, op{kind: opEqual, text: a[ai], oldLine: ai + 1, newLine: bi + 1})
+	}
+
+	return ops
+}
+
+// middleOps diffs the changed region using a longest-common-subsequence table
+func middleOps(a, b []string, offsetA, offsetB int) []op {
+	var ops []op
+
+	// Too large for an LCS table: report the whole region as replaced
+	if len(a)*len(b) > maxLCSCells {
+		for i, line := range a {
+			ops = append(ops, op{kind: opDelete, text: line, oldLine: offsetA + i + 1})
+		}
+		for j, line := range b {
+			ops = append(ops, op{kind: opInsert, text: line, newLine: offsetB + j + 1})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{kind: opEqual, text: a[i], oldLine: offsetA + i + 1, newLine: offsetB + j + 1})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{kind: opDelete, text: a[i], oldLine: offsetA + i + 1})
+			i++
+		default:
+			ops = append(ops, op{kind: opInsert, text: b[j], newLine: offsetB + j + 1})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{kind: opDelete, text: a[i], oldLine: offsetA + i + 1})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{kind: opInsert, text: b[j], newLine: offsetB + j + 1})
+	}
+
+	return ops
+}
+
+// groupHunks splits an edit script into hunks of changes with surrounding context
+func groupHunks(ops []op, context int) [][]op {
+	var hunks [][]op
+
+	i := 0
+	for i < len(ops) {
+		// Find the next change
+		for i < len(ops) && ops[i].kind == opEqual {
+			i++
+		}
+		if i >= len(ops) {
+			break
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// Extend the hunk while changes are within 2*context lines of each other
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run < len(ops) && run-end <= 2*context {
+				end = run
+				continue
+			}
+			end += context
+			if end > run {
+				end = run
+			}
+			break
+		}
+
+		hunks = append(hunks, ops[start:end])
+		i = end
+	}
+
+	return hunks
+}
+
+// writeHunk writes a single hunk with its @@ header
+func writeHunk(b *strings.Builder, hunk []op) {
+	oldStart, newStart := 0, 0
+	oldCount, newCount := 0, 0
+
+	for _, o := range hunk {
+		switch o.kind {
+		case opEqual:
+			if oldStart == 0 {
+				oldStart = o.oldLine
+			}
+			if newStart == 0 {
+				newStart = o.newLine
+			}
+			oldCount++
+			newCount++
+		case opDelete:
+			if oldStart == 0 {
+				oldStart = o.oldLine
+			}
+			oldCount++
+		case opInsert:
+			if newStart == 0 {
+				newStart = o.newLine
+			}
+			newCount++
+		}
+	}
+
+	// Pure insertions/deletions start at the line before, per unified diff convention
+	if oldCount == 0 {
+		oldStart = newStart - 1
+	}
+	if newCount == 0 {
+		newStart = oldStart - 1
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, o := range hunk {
+		switch o.kind {
+		case opEqual:
+			b.WriteString(" " + o.text + "\n")
+		case opDelete:
+			b.WriteString("-" + o.text + "\n")
+		case opInsert:
+			b.WriteString("+" + o.text + "\n")
+		}
+	}
+}
@@ -2,35 +2,89 @@ package extractor
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/riaanpieterse81/ClaudeToGo/internal/diff"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/transcript"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
 )
 
+// maxDiffLength caps the size of rendered diffs kept in event details
+const maxDiffLength = 4000
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
 // DataExtractor handles extracting relevant data from Claude events and transcripts
 type DataExtractor struct {
-	transcriptReader *transcript.Reader
+	transcriptReader  *transcript.Reader
+	maxContentPreview int
+	contextMessages   int // Recent transcript messages summarized in the conversation context (0 = off)
+
+	mu       sync.Mutex
+	metadata map[string]*transcriptMetadata // Metadata read so far, by transcript path
+	authors  map[string]string              // Git author by working directory
 }
 
 // NewDataExtractor creates a new data extractor
 func NewDataExtractor() *DataExtractor {
 	return &DataExtractor{
-		transcriptReader: transcript.NewReader(),
+		transcriptReader:  transcript.NewReader(),
+		maxContentPreview: 200,
+		metadata:          make(map[string]*transcriptMetadata),
+		authors:           make(map[string]string),
+	}
+}
+
+// TranscriptReadTime returns the total time spent reading transcripts so far
+func (de *DataExtractor) TranscriptReadTime() time.Duration {
+	return de.transcriptReader.ReadTime()
+}
+
+// SetContextMessages sets how many recent transcript messages the conversation context added
+// to each event looks at; 0 leaves it out
+func (de *DataExtractor) SetContextMessages(n int) {
+	if n >= 0 {
+		de.contextMessages = n
+	}
+}
+
+// SetMaxContentPreview sets how many characters of tool input content are kept in previews
+func (de *DataExtractor) SetMaxContentPreview(max int) {
+	if max > 0 {
+		de.maxContentPreview = max
 	}
 }
 
 // ProcessEvent processes a Claude hook event and extracts relevant data
 func (de *DataExtractor) ProcessEvent(event *types.ClaudeHookEvent) (*types.ExtractedData, error) {
+	var data *types.ExtractedData
+	var err error
 	switch strings.ToLower(event.HookEventName) {
 	case "stop":
-		return de.ProcessStopEvent(event)
+		data, err = de.ProcessStopEvent(event)
 	case "notification":
-		return de.ProcessNotificationEvent(event)
+		data, err = de.ProcessNotificationEvent(event)
 	default:
 		return nil, fmt.Errorf("unknown hook event type: %s", event.HookEventName)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The conversation context is optional: an event is still sent without it
+	if de.contextMessages > 0 {
+		if conversation, err := de.GetEventContext(event, de.contextMessages); err == nil {
+			data.Conversation = conversation
+		}
+	}
+	return data, nil
 }
 
 // ProcessStopEvent processes a Stop event and extracts the final assistant message
@@ -69,28 +123,30 @@ func (de *DataExtractor) ProcessStopEvent(event *types.ClaudeHookEvent) (*types.
 		CWD:       event.CWD,
 		Timestamp: timestamp,
 		Data:      stopData,
+		Metadata:  de.collectMetadata(event),
 	}, nil
 }
 
 // ProcessNotificationEvent processes a Notification event and extracts tool usage details
 func (de *DataExtractor) ProcessNotificationEvent(event *types.ClaudeHookEvent) (*types.ExtractedData, error) {
-	// Get the last tool use from the transcript
-	lastToolUse, err := de.transcriptReader.GetLastToolUse(event.TranscriptPath)
+	// Find the tool call waiting for permission, in the main agent or a subagent
+	request, err := de.transcriptReader.FindPendingToolUse(event.TranscriptPath, toolMatch(event))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get last tool use: %w", err)
 	}
-
-	// Extract tool use details
-	toolUseContent, err := de.transcriptReader.ExtractToolUseDetails(lastToolUse)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract tool use details: %w", err)
-	}
+	toolUseContent := request.ToolUse
 
 	// Process the tool use based on tool type
 	notificationData, err := de.processToolUse(toolUseContent, event)
 	if err != nil {
 		return nil, fmt.Errorf("failed to process tool use: %w", err)
 	}
+	de.addToolResult(event.TranscriptPath, toolUseContent, notificationData)
+	if request.Subagent {
+		notificationData.Subagent = true
+		notificationData.Agent = request.Agent
+		notificationData.AgentTask = request.Task
+	}
 
 	// Get timestamp (use event timestamp or current time)
 	timestamp := event.Timestamp
@@ -104,9 +160,25 @@ func (de *DataExtractor) ProcessNotificationEvent(event *types.ClaudeHookEvent)
 		CWD:       event.CWD,
 		Timestamp: timestamp,
 		Data:      notificationData,
+		Metadata:  de.collectMetadata(event),
 	}, nil
 }
 
+// permissionToolPattern finds the tool a permission notification is about, e.g.
+// "Claude needs your permission to use Bash"
+var permissionToolPattern = regexp.MustCompile(`permission to use ([\w.:-]+)`)
+
+// toolMatch collects what an event tells about the tool call it is for
+func toolMatch(event *types.ClaudeHookEvent) transcript.ToolMatch {
+	match := transcript.ToolMatch{ID: event.ToolUseID, Name: event.ToolName, Input: event.ToolInput}
+	if match.Name == "" {
+		if found := permissionToolPattern.FindStringSubmatch(event.Message); found != nil {
+			match.Name = found[1]
+		}
+	}
+	return match
+}
+
 // processToolUse processes different types of tool usage
 func (de *DataExtractor) processToolUse(toolUse *types.ContentItem, event *types.ClaudeHookEvent) (*types.NotificationEventData, error) {
 	toolName := toolUse.Name
@@ -132,7 +204,7 @@ func (de *DataExtractor) processToolUse(toolUse *types.ContentItem, event *types
 	// Add tool-specific processing
 	switch strings.ToLower(toolName) {
 	case "write":
-		de.processWriteTool(toolUse, notificationData)
+		de.processWriteTool(toolUse, notificationData, event.CWD)
 	case "read":
 		de.processReadTool(toolUse, notificationData)
 	case "webfetch", "fetch":
@@ -140,7 +212,7 @@ func (de *DataExtractor) processToolUse(toolUse *types.ContentItem, event *types
 	case "bash":
 		de.processBashTool(toolUse, notificationData)
 	case "edit":
-		de.processEditTool(toolUse, notificationData)
+		de.processEditTool(toolUse, notificationData, event.CWD)
 	case "list", "ls":
 		de.processListTool(toolUse, notificationData)
 	default:
@@ -152,7 +224,7 @@ func (de *DataExtractor) processToolUse(toolUse *types.ContentItem, event *types
 }
 
 // processWriteTool handles Write tool specific processing
-func (de *DataExtractor) processWriteTool(toolUse *types.ContentItem, data *types.NotificationEventData) {
+func (de *DataExtractor) processWriteTool(toolUse *types.ContentItem, data *types.NotificationEventData, cwd string) {
 	data.Action = "create_file"
 	
 	if filePath, exists := toolUse.Input["file_path"]; exists {
@@ -161,12 +233,20 @@ func (de *DataExtractor) processWriteTool(toolUse *types.ContentItem, data *type
 	if content, exists := toolUse.Input["content"]; exists {
 		// Truncate very long content for preview
 		contentStr := fmt.Sprintf("%v", content)
-		if len(contentStr) > 200 {
-			data.Details["content_preview"] = contentStr[:200] + "..."
+		if preview, cut := formatter.Cut(contentStr, de.maxContentPreview); cut {
+			data.Details["content_preview"] = preview + "..."
 			data.Details["content_length"] = len(contentStr)
 		} else {
 			data.Details["content_preview"] = contentStr
 		}
+
+		// Writing over an existing file: show what actually changes
+		if filePath, ok := toolUse.Input["file_path"].(string); ok {
+			if existing, err := os.ReadFile(sessionPath(filePath, cwd)); err == nil {
+				data.Details["overwrites_existing"] = true
+				de.addDiffDetails(data, filePath, string(existing), contentStr)
+			}
+		}
 	}
 }
 
@@ -204,7 +284,7 @@ func (de *DataExtractor) processBashTool(toolUse *types.ContentItem, data *types
 }
 
 // processEditTool handles Edit tool specific processing
-func (de *DataExtractor) processEditTool(toolUse *types.ContentItem, data *types.NotificationEventData) {
+func (de *DataExtractor) processEditTool(toolUse *types.ContentItem, data *types.NotificationEventData, cwd string) {
 	data.Action = "edit_file"
 	
 	if filePath, exists := toolUse.Input["file_path"]; exists {
@@ -213,8 +293,8 @@ func (de *DataExtractor) processEditTool(toolUse *types.ContentItem, data *types
 	if oldString, exists := toolUse.Input["old_string"]; exists {
 		// Truncate for preview
 		oldStr := fmt.Sprintf("%v", oldString)
-		if len(oldStr) > 100 {
-			data.Details["old_string_preview"] = oldStr[:100] + "..."
+		if preview, cut := formatter.Cut(oldStr, de.maxContentPreview/2); cut {
+			data.Details["old_string_preview"] = preview + "..."
 		} else {
 			data.Details["old_string_preview"] = oldStr
 		}
@@ -222,12 +302,71 @@ func (de *DataExtractor) processEditTool(toolUse *types.ContentItem, data *types
 	if newString, exists := toolUse.Input["new_string"]; exists {
 		// Truncate for preview
 		newStr := fmt.Sprintf("%v", newString)
-		if len(newStr) > 100 {
-			data.Details["new_string_preview"] = newStr[:100] + "..."
+		if preview, cut := formatter.Cut(newStr, de.maxContentPreview/2); cut {
+			data.Details["new_string_preview"] = preview + "..."
 		} else {
 			data.Details["new_string_preview"] = newStr
 		}
 	}
+
+	de.addEditDiff(toolUse, data, cwd)
+}
+
+// addEditDiff renders a unified diff for an Edit request. When the target file can be
+// read, the edit is applied to it so the diff carries real line numbers and context;
+// otherwise old_string and new_string are diffed directly.
+func (de *DataExtractor) addEditDiff(toolUse *types.ContentItem, data *types.NotificationEventData, cwd string) {
+	oldString, okOld := toolUse.Input["old_string"].(string)
+	newString, okNew := toolUse.Input["new_string"].(string)
+	if !okOld || !okNew {
+		return
+	}
+	filePath, _ := toolUse.Input["file_path"].(string)
+	replaceAll, _ := toolUse.Input["replace_all"].(bool)
+
+	if filePath != "" && oldString != "" {
+		if existing, err := os.ReadFile(sessionPath(filePath, cwd)); err == nil && strings.Contains(string(existing), oldString) {
+			updated := strings.Replace(string(existing), oldString, newString, 1)
+			if replaceAll {
+				updated = strings.ReplaceAll(string(existing), oldString, newString)
+			}
+			de.addDiffDetails(data, filePath, string(existing), updated)
+			return
+		}
+	}
+
+	de.addDiffDetails(data, filePath, oldString, newString)
+}
+
+// sessionPath returns the file a tool's file_path refers to: a relative path is taken from
+// the session's working directory, not the processor's
+func sessionPath(filePath, cwd string) string {
+	if filepath.IsAbs(filePath) || cwd == "" {
+		return filePath
+	}
+	return filepath.Join(cwd, filePath)
+}
+
+// addDiffDetails stores a unified diff and its line counts in the notification details
+func (de *DataExtractor) addDiffDetails(data *types.NotificationEventData, filePath, oldText, newText string) {
+	name := filePath
+	if name == "" {
+		name = "file"
+	}
+
+	unified := diff.Unified("a/"+strings.TrimPrefix(name, "/"), "b/"+strings.TrimPrefix(name, "/"), oldText, newText, diffContextLines)
+	if unified == "" {
+		return
+	}
+
+	if cut, ok := formatter.Cut(unified, maxDiffLength); ok {
+		unified = cut + "\n... (diff truncated)"
+	}
+
+	added, removed := diff.Stats(oldText, newText)
+	data.Details["diff"] = unified
+	data.Details["lines_added"] = added
+	data.Details["lines_removed"] = removed
 }
 
 // processListTool handles List/LS tool specific processing
@@ -319,29 +458,32 @@ func (de *DataExtractor) generateSummary(finalMessage string) string {
 }
 
 // GetEventContext gets additional context for an event by analyzing recent transcript messages
-func (de *DataExtractor) GetEventContext(event *types.ClaudeHookEvent, maxMessages int) (map[string]interface{}, error) {
-	context := make(map[string]interface{})
-	
+func (de *DataExtractor) GetEventContext(event *types.ClaudeHookEvent, maxMessages int) (*types.ConversationContext, error) {
 	// Get recent messages for context
 	recentMessages, err := de.transcriptReader.GetConversationContext(event.TranscriptPath, maxMessages)
 	if err != nil {
-		return context, err // Return empty context rather than error
+		return nil, err
 	}
-	
+
 	// Count message types
 	userMessages := de.transcriptReader.GetMessagesByType(recentMessages, "user")
 	assistantMessages := de.transcriptReader.GetMessagesByType(recentMessages, "assistant")
-	
-	context["recent_user_messages"] = len(userMessages)
-	context["recent_assistant_messages"] = len(assistantMessages)
-	context["total_recent_messages"] = len(recentMessages)
-	
-	// Get session info
-	sessionInfo, err := de.transcriptReader.GetSessionInfo(event.TranscriptPath)
-	if err == nil {
-		context["session_info"] = sessionInfo
+	context := &types.ConversationContext{
+		RecentUserMessages:      len(userMessages),
+		RecentAssistantMessages: len(assistantMessages),
+		TotalRecentMessages:     len(recentMessages),
+	}
+	for i := range userMessages {
+		if !userMessages[i].IsSidechain && de.transcriptReader.PromptText(&userMessages[i]) != "" {
+			context.RecentExchanges++
+		}
 	}
-	
+
+	// The prompt may be older than the recent messages after a long run of tool calls
+	if prompt, err := de.transcriptReader.GetLastPrompt(event.TranscriptPath); err == nil {
+		context.LastPrompt = prompt
+	}
+
 	return context, nil
 }
 
@@ -0,0 +1,137 @@
+package extractor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/transcript"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// maxTrackedTranscripts caps how many transcripts collectMetadata keeps its place in
+const maxTrackedTranscripts = 100
+
+// transcriptMetadata is the metadata read from a transcript so far
+type transcriptMetadata struct {
+	offset    int64 // Where reading continues
+	metadata  types.SessionMetadata
+	seenUsage map[string]bool // Message IDs whose token usage is counted
+}
+
+// collectMetadata gathers repository, branch, model and token usage for an event's session.
+// Missing pieces are simply left empty; metadata never causes extraction to fail. Only the
+// lines a transcript gained since the previous event of its session are read.
+func (de *DataExtractor) collectMetadata(event *types.ClaudeHookEvent) *types.SessionMetadata {
+	de.mu.Lock()
+	defer de.mu.Unlock()
+
+	state := de.metadata[event.TranscriptPath]
+	if state == nil {
+		state = &transcriptMetadata{seenUsage: make(map[string]bool)}
+	}
+	messages, offset, err := de.transcriptReader.ReadNewMessages(event.TranscriptPath, state.offset)
+	if errors.Is(err, transcript.ErrTruncated) {
+		state = &transcriptMetadata{seenUsage: make(map[string]bool)}
+		messages, offset, err = de.transcriptReader.ReadNewMessages(event.TranscriptPath, 0)
+	}
+	if err != nil && len(messages) == 0 && offset == state.offset {
+		return &types.SessionMetadata{RepoName: repoName(event.CWD), GitAuthor: de.gitAuthor(event.CWD)}
+	}
+	state.addMessages(messages)
+	state.offset = offset
+
+	if _, tracked := de.metadata[event.TranscriptPath]; !tracked && len(de.metadata) >= maxTrackedTranscripts {
+		// Forget an arbitrary transcript; it is read from the start again if it comes back
+		for path := range de.metadata {
+			delete(de.metadata, path)
+			break
+		}
+	}
+	de.metadata[event.TranscriptPath] = state
+
+	metadata := state.metadata
+	metadata.RepoName = repoName(event.CWD)
+	metadata.GitAuthor = de.gitAuthor(event.CWD)
+	return &metadata
+}
+
+// gitAuthor returns who commits in a working directory: git's user.email, else user.name.
+// Answers are kept per directory; git isn't asked again until the extractor is recreated.
+func (de *DataExtractor) gitAuthor(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	if author, known := de.authors[dir]; known {
+		return author
+	}
+
+	author := ""
+	for _, key := range []string{"user.email", "user.name"} {
+		output, err := exec.Command("git", "-C", dir, "config", key).Output()
+		if author = strings.TrimSpace(string(output)); err == nil && author != "" {
+			break
+		}
+	}
+	if len(de.authors) >= maxTrackedTranscripts {
+		clear(de.authors)
+	}
+	de.authors[dir] = author
+	return author
+}
+
+// addMessages adds the branch, version, model and token usage of transcript messages
+func (t *transcriptMetadata) addMessages(messages []types.TranscriptMessage) {
+	metadata := &t.metadata
+	// Assistant responses are split across several transcript lines sharing one message ID,
+	// each repeating the same usage, so count usage once per message ID
+	for _, message := range messages {
+		if message.GitBranch != "" {
+			metadata.GitBranch = message.GitBranch
+		}
+		if message.Version != "" {
+			metadata.ClaudeVersion = message.Version
+		}
+		if message.Type != "assistant" {
+			continue
+		}
+		if message.Message.Model != "" {
+			metadata.Model = message.Message.Model
+		}
+		if usage := message.Message.Usage; usage != nil {
+			key := message.Message.ID
+			if key == "" {
+				key = message.UUID
+			}
+			if t.seenUsage[key] {
+				continue
+			}
+			t.seenUsage[key] = true
+			metadata.InputTokens += usage.InputTokens
+			metadata.OutputTokens += usage.OutputTokens
+			metadata.CacheTokens += usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+		}
+	}
+}
+
+// repoName returns the name of the git repository containing dir, falling back to dir's own name
+func repoName(dir string) string {
+	if dir == "" {
+		return ""
+	}
+
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return filepath.Base(current)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+
+	return filepath.Base(dir)
+}
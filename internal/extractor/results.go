@@ -0,0 +1,58 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// maxToolOutputLength caps the tool output kept in event data
+const maxToolOutputLength = 2000
+
+// addToolResult attaches what a tool call returned, when the transcript already holds its
+// tool_result. A tool waiting for permission hasn't run yet, so it usually has none.
+func (de *DataExtractor) addToolResult(transcriptPath string, toolUse *types.ContentItem, data *types.NotificationEventData) {
+	if toolUse.ID == "" {
+		return
+	}
+	item, message, err := de.transcriptReader.FindToolResult(transcriptPath, toolUse.ID)
+	if err != nil {
+		return
+	}
+
+	result := &types.ToolResult{IsError: item.IsError}
+	result.Output, result.Truncated = truncateOutput(de.transcriptReader.ToolResultText(item))
+
+	// Claude Code also records a structured result next to the tool_result
+	if details, ok := message.ToolUseResult.(map[string]interface{}); ok {
+		var truncated bool
+		if stdout, ok := details["stdout"].(string); ok {
+			result.Stdout, truncated = truncateOutput(stdout)
+			result.Truncated = result.Truncated || truncated
+		}
+		if stderr, ok := details["stderr"].(string); ok {
+			result.Stderr, truncated = truncateOutput(stderr)
+			result.Truncated = result.Truncated || truncated
+		}
+		if content, ok := details["content"].(string); ok && strings.ToLower(toolUse.Name) == "write" {
+			result.BytesWritten = len(content)
+		}
+	}
+	if result.BytesWritten == 0 && !result.IsError && strings.ToLower(toolUse.Name) == "write" {
+		if content, ok := toolUse.Input["content"].(string); ok {
+			result.BytesWritten = len(content)
+		}
+	}
+
+	data.Result = result
+}
+
+// truncateOutput shortens tool output to maxToolOutputLength and reports whether it did
+func truncateOutput(output string) (string, bool) {
+	output = strings.TrimRight(output, "\n")
+	if cut, ok := formatter.Cut(output, maxToolOutputLength); ok {
+		return cut + "...", true
+	}
+	return output, false
+}
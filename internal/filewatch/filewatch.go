@@ -0,0 +1,88 @@
+package filewatch
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+)
+
+// Options controls how a file is watched
+type Options struct {
+	// PollInterval is used when polling, either forced or as a fallback
+	PollInterval time.Duration
+	// ForcePolling skips file system notifications (e.g. for network filesystems where they don't fire)
+	ForcePolling bool
+}
+
+// Watch calls onChange whenever path may have changed until ctx is cancelled.
+// File system notifications are used when available; if they cannot be set up
+// (or polling is forced) it falls back to calling onChange every PollInterval.
+func Watch(ctx context.Context, path string, opts Options, logger *logger.Logger, onChange func()) error {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+
+	if !opts.ForcePolling {
+		watcher, err := fsnotify.NewWatcher()
+		if err == nil {
+			// Watch the directory rather than the file so creation, rotation and
+			// replacement of the file are all seen
+			dir := filepath.Dir(path)
+			if err = watcher.Add(dir); err == nil {
+				logger.Debug("Watching %s with file system notifications", path)
+				defer watcher.Close()
+				return watchNotifications(ctx, watcher, path, logger, onChange)
+			}
+			watcher.Close()
+		}
+		logger.Info("File system notifications unavailable (%v), falling back to polling every %v", err, opts.PollInterval)
+	}
+
+	return watchPolling(ctx, opts.PollInterval, onChange)
+}
+
+// watchNotifications dispatches fsnotify events for path to onChange
+func watchNotifications(ctx context.Context, watcher *fsnotify.Watcher, path string, logger *logger.Logger, onChange func()) error {
+	target := filepath.Clean(path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != target {
+				continue
+			}
+			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				onChange()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Error("File watcher error: %v", err)
+		}
+	}
+}
+
+// watchPolling calls onChange on every tick
+func watchPolling(ctx context.Context, interval time.Duration, onChange func()) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			onChange()
+		}
+	}
+}
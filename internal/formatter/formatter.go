@@ -4,28 +4,119 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
 )
 
 // MessengerFormatter handles formatting extracted data for messenger consumption
-type MessengerFormatter struct{}
+type MessengerFormatter struct {
+	options   Options
+	templates map[string]*template.Template
+}
+
+// Options controls optional formatting behaviour
+type Options struct {
+	// TemplatesDir holds user message templates (e.g. notification-bash.tmpl); empty disables templates
+	TemplatesDir string
+	// IncludeEmojis keeps emojis in titles and action labels
+	IncludeEmojis bool
+	// MaxMessageLength truncates message bodies (0 = unlimited)
+	MaxMessageLength int
+	// MaxContentPreview truncates content previews taken from tool input (0 = extractor default)
+	MaxContentPreview int
+	// TimestampFormat is the Go time layout used to display times
+	TimestampFormat string
+	// UseRelativeTime displays recent times as "3 minutes ago"
+	UseRelativeTime bool
+	// ContextMessages adds the user's last prompt and the number of recent exchanges, counted
+	// over this many recent transcript messages (0 = off)
+	ContextMessages int
+	// Projects maps project directories to names, which label messages from inside them
+	Projects map[string]string
+	// ProjectInTitle starts titles with the project name
+	ProjectInTitle bool
+	// Users own the sessions of their git authors and directories
+	Users []User
+}
+
+// DefaultOptions returns the formatting options used when none are configured
+func DefaultOptions() Options {
+	return Options{
+		IncludeEmojis:   true,
+		TimestampFormat: "2006-01-02 15:04:05",
+		ProjectInTitle:  true,
+	}
+}
 
 // NewMessengerFormatter creates a new messenger formatter
 func NewMessengerFormatter() *MessengerFormatter {
-	return &MessengerFormatter{}
+	return &MessengerFormatter{
+		options:   DefaultOptions(),
+		templates: make(map[string]*template.Template),
+	}
+}
+
+// SetOptions changes the formatting options
+func (mf *MessengerFormatter) SetOptions(options Options) {
+	mf.options = options
+	mf.templates = make(map[string]*template.Template)
 }
 
 // FormatForMessenger converts extracted data into a messenger-friendly format
 func (mf *MessengerFormatter) FormatForMessenger(data *types.ExtractedData) (*types.MessengerMessage, error) {
+	var message *types.MessengerMessage
+	var err error
+
 	switch data.EventType {
 	case "stop":
-		return mf.formatStopEvent(data)
+		message, err = mf.formatStopEvent(data)
 	case "notification":
-		return mf.formatNotificationEvent(data)
+		message, err = mf.formatNotificationEvent(data)
 	default:
 		return nil, fmt.Errorf("unknown event type: %s", data.EventType)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	mf.addConversation(data.Conversation, message)
+	mf.addMetadata(data.Metadata, message)
+	message.Attachments = mf.collectAttachments(data)
+	project := ProjectFor(data.CWD, mf.options.Projects, data.Metadata)
+	if project != "" {
+		message.Context["project"] = project
+	}
+	if owner := OwnerFor(data.CWD, data.Metadata, mf.options.Users); owner != "" {
+		message.Context["owner"] = owner
+	}
+
+	// Apply user templates on top of the built-in formatting
+	if err := mf.applyTemplate(data, message, project); err != nil {
+		return nil, err
+	}
+	if project != "" && mf.options.ProjectInTitle {
+		message.Title = projectTitle(message.Title, project)
+	}
+
+	mf.applyLimits(message)
+
+	return message, nil
+}
+
+// applyLimits enforces the emoji and length settings on a finished message
+func (mf *MessengerFormatter) applyLimits(message *types.MessengerMessage) {
+	if !mf.options.IncludeEmojis {
+		message.Title = StripEmojis(message.Title)
+		for i := range message.Actions {
+			message.Actions[i].Label = StripEmojis(message.Actions[i].Label)
+			message.Actions[i].Icon = ""
+		}
+	}
+
+	if mf.options.MaxMessageLength > 0 {
+		message.Message = Truncate(message.Message, mf.options.MaxMessageLength)
+	}
 }
 
 // formatStopEvent formats a Stop event for messenger
@@ -99,6 +190,10 @@ func (mf *MessengerFormatter) formatNotificationEvent(data *types.ExtractedData)
 	// Set title and message based on tool type
 	message.Title = mf.getNotificationTitle(notificationData)
 	message.Message = mf.formatNotificationMessage(notificationData)
+	message.Message += mf.formatToolResult(notificationData.Result)
+	if notificationData.Subagent {
+		message.Message = strings.Replace(message.Message, "Claude wants", mf.subagentLabel(notificationData)+" wants", 1)
+	}
 
 	// Add context information
 	message.Context["cwd"] = data.CWD
@@ -110,6 +205,12 @@ func (mf *MessengerFormatter) formatNotificationEvent(data *types.ExtractedData)
 	for key, value := range notificationData.Details {
 		message.Context[key] = value
 	}
+	if notificationData.Result != nil {
+		message.Context["tool_result"] = notificationData.Result
+	}
+	if notificationData.Subagent {
+		message.Context["agent"] = mf.subagentLabel(notificationData)
+	}
 
 	// Create suggested actions
 	message.Actions = mf.createNotificationActions(notificationData, data)
@@ -117,6 +218,122 @@ func (mf *MessengerFormatter) formatNotificationEvent(data *types.ExtractedData)
 	return message, nil
 }
 
+// collectAttachments gathers the full content behind a message (proposed file content,
+// diffs, the final response) so channels that support uploads can attach it
+func (mf *MessengerFormatter) collectAttachments(data *types.ExtractedData) []types.Attachment {
+	var attachments []types.Attachment
+
+	switch eventData := data.Data.(type) {
+	case *types.StopEventData:
+		if eventData.FinalMessage != "" {
+			attachments = append(attachments, newAttachment("summary.md", "text/markdown", "Claude's final response", eventData.FinalMessage))
+		}
+	case *types.NotificationEventData:
+		fileName := "file"
+		if target, exists := eventData.Details["target_file"]; exists {
+			fileName = filepath.Base(fmt.Sprintf("%v", target))
+		}
+		if content, ok := eventData.Details["content"].(string); ok && strings.ToLower(eventData.ToolName) == "write" {
+			attachments = append(attachments, newAttachment(fileName, "text/plain", "Full proposed file content", content))
+		}
+		if diffText, ok := eventData.Details["diff"].(string); ok {
+			attachments = append(attachments, newAttachment(fileName+".diff", "text/x-diff", "Unified diff of the change", diffText))
+		}
+		if command, ok := eventData.Details["command"].(string); ok && strings.ToLower(eventData.ToolName) == "bash" {
+			attachments = append(attachments, newAttachment("command.sh", "text/x-shellscript", "Full command", command))
+		}
+	}
+
+	return attachments
+}
+
+// newAttachment creates an in-memory attachment
+func newAttachment(name, mimeType, description, content string) types.Attachment {
+	return types.Attachment{
+		Name:        name,
+		MimeType:    mimeType,
+		Description: description,
+		Size:        len(content),
+		Content:     content,
+	}
+}
+
+// addConversation adds the user's last prompt and how much was said recently to the message
+// context and body, so an approver can see why Claude is asking
+func (mf *MessengerFormatter) addConversation(conversation *types.ConversationContext, message *types.MessengerMessage) {
+	if conversation == nil {
+		return
+	}
+
+	message.Context["recent_exchanges"] = conversation.RecentExchanges
+	if conversation.LastPrompt == "" {
+		return
+	}
+	message.Context["last_prompt"] = conversation.LastPrompt
+
+	preview := mf.options.MaxContentPreview
+	if preview <= 0 {
+		preview = 200
+	}
+	prompt := Truncate(strings.Join(strings.Fields(conversation.LastPrompt), " "), preview)
+	message.Message += fmt.Sprintf("\n\n💬 Asked: \"%s\"", prompt)
+	if conversation.RecentExchanges > 1 {
+		message.Message += fmt.Sprintf(" (%d recent exchanges)", conversation.RecentExchanges)
+	}
+}
+
+// addMetadata adds project and model details to the message context and body
+func (mf *MessengerFormatter) addMetadata(metadata *types.SessionMetadata, message *types.MessengerMessage) {
+	if metadata == nil {
+		return
+	}
+
+	var footer []string
+	if metadata.RepoName != "" {
+		message.Context["repo_name"] = metadata.RepoName
+		project := metadata.RepoName
+		if metadata.GitBranch != "" {
+			project += " @ " + metadata.GitBranch
+		}
+		footer = append(footer, "Project: "+project)
+	}
+	if metadata.GitBranch != "" {
+		message.Context["git_branch"] = metadata.GitBranch
+	}
+	if metadata.GitAuthor != "" {
+		message.Context["git_author"] = metadata.GitAuthor
+	}
+	if metadata.Model != "" {
+		message.Context["model"] = metadata.Model
+		footer = append(footer, "Model: "+metadata.Model)
+	}
+	if metadata.ClaudeVersion != "" {
+		message.Context["claude_version"] = metadata.ClaudeVersion
+	}
+	if metadata.InputTokens+metadata.OutputTokens > 0 {
+		message.Context["input_tokens"] = metadata.InputTokens
+		message.Context["output_tokens"] = metadata.OutputTokens
+		message.Context["cache_tokens"] = metadata.CacheTokens
+		footer = append(footer, fmt.Sprintf("Tokens: %s in / %s out", compactCount(metadata.InputTokens+metadata.CacheTokens), compactCount(metadata.OutputTokens)))
+	}
+
+	if len(footer) > 0 {
+		message.Message += "\n\n" + strings.Join(footer, " | ")
+	}
+}
+
+// compactCount renders a count as e.g. 950, 12.3k or 1.2M
+func compactCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
 // formatStopMessage creates a user-friendly message for stop events
 func (mf *MessengerFormatter) formatStopMessage(data *types.StopEventData) string {
 	if data.FinalMessage == "" {
@@ -150,15 +367,23 @@ func (mf *MessengerFormatter) formatNotificationMessage(data *types.Notification
 	case "write":
 		if filePath, exists := data.Details["target_file"]; exists {
 			fileName := filepath.Base(fmt.Sprintf("%v", filePath))
-			baseMessage = fmt.Sprintf("Claude wants to create file: %s", fileName)
-			if preview, exists := data.Details["content_preview"]; exists {
-				baseMessage += fmt.Sprintf("\n\nContent preview:\n%v", preview)
+			if diffText, exists := data.Details["diff"]; exists {
+				baseMessage = fmt.Sprintf("Claude wants to overwrite file: %s", fileName)
+				baseMessage += mf.formatDiffSection(data, diffText)
+			} else {
+				baseMessage = fmt.Sprintf("Claude wants to create file: %s", fileName)
+				if preview, exists := data.Details["content_preview"]; exists {
+					baseMessage += fmt.Sprintf("\n\nContent preview:\n%v", preview)
+				}
 			}
 		}
 	case "edit":
 		if filePath, exists := data.Details["target_file"]; exists {
 			fileName := filepath.Base(fmt.Sprintf("%v", filePath))
 			baseMessage = fmt.Sprintf("Claude wants to edit file: %s", fileName)
+			if diffText, exists := data.Details["diff"]; exists {
+				baseMessage += mf.formatDiffSection(data, diffText)
+			}
 		}
 	case "read":
 		if filePath, exists := data.Details["target_file"]; exists {
@@ -182,6 +407,57 @@ func (mf *MessengerFormatter) formatNotificationMessage(data *types.Notification
 	return baseMessage
 }
 
+// formatDiffSection renders the diff block appended to file change messages
+func (mf *MessengerFormatter) formatDiffSection(data *types.NotificationEventData, diffText interface{}) string {
+	return fmt.Sprintf("\n\nChanges (+%v -%v):\n%v", data.Details["lines_added"], data.Details["lines_removed"], diffText)
+}
+
+// subagentLabel names the subagent that requested a tool, e.g.
+// `Claude's code-reviewer subagent ("Review the diff")`
+func (mf *MessengerFormatter) subagentLabel(data *types.NotificationEventData) string {
+	label := "Claude's subagent"
+	if data.Agent != "" {
+		label = fmt.Sprintf("Claude's %s subagent", data.Agent)
+	}
+	if data.AgentTask != "" {
+		label += fmt.Sprintf(" (%q)", data.AgentTask)
+	}
+	return label
+}
+
+// formatToolResult renders what a tool returned, if it has run
+func (mf *MessengerFormatter) formatToolResult(result *types.ToolResult) string {
+	if result == nil {
+		return ""
+	}
+
+	var section strings.Builder
+	if result.IsError {
+		section.WriteString("\n\n❌ Tool failed")
+	} else {
+		section.WriteString("\n\n✅ Tool result")
+	}
+	if result.BytesWritten > 0 {
+		fmt.Fprintf(&section, " (%d bytes written)", result.BytesWritten)
+	}
+	section.WriteString(":")
+
+	switch {
+	case result.Stdout != "" || result.Stderr != "":
+		if result.Stdout != "" {
+			fmt.Fprintf(&section, "\n%s", result.Stdout)
+		}
+		if result.Stderr != "" {
+			fmt.Fprintf(&section, "\nstderr:\n%s", result.Stderr)
+		}
+	case result.Output != "":
+		fmt.Fprintf(&section, "\n%s", result.Output)
+	default:
+		section.WriteString(" (no output)")
+	}
+	return section.String()
+}
+
 // getNotificationTitle creates a title for notification events
 func (mf *MessengerFormatter) getNotificationTitle(data *types.NotificationEventData) string {
 	switch strings.ToLower(data.ToolName) {
@@ -0,0 +1,45 @@
+package formatter
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// User is a developer whose sessions are recognized by git author or directory
+type User struct {
+	Name       string
+	GitAuthors []string // git user.email or user.name values
+	Paths      []string // Directories the developer's sessions run in
+}
+
+// OwnerFor names the user a session belongs to: the user with the repository's git author,
+// else the user whose path is the longest one containing cwd. It returns "" for a session
+// no user claims.
+func OwnerFor(cwd string, metadata *types.SessionMetadata, users []User) string {
+	if metadata != nil && metadata.GitAuthor != "" {
+		for _, user := range users {
+			for _, author := range user.GitAuthors {
+				if strings.EqualFold(author, metadata.GitAuthor) {
+					return user.Name
+				}
+			}
+		}
+	}
+
+	if cwd == "" {
+		return ""
+	}
+	cwd = filepath.Clean(cwd)
+	best, owner := "", ""
+	for _, user := range users {
+		for _, dir := range user.Paths {
+			dir = filepath.Clean(dir)
+			if (cwd == dir || strings.HasPrefix(cwd, dir+string(filepath.Separator))) && len(dir) > len(best) {
+				best, owner = dir, user.Name
+			}
+		}
+	}
+	return owner
+}
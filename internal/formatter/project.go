@@ -0,0 +1,55 @@
+package formatter
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// ProjectFor names the project an event from a working directory belongs to: the project
+// whose directory is the longest one containing cwd, else the repository name, else the
+// directory's own name. projects maps directories to project names.
+func ProjectFor(cwd string, projects map[string]string, metadata *types.SessionMetadata) string {
+	if cwd != "" {
+		cwd = filepath.Clean(cwd)
+		best, project := "", ""
+		for dir, name := range projects {
+			dir = filepath.Clean(dir)
+			if (cwd == dir || strings.HasPrefix(cwd, dir+string(filepath.Separator))) && len(dir) > len(best) {
+				best, project = dir, name
+			}
+		}
+		if project != "" {
+			return project
+		}
+	}
+
+	if metadata != nil && metadata.RepoName != "" {
+		return metadata.RepoName
+	}
+	if base := filepath.Base(cwd); cwd != "" && base != "." && base != string(filepath.Separator) {
+		return base
+	}
+	return ""
+}
+
+// projectTitle starts a title with the project name, after its leading emoji if it has one
+func projectTitle(title, project string) string {
+	label := "[" + project + "]"
+	if emoji, rest, found := strings.Cut(title, " "); found && StripEmojis(emoji) == "" {
+		return emoji + " " + label + " " + rest
+	}
+	return label + " " + title
+}
+
+// SessionTitle adds a session label to a title, inside the project's brackets when the title
+// starts with them, so concurrent sessions of a project can be told apart
+func SessionTitle(title, project, label string) string {
+	if project != "" {
+		if bracket := "[" + project + "]"; strings.Contains(title, bracket) {
+			return strings.Replace(title, bracket, "["+project+" · "+label+"]", 1)
+		}
+	}
+	return projectTitle(title, label)
+}
@@ -0,0 +1,128 @@
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// TemplateData is the data made available to user message templates
+type TemplateData struct {
+	*types.ExtractedData
+	Stop         *types.StopEventData
+	Notification *types.NotificationEventData
+	// Title and Message hold the built-in formatting, so templates can wrap rather than replace it
+	Title   string
+	Message string
+	// Project is the project the event belongs to (see ProjectFor)
+	Project string
+}
+
+// templateFuncs are helper functions available inside message templates
+var templateFuncs = template.FuncMap{
+	"base":  filepath.Base,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"truncate": func(max int, s string) string {
+		if cut, ok := Cut(s, max); ok {
+			return cut + "..."
+		}
+		return s
+	},
+}
+
+// findTemplate returns the most specific template file for an event, or "" if none exists.
+// Lookup order: <event>-<tool>.tmpl, then <event>.tmpl (e.g. notification-bash.tmpl, notification.tmpl)
+func (mf *MessengerFormatter) findTemplate(data *types.ExtractedData) string {
+	if mf.options.TemplatesDir == "" {
+		return ""
+	}
+
+	var candidates []string
+	if notificationData, ok := data.Data.(*types.NotificationEventData); ok && notificationData.ToolName != "" {
+		candidates = append(candidates, fmt.Sprintf("%s-%s.tmpl", data.EventType, strings.ToLower(notificationData.ToolName)))
+	}
+	candidates = append(candidates, fmt.Sprintf("%s.tmpl", data.EventType))
+
+	for _, name := range candidates {
+		path := filepath.Join(mf.options.TemplatesDir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// loadTemplate parses a template file, caching the result for subsequent messages
+func (mf *MessengerFormatter) loadTemplate(path string) (*template.Template, error) {
+	if tmpl, exists := mf.templates[path]; exists {
+		return tmpl, nil
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+
+	mf.templates[path] = tmpl
+	return tmpl, nil
+}
+
+// applyTemplate overrides a message's title and body from a user template, if one matches.
+// A template file may define a "title" block, a "body" block, or both; without a "body"
+// block, the file's own text outside its blocks is the body. Anything it does not define
+// keeps the built-in formatting.
+func (mf *MessengerFormatter) applyTemplate(data *types.ExtractedData, message *types.MessengerMessage, project string) error {
+	path := mf.findTemplate(data)
+	if path == "" {
+		return nil
+	}
+
+	tmpl, err := mf.loadTemplate(path)
+	if err != nil {
+		return err
+	}
+
+	templateData := TemplateData{
+		ExtractedData: data,
+		Title:         message.Title,
+		Message:       message.Message,
+		Project:       project,
+	}
+	switch eventData := data.Data.(type) {
+	case *types.StopEventData:
+		templateData.Stop = eventData
+	case *types.NotificationEventData:
+		templateData.Notification = eventData
+	}
+
+	if t := tmpl.Lookup("title"); t != nil {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, templateData); err != nil {
+			return fmt.Errorf("failed to execute title template in %s: %w", path, err)
+		}
+		message.Title = strings.TrimSpace(buf.String())
+	}
+
+	body := tmpl.Lookup("body")
+	if body == nil {
+		body = tmpl
+	}
+	var buf bytes.Buffer
+	if err := body.Execute(&buf, templateData); err != nil {
+		return fmt.Errorf("failed to execute body template in %s: %w", path, err)
+	}
+	// A file of only blocks leaves nothing outside them
+	if text := strings.TrimSpace(buf.String()); text != "" || body != tmpl {
+		message.Message = text
+	}
+
+	return nil
+}
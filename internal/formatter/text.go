@@ -0,0 +1,140 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// StripEmojis removes emoji characters (and their joiners/variation selectors) from s
+func StripEmojis(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if isEmoji(r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	// Collapse the gaps left behind, e.g. "✅ Approve" -> "Approve"
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// isEmoji reports whether r is an emoji or an emoji modifier
+func isEmoji(r rune) bool {
+	switch {
+	case r == 0x200D: // zero width joiner
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F: // variation selectors
+		return true
+	case r >= 0x1F000 && r <= 0x1FAFF: // pictographs, emoticons, transport, symbols
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // misc symbols and dingbats
+		return true
+	case r >= 0x2300 && r <= 0x23FF: // misc technical (⏱️, ⏹️, ⌛)
+		return true
+	case r >= 0x2190 && r <= 0x21FF: // arrows
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // misc symbols and arrows (⭐, ⬆️)
+		return true
+	case r == 0x2139 || r == 0x203C || r == 0x2049: // ℹ️ ‼️ ⁉️
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // tag characters (flag sequences)
+		return true
+	}
+	return false
+}
+
+// Truncate shortens s to at most max runes, ending with "..." when cut
+func Truncate(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	if max <= 3 {
+		cut, _ := Cut(s, max)
+		return cut
+	}
+	cut, _ := Cut(s, max-3)
+	return cut + "..."
+}
+
+// Cut returns the first max runes of s and whether anything was cut off. Cutting on rune
+// boundaries keeps multi-byte characters whole; a negative max leaves s as it is.
+func Cut(s string, max int) (string, bool) {
+	if max < 0 || len(s) <= max {
+		return s, false
+	}
+	i := 0
+	for n := range s {
+		if i == max {
+			return s[:n], true
+		}
+		i++
+	}
+	return s, false
+}
+
+// relativeTimeLimit is how old a time can be before relative formatting falls back to the layout
+const relativeTimeLimit = 7 * 24 * time.Hour
+
+// FormatTime renders a time for display, as "3 minutes ago" style text when relative
+// times are enabled and the time is recent, otherwise using the configured layout.
+// It is meant to be called when a message is shown so relative times stay accurate.
+func (o Options) FormatTime(t time.Time) string {
+	layout := o.TimestampFormat
+	if layout == "" {
+		layout = "2006-01-02 15:04:05"
+	}
+
+	if o.UseRelativeTime {
+		if relative, ok := RelativeTime(t, time.Now()); ok {
+			return relative
+		}
+	}
+
+	return t.Local().Format(layout)
+}
+
+// FormatTimestamp is FormatTime for RFC3339 timestamp strings; unparseable input is returned as-is
+func (o Options) FormatTimestamp(timestamp string) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return o.FormatTime(t)
+}
+
+// RelativeTime describes t relative to now, e.g. "just now", "5 minutes ago", "in 2 hours".
+// It returns false when t is too far from now for a relative description to be useful.
+func RelativeTime(t, now time.Time) (string, bool) {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d > relativeTimeLimit {
+		return "", false
+	}
+
+	var amount int
+	var unit string
+	switch {
+	case d < time.Minute:
+		return "just now", true
+	case d < time.Hour:
+		amount, unit = int(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		amount, unit = int(d/time.Hour), "hour"
+	default:
+		amount, unit = int(d/(24*time.Hour)), "day"
+	}
+
+	if amount != 1 {
+		unit += "s"
+	}
+	if future {
+		return fmt.Sprintf("in %d %s", amount, unit), true
+	}
+	return fmt.Sprintf("%d %s ago", amount, unit), true
+}
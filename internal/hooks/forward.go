@@ -0,0 +1,267 @@
+package hooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/redact"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+const (
+	// forwardAttempts is how often an event is posted before it is spooled; Claude Code
+	// waits for the hook, so retries are few and short
+	forwardAttempts = 3
+	forwardBackoff  = 250 * time.Millisecond
+	forwardTimeout  = 3 * time.Second
+	// flushLimit is how many spooled events one hook run sends, oldest first
+	flushLimit = 50
+	// staleClaim is how long a spooled event claimed by another hook run is left alone
+	staleClaim = time.Minute
+)
+
+// Spool file suffixes: waiting to be sent, and claimed by a hook run sending it
+const (
+	spoolSuffix   = ".json"
+	sendingSuffix = ".sending"
+)
+
+// Forwarder posts hook events to a remote ClaudeToGo service's POST /api/events, keeping
+// the ones it can't deliver in a spool directory until the service is reachable again
+type Forwarder struct {
+	url      string
+	secret   string // HMAC key for X-ClaudeToGo-Signature (empty = unsigned)
+	token    string // API token sent as Authorization: Bearer (empty = none)
+	spoolDir string
+	client   *http.Client
+	logger   *logger.Logger
+}
+
+// forwardError is an answer from the service; only some are worth retrying
+type forwardError struct {
+	status  int
+	message string
+}
+
+func (e *forwardError) Error() string {
+	return fmt.Sprintf("service answered %d: %s", e.status, e.message)
+}
+
+// DefaultSpoolDir returns where undelivered events are kept when hooks.spool_dir is empty
+func DefaultSpoolDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "claudetogo", "spool")
+}
+
+// NewForwarder creates a forwarder to the service at serviceURL, such as
+// https://host:8787; a URL without a path posts to /api/events
+func NewForwarder(serviceURL, secret, token, spoolDir string, logger *logger.Logger) (*Forwarder, error) {
+	u, err := url.Parse(serviceURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid forward URL %q: must be http:// or https:// with a host", serviceURL)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/api/events"
+	}
+	if spoolDir == "" {
+		spoolDir = DefaultSpoolDir()
+	}
+	return &Forwarder{
+		url:      u.String(),
+		secret:   secret,
+		token:    token,
+		spoolDir: spoolDir,
+		client:   &http.Client{Timeout: forwardTimeout},
+		logger:   logger,
+	}, nil
+}
+
+// Forward sends an event after the events spooled before it, so the service receives them
+// in order. An event that can't be delivered is spooled for the next hook run.
+func (f *Forwarder) Forward(event types.ClaudeHookEvent) error {
+	if event.Timestamp == "" {
+		// A spooled event arrives late; it keeps the time it happened
+		event.Timestamp = time.Now().Format(time.RFC3339)
+	}
+	redact.Value(&event)
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	if err := f.Flush(); err != nil {
+		return f.spoolAfter(body, err)
+	}
+	err = f.post(body)
+	for attempt := 1; attempt < forwardAttempts && retryable(err); attempt++ {
+		time.Sleep(forwardBackoff * time.Duration(attempt))
+		err = f.post(body)
+	}
+	if err != nil && retryable(err) {
+		return f.spoolAfter(body, err)
+	}
+	return err
+}
+
+// Flush sends spooled events, oldest first, stopping at the first one the service can't
+// take yet. Events the service rejects outright are dropped.
+func (f *Forwarder) Flush() error {
+	entries, err := os.ReadDir(f.spoolDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read spool: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, sendingSuffix) && f.reclaim(name) {
+			name = strings.TrimSuffix(name, sendingSuffix) + spoolSuffix
+		}
+		if strings.HasSuffix(name, spoolSuffix) {
+			names = append(names, name)
+		}
+	}
+	// Names start with the time the event was spooled
+	sort.Strings(names)
+	if len(names) > flushLimit {
+		names = names[:flushLimit]
+	}
+
+	for _, name := range names {
+		path := filepath.Join(f.spoolDir, name)
+		claimed := strings.TrimSuffix(path, spoolSuffix) + sendingSuffix
+		// Another hook run may be sending the same events
+		if err := os.Rename(path, claimed); err != nil {
+			continue
+		}
+		body, err := os.ReadFile(claimed)
+		if err != nil {
+			os.Rename(claimed, path)
+			return fmt.Errorf("failed to read spooled event: %w", err)
+		}
+
+		err = f.post(body)
+		if retryable(err) {
+			os.Rename(claimed, path)
+			return err
+		}
+		if err != nil {
+			f.logger.Warn("Dropping spooled event %s: %v", name, err)
+		}
+		os.Remove(claimed)
+	}
+	return nil
+}
+
+// reclaim puts back a spooled event claimed by a hook run that stopped before sending it
+func (f *Forwarder) reclaim(name string) bool {
+	path := filepath.Join(f.spoolDir, name)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) < staleClaim {
+		return false
+	}
+	return os.Rename(path, strings.TrimSuffix(path, sendingSuffix)+spoolSuffix) == nil
+}
+
+// post sends one event, signing it when a secret is set
+func (f *Forwarder) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, f.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if f.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(f.secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		req.Header.Set("X-ClaudeToGo-Timestamp", timestamp)
+		req.Header.Set("X-ClaudeToGo-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	if f.token != "" {
+		req.Header.Set("Authorization", "Bearer "+f.token)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", f.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var answer struct {
+		Error string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if json.Unmarshal(data, &answer) != nil || answer.Error == "" {
+		answer.Error = strings.TrimSpace(string(data))
+	}
+	return &forwardError{status: resp.StatusCode, message: answer.Error}
+}
+
+// retryable reports whether sending again later may succeed: the service was unreachable,
+// failed, or refused credentials that can still be fixed. A malformed event never will.
+func retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var answer *forwardError
+	if !errors.As(err, &answer) {
+		return true
+	}
+	return answer.status >= 500 || answer.status == http.StatusUnauthorized ||
+		answer.status == http.StatusForbidden || answer.status == http.StatusTooManyRequests
+}
+
+// spoolAfter keeps an event that couldn't be sent because of cause
+func (f *Forwarder) spoolAfter(body []byte, cause error) error {
+	if err := f.spool(body); err != nil {
+		return fmt.Errorf("failed to forward event (%v) and %w", cause, err)
+	}
+	return fmt.Errorf("failed to forward event, spooled in %s: %w", f.spoolDir, cause)
+}
+
+// spool writes an event to the spool directory, named so they sort in the order spooled
+func (f *Forwarder) spool(body []byte) error {
+	if err := os.MkdirAll(f.spoolDir, 0700); err != nil {
+		return fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	name := fmt.Sprintf("%020d-%s", time.Now().UnixNano(), hex.EncodeToString(suffix))
+
+	// Written under a name Flush ignores, so it never sends half an event
+	tmp := filepath.Join(f.spoolDir, name+".tmp")
+	if err := os.WriteFile(tmp, body, 0600); err != nil {
+		return fmt.Errorf("failed to spool event: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(f.spoolDir, name+spoolSuffix)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to spool event: %w", err)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/redact"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
 )
 
@@ -53,12 +54,14 @@ func SaveEvent(event types.ClaudeHookEvent, config types.Config, logger *logger.
 	}
 	defer file.Close()
 
+	// Tool inputs and responses can carry credentials; they are masked before they reach disk
+	redact.Value(&event)
 	encoder := json.NewEncoder(file)
 	if err := encoder.Encode(event); err != nil {
 		return fmt.Errorf("failed to encode event: %w", err)
 	}
 
-	logger.Debug("Saved event: %s (Session: %s)", event.HookEventName, event.SessionID)
+	logger.WithEvent(event).Debug("Saved event")
 	return nil
 }
 
@@ -85,8 +88,9 @@ func SendResponse(response types.ClaudeHookResponse, logger *logger.Logger) erro
 	return nil
 }
 
-// ProcessFromStdin reads and processes a hook event from stdin
-func ProcessFromStdin(config types.Config, logger *logger.Logger) error {
+// ProcessFromStdin reads and processes a hook event from stdin, also sending it to a remote
+// service when forwarder is set
+func ProcessFromStdin(config types.Config, forwarder *Forwarder, logger *logger.Logger) error {
 	var event types.ClaudeHookEvent
 	decoder := json.NewDecoder(os.Stdin)
 	if err := decoder.Decode(&event); err != nil {
@@ -96,6 +100,14 @@ func ProcessFromStdin(config types.Config, logger *logger.Logger) error {
 	if err := SaveEvent(event, config, logger); err != nil {
 		return fmt.Errorf("failed to save hook event: %w", err)
 	}
+	logger = logger.WithEvent(event)
+
+	// The local log stays complete; a forward that fails is retried from the spool later
+	if forwarder != nil {
+		if err := forwarder.Forward(event); err != nil {
+			logger.Warn("%v", err)
+		}
+	}
 
 	// Process the event and generate response
 	response := ProcessEvent(event, logger)
@@ -0,0 +1,159 @@
+package jsonl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// StringFields returns the values of top-level string fields of a JSON object, in the order
+// of keys ("" for fields that are missing or not strings), without decoding the rest of it.
+// Other values are only skipped over, which costs far less than unmarshalling a line whose
+// other fields hold large tool inputs.
+func StringFields(line []byte, keys ...string) ([]string, error) {
+	values := make([]string, len(keys))
+	s := scanner{data: line}
+	s.skipSpace()
+	if !s.consume('{') {
+		return nil, s.errorf("expected an object")
+	}
+	s.skipSpace()
+	if s.consume('}') {
+		return values, nil
+	}
+	for {
+		s.skipSpace()
+		start := s.pos
+		if err := s.skipString(); err != nil {
+			return nil, err
+		}
+		key := line[start+1 : s.pos-1]
+		s.skipSpace()
+		if !s.consume(':') {
+			return nil, s.errorf("expected ':'")
+		}
+		s.skipSpace()
+
+		index := -1
+		for i, wanted := range keys {
+			if string(key) == wanted {
+				index = i
+				break
+			}
+		}
+		start = s.pos
+		if err := s.skipValue(); err != nil {
+			return nil, err
+		}
+		if index >= 0 && line[start] == '"' {
+			raw := line[start+1 : s.pos-1]
+			if bytes.IndexByte(raw, '\\') < 0 {
+				values[index] = string(raw)
+			} else if err := json.Unmarshal(line[start:s.pos], &values[index]); err != nil {
+				return nil, s.errorf("invalid string: %v", err)
+			}
+		}
+
+		s.skipSpace()
+		if s.consume('}') {
+			return values, nil
+		}
+		if !s.consume(',') {
+			return nil, s.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+// scanner walks over JSON text without decoding it
+type scanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *scanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid JSON at byte %d: %s", s.pos, fmt.Sprintf(format, args...))
+}
+
+// consume skips c if it is next
+func (s *scanner) consume(c byte) bool {
+	if s.pos < len(s.data) && s.data[s.pos] == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// skipString moves past a string, which must be next
+func (s *scanner) skipString() error {
+	if !s.consume('"') {
+		return s.errorf("expected a string")
+	}
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			return nil
+		default:
+			s.pos++
+		}
+	}
+	return s.errorf("unterminated string")
+}
+
+// skipValue moves past the next value
+func (s *scanner) skipValue() error {
+	if s.pos >= len(s.data) {
+		return s.errorf("expected a value")
+	}
+	switch s.data[s.pos] {
+	case '"':
+		return s.skipString()
+	case '{', '[':
+		depth := 0
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case '"':
+				if err := s.skipString(); err != nil {
+					return err
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			s.pos++
+			if depth == 0 {
+				return nil
+			}
+		}
+		return s.errorf("unterminated value")
+	default:
+		// Number, true, false or null
+		start := s.pos
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case ',', '}', ']', ' ', '\t', '\n', '\r':
+				if s.pos == start {
+					return s.errorf("expected a value")
+				}
+				return nil
+			}
+			s.pos++
+		}
+		return nil
+	}
+}
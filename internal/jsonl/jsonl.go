@@ -0,0 +1,192 @@
+// Package jsonl reads JSON Lines files (events, transcripts, quarantine) whose lines can
+// hold whole file contents, with a limit on how much of one line is kept in memory
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// DefaultMaxLineSize is the longest line read unless SetMaxLineSize changes it
+const DefaultMaxLineSize = 64 * 1024 * 1024
+
+// maxLineSize is the longest line Reader returns; longer lines are skipped
+var maxLineSize atomic.Int64
+
+func init() {
+	maxLineSize.Store(DefaultMaxLineSize)
+}
+
+// SetMaxLineSize sets the longest line, in bytes, read by readers created afterwards
+func SetMaxLineSize(n int64) {
+	if n > 0 {
+		maxLineSize.Store(n)
+	}
+}
+
+// MaxLineSize returns the longest line, in bytes, new readers return
+func MaxLineSize() int64 {
+	return maxLineSize.Load()
+}
+
+// ErrLineTooLong reports a line that was skipped for exceeding the maximum line size
+var ErrLineTooLong = errors.New("line exceeds the maximum line size")
+
+// LineTooLongError describes a skipped line
+type LineTooLongError struct {
+	Line int   // Line number, from 1
+	Size int64 // Length of the line in bytes
+	Max  int64
+}
+
+func (e *LineTooLongError) Error() string {
+	return fmt.Sprintf("line %d is %d bytes, over the maximum line size of %d bytes", e.Line, e.Size, e.Max)
+}
+
+// Unwrap lets errors.Is match ErrLineTooLong
+func (e *LineTooLongError) Unwrap() error {
+	return ErrLineTooLong
+}
+
+// Reader returns the non-empty lines of a JSON Lines stream. Unlike bufio.Scanner it has no
+// fixed token size: lines of any length up to the maximum line size are returned, and longer
+// ones are read past without being kept, so one huge line doesn't stop the rest being read.
+type Reader struct {
+	r       *bufio.Reader
+	max     int64
+	line    int
+	offset  int64
+	partial bool
+}
+
+// NewReader creates a reader using the current maximum line size
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, 64*1024), max: MaxLineSize()}
+}
+
+// Next returns the next non-empty line with surrounding whitespace trimmed, or io.EOF at the
+// end. A line over the maximum size is skipped and reported as a *LineTooLongError; reading
+// can continue with the next call. The returned slice is only valid until the next call.
+func (r *Reader) Next() ([]byte, error) {
+	for {
+		line, size, err := r.readLine()
+		if size == 0 && err != nil {
+			return nil, err
+		}
+		r.partial = err == io.EOF
+		r.line++
+		r.offset += size
+		if size > r.max {
+			return nil, &LineTooLongError{Line: r.line, Size: size, Max: r.max}
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Line returns the number of the line last returned or skipped, from 1
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Partial reports whether the line last returned or skipped ended without a newline, as a
+// line still being written does
+func (r *Reader) Partial() bool {
+	return r.partial
+}
+
+// Offset returns how many bytes have been consumed, up to the end of the last line read
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+// readLine reads up to and including the next newline, keeping at most max bytes. It returns
+// the kept bytes, the full length of the line, and io.EOF for a last line without newline.
+func (r *Reader) readLine() ([]byte, int64, error) {
+	var line []byte
+	var size int64
+	for {
+		chunk, err := r.r.ReadSlice('\n')
+		size += int64(len(chunk))
+		if size <= r.max {
+			line = append(line, chunk...)
+		} else {
+			line = nil
+		}
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err != nil:
+			return line, size, err
+		default:
+			return line, size, nil
+		}
+	}
+}
+
+// reverseChunkSize is how much of a stream ScanBackward reads at a time
+const reverseChunkSize = 64 * 1024
+
+// ScanBackward calls fn with the non-empty lines of r, which is size bytes long, from the last
+// to the first until fn returns false. r is read from the end in chunks, so reaching a recent
+// line doesn't read the whole stream. Lines over the maximum line size are skipped. partial is
+// set for a last line without newline, which may still be being written. The line is only
+// valid during the call.
+func ScanBackward(r io.ReaderAt, size int64, fn func(line []byte, partial bool) bool) error {
+	pos := size
+	max := MaxLineSize()
+	var carry []byte  // Start of a line whose beginning lies in an earlier chunk
+	skipping := false // Reading through a line over the maximum line size
+	final := true     // The next line visited is the last one in the stream
+	for pos > 0 {
+		n := int64(reverseChunkSize)
+		if pos < n {
+			n = pos
+		}
+		pos -= n
+		chunk := make([]byte, n, n+int64(len(carry)))
+		if _, err := r.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return err
+		}
+		chunk = append(chunk, carry...)
+
+		lines := bytes.Split(chunk, []byte("\n"))
+		if skipping {
+			// The end of this chunk is the beginning of the oversized line
+			lines = lines[:len(lines)-1]
+			if len(lines) == 0 {
+				continue
+			}
+			skipping, final = false, false
+		}
+		carry = nil
+		if pos > 0 {
+			// The first line may continue in the previous chunk
+			carry, lines = lines[0], lines[1:]
+			if int64(len(carry)) > max {
+				carry, skipping = nil, true
+			}
+		}
+		for i := len(lines) - 1; i >= 0; i-- {
+			// Split leaves an empty last line when the stream ends with a newline
+			partial := final && len(lines[i]) > 0
+			final = false
+			line := bytes.TrimSpace(lines[i])
+			if len(line) == 0 || int64(len(lines[i])) > max {
+				continue
+			}
+			if !fn(line, partial) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package layout arranges messenger files in subdirectories of the output directory, e.g.
+// one per project and session, and finds them again wherever they were written
+package layout
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Fields are the values a layout template can use
+type Fields struct {
+	Project   string // Project name, "unknown" when the event has none
+	Session   string // First 8 characters of the session ID
+	SessionID string // Full session ID
+	Event     string // Hook event, lower case: notification, stop, ...
+	Date      string // Day of the event, 2006-01-02
+}
+
+// Layout renders the subdirectory a message is written to
+type Layout struct {
+	tmpl *template.Template // nil for the flat layout
+}
+
+// Parse compiles a layout template such as "{{.Project}}/{{.Session}}"; an empty template
+// keeps every file directly in the output directory
+func Parse(text string) (*Layout, error) {
+	if strings.TrimSpace(text) == "" {
+		return &Layout{}, nil
+	}
+	tmpl, err := template.New("layout").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid layout: %w", err)
+	}
+	l := &Layout{tmpl: tmpl}
+	if _, err := l.Dir(Fields{Project: "p", Session: "s", SessionID: "s", Event: "e", Date: "d"}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Dir returns the directory, relative to the output directory, a message with these fields
+// is written to ("" for the flat layout). Field values can't add path levels or leave the
+// output directory.
+func (l *Layout) Dir(fields Fields) (string, error) {
+	if l == nil || l.tmpl == nil {
+		return "", nil
+	}
+	if fields.Project == "" {
+		fields.Project = "unknown"
+	}
+	fields.Project = segment(fields.Project)
+	fields.Session = segment(fields.Session)
+	fields.SessionID = segment(fields.SessionID)
+	fields.Event = segment(fields.Event)
+	fields.Date = segment(fields.Date)
+
+	var buf bytes.Buffer
+	if err := l.tmpl.Execute(&buf, fields); err != nil {
+		return "", fmt.Errorf("invalid layout: %w", err)
+	}
+	dir := filepath.Clean(filepath.FromSlash(strings.TrimSpace(buf.String())))
+	if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid layout: %q is outside the output directory", dir)
+	}
+	if dir == "." {
+		return "", nil
+	}
+	return dir, nil
+}
+
+// segment makes a field value safe to use as a single directory name
+func segment(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < ' ' {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(value))
+	// A leading dot would hide the directory from Find
+	value = strings.TrimLeft(value, ".")
+	if value == "" {
+		return "_"
+	}
+	return value
+}
+
+// Find returns the messenger files in an output directory and its layout subdirectories
+// whose names match a filepath.Match pattern. Hidden directories and those ClaudeToGo keeps
+// other files in (attachments, responses, test-samples) are skipped.
+func Find(outputDir, pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	err := filepath.WalkDir(outputDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == outputDir && os.IsNotExist(err) {
+				return filepath.SkipAll
+			}
+			return nil // Unreadable subdirectories are left out
+		}
+		if entry.IsDir() {
+			if path != outputDir && skipDir(entry.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if matched, _ := filepath.Match(pattern, entry.Name()); matched {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// skipDir reports whether a directory holds something other than messenger files
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "attachments" || name == "responses" || name == "test-samples"
+}
@@ -1,10 +1,158 @@
 package logger
 
-import "log"
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
 
-// Logger provides structured logging with levels
+	"github.com/riaanpieterse81/ClaudeToGo/internal/redact"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// Log formats
+const (
+	FormatText = "text" // slog's key=value lines
+	FormatJSON = "json" // One JSON object per line, for Loki, ELK and the like
+)
+
+// Components names the parts of ClaudeToGo whose log level can be set on its own
+var Components = []string{"watcher", "ingest", "delivery", "api", "heartbeat", "reload", "hooks", "responder"}
+
+// Log targets
+const (
+	TargetStderr   = "stderr"   // The standard log output: stderr, or what it was redirected to
+	TargetSyslog   = "syslog"   // The local syslog daemon
+	TargetJournald = "journald" // The systemd journal
+)
+
+var (
+	mu              sync.RWMutex
+	format          = FormatText
+	minLevel        = slog.LevelInfo
+	componentLevels = map[string]slog.Level{}
+	target          systemLog // nil writes to the standard log output
+)
+
+// systemLog is a system logging service that records each line with its priority
+type systemLog interface {
+	WriteLevel(level slog.Level, line []byte) error
+	Close() error
+}
+
+// OpenTarget sends every logger's lines to target (TargetStderr, TargetSyslog or
+// TargetJournald), tagged with tag as the program name. The returned function closes the
+// target and goes back to the standard log output.
+func OpenTarget(name, tag string) (func(), error) {
+	var system systemLog
+	var err error
+	switch name {
+	case TargetStderr, "":
+		return func() {}, nil
+	case TargetSyslog:
+		system, err = openSyslog(tag)
+	case TargetJournald:
+		system, err = openJournal(tag)
+	default:
+		return nil, fmt.Errorf("invalid log target %q, must be stderr, syslog or journald", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+
+	mu.Lock()
+	target = system
+	mu.Unlock()
+	return func() {
+		mu.Lock()
+		target = nil
+		mu.Unlock()
+		system.Close()
+	}, nil
+}
+
+// SetFormat chooses how every logger writes its lines: FormatText or FormatJSON
+func SetFormat(name string) error {
+	switch name {
+	case FormatText, FormatJSON:
+	case "":
+		name = FormatText
+	default:
+		return fmt.Errorf("invalid log format %q, must be text or json", name)
+	}
+	mu.Lock()
+	format = name
+	mu.Unlock()
+	return nil
+}
+
+// SetLevel sets the lowest level logged: debug, info, warn or error. Debug lines are also
+// logged by loggers created verbose, whatever the level.
+func SetLevel(name string) error {
+	level, err := parseLevel(name)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	minLevel = level
+	mu.Unlock()
+	return nil
+}
+
+// SetComponentLevels sets the lowest level logged by each named component (see Components),
+// overriding the level set with SetLevel. Components not in levels go back to that level.
+func SetComponentLevels(levels map[string]string) error {
+	parsed := make(map[string]slog.Level, len(levels))
+	for component, name := range levels {
+		if !isComponent(component) {
+			return fmt.Errorf("unknown log component %q, must be one of %s", component, strings.Join(Components, ", "))
+		}
+		level, err := parseLevel(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", component, err)
+		}
+		parsed[component] = level
+	}
+	mu.Lock()
+	componentLevels = parsed
+	mu.Unlock()
+	return nil
+}
+
+// parseLevel turns a level name into its slog level
+func parseLevel(name string) (slog.Level, error) {
+	switch name {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q, must be debug, info, warn or error", name)
+}
+
+// isComponent reports whether name is one of Components
+func isComponent(name string) bool {
+	for _, component := range Components {
+		if component == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Logger provides structured logging with levels on top of log/slog
 type Logger struct {
-	verbose bool
+	verbose   bool
+	component string
+	fields    []any
 }
 
 // New creates a new logger instance
@@ -12,19 +160,135 @@ func New(verbose bool) *Logger {
 	return &Logger{verbose: verbose}
 }
 
+// With returns a logger that adds key-value fields (e.g. "session_id", id) to every line
+func (l *Logger) With(args ...any) *Logger {
+	fields := make([]any, 0, len(l.fields)+len(args))
+	fields = append(append(fields, l.fields...), args...)
+	return &Logger{verbose: l.verbose, component: l.component, fields: fields}
+}
+
+// Component returns a logger for one of Components: its lines are tagged with the component
+// and filtered by the component's own level, if one is set
+func (l *Logger) Component(name string) *Logger {
+	fields := make([]any, 0, len(l.fields)+2)
+	for i := 0; i+1 < len(l.fields); i += 2 {
+		if l.fields[i] != "component" {
+			fields = append(fields, l.fields[i], l.fields[i+1])
+		}
+	}
+	fields = append(fields, "component", name)
+	return &Logger{verbose: l.verbose, component: name, fields: fields}
+}
+
+// WithEvent returns a logger that tags every line with the event's session, type and tool
+func (l *Logger) WithEvent(event types.ClaudeHookEvent) *Logger {
+	args := []any{"session_id", event.SessionID, "event", event.HookEventName}
+	if event.ToolName != "" {
+		args = append(args, "tool", event.ToolName)
+	}
+	return l.With(args...)
+}
+
 // Info logs an info level message
 func (l *Logger) Info(msg string, args ...any) {
-	log.Printf("[INFO] "+msg, args...)
+	l.log(slog.LevelInfo, msg, args...)
+}
+
+// Warn logs a warning: something is wrong but work goes on
+func (l *Logger) Warn(msg string, args ...any) {
+	l.log(slog.LevelWarn, msg, args...)
 }
 
 // Error logs an error level message
 func (l *Logger) Error(msg string, args ...any) {
-	log.Printf("[ERROR] "+msg, args...)
+	l.log(slog.LevelError, msg, args...)
 }
 
-// Debug logs a debug level message (only if verbose is enabled)
+// Debug logs a debug level message (if verbose is enabled or the level is debug)
 func (l *Logger) Debug(msg string, args ...any) {
-	if l.verbose {
-		log.Printf("[DEBUG] "+msg, args...)
+	l.log(slog.LevelDebug, msg, args...)
+}
+
+// log formats msg with args and writes it with the logger's fields, unless its level is
+// below the configured one
+func (l *Logger) log(level slog.Level, msg string, args ...any) {
+	mu.RLock()
+	threshold, ok := componentLevels[l.component]
+	if !ok {
+		threshold = minLevel
+	}
+	enabled := level >= threshold || (level == slog.LevelDebug && l.verbose)
+	mu.RUnlock()
+	if !enabled {
+		return
+	}
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	slog.New(newHandler(level)).With(l.fields...).Log(context.Background(), level, msg)
+}
+
+// newHandler creates a handler in the current format for a line of the given level. It writes
+// to the log target, or else to the standard log package's output, so redirecting that (as the
+// dashboard does) redirects these lines too.
+func newHandler(level slog.Level) slog.Handler {
+	mu.RLock()
+	name, system := format, target
+	mu.RUnlock()
+
+	var w io.Writer = stdlogWriter{}
+	if system != nil {
+		w = systemWriter{system: system, level: level}
 	}
-}
\ No newline at end of file
+	options := &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			// Syslog and the journal timestamp every line themselves
+			if system != nil && len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			// Secrets are masked in the message and every field
+			if attr.Value.Kind() == slog.KindString {
+				return slog.String(attr.Key, redact.String(attr.Value.String()))
+			}
+			return attr
+		},
+	}
+	if name == FormatJSON {
+		return slog.NewJSONHandler(w, options)
+	}
+	return slog.NewTextHandler(w, options)
+}
+
+// stdlogWriter writes to whatever the standard log package currently writes to
+type stdlogWriter struct{}
+
+func (stdlogWriter) Write(p []byte) (int, error) {
+	return log.Writer().Write(p)
+}
+
+// systemWriter writes lines of one level to a system logging service
+type systemWriter struct {
+	system systemLog
+	level  slog.Level
+}
+
+func (w systemWriter) Write(p []byte) (int, error) {
+	if err := w.system.WriteLevel(w.level, bytes.TrimSuffix(p, []byte("\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// priority maps a level to its syslog priority, which the journal uses too
+func priority(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3 // err
+	case level >= slog.LevelWarn:
+		return 4 // warning
+	case level >= slog.LevelInfo:
+		return 6 // info
+	}
+	return 7 // debug
+}
@@ -0,0 +1,18 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// openSyslog is not available: there is no syslog daemon on this platform
+func openSyslog(tag string) (systemLog, error) {
+	return nil, fmt.Errorf("syslog is not supported on %s", runtime.GOOS)
+}
+
+// openJournal is not available: the systemd journal only exists on Linux
+func openJournal(tag string) (systemLog, error) {
+	return nil, fmt.Errorf("journald is not supported on %s", runtime.GOOS)
+}
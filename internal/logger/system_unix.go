@@ -0,0 +1,78 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"log/syslog"
+	"net"
+)
+
+// journalSocket is where systemd-journald receives native protocol messages
+const journalSocket = "/run/systemd/journal/socket"
+
+// syslogWriter logs to the local syslog daemon in the daemon facility
+type syslogWriter struct {
+	writer *syslog.Writer
+}
+
+// openSyslog connects to the local syslog daemon
+func openSyslog(tag string) (systemLog, error) {
+	writer, err := syslog.New(syslog.LOG_DAEMON|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogWriter{writer: writer}, nil
+}
+
+func (s *syslogWriter) WriteLevel(level slog.Level, line []byte) error {
+	switch priority(level) {
+	case 3:
+		return s.writer.Err(string(line))
+	case 4:
+		return s.writer.Warning(string(line))
+	case 6:
+		return s.writer.Info(string(line))
+	}
+	return s.writer.Debug(string(line))
+}
+
+func (s *syslogWriter) Close() error {
+	return s.writer.Close()
+}
+
+// journalWriter logs to the systemd journal over its native protocol, so each entry carries
+// its priority and identifier as journal fields
+type journalWriter struct {
+	conn *net.UnixConn
+	tag  string
+}
+
+// openJournal connects to the systemd journal
+func openJournal(tag string) (systemLog, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journalWriter{conn: conn, tag: tag}, nil
+}
+
+func (j *journalWriter) WriteLevel(level slog.Level, line []byte) error {
+	var entry bytes.Buffer
+	fmt.Fprintf(&entry, "PRIORITY=%d\nSYSLOG_IDENTIFIER=%s\n", priority(level), j.tag)
+	// MESSAGE is length-prefixed, so a message may span lines (a panic's stack, say)
+	entry.WriteString("MESSAGE\n")
+	binary.Write(&entry, binary.LittleEndian, uint64(len(line)))
+	entry.Write(line)
+	entry.WriteByte('\n')
+
+	_, err := j.conn.Write(entry.Bytes())
+	return err
+}
+
+func (j *journalWriter) Close() error {
+	return j.conn.Close()
+}
@@ -0,0 +1,536 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/filewatch"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/responder"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// maxFeedEvents is how many events the dashboard keeps in its live feed
+const maxFeedEvents = 200
+
+// maxSessionEvents is how many recent events are kept per session for inspection
+const maxSessionEvents = 20
+
+// pendingRefreshInterval is how often pending approvals are re-read, since they are
+// written by the processor rather than appearing in the event log
+const pendingRefreshInterval = 2 * time.Second
+
+// pane identifies one of the dashboard's selectable panes
+type pane int
+
+const (
+	panePending pane = iota
+	paneSessions
+	paneFeed
+	paneCount
+)
+
+// paneTitles are the headings shown above each pane
+var paneTitles = [paneCount]string{"Pending approvals", "Sessions", "Event feed"}
+
+// sessionSummary is the dashboard's view of a single Claude session
+type sessionSummary struct {
+	SessionID  string
+	CWD        string
+	LastEvent  string
+	LastSeen   string
+	EventCount int
+	Recent     []types.ClaudeHookEvent
+}
+
+// Dashboard is an interactive terminal view of the live event feed, pending approvals and sessions
+type Dashboard struct {
+	config    types.Config
+	responder *responder.ResponseHandler
+	display   formatter.Options
+	logger    *logger.Logger
+
+	feed     []types.ClaudeHookEvent
+	pending  []*responder.PendingAction
+	sessions map[string]*sessionSummary
+
+	focus    pane
+	selected [paneCount]int
+	inspect  []string // lines of the inspect overlay, nil when closed
+	status   string
+
+	logLines *logCapture
+}
+
+// NewDashboard creates a dashboard over the given event log, using rh to list and answer pending approvals
+func NewDashboard(config types.Config, rh *responder.ResponseHandler, display formatter.Options, logger *logger.Logger) *Dashboard {
+	return &Dashboard{
+		config:    config,
+		responder: rh,
+		display:   display,
+		logger:    logger,
+		sessions:  make(map[string]*sessionSummary),
+		focus:     panePending,
+		logLines:  &logCapture{},
+	}
+}
+
+// Run shows the dashboard until the user quits or ctx is cancelled
+func (d *Dashboard) Run(ctx context.Context) error {
+	stdinFd := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdinFd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("dashboard requires an interactive terminal")
+	}
+
+	// Load existing history before taking over the screen
+	var position logPosition
+	events, err := readNewEvents(d.config.LogFile, &position, d.logger)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		d.addEvent(event)
+	}
+	d.refreshPending()
+
+	oldState, err := term.MakeRaw(stdinFd)
+	if err != nil {
+		return fmt.Errorf("failed to put terminal in raw mode: %w", err)
+	}
+
+	// Log output would corrupt the screen, so show the latest line in the footer instead
+	log.SetOutput(d.logLines)
+
+	fmt.Print("\x1b[?1049h\x1b[?25l") // alternate screen, hide cursor
+	defer func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		term.Restore(stdinFd, oldState)
+		log.SetOutput(os.Stderr)
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	keys := make(chan []byte)
+	go readKeys(ctx, keys)
+
+	changes := make(chan struct{}, 1)
+	watchOptions := filewatch.Options{
+		PollInterval: d.config.PollInterval,
+		ForcePolling: d.config.UsePolling,
+	}
+	go filewatch.Watch(ctx, d.config.LogFile, watchOptions, d.logger, func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	})
+
+	ticker := time.NewTicker(pendingRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		d.render()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changes:
+			events, err := readNewEvents(d.config.LogFile, &position, d.logger)
+			if err != nil {
+				d.status = err.Error()
+				continue
+			}
+			for _, event := range events {
+				d.addEvent(event)
+			}
+			d.refreshPending()
+		case <-ticker.C:
+			d.refreshPending()
+		case key := <-keys:
+			if quit := d.handleKey(key); quit {
+				return nil
+			}
+		}
+	}
+}
+
+// readKeys forwards raw key presses from stdin until ctx is cancelled
+func readKeys(ctx context.Context, keys chan<- []byte) {
+	buf := make([]byte, 16)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return
+		}
+		key := append([]byte(nil), buf[:n]...)
+		select {
+		case keys <- key:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// addEvent records an event in the feed and its session summary
+func (d *Dashboard) addEvent(event types.ClaudeHookEvent) {
+	d.feed = append(d.feed, event)
+	if len(d.feed) > maxFeedEvents {
+		d.feed = d.feed[len(d.feed)-maxFeedEvents:]
+	}
+
+	summary, exists := d.sessions[event.SessionID]
+	if !exists {
+		summary = &sessionSummary{SessionID: event.SessionID}
+		d.sessions[event.SessionID] = summary
+	}
+	summary.EventCount++
+	summary.LastEvent = event.HookEventName
+	summary.LastSeen = event.Timestamp
+	if event.CWD != "" {
+		summary.CWD = event.CWD
+	}
+	summary.Recent = append(summary.Recent, event)
+	if len(summary.Recent) > maxSessionEvents {
+		summary.Recent = summary.Recent[len(summary.Recent)-maxSessionEvents:]
+	}
+}
+
+// refreshPending re-reads pending approvals from the messenger output directory
+func (d *Dashboard) refreshPending() {
+	pending, err := d.responder.ListPendingActions()
+	if err != nil {
+		d.status = fmt.Sprintf("Failed to list pending actions: %v", err)
+		return
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.After(pending[j].CreatedAt)
+	})
+	d.pending = pending
+	d.clampSelection(panePending)
+}
+
+// sortedSessions returns sessions with the most recently active first
+func (d *Dashboard) sortedSessions() []*sessionSummary {
+	sessions := make([]*sessionSummary, 0, len(d.sessions))
+	for _, summary := range d.sessions {
+		sessions = append(sessions, summary)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeen > sessions[j].LastSeen
+	})
+	return sessions
+}
+
+// paneLength returns the number of selectable rows in a pane
+func (d *Dashboard) paneLength(p pane) int {
+	switch p {
+	case panePending:
+		return len(d.pending)
+	case paneSessions:
+		return len(d.sessions)
+	default:
+		return len(d.feed)
+	}
+}
+
+// clampSelection keeps a pane's selection within its rows
+func (d *Dashboard) clampSelection(p pane) {
+	length := d.paneLength(p)
+	if d.selected[p] >= length {
+		d.selected[p] = length - 1
+	}
+	if d.selected[p] < 0 {
+		d.selected[p] = 0
+	}
+}
+
+// handleKey applies a key press, returning true when the dashboard should exit
+func (d *Dashboard) handleKey(key []byte) bool {
+	d.status = ""
+
+	switch string(key) {
+	case "q", "\x03": // q, Ctrl+C
+		return true
+	case "\x1b": // Esc
+		d.inspect = nil
+	case "\t":
+		d.focus = (d.focus + 1) % paneCount
+		d.inspect = nil
+	case "\x1b[Z": // Shift+Tab
+		d.focus = (d.focus + paneCount - 1) % paneCount
+		d.inspect = nil
+	case "j", "\x1b[B":
+		d.selected[d.focus]++
+		d.clampSelection(d.focus)
+	case "k", "\x1b[A":
+		d.selected[d.focus]--
+		d.clampSelection(d.focus)
+	case "a":
+		d.respond("approve")
+	case "r":
+		d.respond("reject")
+	case "i", "\r":
+		d.inspectSelection()
+	}
+	return false
+}
+
+// respond answers the selected pending approval
+func (d *Dashboard) respond(action string) {
+	if d.focus != panePending || len(d.pending) == 0 {
+		d.status = "Select a pending approval to " + action
+		return
+	}
+
+	selected := d.pending[d.selected[panePending]]
+	if err := d.responder.HandleResponse(selected.SessionID, action); err != nil {
+		d.status = fmt.Sprintf("Failed to %s %s: %v", action, session.Short(selected.SessionID), err)
+		return
+	}
+
+	d.status = fmt.Sprintf("Recorded %s for session %s", action, session.Short(selected.SessionID))
+	d.inspect = nil
+	d.refreshPending()
+}
+
+// inspectSelection opens the inspect overlay for the selected row
+func (d *Dashboard) inspectSelection() {
+	if d.paneLength(d.focus) == 0 {
+		return
+	}
+	index := d.selected[d.focus]
+
+	switch d.focus {
+	case panePending:
+		action := d.pending[index]
+		lines := []string{"Session:  " + session.WithLabel(action.SessionID, action.SessionLabel)}
+		if len(action.SessionTags) > 0 {
+			lines = append(lines, "Tags:     "+strings.Join(action.SessionTags, ", "))
+		}
+		if action.SessionNote != "" {
+			lines = append(lines, "Note:     "+action.SessionNote)
+		}
+		lines = append(lines,
+			"Title:    "+action.Title,
+			"Created:  "+d.display.FormatTime(action.CreatedAt),
+			"File:     "+action.MessengerFile,
+			"",
+		)
+		lines = append(lines, strings.Split(action.Message, "\n")...)
+		if status, err := d.responder.GetSessionStatus(action.SessionID); err == nil && len(status.Context) > 0 {
+			lines = append(lines, "", "Context:")
+			keys := make([]string, 0, len(status.Context))
+			for key := range status.Context {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				lines = append(lines, fmt.Sprintf("  %s: %v", key, status.Context[key]))
+			}
+		}
+		d.inspect = lines
+
+	case paneSessions:
+		summary := d.sortedSessions()[index]
+		lines := []string{
+			"Session:  " + summary.SessionID,
+			"CWD:      " + summary.CWD,
+			fmt.Sprintf("Events:   %d", summary.EventCount),
+			"",
+			"Recent events:",
+		}
+		for i := len(summary.Recent) - 1; i >= 0; i-- {
+			lines = append(lines, "  "+d.eventLine(summary.Recent[i]))
+		}
+		d.inspect = lines
+
+	case paneFeed:
+		event := d.feed[len(d.feed)-1-index]
+		d.inspect = []string{
+			"Session:    " + event.SessionID,
+			"Event:      " + event.HookEventName,
+			"Tool:       " + event.ToolName,
+			"Time:       " + d.display.FormatTimestamp(event.Timestamp),
+			"CWD:        " + event.CWD,
+			"Transcript: " + event.TranscriptPath,
+			"",
+			event.Message,
+		}
+	}
+}
+
+// render redraws the whole screen
+func (d *Dashboard) render() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+
+	var lines []string
+	header := fmt.Sprintf("ClaudeToGo dashboard | log: %s | %d pending | %d sessions",
+		d.config.LogFile, len(d.pending), len(d.sessions))
+	lines = append(lines, "\x1b[1m"+formatter.Truncate(header, width)+"\x1b[0m")
+
+	// Header and footer take 3 lines; each pane has a title line
+	body := height - 3
+	if d.inspect != nil {
+		lines = append(lines, d.paneTitle(paneTitles[d.focus]+" > inspect", true, width))
+		for i := 0; i < body-1; i++ {
+			line := ""
+			if i < len(d.inspect) {
+				line = d.inspect[i]
+			}
+			lines = append(lines, formatter.Truncate(line, width))
+		}
+	} else {
+		pendingRows := body / 4
+		sessionRows := body / 4
+		feedRows := body - pendingRows - sessionRows - 3
+
+		lines = append(lines, d.renderPane(panePending, d.pendingRows(), pendingRows, width)...)
+		lines = append(lines, d.renderPane(paneSessions, d.sessionRows(), sessionRows, width)...)
+		lines = append(lines, d.renderPane(paneFeed, d.feedRows(), feedRows, width)...)
+	}
+
+	status := d.status
+	if status == "" {
+		status = d.logLines.Last()
+	}
+	lines = append(lines, formatter.Truncate(status, width))
+	help := "Tab switch pane | j/k move | a approve | r reject | i/Enter inspect | Esc close | q quit"
+	lines = append(lines, "\x1b[2m"+formatter.Truncate(help, width)+"\x1b[0m")
+
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range lines {
+		if i >= height {
+			break
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[K")
+		if i < len(lines)-1 && i < height-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString("\x1b[J")
+	fmt.Print(b.String())
+}
+
+// renderPane renders a pane title and as many rows as fit, scrolled to keep the selection visible
+func (d *Dashboard) renderPane(p pane, rows []string, visible, width int) []string {
+	focused := d.focus == p
+	lines := []string{d.paneTitle(fmt.Sprintf("%s (%d)", paneTitles[p], len(rows)), focused, width)}
+	if visible < 1 {
+		return lines
+	}
+
+	offset := 0
+	if d.selected[p] >= visible {
+		offset = d.selected[p] - visible + 1
+	}
+
+	for i := 0; i < visible; i++ {
+		index := offset + i
+		if index >= len(rows) {
+			lines = append(lines, "")
+			continue
+		}
+		line := formatter.Truncate("  "+rows[index], width)
+		if focused && index == d.selected[p] {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// paneTitle renders a pane heading, highlighted when the pane has focus
+func (d *Dashboard) paneTitle(title string, focused bool, width int) string {
+	if focused {
+		return "\x1b[1;36m" + formatter.Truncate("> "+title, width) + "\x1b[0m"
+	}
+	return formatter.Truncate("  "+title, width)
+}
+
+// pendingRows returns the display rows for pending approvals
+func (d *Dashboard) pendingRows() []string {
+	rows := make([]string, 0, len(d.pending))
+	for _, action := range d.pending {
+		rows = append(rows, fmt.Sprintf("%-8s  %-16s  %s",
+			session.WithLabel(session.Short(action.SessionID), action.SessionLabel), d.display.FormatTime(action.CreatedAt), singleLine(action.Title)))
+	}
+	return rows
+}
+
+// sessionRows returns the display rows for known sessions
+func (d *Dashboard) sessionRows() []string {
+	sessions := d.sortedSessions()
+	rows := make([]string, 0, len(sessions))
+	for _, summary := range sessions {
+		rows = append(rows, fmt.Sprintf("%-8s  %-16s  %-16s  %4d events  %s",
+			session.Short(summary.SessionID), d.display.FormatTimestamp(summary.LastSeen),
+			summary.LastEvent, summary.EventCount, summary.CWD))
+	}
+	return rows
+}
+
+// feedRows returns the display rows for the event feed, newest first
+func (d *Dashboard) feedRows() []string {
+	rows := make([]string, 0, len(d.feed))
+	for i := len(d.feed) - 1; i >= 0; i-- {
+		rows = append(rows, d.eventLine(d.feed[i]))
+	}
+	return rows
+}
+
+// eventLine renders a single event as one line
+func (d *Dashboard) eventLine(event types.ClaudeHookEvent) string {
+	line := fmt.Sprintf("%-16s  %-8s  %-16s", d.display.FormatTimestamp(event.Timestamp), session.Short(event.SessionID), event.HookEventName)
+	if event.ToolName != "" {
+		line += "  " + event.ToolName
+	}
+	if event.Message != "" {
+		line += "  " + singleLine(event.Message)
+	}
+	return line
+}
+
+
+// singleLine collapses newlines so text fits on one row
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// logCapture is an io.Writer that keeps the most recent log line
+type logCapture struct {
+	mu   sync.Mutex
+	last string
+}
+
+// Write records the last non-empty line written
+func (lc *logCapture) Write(p []byte) (int, error) {
+	lines := strings.Split(strings.TrimSpace(string(p)), "\n")
+	lc.mu.Lock()
+	lc.last = lines[len(lines)-1]
+	lc.mu.Unlock()
+	return len(p), nil
+}
+
+// Last returns the most recent log line
+func (lc *logCapture) Last() string {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.last
+}
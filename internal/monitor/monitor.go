@@ -6,57 +6,254 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"golang.org/x/term"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/extractor"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/filewatch"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/transcript"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
 )
 
-// formatEventOutput formats an event for display
-func formatEventOutput(event types.ClaudeHookEvent) string {
-	timestamp := time.Now().Format("15:04:05")
-	sessionID := event.SessionID
-	if len(sessionID) > 8 {
-		sessionID = sessionID[:8]
+// ANSI colors for monitor output
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorDim    = "\x1b[2m"
+)
+
+// maxExcerptLength caps each transcript excerpt shown under an event
+const maxExcerptLength = 160
+
+// eventPrinter renders monitored events as console lines
+type eventPrinter struct {
+	extractor    *extractor.DataExtractor
+	transcripts  *transcript.Reader
+	color        bool
+	contextLines int       // transcript excerpts to show under each event
+	since        time.Time // events before this are skipped when replaying
+}
+
+// newEventPrinter creates a printer, coloring output unless disabled by config,
+// the NO_COLOR environment variable, or stdout not being a terminal
+func newEventPrinter(config types.Config) *eventPrinter {
+	color := !config.NoColor && os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+	return &eventPrinter{
+		extractor:    extractor.NewDataExtractor(),
+		transcripts:  transcript.NewReader(),
+		color:        color,
+		contextLines: config.ShowContext,
+		since:        config.Since,
+	}
+}
+
+// print writes a single event line to stdout, labelled with its source log when given
+func (p *eventPrinter) print(event types.ClaudeHookEvent, source string) {
+	if !p.since.IsZero() {
+		if t, err := time.Parse(time.RFC3339, event.Timestamp); err == nil && t.Before(p.since) {
+			return
+		}
+	}
+
+	// Transcript details are best effort; the line is still useful without them
+	data, err := p.extractor.ProcessEvent(&event)
+	if err != nil {
+		data = nil
 	}
 
+	line := formatEventOutput(event, data)
+	if source != "" {
+		line += fmt.Sprintf(" | Source: %s", source)
+	}
+	if color := eventColor(data); p.color && color != "" {
+		line = color + line + colorReset
+	}
+	fmt.Println(line)
+
+	if p.contextLines > 0 && event.TranscriptPath != "" {
+		for _, excerpt := range p.transcriptExcerpts(event.TranscriptPath) {
+			if p.color {
+				excerpt = colorDim + excerpt + colorReset
+			}
+			fmt.Println(excerpt)
+		}
+	}
+}
+
+// transcriptExcerpts returns the last few assistant messages and tool invocations from a
+// transcript, oldest first, as indented one-line excerpts
+func (p *eventPrinter) transcriptExcerpts(transcriptPath string) []string {
+	var excerpts []string
+	err := p.transcripts.ScanBackward(transcriptPath, func(message *types.TranscriptMessage) bool {
+		var excerpt string
+
+		switch message.Type {
+		case "assistant":
+			if toolUse, err := p.transcripts.ExtractToolUseDetails(message); err == nil {
+				excerpt = "tool: " + toolUse.Name
+				if target := toolTarget(&types.NotificationEventData{Details: toolUse.Input}); target != "" {
+					excerpt += " " + target
+				}
+			} else if text := p.transcripts.ExtractTextContent(message); text != "" {
+				excerpt = "assistant: " + text
+			}
+		case "user":
+			// Tool results are user messages without text; only show what the user typed
+			if text, ok := message.Message.Content.(string); ok && text != "" {
+				excerpt = "user: " + text
+			}
+		}
+
+		if excerpt != "" {
+			excerpts = append(excerpts, "    ↳ "+formatter.Truncate(strings.Join(strings.Fields(excerpt), " "), maxExcerptLength))
+		}
+		return len(excerpts) < p.contextLines
+	})
+	if err != nil {
+		return []string{"    ↳ (transcript unavailable: " + err.Error() + ")"}
+	}
+
+	// Collected newest first; show in conversation order
+	for i, j := 0, len(excerpts)-1; i < j; i, j = i+1, j-1 {
+		excerpts[i], excerpts[j] = excerpts[j], excerpts[i]
+	}
+	return excerpts
+}
+
+// formatEventOutput formats an event for display, including the tool target when extracted data is available
+func formatEventOutput(event types.ClaudeHookEvent, data *types.ExtractedData) string {
+	// Use the event's own time so replayed events show when they happened
+	eventTime := time.Now()
+	if t, err := time.Parse(time.RFC3339, event.Timestamp); err == nil {
+		eventTime = t.Local()
+	}
+	timestamp := eventTime.Format("15:04:05")
+	sessionID := session.Short(event.SessionID)
+
 	toolInfo := ""
 	if event.ToolName != "" {
 		toolInfo = fmt.Sprintf(" | Tool: %s", event.ToolName)
 	}
 
+	if data != nil {
+		switch eventData := data.Data.(type) {
+		case *types.NotificationEventData:
+			if toolInfo == "" {
+				toolInfo = fmt.Sprintf(" | Tool: %s", eventData.ToolName)
+			}
+			if target := toolTarget(eventData); target != "" {
+				toolInfo += fmt.Sprintf(" | %s", target)
+			}
+		case *types.StopEventData:
+			toolInfo += fmt.Sprintf(" | Status: %s", eventData.TaskStatus)
+		}
+	}
+
 	return fmt.Sprintf("[%s] 🎯 %s | Session: %s%s",
 		timestamp, event.HookEventName, sessionID, toolInfo)
 }
 
-// checkForNewEvents checks for and processes new events in the log file
-func checkForNewEvents(logFile string, lastSize *int64, logger *logger.Logger) error {
+// toolTarget returns what a tool acts on (file path, command or URL), shortened to one line
+func toolTarget(data *types.NotificationEventData) string {
+	for _, key := range []string{"target_file", "file_path", "command", "target_url", "url", "path", "pattern"} {
+		if value, exists := data.Details[key]; exists {
+			target := strings.Join(strings.Fields(fmt.Sprintf("%v", value)), " ")
+			return formatter.Truncate(target, 80)
+		}
+	}
+	return ""
+}
+
+// eventColor picks a color by severity: failures red, approvals yellow, completions green
+func eventColor(data *types.ExtractedData) string {
+	if data == nil {
+		return ""
+	}
+
+	switch eventData := data.Data.(type) {
+	case *types.NotificationEventData:
+		return colorYellow
+	case *types.StopEventData:
+		switch eventData.TaskStatus {
+		case "error":
+			return colorRed
+		case "completed":
+			return colorGreen
+		case "cancelled":
+			return colorDim
+		}
+	}
+	return ""
+}
+
+// logPosition tracks how far into the log file events have been read
+type logPosition struct {
+	offset int64
+	file   os.FileInfo // identity of the file the offset belongs to
+}
+
+// checkForNewEvents checks for and prints new events in the log file
+func checkForNewEvents(logFile string, position *logPosition, printer *eventPrinter, source string, logger *logger.Logger) error {
+	events, err := readNewEvents(logFile, position, logger)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		printer.print(event, source)
+	}
+
+	return nil
+}
+
+// readNewEvents returns the events appended to the log file since the last read, advancing position.
+// If the file was truncated or replaced (e.g. by log rotation) reading restarts from the beginning.
+func readNewEvents(logFile string, position *logPosition, logger *logger.Logger) ([]types.ClaudeHookEvent, error) {
 	info, err := os.Stat(logFile)
 	if err != nil {
 		if os.IsNotExist(err) {
 			logger.Debug("Log file does not exist yet: %s", logFile)
-			return nil
+			return nil, nil
 		}
-		return fmt.Errorf("failed to stat log file: %w", err)
+		return nil, fmt.Errorf("failed to stat log file: %w", err)
 	}
 
 	currentSize := info.Size()
-	if currentSize <= *lastSize {
-		return nil
+	if position.file != nil && !os.SameFile(position.file, info) {
+		logger.Info("Log file was replaced, reading from the start: %s", logFile)
+		position.offset = 0
+	} else if currentSize < position.offset {
+		logger.Info("Log file was truncated (%d -> %d bytes), reading from the start: %s", position.offset, currentSize, logFile)
+		position.offset = 0
+	}
+	position.file = info
+
+	if currentSize <= position.offset {
+		return nil, nil
 	}
 
-	logger.Debug("File size changed: %d -> %d", *lastSize, currentSize)
+	logger.Debug("File size changed: %d -> %d", position.offset, currentSize)
 
 	file, err := os.Open(logFile)
 	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
+		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
 	defer file.Close()
 
-	if _, err := file.Seek(*lastSize, 0); err != nil {
-		return fmt.Errorf("failed to seek in log file: %w", err)
+	if _, err := file.Seek(position.offset, 0); err != nil {
+		return nil, fmt.Errorf("failed to seek in log file: %w", err)
 	}
 
+	var events []types.ClaudeHookEvent
 	decoder := json.NewDecoder(file)
 	for decoder.More() {
 		var event types.ClaudeHookEvent
@@ -68,35 +265,134 @@ func checkForNewEvents(logFile string, lastSize *int64, logger *logger.Logger) e
 			continue
 		}
 
-		fmt.Println(formatEventOutput(event))
+		events = append(events, event)
 	}
 
-	*lastSize = currentSize
-	return nil
+	position.offset = currentSize
+	return events, nil
+}
+
+// ParseSince parses a --since value: a duration back from now (e.g. "2h", or "7d" in days),
+// an RFC3339 timestamp, or a local date/time such as "2006-01-02" or "2006-01-02 15:04"
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q (use a duration like 2h or 7d, an RFC3339 timestamp or YYYY-MM-DD [HH:MM])", value)
 }
 
-// Start monitors the log file for new events with graceful shutdown
+// sourceRescanInterval is how often log file globs are re-expanded to pick up new logs
+const sourceRescanInterval = 10 * time.Second
+
+// Start monitors the log files for new events with graceful shutdown
 func Start(ctx context.Context, config types.Config, logger *logger.Logger) error {
-	logger.Info("Starting event monitor (Poll interval: %v)", config.PollInterval)
+	if config.UsePolling {
+		logger.Info("Starting event monitor (Poll interval: %v)", config.PollInterval)
+	} else {
+		logger.Info("Starting event monitor (file system notifications)")
+	}
 
-	var lastSize int64 = 0
-	if info, err := os.Stat(config.LogFile); err == nil {
-		lastSize = info.Size()
-		logger.Debug("Initial file size: %d bytes", lastSize)
+	patterns := config.LogFiles
+	if len(patterns) == 0 {
+		patterns = []string{config.LogFile}
 	}
 
-	ticker := time.NewTicker(config.PollInterval)
-	defer ticker.Stop()
+	printer := newEventPrinter(config)
+	replay := config.FromStart || !config.Since.IsZero()
+	if replay {
+		logger.Info("Replaying existing events before following new ones")
+	}
+
+	// Label lines with their source only when more than one log can be involved
+	labelled := len(patterns) > 1 || hasGlob(patterns)
+
+	watchOptions := filewatch.Options{
+		PollInterval: config.PollInterval,
+		ForcePolling: config.UsePolling,
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	watching := make(map[string]bool)
+
+	// watch starts following a log file, from its current end unless fromStart is set
+	watch := func(logFile string, fromStart bool) {
+		var position logPosition
+		if info, err := os.Stat(logFile); err == nil && !fromStart {
+			position = logPosition{offset: info.Size(), file: info}
+			logger.Debug("Initial size of %s: %d bytes", logFile, position.offset)
+		}
+
+		source := ""
+		if labelled {
+			source = sourceLabel(logFile)
+			logger.Info("Watching %s (%s)", logFile, source)
+		}
+
+		check := func() {
+			// Serialize output so lines from different logs never interleave mid-line
+			mu.Lock()
+			defer mu.Unlock()
+			if err := checkForNewEvents(logFile, &position, printer, source, logger); err != nil {
+				logger.Error("Error checking for events in %s: %v", logFile, err)
+			}
+		}
 
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Info("Monitor stopping...")
-			return ctx.Err()
-		case <-ticker.C:
-			if err := checkForNewEvents(config.LogFile, &lastSize, logger); err != nil {
-				logger.Error("Error checking for events: %v", err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Show anything already pending (everything, when replaying) before waiting for changes
+			check()
+			filewatch.Watch(ctx, logFile, watchOptions, logger, check)
+		}()
+	}
+
+	// Logs that appear after startup are new, so everything in them is shown
+	addSources := func(fromStart bool) {
+		for _, logFile := range expandLogFiles(patterns) {
+			if !watching[logFile] {
+				watching[logFile] = true
+				watch(logFile, fromStart)
+			}
+		}
+	}
+
+	addSources(replay)
+	if len(watching) == 0 {
+		logger.Info("No log files match %s yet, waiting for them to appear", strings.Join(patterns, ", "))
+	}
+
+	if hasGlob(patterns) {
+		ticker := time.NewTicker(sourceRescanInterval)
+		defer ticker.Stop()
+
+	rescan:
+		for {
+			select {
+			case <-ctx.Done():
+				break rescan
+			case <-ticker.C:
+				addSources(true)
 			}
 		}
 	}
-}
\ No newline at end of file
+
+	<-ctx.Done()
+	wg.Wait()
+
+	logger.Info("Monitor stopping...")
+	return ctx.Err()
+}
@@ -0,0 +1,78 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// expandLogFiles resolves log file patterns (plain paths, ~ paths or globs) into file paths.
+// Plain paths are kept even if they don't exist yet so the monitor can wait for them.
+func expandLogFiles(patterns []string) []string {
+	seen := make(map[string]bool)
+	var files []string
+
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, pattern := range patterns {
+		pattern = expandHome(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+
+		if !strings.ContainsAny(pattern, "*?[") {
+			add(pattern)
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			add(match)
+		}
+	}
+
+	return files
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// hasGlob reports whether any pattern could match files created later
+func hasGlob(patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, "*?[") {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceLabel names a log file by its project directory (e.g. ~/projects/api/claude-events.jsonl -> "api")
+func sourceLabel(path string) string {
+	dir := filepath.Base(filepath.Dir(path))
+	if dir == "." || dir == string(filepath.Separator) {
+		if abs, err := filepath.Abs(path); err == nil {
+			dir = filepath.Base(filepath.Dir(abs))
+		}
+	}
+	return dir
+}
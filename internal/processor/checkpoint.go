@@ -0,0 +1,176 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// checkpointFileName is the file (inside the output directory) that tracks batch progress
+const checkpointFileName = ".process-checkpoint.json"
+
+// checkpointInterval is how many events are processed between checkpoint writes
+const checkpointInterval = 50
+
+// Checkpoint records how far a batch run got through an events file
+type Checkpoint struct {
+	EventsFile     string    `json:"events_file"`
+	ProcessedCount int       `json:"processed_count"`
+	TotalEvents    int       `json:"total_events"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ProcessEventsWithCheckpoint processes all events from an events file, saving progress
+// periodically so an interrupted run can be resumed. When resume is true and a checkpoint
+// for the same events file exists, events before the checkpoint are skipped. Events are
+// processed as they are read, so large files are handled in bounded memory.
+func (ep *EventProcessor) ProcessEventsWithCheckpoint(ctx context.Context, eventsFilePath string, resume bool) ([]string, error) {
+	// Count events up front for the checkpoint's progress
+	total, err := ep.countEvents(eventsFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events from file: %w", err)
+	}
+
+	start := 0
+	if resume {
+		checkpoint, err := ep.LoadCheckpoint(eventsFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load checkpoint: %w", err)
+		}
+		if checkpoint != nil && checkpoint.ProcessedCount <= total {
+			start = checkpoint.ProcessedCount
+			fmt.Printf("Resuming from event %d of %d\n", start+1, total)
+		}
+	}
+
+	var outputFiles []string
+	var interrupted error
+	i := 0
+	err = ep.scanEvents(eventsFilePath, func(event *types.ClaudeHookEvent) bool {
+		if i < start {
+			i++
+			return true
+		}
+
+		// Stop on interruption, recording exactly where we got to
+		select {
+		case <-ctx.Done():
+			if err := ep.saveCheckpoint(eventsFilePath, i, total); err != nil {
+				fmt.Printf("Warning: Failed to save checkpoint: %v\n", err)
+			}
+			interrupted = ctx.Err()
+			return false
+		default:
+		}
+
+		outputFile, err := ep.ProcessEventAndSave(event)
+		if err != nil {
+			fmt.Printf("Warning: Failed to process event %d: %v\n", i+1, err)
+			ep.quarantineEvent(event, err)
+		} else if outputFile != "" {
+			outputFiles = append(outputFiles, outputFile)
+		}
+
+		i++
+		if i%checkpointInterval == 0 {
+			if err := ep.saveCheckpoint(eventsFilePath, i, total); err != nil {
+				fmt.Printf("Warning: Failed to save checkpoint: %v\n", err)
+			}
+		}
+		return true
+	})
+	if interrupted != nil {
+		return outputFiles, interrupted
+	}
+	if err != nil {
+		return outputFiles, fmt.Errorf("failed to read events from file: %w", err)
+	}
+
+	// Run completed, nothing left to resume
+	if err := ep.ClearCheckpoint(); err != nil {
+		fmt.Printf("Warning: Failed to remove checkpoint: %v\n", err)
+	}
+
+	return outputFiles, nil
+}
+
+// LoadCheckpoint returns the saved checkpoint for an events file, or nil if there is none
+func (ep *EventProcessor) LoadCheckpoint(eventsFilePath string) (*Checkpoint, error) {
+	data, err := os.ReadFile(ep.checkpointPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+
+	// A checkpoint for a different events file does not apply
+	if !ep.sameFile(checkpoint.EventsFile, eventsFilePath) {
+		return nil, nil
+	}
+
+	return &checkpoint, nil
+}
+
+// ClearCheckpoint removes any saved checkpoint
+func (ep *EventProcessor) ClearCheckpoint() error {
+	if err := os.Remove(ep.checkpointPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// saveCheckpoint writes the current batch progress to the checkpoint file
+func (ep *EventProcessor) saveCheckpoint(eventsFilePath string, processed, total int) error {
+	if err := ep.ensureDirectoryExists(ep.outputDir); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	absPath, err := filepath.Abs(eventsFilePath)
+	if err != nil {
+		absPath = eventsFilePath
+	}
+
+	checkpoint := Checkpoint{
+		EventsFile:     absPath,
+		ProcessedCount: processed,
+		TotalEvents:    total,
+		UpdatedAt:      time.Now(),
+	}
+
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(ep.checkpointPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// checkpointPath returns the location of the checkpoint file
+func (ep *EventProcessor) checkpointPath() string {
+	return filepath.Join(ep.outputDir, checkpointFileName)
+}
+
+// sameFile reports whether two paths refer to the same events file
+func (ep *EventProcessor) sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return absA == absB
+}
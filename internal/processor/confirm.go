@@ -0,0 +1,50 @@
+package processor
+
+import (
+	"fmt"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/risk"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// requireConfirmation gives a dangerous request a one-time confirmation code that must be
+// supplied to approve it, and says so in the message. Chat buttons and other one-tap
+// approvals are refused for these requests. The code is kept out of the message: it goes
+// out with the notification, or is printed when no notifications are sent, and the output
+// directory only holds its HMAC.
+func (ep *EventProcessor) requireConfirmation(message *types.MessengerMessage) error {
+	if message.Type != "action_needed" {
+		return nil
+	}
+	reason := risk.Assess(message.Context)
+	if reason == "" {
+		return nil
+	}
+
+	code, err := risk.NewCode()
+	if err != nil {
+		return fmt.Errorf("failed to create confirmation code: %w", err)
+	}
+	if err := risk.NewCodes(ep.outputDir).Add(message.SessionID, message.MessageID, code); err != nil {
+		return err
+	}
+	message.Context["risk"] = reason
+	message.ConfirmationCode = code
+
+	notice := fmt.Sprintf("\n\n🔐 High risk: %s. Approving takes the one-time confirmation code sent with the notification:\nclaudetogo --respond --session %s --action approve --code CODE",
+		reason, message.SessionID)
+	if ep.emojis {
+		message.Title = "⚠️ " + message.Title
+	} else {
+		notice = formatter.StripEmojis(notice)
+	}
+	message.Message += notice
+	for i, action := range message.Actions {
+		if action.Type == "approve" {
+			message.Actions[i].Command += " --code CODE"
+			message.Actions[i].Description += " (requires the confirmation code)"
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package processor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// targetKeys are the message context fields naming what a tool call acts on
+var targetKeys = []string{"target_file", "target_path", "target_url", "command"}
+
+// debouncer collapses repeats of an event, such as the Notification Claude Code sends again
+// when it retries a tool, into the first one
+type debouncer struct {
+	windows map[string]time.Duration // Debounce window by hook event name
+	last    map[string]time.Time     // When each event key was last let through
+}
+
+// SetDebounce sets, by hook event name, how long after an event repeats of it are dropped.
+// A repeat is an event of the same type, session, tool and target.
+func (ep *EventProcessor) SetDebounce(windows map[string]time.Duration) {
+	ep.debounce.windows = windows
+}
+
+// debounced reports whether an event repeats one let through within its type's window,
+// remembering it otherwise
+func (d *debouncer) debounced(event *types.ClaudeHookEvent, message *types.MessengerMessage) bool {
+	window := d.windows[event.HookEventName]
+	if window <= 0 {
+		return false
+	}
+
+	// Event times are used, so replaying a log debounces it as it was when written
+	at, err := time.Parse(time.RFC3339Nano, event.Timestamp)
+	if err != nil {
+		at = time.Now()
+	}
+	if d.last == nil {
+		d.last = make(map[string]time.Time)
+	}
+	for key, seen := range d.last {
+		if at.Sub(seen) > d.longestWindow() {
+			delete(d.last, key)
+		}
+	}
+
+	key := eventKey(event, message)
+	if seen, ok := d.last[key]; ok && at.Sub(seen) >= 0 && at.Sub(seen) < window {
+		return true
+	}
+	d.last[key] = at
+	return false
+}
+
+// longestWindow returns the longest debounce window, beyond which no event is a repeat
+func (d *debouncer) longestWindow() time.Duration {
+	var longest time.Duration
+	for _, window := range d.windows {
+		if window > longest {
+			longest = window
+		}
+	}
+	return longest
+}
+
+// eventKey identifies what an event is about: its type, session, tool and target
+func eventKey(event *types.ClaudeHookEvent, message *types.MessengerMessage) string {
+	target := ""
+	for _, key := range targetKeys {
+		if value, ok := message.Context[key]; ok {
+			target = fmt.Sprint(value)
+			break
+		}
+	}
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s", event.HookEventName, event.SessionID, eventToolName(event), target)
+}
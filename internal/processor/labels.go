@@ -0,0 +1,52 @@
+package processor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// labelSession records a short label, and any tags and note, for the message's session in
+// its context and, while another session of the same project is running, adds the label to
+// the title so approvers can tell the sessions' requests apart
+func (ep *EventProcessor) labelSession(message *types.MessengerMessage) {
+	at, err := time.Parse(time.RFC3339Nano, message.Timestamp)
+	if err != nil {
+		at = time.Now()
+	}
+
+	registry := session.NewRegistry(ep.outputDir)
+	record, err := registry.Get(message.SessionID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to read session registry: %v\n", err)
+		return
+	}
+	if record == nil {
+		// The first event of a session starts it
+		record = &session.Record{ID: message.SessionID, FirstSeen: at}
+	}
+	if branch, _ := message.Context["git_branch"].(string); branch != "" && record.Branch == "" {
+		record.Branch = branch
+	}
+	label := record.Label()
+	message.Context["session_label"] = label
+	if len(record.Tags) > 0 {
+		message.Context["session_tags"] = record.Tags
+	}
+	if note := record.LastNote(); note != "" {
+		message.Context["session_note"] = note
+	}
+
+	project, _ := message.Context["project"].(string)
+	concurrent, err := registry.Concurrent(message.SessionID, project, at)
+	if err != nil {
+		fmt.Printf("Warning: Failed to read session registry: %v\n", err)
+		return
+	}
+	if concurrent {
+		message.Title = formatter.SessionTitle(message.Title, project, label)
+	}
+}
@@ -0,0 +1,201 @@
+package processor
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// ledgerFileName is the file (inside the output directory) listing the identities of the
+// events watch mode has handled
+const ledgerFileName = ".processed-events"
+
+// ledgerLimit is how many identities the ledger keeps once it is compacted
+const ledgerLimit = 10000
+
+// ledger records which events have been handled, so an events file that is rewritten or
+// compacted doesn't get its events processed twice
+type ledger struct {
+	path  string
+	ids   map[string]bool
+	order []string // Identities in the order they were recorded, as in the file
+}
+
+// eventIdentity identifies a logged event by a hash of its decoded fields, so the same
+// event keeps its identity when the file it is in is rewritten with different formatting
+func eventIdentity(event *types.ClaudeHookEvent) string {
+	data, err := json.Marshal(event)
+	if err != nil {
+		data = []byte(event.SessionID + "\x00" + event.HookEventName + "\x00" + event.Timestamp + "\x00" + event.Message)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// processedLedger returns the ledger of the output directory, loading it on first use
+func (ep *EventProcessor) processedLedger() (*ledger, error) {
+	path := filepath.Join(ep.outputDir, ledgerFileName)
+	if ep.ledger != nil && ep.ledger.path == path {
+		return ep.ledger, nil
+	}
+
+	l := &ledger{path: path, ids: make(map[string]bool)}
+	file, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to open processed events ledger: %w", err)
+	}
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if id := strings.TrimSpace(scanner.Text()); id != "" && !l.ids[id] {
+				l.ids[id] = true
+				l.order = append(l.order, id)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read processed events ledger: %w", err)
+		}
+	}
+	ep.ledger = l
+	return l, nil
+}
+
+// add records identities not yet in the ledger, compacting the file once it holds twice
+// the limit
+func (l *ledger) add(ids []string) error {
+	var added []string
+	for _, id := range ids {
+		if !l.ids[id] {
+			l.ids[id] = true
+			l.order = append(l.order, id)
+			added = append(added, id)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	if len(l.order) > 2*ledgerLimit {
+		return l.compact()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open processed events ledger: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(strings.Join(added, "\n") + "\n"); err != nil {
+		return fmt.Errorf("failed to write processed events ledger: %w", err)
+	}
+	return nil
+}
+
+// compact keeps only the newest ledgerLimit identities
+func (l *ledger) compact() error {
+	dropped := l.order[:len(l.order)-ledgerLimit]
+	for _, id := range dropped {
+		delete(l.ids, id)
+	}
+	l.order = append([]string(nil), l.order[len(dropped):]...)
+
+	if err := atomicfile.WriteFile(l.path, []byte(strings.Join(l.order, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write processed events ledger: %w", err)
+	}
+	return nil
+}
+
+// ProcessNewEvents processes the events not already in the processed ledger and records
+// them there, so each event is handled exactly once however often the events file is read
+// again. Failed events are quarantined, and recorded too. It returns the files written.
+func (ep *EventProcessor) ProcessNewEvents(events []types.ClaudeHookEvent) []string {
+	l, err := ep.processedLedger()
+	if err != nil {
+		fmt.Printf("Warning: %v, processing events without it\n", err)
+		return ep.ProcessEvents(events)
+	}
+
+	var outputFiles []string
+	for i := range events {
+		if outputFile := ep.processNewEvent(l, &events[i], i+1); outputFile != "" {
+			outputFiles = append(outputFiles, outputFile)
+		}
+	}
+	return outputFiles
+}
+
+// processNewEvent processes one event unless the ledger already has it, returning the file
+// written
+func (ep *EventProcessor) processNewEvent(l *ledger, event *types.ClaudeHookEvent, n int) string {
+	id := eventIdentity(event)
+	if l.ids[id] {
+		return ""
+	}
+	outputFile, procErr := ep.ProcessEventAndSave(event)
+	if procErr != nil {
+		fmt.Printf("Warning: Failed to process event %d: %v\n", n, procErr)
+		ep.quarantineEvent(event, procErr)
+	}
+	if err := l.add([]string{id}); err != nil {
+		fmt.Printf("Warning: Failed to record processed event: %v\n", err)
+	}
+	return outputFile
+}
+
+// seedBatchSize is how many identities are recorded at a time when seeding the ledger
+const seedBatchSize = 1000
+
+// CatchUp brings watch mode up to date with its events file when it starts. The first time,
+// with no processed ledger yet, the events already in the file are recorded as processed
+// without notifying. After that, the events logged since the position saved when watch mode
+// last ran (the whole file when there is none) go through the ledger, so those logged while
+// it was stopped are handled once. Events are streamed, so a large file takes bounded memory.
+// It returns how many events were read and the files written.
+func (ep *EventProcessor) CatchUp(tail *Tail) (int, []string, error) {
+	_, statErr := os.Stat(filepath.Join(ep.outputDir, ledgerFileName))
+	seed := os.IsNotExist(statErr)
+	l, err := ep.processedLedger()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	count := 0
+	var outputFiles, ids []string
+	var addErr error
+	if seed {
+		_, err = ep.ScanTail(tail, func(event *types.ClaudeHookEvent) {
+			count++
+			if ids = append(ids, eventIdentity(event)); len(ids) == seedBatchSize {
+				addErr = errors.Join(addErr, l.add(ids))
+				ids = ids[:0]
+			}
+		})
+		addErr = errors.Join(addErr, l.add(ids))
+	} else {
+		ep.resumeTail(tail)
+		_, err = ep.ScanTail(tail, func(event *types.ClaudeHookEvent) {
+			count++
+			if outputFile := ep.processNewEvent(l, event, count); outputFile != "" {
+				outputFiles = append(outputFiles, outputFile)
+			}
+		})
+	}
+	if err != nil {
+		return count, outputFiles, err
+	}
+	if addErr != nil {
+		return count, outputFiles, fmt.Errorf("failed to record existing events as processed: %w", addErr)
+	}
+	return count, outputFiles, ep.SaveTail(tail)
+}
@@ -1,7 +1,6 @@
 package processor
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,18 +8,39 @@ import (
 	"strings"
 	"time"
 
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/extractor"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/layout"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/redact"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
 )
 
 // EventProcessor handles the complete pipeline from Claude events to messenger JSON files
 type EventProcessor struct {
-	extractor *extractor.DataExtractor
-	formatter *formatter.MessengerFormatter
-	outputDir string
+	extractor  *extractor.DataExtractor
+	formatter  *formatter.MessengerFormatter
+	outputDir  string
+	fileFormat string
+	rotateSize int64
+	threads    map[string]threadState
+	lastSaved  *types.ClaudeHookEvent // most recent event saved successfully, for status reporting
+	onSaved    func(*types.MessengerMessage)
+	onTimed    func(*types.ClaudeHookEvent, StageTimings)
+	timings    StageTimings   // stages of the event being processed
+	emojis     bool           // include emojis in text added after formatting
+	layout     *layout.Layout // subdirectories messages are written to in "json" mode
+	ledger     *ledger        // identities of events already handled in watch mode
+	debounce   debouncer      // drops repeats of noisy events
+
+	newlyQuarantined []QuarantinedEvent // failures not yet taken for status reporting
+	quarantineCount  quarantineCount    // entries in the quarantine file, by its size and time
 }
 
+// JSONLFileName is the single file messages are appended to in "jsonl" mode
+const JSONLFileName = "messages.jsonl"
+
 // NewEventProcessor creates a new event processor
 func NewEventProcessor(outputDir string) *EventProcessor {
 	// Default output directory if not specified
@@ -29,110 +49,159 @@ func NewEventProcessor(outputDir string) *EventProcessor {
 	}
 
 	return &EventProcessor{
-		extractor: extractor.NewDataExtractor(),
-		formatter: formatter.NewMessengerFormatter(),
-		outputDir: outputDir,
+		extractor:  extractor.NewDataExtractor(),
+		formatter:  formatter.NewMessengerFormatter(),
+		outputDir:  outputDir,
+		fileFormat: "json",
+		emojis:     true,
 	}
 }
 
 // ProcessEvent processes a single Claude hook event and generates a messenger JSON file
 func (ep *EventProcessor) ProcessEvent(event *types.ClaudeHookEvent) (*types.MessengerMessage, error) {
+	ep.timings = StageTimings{}
+
 	// Extract data from the event
+	started, readBefore := time.Now(), ep.extractor.TranscriptReadTime()
 	extractedData, err := ep.extractor.ProcessEvent(event)
+	ep.timings.TranscriptRead = ep.extractor.TranscriptReadTime() - readBefore
+	ep.timings.Extraction = time.Since(started) - ep.timings.TranscriptRead
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract data from event: %w", err)
 	}
 
 	// Format for messenger
+	started = time.Now()
 	messengerMessage, err := ep.formatter.CreateActionableMessage(extractedData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to format message for messenger: %w", err)
 	}
 
+	// Group messages from the same session into one thread
+	ep.assignThread(messengerMessage)
+	// Concurrent sessions of a project are labelled so their requests aren't mixed up
+	ep.labelSession(messengerMessage)
+	// Dangerous requests can only be approved with a one-time code; checked before secrets are masked
+	if err := ep.requireConfirmation(messengerMessage); err != nil {
+		return nil, err
+	}
+	// Mask secrets from tool inputs and the transcript before the message is stored or sent
+	redact.Value(messengerMessage)
+	ep.timings.Formatting = time.Since(started)
+
 	return messengerMessage, nil
 }
 
-// ProcessEventAndSave processes an event and saves the result to a JSON file
+// ProcessEventAndSave processes an event and saves the result to a JSON file. SessionStart
+// and SessionEnd events only mark the session's lifetime in the registry and return no file,
+// as do repeats of an event within its debounce window.
 func (ep *EventProcessor) ProcessEventAndSave(event *types.ClaudeHookEvent) (string, error) {
+	if isLifecycleEvent(event) {
+		return "", ep.recordLifecycle(event)
+	}
+
 	// Process the event
 	messengerMessage, err := ep.ProcessEvent(event)
 	if err != nil {
 		return "", err
 	}
 
-	// Generate filename
+	// A retried tool call would otherwise ask for the same approval twice
+	if ep.debounce.debounced(event, messengerMessage) {
+		return "", nil
+	}
+
+	// Store attachments next to the messages before the message references them
+	writeStarted := time.Now()
+	if err := ep.saveAttachments(messengerMessage); err != nil {
+		return "", fmt.Errorf("failed to save attachments: %w", err)
+	}
+
+	// In jsonl mode all messages go to a single append-only file
+	if ep.fileFormat == "jsonl" {
+		jsonlPath := filepath.Join(ep.outputDir, JSONLFileName)
+		if err := ep.appendMessageToJSONL(messengerMessage, jsonlPath); err != nil {
+			return "", fmt.Errorf("failed to append message to jsonl file: %w", err)
+		}
+		ep.timings.Write = time.Since(writeStarted)
+		ep.recordSaved(event, messengerMessage)
+		return jsonlPath, nil
+	}
+
+	// Generate filename, in the layout's subdirectory
+	dir, err := ep.messageDir(event, messengerMessage)
+	if err != nil {
+		return "", err
+	}
 	filename := ep.generateFileName(event)
-	filepath := filepath.Join(ep.outputDir, filename)
+	filepath := filepath.Join(ep.outputDir, dir, filename)
 
 	// Save to file
 	err = ep.saveMessageToFile(messengerMessage, filepath)
 	if err != nil {
 		return "", fmt.Errorf("failed to save message to file: %w", err)
 	}
+	ep.timings.Write = time.Since(writeStarted)
 
+	ep.recordSaved(event, messengerMessage)
 	return filepath, nil
 }
 
-// ProcessEventsFromFile processes all events from a claude-events.jsonl file
+// ProcessEventsFromFile processes all events from a claude-events.jsonl file, one at a time
+// as they are read, so the whole file is never held in memory
 func (ep *EventProcessor) ProcessEventsFromFile(eventsFilePath string) ([]string, error) {
-	// Read events from file
-	events, err := ep.readEventsFromFile(eventsFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read events from file: %w", err)
-	}
-
 	var outputFiles []string
-
-	// Process each event
-	for i, event := range events {
-		outputFile, err := ep.ProcessEventAndSave(&event)
+	i := 0
+	err := ep.scanEvents(eventsFilePath, func(event *types.ClaudeHookEvent) bool {
+		i++
+		outputFile, err := ep.ProcessEventAndSave(event)
 		if err != nil {
-			fmt.Printf("Warning: Failed to process event %d: %v\n", i+1, err)
-			continue
+			fmt.Printf("Warning: Failed to process event %d: %v\n", i, err)
+			ep.quarantineEvent(event, err)
+			return true
+		}
+		if outputFile != "" {
+			outputFiles = append(outputFiles, outputFile)
 		}
-		outputFiles = append(outputFiles, outputFile)
+		return true
+	})
+	if err != nil {
+		return outputFiles, fmt.Errorf("failed to read events from file: %w", err)
 	}
 
 	return outputFiles, nil
 }
 
-// ProcessLatestEvents processes only the most recent events (useful for monitoring)
+// ProcessLatestEvents processes only the most recent events (useful for monitoring). The
+// events file is read from the end, so only those events are parsed.
 func (ep *EventProcessor) ProcessLatestEvents(eventsFilePath string, maxEvents int) ([]string, error) {
-	// Read all events
-	events, err := ep.readEventsFromFile(eventsFilePath)
+	events, err := ep.latestEvents(eventsFilePath, maxEvents)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read events from file: %w", err)
 	}
+	return ep.ProcessEvents(events), nil
+}
 
-	// Take only the latest events
-	start := 0
-	if len(events) > maxEvents {
-		start = len(events) - maxEvents
-	}
-	latestEvents := events[start:]
-
+// ProcessEvents processes and saves events in order, quarantining the ones that fail, and
+// returns the files written
+func (ep *EventProcessor) ProcessEvents(events []types.ClaudeHookEvent) []string {
 	var outputFiles []string
-
-	// Process each latest event
-	for i, event := range latestEvents {
+	for i, event := range events {
 		outputFile, err := ep.ProcessEventAndSave(&event)
 		if err != nil {
 			fmt.Printf("Warning: Failed to process latest event %d: %v\n", i+1, err)
+			ep.quarantineEvent(&event, err)
 			continue
 		}
-		outputFiles = append(outputFiles, outputFile)
+		if outputFile != "" {
+			outputFiles = append(outputFiles, outputFile)
+		}
 	}
-
-	return outputFiles, nil
+	return outputFiles
 }
 
 // GenerateTestData creates sample JSON files using real event data
 func (ep *EventProcessor) GenerateTestData(eventsFilePath string) error {
-	events, err := ep.readEventsFromFile(eventsFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to read events: %w", err)
-	}
-
 	// Create test output directory
 	testDir := filepath.Join(ep.outputDir, "test-samples")
 	if err := ep.ensureDirectoryExists(testDir); err != nil {
@@ -142,31 +211,33 @@ func (ep *EventProcessor) GenerateTestData(eventsFilePath string) error {
 	// Process a few sample events of different types
 	var stopEventProcessed, notificationEventProcessed bool
 	
-	for i, event := range events {
-		// Skip if we've already processed both types
+	i := -1
+	err := ep.scanEvents(eventsFilePath, func(event *types.ClaudeHookEvent) bool {
+		i++
+		// Stop once both types are processed
 		if stopEventProcessed && notificationEventProcessed {
-			break
+			return false
 		}
 
 		// Skip if this event type is already processed
 		if event.HookEventName == "Stop" && stopEventProcessed {
-			continue
+			return true
 		}
 		if event.HookEventName == "Notification" && notificationEventProcessed {
-			continue
+			return true
 		}
 
 		// Check if transcript file exists
 		if !ep.fileExists(event.TranscriptPath) {
 			fmt.Printf("Skipping event %d: transcript file not found: %s\n", i+1, event.TranscriptPath)
-			continue
+			return true
 		}
 
 		// Process the event
-		messengerMessage, err := ep.ProcessEvent(&event)
+		messengerMessage, err := ep.ProcessEvent(event)
 		if err != nil {
 			fmt.Printf("Warning: Failed to process test event %d: %v\n", i+1, err)
-			continue
+			return true
 		}
 
 		// Generate test filename
@@ -178,7 +249,7 @@ func (ep *EventProcessor) GenerateTestData(eventsFilePath string) error {
 		err = ep.saveMessageToFile(messengerMessage, filepath)
 		if err != nil {
 			fmt.Printf("Warning: Failed to save test sample %s: %v\n", filename, err)
-			continue
+			return true
 		}
 
 		// Mark as processed
@@ -189,49 +260,56 @@ func (ep *EventProcessor) GenerateTestData(eventsFilePath string) error {
 		}
 
 		fmt.Printf("Created test sample: %s\n", filepath)
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
 	}
 
 	return nil
 }
 
-// readEventsFromFile reads claude hook events from a JSONL file
-func (ep *EventProcessor) readEventsFromFile(filePath string) ([]types.ClaudeHookEvent, error) {
-	if !ep.fileExists(filePath) {
-		return nil, fmt.Errorf("events file does not exist: %s", filePath)
-	}
+// Events reads every event in an events file
+func (ep *EventProcessor) Events(eventsFilePath string) ([]types.ClaudeHookEvent, error) {
+	return ep.readEventsFromFile(eventsFilePath)
+}
 
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open events file: %w", err)
+// SessionEvents returns the events of one session in an events file, oldest first. A prefix
+// of the session ID is enough.
+func (ep *EventProcessor) SessionEvents(eventsFilePath, sessionID string) ([]types.ClaudeHookEvent, error) {
+	var sessionEvents []types.ClaudeHookEvent
+	if sessionID == "" {
+		return nil, nil
 	}
-	defer file.Close()
-
-	var events []types.ClaudeHookEvent
-	scanner := bufio.NewScanner(file)
-
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-		
-		// Skip empty lines
-		if line == "" {
-			continue
+	// Only the events of the session are decoded in full
+	err := ep.scanEventFields(eventsFilePath, []string{"session_id"}, func(fields []string, line []byte) bool {
+		if !strings.HasPrefix(fields[0], sessionID) {
+			return true
 		}
-
 		var event types.ClaudeHookEvent
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			fmt.Printf("Warning: Failed to parse line %d in events file: %v\n", lineNum, err)
-			continue
+		if err := json.Unmarshal(line, &event); err != nil {
+			fmt.Printf("Warning: Failed to parse event of session %s: %v\n", fields[0], err)
+			return true
 		}
-
-		events = append(events, event)
+		sessionEvents = append(sessionEvents, event)
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
+	return sessionEvents, nil
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading events file: %w", err)
+// readEventsFromFile reads claude hook events from a JSONL file
+func (ep *EventProcessor) readEventsFromFile(filePath string) ([]types.ClaudeHookEvent, error) {
+	var events []types.ClaudeHookEvent
+	err := ep.scanEvents(filePath, func(event *types.ClaudeHookEvent) bool {
+		events = append(events, *event)
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
-
 	return events, nil
 }
 
@@ -250,7 +328,7 @@ func (ep *EventProcessor) saveMessageToFile(message *types.MessengerMessage, fil
 	}
 
 	// Write to file
-	err = os.WriteFile(filePath, jsonData, 0644)
+	err = atomicfile.WriteFile(filePath, jsonData, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write JSON file: %w", err)
 	}
@@ -258,6 +336,107 @@ func (ep *EventProcessor) saveMessageToFile(message *types.MessengerMessage, fil
 	return nil
 }
 
+// saveAttachments writes a message's attachments to <output-dir>/attachments/<message-id>/
+// and records where each one was stored
+func (ep *EventProcessor) saveAttachments(message *types.MessengerMessage) error {
+	if len(message.Attachments) == 0 {
+		return nil
+	}
+
+	dir := filepath.Join(ep.outputDir, "attachments", message.MessageID)
+	if err := ep.ensureDirectoryExists(dir); err != nil {
+		return fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+
+	for i := range message.Attachments {
+		attachment := &message.Attachments[i]
+		path := filepath.Join(dir, filepath.Base(attachment.Name))
+		if err := atomicfile.WriteFile(path, []byte(attachment.Content), 0644); err != nil {
+			return fmt.Errorf("failed to write attachment %s: %w", attachment.Name, err)
+		}
+		attachment.Path = path
+	}
+
+	return nil
+}
+
+// appendMessageToJSONL appends a messenger message as a single line to a JSONL file,
+// rotating the file first if the append would push it past the configured size
+func (ep *EventProcessor) appendMessageToJSONL(message *types.MessengerMessage, filePath string) error {
+	// Ensure output directory exists
+	dir := filepath.Dir(filePath)
+	if err := ep.ensureDirectoryExists(dir); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Marshal to compact JSON (one message per line)
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message to JSON: %w", err)
+	}
+	jsonData = append(jsonData, '\n')
+
+	if err := ep.rotateJSONLIfNeeded(filePath, int64(len(jsonData))); err != nil {
+		return fmt.Errorf("failed to rotate jsonl file: %w", err)
+	}
+
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open jsonl file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(jsonData); err != nil {
+		return fmt.Errorf("failed to write jsonl file: %w", err)
+	}
+
+	return nil
+}
+
+// rotateJSONLIfNeeded renames the JSONL file to a timestamped name when appending
+// pending bytes would exceed the rotation size (0 disables rotation)
+func (ep *EventProcessor) rotateJSONLIfNeeded(filePath string, pending int64) error {
+	if ep.rotateSize <= 0 {
+		return nil
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Never rotate an empty file, even if a single message is larger than the limit
+	if info.Size() == 0 || info.Size()+pending <= ep.rotateSize {
+		return nil
+	}
+
+	ext := filepath.Ext(filePath)
+	base := strings.TrimSuffix(filePath, ext)
+	rotatedPath := fmt.Sprintf("%s-%s%s", base, time.Now().Format("2006-01-02T15-04-05.000"), ext)
+
+	return os.Rename(filePath, rotatedPath)
+}
+
+// messageDir returns the subdirectory of the output directory a message is written to
+func (ep *EventProcessor) messageDir(event *types.ClaudeHookEvent, message *types.MessengerMessage) (string, error) {
+	date := time.Now().Format("2006-01-02")
+	if t, err := time.Parse(time.RFC3339Nano, event.Timestamp); err == nil {
+		date = t.Format("2006-01-02")
+	}
+	project, _ := message.Context["project"].(string)
+
+	return ep.layout.Dir(layout.Fields{
+		Project:   project,
+		Session:   session.Short(event.SessionID),
+		SessionID: event.SessionID,
+		Event:     strings.ToLower(event.HookEventName),
+		Date:      date,
+	})
+}
+
 // generateFileName creates a filename for a messenger JSON file
 func (ep *EventProcessor) generateFileName(event *types.ClaudeHookEvent) string {
 	// Use current time if timestamp is empty
@@ -270,12 +449,8 @@ func (ep *EventProcessor) generateFileName(event *types.ClaudeHookEvent) string
 	}
 
 	eventType := strings.ToLower(event.HookEventName)
-	sessionShort := event.SessionID
-	if len(sessionShort) > 8 {
-		sessionShort = sessionShort[:8]
-	}
 
-	return fmt.Sprintf("messenger-%s-%s-%s.json", eventType, sessionShort, timestamp)
+	return fmt.Sprintf("messenger-%s-%s-%s.json", eventType, session.FileName(event.SessionID), timestamp)
 }
 
 // ensureDirectoryExists creates a directory if it doesn't exist
@@ -300,24 +475,118 @@ func (ep *EventProcessor) GetOutputDirectory() string {
 // SetOutputDirectory changes the output directory
 func (ep *EventProcessor) SetOutputDirectory(dir string) {
 	ep.outputDir = dir
+	ep.threads = nil
 }
 
-// GetProcessingStats returns statistics about processed events
-func (ep *EventProcessor) GetProcessingStats(eventsFilePath string) (*ProcessingStats, error) {
-	events, err := ep.readEventsFromFile(eventsFilePath)
+// SetFileFormat sets the output format ("json" for one file per message, "jsonl" for
+// a single appended file) and the size in bytes at which the jsonl file is rotated
+func (ep *EventProcessor) SetFileFormat(format string, rotateSize int64) {
+	if format == "" {
+		format = "json"
+	}
+	ep.fileFormat = format
+	ep.rotateSize = rotateSize
+}
+
+// SetLayout sets the template of the subdirectories messages are written to, e.g.
+// "{{.Project}}/{{.Session}}"; an empty layout writes them all to the output directory
+func (ep *EventProcessor) SetLayout(text string) error {
+	l, err := layout.Parse(text)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	ep.layout = l
+	return nil
+}
+
+// SetFormatterOptions changes how messages are formatted (templates, emojis, length limits)
+func (ep *EventProcessor) SetFormatterOptions(options formatter.Options) {
+	ep.formatter.SetOptions(options)
+	ep.emojis = options.IncludeEmojis
+	ep.extractor.SetMaxContentPreview(options.MaxContentPreview)
+	ep.extractor.SetContextMessages(options.ContextMessages)
+}
+
+// SetMessageHandler sets a function called with every message after it has been saved
+func (ep *EventProcessor) SetMessageHandler(handler func(*types.MessengerMessage)) {
+	ep.onSaved = handler
+}
+
+// recordSaved remembers a copy of the most recently saved event, registers it with its
+// session, reports its stage timings and passes its message on
+func (ep *EventProcessor) recordSaved(event *types.ClaudeHookEvent, message *types.MessengerMessage) {
+	saved := *event
+	ep.lastSaved = &saved
+	if err := session.NewRegistry(ep.outputDir).RecordMessage(message); err != nil {
+		fmt.Printf("Warning: Failed to update session registry: %v\n", err)
 	}
+	if ep.onTimed != nil {
+		ep.onTimed(&saved, ep.timings)
+	}
+	if ep.onSaved != nil {
+		ep.onSaved(message)
+	} else if message.ConfirmationCode != "" {
+		// Without notifications the code is only shown here
+		fmt.Printf("Confirmation code for high-risk request in session %s: %s\n", message.SessionID, message.ConfirmationCode)
+	}
+}
 
+// isLifecycleEvent reports whether an event marks the start or end of a session
+func isLifecycleEvent(event *types.ClaudeHookEvent) bool {
+	return event.HookEventName == "SessionStart" || event.HookEventName == "SessionEnd"
+}
+
+// recordLifecycle registers a SessionStart or SessionEnd event with the session registry
+func (ep *EventProcessor) recordLifecycle(event *types.ClaudeHookEvent) error {
+	at, err := time.Parse(time.RFC3339Nano, event.Timestamp)
+	if err != nil {
+		return fmt.Errorf("invalid %s timestamp %q: %w", event.HookEventName, event.Timestamp, err)
+	}
+	if err := session.NewRegistry(ep.outputDir).RecordLifecycle(event.SessionID, event.HookEventName, event.CWD, at); err != nil {
+		return fmt.Errorf("failed to update session registry: %w", err)
+	}
+	if event.HookEventName == "SessionEnd" {
+		ep.endThread(event.SessionID)
+	}
+	return nil
+}
+
+// LastSavedEvent returns the most recent event that was processed and saved, or nil
+func (ep *EventProcessor) LastSavedEvent() *types.ClaudeHookEvent {
+	return ep.lastSaved
+}
+
+// GetFileFormat returns the configured output format
+func (ep *EventProcessor) GetFileFormat() string {
+	return ep.fileFormat
+}
+
+// GetProcessingStats returns statistics about processed events
+func (ep *EventProcessor) GetProcessingStats(eventsFilePath string) (*ProcessingStats, error) {
 	stats := &ProcessingStats{
-		TotalEvents:         len(events),
+		TotalEvents:         0,
 		StopEvents:          0,
 		NotificationEvents:  0,
 		MissingTranscripts:  0,
 		ProcessableEvents:   0,
+		EventsByTool:        make(map[string]int),
+		EventsBySession:     make(map[string]int),
+		EventsByDay:         make(map[string]int),
+		TaskStatuses:        make(map[string]int),
 	}
 
-	for _, event := range events {
+	// Only the fields counted are decoded; stop events are decoded in full for their status
+	keys := []string{"hook_event_name", "session_id", "timestamp", "tool_name", "message", "transcript_path"}
+	err := ep.scanEventFields(eventsFilePath, keys, func(fields []string, line []byte) bool {
+		event := &types.ClaudeHookEvent{
+			HookEventName:  fields[0],
+			SessionID:      fields[1],
+			Timestamp:      fields[2],
+			ToolName:       fields[3],
+			Message:        fields[4],
+			TranscriptPath: fields[5],
+		}
+		stats.TotalEvents++
 		switch event.HookEventName {
 		case "Stop":
 			stats.StopEvents++
@@ -325,21 +594,83 @@ func (ep *EventProcessor) GetProcessingStats(eventsFilePath string) (*Processing
 			stats.NotificationEvents++
 		}
 
+		stats.EventsBySession[event.SessionID]++
+		stats.EventsByDay[eventDay(event.Timestamp)]++
+		if toolName := eventToolName(event); toolName != "" {
+			stats.EventsByTool[toolName]++
+		}
+
 		if ep.fileExists(event.TranscriptPath) {
 			stats.ProcessableEvents++
 		} else {
 			stats.MissingTranscripts++
+			return true
+		}
+
+		// Task status is only known once the stop event's transcript is analysed
+		if event.HookEventName == "Stop" {
+			if err := json.Unmarshal(line, event); err != nil {
+				stats.TaskStatuses["unknown"]++
+				return true
+			}
+			extracted, err := ep.extractor.ProcessStopEvent(event)
+			if err != nil {
+				stats.TaskStatuses["unknown"]++
+				return true
+			}
+			if stopData, ok := extracted.Data.(*types.StopEventData); ok {
+				stats.TaskStatuses[stopData.TaskStatus]++
+			}
 		}
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return stats, nil
 }
 
+// eventToolName returns the tool an event refers to, falling back to the
+// "Claude needs your permission to use <Tool>" notification message
+func eventToolName(event *types.ClaudeHookEvent) string {
+	if event.ToolName != "" {
+		return event.ToolName
+	}
+
+	const marker = "permission to use "
+	if idx := strings.Index(event.Message, marker); idx >= 0 {
+		fields := strings.Fields(event.Message[idx+len(marker):])
+		if len(fields) > 0 {
+			return strings.TrimRight(fields[0], ".,!?")
+		}
+	}
+
+	return ""
+}
+
+// eventDay returns the YYYY-MM-DD day of an event timestamp, or "unknown"
+func eventDay(timestamp string) string {
+	if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+		return t.Format("2006-01-02")
+	}
+	if len(timestamp) >= 10 {
+		if t, err := time.Parse("2006-01-02", timestamp[:10]); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return "unknown"
+}
+
 // ProcessingStats contains statistics about event processing
 type ProcessingStats struct {
-	TotalEvents        int `json:"total_events"`
-	StopEvents         int `json:"stop_events"`
-	NotificationEvents int `json:"notification_events"`
-	ProcessableEvents  int `json:"processable_events"`
-	MissingTranscripts int `json:"missing_transcripts"`
-}
\ No newline at end of file
+	TotalEvents        int            `json:"total_events"`
+	StopEvents         int            `json:"stop_events"`
+	NotificationEvents int            `json:"notification_events"`
+	ProcessableEvents  int            `json:"processable_events"`
+	MissingTranscripts int            `json:"missing_transcripts"`
+	EventsByTool       map[string]int `json:"events_by_tool"`
+	EventsBySession    map[string]int `json:"events_by_session"`
+	EventsByDay        map[string]int `json:"events_by_day"`
+	TaskStatuses       map[string]int `json:"task_statuses"`
+}
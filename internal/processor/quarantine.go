@@ -0,0 +1,244 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/jsonl"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/redact"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// quarantineFileName is the JSONL file (inside <output-dir>/quarantine) holding failed events
+const quarantineFileName = "failed-events.jsonl"
+
+// QuarantinedEvent is an event that could not be processed, along with why
+type QuarantinedEvent struct {
+	Event    types.ClaudeHookEvent `json:"event"`
+	Error    string                `json:"error"`
+	FailedAt string                `json:"failed_at"`
+	Attempts int                   `json:"attempts"`
+}
+
+// RetryResult summarises a retry-failed run
+type RetryResult struct {
+	OutputFiles    []string
+	StillFailing   []QuarantinedEvent
+	TotalAttempted int
+}
+
+// QuarantinePath returns the location of the quarantine file
+func (ep *EventProcessor) QuarantinePath() string {
+	return filepath.Join(ep.outputDir, "quarantine", quarantineFileName)
+}
+
+// quarantineEvent records a failed event so it can be inspected and retried later.
+// An event that is already quarantined has its error and attempt count updated instead.
+func (ep *EventProcessor) quarantineEvent(event *types.ClaudeHookEvent, procErr error) {
+	entries, err := ep.LoadQuarantinedEvents()
+	if err != nil {
+		fmt.Printf("Warning: Failed to quarantine event: %v\n", err)
+		return
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	found := false
+	for i := range entries {
+		if sameEvent(&entries[i].Event, event) {
+			entries[i].Error = procErr.Error()
+			entries[i].FailedAt = now
+			entries[i].Attempts++
+			found = true
+			break
+		}
+	}
+	if !found {
+		entries = append(entries, QuarantinedEvent{
+			Event:    *event,
+			Error:    procErr.Error(),
+			FailedAt: now,
+			Attempts: 1,
+		})
+	}
+
+	if err := ep.writeQuarantine(entries); err != nil {
+		fmt.Printf("Warning: Failed to quarantine event: %v\n", err)
+		return
+	}
+	if !found && len(ep.newlyQuarantined) < maxNewlyQuarantined {
+		ep.newlyQuarantined = append(ep.newlyQuarantined, entries[len(entries)-1])
+	}
+}
+
+// maxNewlyQuarantined caps the events TakeQuarantined holds between calls
+const maxNewlyQuarantined = 100
+
+// TakeQuarantined returns the events this processor newly quarantined since the last call,
+// so status updates report failures without reading the quarantine file
+func (ep *EventProcessor) TakeQuarantined() []QuarantinedEvent {
+	taken := ep.newlyQuarantined
+	ep.newlyQuarantined = nil
+	return taken
+}
+
+// quarantineCount is the number of entries in the quarantine file when it had a size and
+// modification time
+type quarantineCount struct {
+	size    int64
+	modTime time.Time
+	count   int
+}
+
+// QuarantinedCount returns how many events are in quarantine. The file's lines are counted,
+// not decoded, and only again after the file changed.
+func (ep *EventProcessor) QuarantinedCount() (int, error) {
+	info, err := os.Stat(ep.QuarantinePath())
+	if os.IsNotExist(err) {
+		ep.quarantineCount = quarantineCount{}
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read quarantine file: %w", err)
+	}
+	cached := ep.quarantineCount
+	if cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.count, nil
+	}
+
+	file, err := os.Open(ep.QuarantinePath())
+	if err != nil {
+		return 0, fmt.Errorf("failed to open quarantine file: %w", err)
+	}
+	defer file.Close()
+
+	count := 0
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := file.Read(buf)
+		count += bytes.Count(buf[:n], []byte{'\n'})
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error reading quarantine file: %w", err)
+		}
+	}
+	ep.quarantineCount = quarantineCount{size: info.Size(), modTime: info.ModTime(), count: count}
+	return count, nil
+}
+
+// sameEvent reports whether two hook events are the same logged event
+func sameEvent(a, b *types.ClaudeHookEvent) bool {
+	return a.SessionID == b.SessionID &&
+		a.HookEventName == b.HookEventName &&
+		a.Timestamp == b.Timestamp &&
+		a.Message == b.Message
+}
+
+// LoadQuarantinedEvents reads all quarantined events
+func (ep *EventProcessor) LoadQuarantinedEvents() ([]QuarantinedEvent, error) {
+	file, err := os.Open(ep.QuarantinePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open quarantine file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []QuarantinedEvent
+	reader := jsonl.NewReader(file)
+	for {
+		line, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, jsonl.ErrLineTooLong) {
+			fmt.Printf("Warning: Skipping line in quarantine file: %v (raise processing.max_line_size_mb to read it)\n", err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading quarantine file: %w", err)
+		}
+
+		var entry QuarantinedEvent
+		if err := json.Unmarshal(line, &entry); err != nil {
+			fmt.Printf("Warning: Failed to parse line %d in quarantine file: %v\n", reader.Line(), err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// RetryFailedEvents reprocesses every quarantined event. Events that succeed are
+// removed from quarantine; events that fail again stay with their updated error.
+func (ep *EventProcessor) RetryFailedEvents() (*RetryResult, error) {
+	entries, err := ep.LoadQuarantinedEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RetryResult{TotalAttempted: len(entries)}
+
+	for _, entry := range entries {
+		event := entry.Event
+		outputFile, err := ep.ProcessEventAndSave(&event)
+		if err != nil {
+			entry.Error = err.Error()
+			entry.FailedAt = time.Now().Format(time.RFC3339)
+			entry.Attempts++
+			result.StillFailing = append(result.StillFailing, entry)
+			continue
+		}
+		if outputFile != "" {
+			result.OutputFiles = append(result.OutputFiles, outputFile)
+		}
+	}
+
+	// Rewrite the quarantine with only the events that are still failing
+	if err := ep.writeQuarantine(result.StillFailing); err != nil {
+		return nil, fmt.Errorf("failed to update quarantine file: %w", err)
+	}
+
+	return result, nil
+}
+
+// writeQuarantine replaces the quarantine file with the given entries, removing it entirely
+// when there is nothing left in quarantine. The file is replaced whole, so a crash while
+// writing leaves the previous quarantine.
+func (ep *EventProcessor) writeQuarantine(entries []QuarantinedEvent) error {
+	path := ep.QuarantinePath()
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	redact.Value(&entries)
+	if err := ep.ensureDirectoryExists(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to encode quarantine entry: %w", err)
+		}
+	}
+
+	if err := atomicfile.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write quarantine file: %w", err)
+	}
+	return nil
+}
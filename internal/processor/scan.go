@@ -0,0 +1,148 @@
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/jsonl"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// scanEvents calls fn with the events of an events file, oldest first, until fn returns
+// false. Events are decoded one line at a time, so a file of any size is read in bounded
+// memory. Lines that fail to parse are skipped, as are lines over the maximum line size.
+func (ep *EventProcessor) scanEvents(filePath string, fn func(*types.ClaudeHookEvent) bool) error {
+	return ep.scanEventLines(filePath, func(line []byte, number int) bool {
+		var event types.ClaudeHookEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			fmt.Printf("Warning: Failed to parse line %d in events file: %v\n", number, err)
+			return true
+		}
+		return fn(&event)
+	})
+}
+
+// scanEventFields calls fn with the given top-level string fields of each event in an events
+// file until fn returns false, without decoding the rest of the events; line holds the whole
+// event, for the few that need decoding. Stats and filters that only look at a few fields
+// read large files much faster this way.
+func (ep *EventProcessor) scanEventFields(filePath string, keys []string, fn func(fields []string, line []byte) bool) error {
+	return ep.scanEventLines(filePath, func(line []byte, number int) bool {
+		fields, err := jsonl.StringFields(line, keys...)
+		if err != nil {
+			fmt.Printf("Warning: Failed to parse line %d in events file: %v\n", number, err)
+			return true
+		}
+		return fn(fields, line)
+	})
+}
+
+// scanEventLines calls fn with each line of an events file and its number until fn returns
+// false. The line is only valid during the call.
+func (ep *EventProcessor) scanEventLines(filePath string, fn func(line []byte, number int) bool) error {
+	file, err := ep.openEvents(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := jsonl.NewReader(file)
+	for {
+		line, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if errors.Is(err, jsonl.ErrLineTooLong) {
+			fmt.Printf("Warning: Skipping line in events file: %v (raise processing.max_line_size_mb to read it)\n", err)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error reading events file: %w", err)
+		}
+		if !fn(line, reader.Line()) {
+			return nil
+		}
+	}
+}
+
+// latestEvents returns the last n events of an events file, oldest first. The file is read
+// from the end, so only the lines holding those events are parsed.
+func (ep *EventProcessor) latestEvents(filePath string, n int) ([]types.ClaudeHookEvent, error) {
+	file, err := ep.openEvents(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events file: %w", err)
+	}
+
+	var events []types.ClaudeHookEvent
+	err = jsonl.ScanBackward(file, info.Size(), func(line []byte, partial bool) bool {
+		if len(events) >= n {
+			return false
+		}
+		var event types.ClaudeHookEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			if !partial {
+				fmt.Printf("Warning: Failed to parse line in events file: %v\n", err)
+			}
+			return true
+		}
+		events = append(events, event)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading events file: %w", err)
+	}
+
+	// Collected newest first
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
+
+// countEvents returns how many lines of an events file hold JSON, without decoding them
+func (ep *EventProcessor) countEvents(filePath string) (int, error) {
+	file, err := ep.openEvents(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	count := 0
+	reader := jsonl.NewReader(file)
+	for {
+		line, err := reader.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if errors.Is(err, jsonl.ErrLineTooLong) {
+			continue
+		}
+		if err != nil {
+			return count, fmt.Errorf("error reading events file: %w", err)
+		}
+		if json.Valid(line) {
+			count++
+		}
+	}
+}
+
+// openEvents opens an events file for reading
+func (ep *EventProcessor) openEvents(filePath string) (*os.File, error) {
+	if !ep.fileExists(filePath) {
+		return nil, fmt.Errorf("events file does not exist: %s", filePath)
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events file: %w", err)
+	}
+	return file, nil
+}
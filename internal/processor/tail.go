@@ -0,0 +1,202 @@
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/jsonl"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// ErrEventsTruncated is returned by ReadNewEvents when the events file is shorter than the
+// offset, or the offset no longer follows a complete line: it was truncated or rewritten, so
+// reading has to start over
+var ErrEventsTruncated = errors.New("events file was truncated or rewritten")
+
+// tailStateFileName is the file (inside the output directory) recording how far watch mode
+// got through its events file, so a restart continues from there
+const tailStateFileName = ".events-offset.json"
+
+// tailState is the saved position of a tail
+type tailState struct {
+	EventsFile string `json:"events_file"`
+	Offset     int64  `json:"offset"`
+}
+
+// Tail follows an events file for watch mode and the service. The offset belongs to one file:
+// when the file shrinks, is rotated away or replaced by another inode, reading starts over at
+// the beginning of the file now at the path.
+type Tail struct {
+	path    string
+	offset  int64
+	size    int64
+	modTime time.Time
+	info    os.FileInfo // File the offset is into; nil while there is none
+	saved   int64       // Offset last saved with SaveTail
+}
+
+// NewTail returns a tail that reads an events file from its start
+func NewTail(path string) *Tail {
+	return &Tail{path: path}
+}
+
+// Offset returns the end of the last complete event line read
+func (t *Tail) Offset() int64 {
+	return t.offset
+}
+
+// SaveTail records a tail's position once the events before it have been handled, for
+// CatchUp to continue from after a restart
+func (ep *EventProcessor) SaveTail(tail *Tail) error {
+	if tail.offset == tail.saved {
+		return nil
+	}
+	absPath, err := filepath.Abs(tail.path)
+	if err != nil {
+		absPath = tail.path
+	}
+	data, err := json.Marshal(tailState{EventsFile: absPath, Offset: tail.offset})
+	if err != nil {
+		return fmt.Errorf("failed to marshal events file position: %w", err)
+	}
+	if err := ep.ensureDirectoryExists(ep.outputDir); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(filepath.Join(ep.outputDir, tailStateFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to save events file position: %w", err)
+	}
+	tail.saved = tail.offset
+	return nil
+}
+
+// resumeTail moves a tail that hasn't read anything yet to the position saved for its file.
+// A position the file has since been truncated or rewritten past is detected when reading,
+// which then starts over.
+func (ep *EventProcessor) resumeTail(tail *Tail) {
+	if tail.info != nil || tail.offset != 0 {
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(ep.outputDir, tailStateFileName))
+	if err != nil {
+		return
+	}
+	var state tailState
+	if json.Unmarshal(data, &state) != nil || !ep.sameFile(state.EventsFile, tail.path) {
+		return
+	}
+	tail.offset, tail.saved = state.Offset, state.Offset
+}
+
+// ReadTail returns the events appended to a tail's file since the last call. reset is true
+// when the file was truncated, rotated or replaced since then, and the events are read from
+// the start of the new file; events already handled are left to the processed ledger.
+func (ep *EventProcessor) ReadTail(tail *Tail) (events []types.ClaudeHookEvent, reset bool, err error) {
+	reset, err = ep.ScanTail(tail, func(event *types.ClaudeHookEvent) {
+		events = append(events, *event)
+	})
+	return events, reset, err
+}
+
+// ScanTail is ReadTail calling fn with each event as it is read, so catching up with a
+// large file takes bounded memory
+func (ep *EventProcessor) ScanTail(tail *Tail, fn func(*types.ClaudeHookEvent)) (reset bool, err error) {
+	info, err := os.Stat(tail.path)
+	if os.IsNotExist(err) {
+		// Rotated away and not recreated yet: its successor is read from the start
+		reset = tail.info != nil
+		*tail = Tail{path: tail.path}
+		return reset, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to stat events file: %w", err)
+	}
+
+	if tail.info != nil && !os.SameFile(tail.info, info) {
+		reset = true
+		tail.offset = 0
+	} else if tail.info != nil && info.Size() == tail.size && info.ModTime().Equal(tail.modTime) {
+		return false, nil // Unchanged
+	}
+	tail.info, tail.size, tail.modTime = info, info.Size(), info.ModTime()
+
+	offset, err := ep.scanNewEvents(tail.path, tail.offset, fn)
+	if errors.Is(err, ErrEventsTruncated) {
+		reset = true
+		offset, err = ep.scanNewEvents(tail.path, 0, fn)
+	}
+	tail.offset = offset
+	return reset, err
+}
+
+// ReadNewEvents parses the complete lines an events file gained after offset and returns them
+// with the offset to continue from, so a watcher reads each event once instead of the whole
+// file on every poll. An incomplete last line is left for the next call; lines that fail to
+// parse are skipped, as when reading the whole file.
+func (ep *EventProcessor) ReadNewEvents(eventsFilePath string, offset int64) ([]types.ClaudeHookEvent, int64, error) {
+	var events []types.ClaudeHookEvent
+	offset, err := ep.scanNewEvents(eventsFilePath, offset, func(event *types.ClaudeHookEvent) {
+		events = append(events, *event)
+	})
+	return events, offset, err
+}
+
+// scanNewEvents is ReadNewEvents calling fn with each event as it is read
+func (ep *EventProcessor) scanNewEvents(eventsFilePath string, offset int64, fn func(*types.ClaudeHookEvent)) (int64, error) {
+	file, err := os.Open(eventsFilePath)
+	if err != nil {
+		return offset, fmt.Errorf("failed to open events file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return offset, fmt.Errorf("failed to read events file: %w", err)
+	}
+	if info.Size() < offset {
+		return offset, ErrEventsTruncated
+	}
+	// A file rewritten past the offset no longer has a line ending just before it
+	if offset > 0 {
+		var last [1]byte
+		if _, err := file.ReadAt(last[:], offset-1); err != nil {
+			return offset, fmt.Errorf("failed to read events file: %w", err)
+		}
+		if last[0] != '\n' {
+			return offset, ErrEventsTruncated
+		}
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, fmt.Errorf("failed to read events file: %w", err)
+	}
+
+	start := offset
+	reader := jsonl.NewReader(file)
+	for {
+		line, err := reader.Next()
+		if err == io.EOF || reader.Partial() {
+			// Incomplete line: hooks may still be writing it
+			return offset, nil
+		}
+		offset = start + reader.Offset()
+		if errors.Is(err, jsonl.ErrLineTooLong) {
+			fmt.Printf("Warning: Skipping line in events file: %v (raise processing.max_line_size_mb to read it)\n", err)
+			continue
+		}
+		if err != nil {
+			return offset, fmt.Errorf("error reading events file: %w", err)
+		}
+
+		var event types.ClaudeHookEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			fmt.Printf("Warning: Failed to parse line in events file at byte %d: %v\n", start+reader.Offset(), err)
+			continue
+		}
+		fn(&event)
+	}
+}
@@ -0,0 +1,108 @@
+package processor
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// threadIndexFileName persists the first and last message of each session thread between runs
+const threadIndexFileName = ".threads.json"
+
+// threadState tracks one session's message thread
+type threadState struct {
+	RootMessageID string `json:"root_message_id"`
+	LastMessageID string `json:"last_message_id"`
+	LastReplyTo   string `json:"last_reply_to,omitempty"` // MessageID the last message replies to
+}
+
+// assignThread gives a message its MessageID and places it in its session's thread.
+// Channels map ThreadID/ReplyTo onto their own threading (Slack thread_ts, Telegram
+// reply_to_message_id) so one session reads as a single conversation.
+func (ep *EventProcessor) assignThread(message *types.MessengerMessage) {
+	message.MessageID = messageID(message)
+	message.ThreadID = "session-" + message.SessionID
+
+	threads := ep.loadThreadIndex()
+	state, exists := threads[message.ThreadID]
+	if exists {
+		// Reprocessing the first or last event keeps its place in the thread; other
+		// reprocessed events reply to the last message
+		switch message.MessageID {
+		case state.RootMessageID:
+			return
+		case state.LastMessageID:
+			message.ReplyTo = state.LastReplyTo
+			return
+		}
+		message.ReplyTo = state.LastMessageID
+	} else {
+		state.RootMessageID = message.MessageID
+	}
+
+	state.LastMessageID = message.MessageID
+	state.LastReplyTo = message.ReplyTo
+	threads[message.ThreadID] = state
+
+	if err := ep.saveThreadIndex(threads); err != nil {
+		fmt.Printf("Warning: Failed to save thread index: %v\n", err)
+	}
+}
+
+// endThread forgets the thread of a session that ended, so the index only holds the threads
+// of sessions that can still send messages
+func (ep *EventProcessor) endThread(sessionID string) {
+	threads := ep.loadThreadIndex()
+	if _, ok := threads["session-"+sessionID]; !ok {
+		return
+	}
+	delete(threads, "session-"+sessionID)
+	if err := ep.saveThreadIndex(threads); err != nil {
+		fmt.Printf("Warning: Failed to save thread index: %v\n", err)
+	}
+}
+
+// messageID derives a stable identifier for a message from the event it came from
+func messageID(message *types.MessengerMessage) string {
+	sum := sha1.Sum([]byte(message.SessionID + "|" + message.Type + "|" + message.Timestamp))
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadThreadIndex reads the thread index, caching it for the processor's lifetime
+func (ep *EventProcessor) loadThreadIndex() map[string]threadState {
+	if ep.threads != nil {
+		return ep.threads
+	}
+
+	ep.threads = make(map[string]threadState)
+	data, err := os.ReadFile(filepath.Join(ep.outputDir, threadIndexFileName))
+	if err != nil {
+		return ep.threads
+	}
+	if err := json.Unmarshal(data, &ep.threads); err != nil {
+		fmt.Printf("Warning: Failed to parse thread index, starting fresh: %v\n", err)
+		ep.threads = make(map[string]threadState)
+	}
+
+	return ep.threads
+}
+
+// saveThreadIndex writes the thread index to the output directory
+func (ep *EventProcessor) saveThreadIndex(threads map[string]threadState) error {
+	if err := ep.ensureDirectoryExists(ep.outputDir); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(threads, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(filepath.Join(ep.outputDir, threadIndexFileName), data, 0644)
+}
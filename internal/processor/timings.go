@@ -0,0 +1,29 @@
+package processor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// StageTimings is the wall-clock time one event spent in each processing stage
+type StageTimings struct {
+	TranscriptRead time.Duration // Reading and parsing the session's transcript
+	Extraction     time.Duration // Extracting the event's data, not counting transcript reads
+	Formatting     time.Duration // Turning the data into a messenger message
+	Write          time.Duration // Saving the message and its attachments
+}
+
+// String lists the stages with their times, for logs
+func (t StageTimings) String() string {
+	return fmt.Sprintf("transcript read %v, extraction %v, formatting %v, write %v",
+		t.TranscriptRead.Round(time.Microsecond), t.Extraction.Round(time.Microsecond),
+		t.Formatting.Round(time.Microsecond), t.Write.Round(time.Microsecond))
+}
+
+// SetTimingsHandler sets a function called with every saved event and the time it spent in
+// each stage, to locate slow stages (a transcript on a network filesystem, say)
+func (ep *EventProcessor) SetTimingsHandler(handler func(*types.ClaudeHookEvent, StageTimings)) {
+	ep.onTimed = handler
+}
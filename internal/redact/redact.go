@@ -0,0 +1,208 @@
+// Package redact masks secrets — API keys, tokens, passwords, private keys — in text
+// before ClaudeToGo logs or stores it
+package redact
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// Pattern is a named regular expression matching a secret. When the expression has a group
+// named "secret", only that group is masked, so the key in "password=hunter2" stays readable.
+type Pattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+}
+
+// Builtin are the secrets redacted unless redaction is turned off
+var Builtin = []Pattern{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"telegram_token", regexp.MustCompile(`\b\d{8,10}:[A-Za-z0-9_-]{35}\b`)},
+	{"api_key", regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"bearer_token", regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9._~+/=-]{16,})`)},
+	{"url_password", regexp.MustCompile(`://[^/\s:@]+:(?P<secret>[^/\s@]+)@`)},
+	{"assignment", regexp.MustCompile(`(?i)\b(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key)["']?\s*[:=]\s*["']?(?P<secret>[^\s"'&,;]{6,})`)},
+}
+
+// Redactor masks the matches of its patterns
+type Redactor struct {
+	patterns []Pattern
+}
+
+// New creates a redactor for the built-in patterns and custom name -> expression patterns
+func New(custom map[string]string) (*Redactor, error) {
+	r := &Redactor{patterns: append([]Pattern(nil), Builtin...)}
+
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := regexp.Compile(custom[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s: %w", name, err)
+		}
+		r.patterns = append(r.patterns, Pattern{Name: name, Regexp: re})
+	}
+	return r, nil
+}
+
+// Match is a secret found in text
+type Match struct {
+	Pattern string // Name of the pattern that matched
+	Start   int    // Byte offsets of the masked text
+	End     int
+}
+
+// Find returns the secrets in text in the order they appear. Where patterns overlap, the
+// one applied first (built-in before custom) is reported.
+func (r *Redactor) Find(text string) []Match {
+	var matches []Match
+	for _, pattern := range r.patterns {
+		group := pattern.Regexp.SubexpIndex("secret")
+		for _, loc := range pattern.Regexp.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			if group > 0 && loc[2*group] >= 0 {
+				start, end = loc[2*group], loc[2*group+1]
+			}
+			if !overlaps(matches, start, end) {
+				matches = append(matches, Match{Pattern: pattern.Name, Start: start, End: end})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+	return matches
+}
+
+// overlaps reports whether start..end overlaps one of matches
+func overlaps(matches []Match, start, end int) bool {
+	for _, match := range matches {
+		if start < match.End && match.Start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns text with every secret replaced by [REDACTED:<pattern>]
+func (r *Redactor) String(text string) string {
+	for _, pattern := range r.patterns {
+		if !pattern.Regexp.MatchString(text) {
+			continue
+		}
+		mask := "[REDACTED:" + pattern.Name + "]"
+		group := pattern.Regexp.SubexpIndex("secret")
+		text = pattern.Regexp.ReplaceAllStringFunc(text, func(match string) string {
+			if group <= 0 {
+				return mask
+			}
+			loc := pattern.Regexp.FindStringSubmatchIndex(match)
+			if loc == nil || loc[2*group] < 0 {
+				return mask
+			}
+			return match[:loc[2*group]] + mask + match[loc[2*group+1]:]
+		})
+	}
+	return text
+}
+
+// Value redacts every string reachable from ptr — struct fields, map values, slice
+// elements — in place
+func (r *Redactor) Value(ptr interface{}) {
+	r.value(reflect.ValueOf(ptr))
+}
+
+func (r *Redactor) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(r.String(v.String()))
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			r.value(v.Elem())
+		}
+	case reflect.Interface:
+		// The value in an interface can't be changed in place, so redact a copy and store it back
+		if !v.IsNil() && v.CanSet() {
+			elem := reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+			r.value(elem)
+			v.Set(elem)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				r.value(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			r.value(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			r.value(elem)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
+
+var (
+	mu      sync.RWMutex
+	current *Redactor // nil when redaction is off
+)
+
+func init() {
+	current, _ = New(nil)
+}
+
+// Configure turns redaction on or off for everything ClaudeToGo logs and stores, adding
+// custom patterns to the built-in ones
+func Configure(enabled bool, custom map[string]string) error {
+	var r *Redactor
+	if enabled {
+		var err error
+		if r, err = New(custom); err != nil {
+			return err
+		}
+	}
+	mu.Lock()
+	current = r
+	mu.Unlock()
+	return nil
+}
+
+// String redacts text with the configured patterns
+func String(text string) string {
+	mu.RLock()
+	r := current
+	mu.RUnlock()
+	if r == nil {
+		return text
+	}
+	return r.String(text)
+}
+
+// Value redacts the strings reachable from ptr with the configured patterns
+func Value(ptr interface{}) {
+	mu.RLock()
+	r := current
+	mu.RUnlock()
+	if r != nil {
+		r.Value(ptr)
+	}
+}
@@ -0,0 +1,251 @@
+// Package report aggregates activity across Claude sessions over a period: tool usage,
+// approvals and rejections, approval latency, the busiest projects and token usage
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/processor"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/transcript"
+)
+
+// Report is the activity of all sessions over a period
+type Report struct {
+	Since              time.Time      `json:"since"`
+	Until              time.Time      `json:"until"`
+	Sessions           int            `json:"sessions"` // Sessions with an event in the period
+	Events             int            `json:"events"`
+	Requests           int            `json:"requests"` // Permission requests
+	Approvals          int            `json:"approvals"`
+	Rejections         int            `json:"rejections"`
+	ApprovalRate       float64        `json:"approval_rate"` // Share of responses that approved, 0-1
+	RejectionRate      float64        `json:"rejection_rate"`
+	ApprovalLatencyAvg string         `json:"approval_latency_avg,omitempty"` // From request to response
+	ApprovalLatencyMax string         `json:"approval_latency_max,omitempty"`
+	Tools              []ToolUsage    `json:"tools"`    // Most used first
+	Projects           []ProjectStats `json:"projects"` // Busiest first
+	Tokens             Tokens         `json:"tokens"`
+	Transcripts        int            `json:"transcripts"` // Transcripts tools and tokens were counted from
+
+	latencyTotal, latencyMax time.Duration
+	latencies                int
+}
+
+// ToolUsage is how often a tool was called
+type ToolUsage struct {
+	Name  string `json:"name"`
+	Calls int    `json:"calls"`
+}
+
+// ProjectStats is the activity of one project's sessions
+type ProjectStats struct {
+	Name       string `json:"name"`
+	Sessions   int    `json:"sessions"`
+	Events     int    `json:"events"`
+	Requests   int    `json:"requests"`
+	Approvals  int    `json:"approvals"`
+	Rejections int    `json:"rejections"`
+	Tokens     int    `json:"tokens"` // Input, cache and output tokens
+}
+
+// Tokens is the token usage of the period
+type Tokens struct {
+	Input  int `json:"input"`
+	Output int `json:"output"`
+	Cache  int `json:"cache"` // Cache creation and cache read input tokens
+}
+
+// Total returns all tokens used
+func (t Tokens) Total() int {
+	return t.Input + t.Output + t.Cache
+}
+
+// Sources are where a report is read from; any of them may be missing
+type Sources struct {
+	OutputDir      string // Holds the session registry
+	EventsFile     string // Hook events
+	TranscriptsDir string // Searched for transcripts no event names
+}
+
+// Build aggregates the sessions active between since and until. Events and permission
+// requests are counted from the events file, falling back to the registry's totals for
+// sessions whose events are gone; tools and tokens come from the sessions' transcripts.
+func Build(sources Sources, since, until time.Time) (*Report, error) {
+	records, err := session.NewRegistry(sources.OutputDir).List()
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Since: since, Until: until, Tools: []ToolUsage{}, Projects: []ProjectStats{}}
+
+	// Per session: events and requests in the period, and the transcript events name
+	events := make(map[string]int)
+	requests := make(map[string]int)
+	transcripts := make(map[string]string)
+	if sources.EventsFile != "" {
+		// A missing events file leaves the registry to go by
+		all, _ := processor.NewEventProcessor("").Events(sources.EventsFile)
+		for _, event := range all {
+			if event.TranscriptPath != "" {
+				transcripts[event.SessionID] = event.TranscriptPath
+			}
+			at, err := time.Parse(time.RFC3339Nano, event.Timestamp)
+			if err != nil || at.Before(since) || at.After(until) {
+				continue
+			}
+			events[event.SessionID]++
+			if event.HookEventName == "Notification" {
+				requests[event.SessionID]++
+			}
+		}
+	}
+
+	projects := make(map[string]*ProjectStats)
+	tools := make(map[string]int)
+	for _, record := range records {
+		if record.LastEvent.Before(since) || record.Start().After(until) {
+			continue
+		}
+		name := record.Project
+		if name == "" {
+			name = "(unknown)"
+		}
+		project := projects[name]
+		if project == nil {
+			project = &ProjectStats{Name: name}
+			projects[name] = project
+		}
+
+		sessionEvents, sessionRequests := events[record.ID], requests[record.ID]
+		if sessionEvents == 0 {
+			sessionEvents, sessionRequests = record.Events, record.Requests
+		}
+		report.Sessions++
+		report.Events += sessionEvents
+		report.Requests += sessionRequests
+		project.Sessions++
+		project.Events += sessionEvents
+		project.Requests += sessionRequests
+
+		for _, response := range record.Responses {
+			if response.At.Before(since) || response.At.After(until) {
+				continue
+			}
+			if response.Action == "reject" {
+				report.Rejections++
+				project.Rejections++
+			} else {
+				report.Approvals++
+				project.Approvals++
+			}
+			if latency, ok := response.Latency(); ok {
+				report.addLatency(latency)
+			}
+		}
+
+		path := transcripts[record.ID]
+		if path == "" && sources.TranscriptsDir != "" {
+			path, _ = transcript.FindSessionTranscript(sources.TranscriptsDir, record.ID)
+		}
+		if path == "" {
+			continue
+		}
+		activity, err := transcript.NewReader().Activity(path, since)
+		if err != nil {
+			// Transcripts are removed by Claude Code after a while
+			continue
+		}
+		report.Transcripts++
+		for tool, calls := range activity.ToolCalls {
+			tools[tool] += calls
+		}
+		report.Tokens.Input += activity.InputTokens
+		report.Tokens.Output += activity.OutputTokens
+		report.Tokens.Cache += activity.CacheTokens
+		project.Tokens += activity.InputTokens + activity.OutputTokens + activity.CacheTokens
+	}
+
+	if responses := report.Approvals + report.Rejections; responses > 0 {
+		report.ApprovalRate = float64(report.Approvals) / float64(responses)
+		report.RejectionRate = float64(report.Rejections) / float64(responses)
+	}
+	if report.latencies > 0 {
+		report.ApprovalLatencyAvg = (report.latencyTotal / time.Duration(report.latencies)).Round(time.Second).String()
+		report.ApprovalLatencyMax = report.latencyMax.Round(time.Second).String()
+	}
+
+	for name, calls := range tools {
+		report.Tools = append(report.Tools, ToolUsage{Name: name, Calls: calls})
+	}
+	sort.Slice(report.Tools, func(i, j int) bool {
+		if report.Tools[i].Calls != report.Tools[j].Calls {
+			return report.Tools[i].Calls > report.Tools[j].Calls
+		}
+		return report.Tools[i].Name < report.Tools[j].Name
+	})
+	for _, project := range projects {
+		report.Projects = append(report.Projects, *project)
+	}
+	sort.Slice(report.Projects, func(i, j int) bool {
+		a, b := report.Projects[i], report.Projects[j]
+		if a.Events != b.Events {
+			return a.Events > b.Events
+		}
+		return a.Name < b.Name
+	})
+	return report, nil
+}
+
+// addLatency counts how long one request waited for its response
+func (r *Report) addLatency(latency time.Duration) {
+	r.latencies++
+	r.latencyTotal += latency
+	if latency > r.latencyMax {
+		r.latencyMax = latency
+	}
+}
+
+// Markdown renders the report for a wiki page, ticket or chat
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# ClaudeToGo Report\n\n")
+	fmt.Fprintf(&b, "%s to %s\n\n", r.Since.Local().Format("2006-01-02 15:04"), r.Until.Local().Format("2006-01-02 15:04"))
+
+	fmt.Fprintf(&b, "## Overview\n\n")
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Sessions | %d |\n", r.Sessions)
+	fmt.Fprintf(&b, "| Events | %d |\n", r.Events)
+	fmt.Fprintf(&b, "| Permission requests | %d |\n", r.Requests)
+	fmt.Fprintf(&b, "| Approved | %d (%s) |\n", r.Approvals, Percent(r.ApprovalRate))
+	fmt.Fprintf(&b, "| Rejected | %d (%s) |\n", r.Rejections, Percent(r.RejectionRate))
+	if r.ApprovalLatencyAvg != "" {
+		fmt.Fprintf(&b, "| Approval latency | %s average, %s longest |\n", r.ApprovalLatencyAvg, r.ApprovalLatencyMax)
+	}
+	fmt.Fprintf(&b, "| Tokens | %d in, %d cache, %d out |\n", r.Tokens.Input, r.Tokens.Cache, r.Tokens.Output)
+
+	if len(r.Projects) > 0 {
+		fmt.Fprintf(&b, "\n## Busiest Projects\n\n")
+		fmt.Fprintf(&b, "| Project | Sessions | Events | Requests | Approved | Rejected | Tokens |\n")
+		fmt.Fprintf(&b, "|---|---:|---:|---:|---:|---:|---:|\n")
+		for _, p := range r.Projects {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %d | %d |\n", p.Name, p.Sessions, p.Events, p.Requests, p.Approvals, p.Rejections, p.Tokens)
+		}
+	}
+
+	if len(r.Tools) > 0 {
+		fmt.Fprintf(&b, "\n## Tool Usage\n\n")
+		fmt.Fprintf(&b, "| Tool | Calls |\n|---|---:|\n")
+		for _, tool := range r.Tools {
+			fmt.Fprintf(&b, "| %s | %d |\n", tool.Name, tool.Calls)
+		}
+	}
+	return b.String()
+}
+
+// Percent formats a 0-1 rate as a percentage
+func Percent(rate float64) string {
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
@@ -0,0 +1,61 @@
+package responder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// ErrUnsupportedAction is returned when a response's action isn't one the message offers,
+// or one ClaudeToGo can execute
+var ErrUnsupportedAction = errors.New("unsupported action")
+
+// actionHandler executes one type of action
+type actionHandler struct {
+	decides bool // Answers the request, so it is recorded as the session's response
+	execute func(rh *ResponseHandler, sessionID string, message *types.MessengerMessage) error
+}
+
+// actionHandlers holds every action type a response can carry. Messages only offer these,
+// and an action type missing here is rejected rather than recorded and ignored.
+var actionHandlers = map[string]actionHandler{
+	"approve": {decides: true, execute: (*ResponseHandler).executeApproval},
+	"reject":  {decides: true, execute: (*ResponseHandler).executeRejection},
+	"info":    {execute: (*ResponseHandler).showInfo},
+	"modify":  {execute: (*ResponseHandler).showReview},
+}
+
+// actionFor returns the handler for an action on a message. A message that lists actions
+// takes only those; one without (from before messages listed them) takes approve, reject and
+// info. Only action_needed messages can be approved or rejected.
+func (rh *ResponseHandler) actionFor(message *types.MessengerMessage, action string) (actionHandler, error) {
+	if len(message.Actions) > 0 {
+		var offered []string
+		found := false
+		for _, msgAction := range message.Actions {
+			if msgAction.Type == action {
+				found = true
+			}
+			if !slices.Contains(offered, msgAction.Type) {
+				offered = append(offered, msgAction.Type)
+			}
+		}
+		if !found {
+			return actionHandler{}, fmt.Errorf("%w '%s' for this %s message (it offers %s)", ErrUnsupportedAction, action, message.Type, strings.Join(offered, ", "))
+		}
+	} else if action != "approve" && action != "reject" && action != "info" {
+		return actionHandler{}, fmt.Errorf("%w '%s' for this %s message (approve, reject or info)", ErrUnsupportedAction, action, message.Type)
+	}
+
+	handler, ok := actionHandlers[action]
+	if !ok {
+		return actionHandler{}, fmt.Errorf("%w '%s': the message offers it, but ClaudeToGo can't execute it", ErrUnsupportedAction, action)
+	}
+	if handler.decides && message.Type != "action_needed" {
+		return actionHandler{}, fmt.Errorf("%w '%s' for this %s message: only action_needed messages can be approved or rejected", ErrUnsupportedAction, action, message.Type)
+	}
+	return handler, nil
+}
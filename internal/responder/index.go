@@ -0,0 +1,349 @@
+package responder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/jsonl"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/layout"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// indexFileName is the file (inside the output directory) caching the headers of messenger files
+const indexFileName = ".message-index.json"
+
+// messageHeader is what listing and finding messages needs from a messenger file, so the
+// files themselves are only read when they are new or have changed
+type messageHeader struct {
+	ModTime      time.Time `json:"mod_time"`
+	Size         int64     `json:"size"`
+	SessionID    string    `json:"session_id"`
+	Type         string    `json:"type"`
+	Title        string    `json:"title,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	Risk         string    `json:"risk,omitempty"`
+	SessionLabel string    `json:"session_label,omitempty"`
+	SessionNote  string    `json:"session_note,omitempty"`
+	Line         int       `json:"line,omitempty"` // Line of a message in a jsonl file, from 1
+}
+
+// indexedFile is a messenger file with its header. For a message in a jsonl file, Path is
+// its location: the file and line, as in "messages.jsonl:12".
+type indexedFile struct {
+	Path string
+	*messageHeader
+}
+
+// kind returns the hook event a message is for, "notification" or "stop", or "" for others.
+// Messages in jsonl files aren't named after their event, so their type stands for it.
+func (f indexedFile) kind() string {
+	if f.Line > 0 {
+		switch f.Type {
+		case "action_needed":
+			return "notification"
+		case "completion":
+			return "stop"
+		}
+		return ""
+	}
+	for _, kind := range []string{"notification", "stop"} {
+		if strings.HasPrefix(filepath.Base(f.Path), "messenger-"+kind+"-") {
+			return kind
+		}
+	}
+	return ""
+}
+
+// jsonlFile is the index entry of a jsonl messenger file ("jsonl" file format): the headers
+// of its messages, and how far it was read
+type jsonlFile struct {
+	ModTime  time.Time        `json:"mod_time"`
+	Size     int64            `json:"size"`  // End of the last complete line read
+	Lines    int              `json:"lines"` // Lines up to Size
+	Messages []*messageHeader `json:"messages"`
+}
+
+// messageIndex maps messenger file paths, relative to the output directory, to their headers
+type messageIndex struct {
+	Files map[string]*messageHeader `json:"files"`
+	JSONL map[string]*jsonlFile     `json:"jsonl,omitempty"`
+}
+
+// messengerFiles returns the messenger files in the output directory with their headers, in
+// the order layout.Find gives them, followed by the messages in jsonl files (rotated ones
+// first). Headers come from the index, which is kept in memory and in the output directory;
+// a file is read again only when its size or modification time changed, a jsonl file only
+// from where it was last read to, and files that are gone are dropped from the index.
+func (rh *ResponseHandler) messengerFiles() ([]indexedFile, error) {
+	matches, err := layout.Find(rh.outputDir, "messenger-*.json")
+	if err != nil {
+		return nil, err
+	}
+	jsonlMatches, err := filepath.Glob(filepath.Join(rh.outputDir, "messages*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	rh.indexMu.Lock()
+	defer rh.indexMu.Unlock()
+	if rh.index == nil {
+		rh.index = rh.loadIndex()
+	}
+
+	changed := false
+	seen := make(map[string]bool, len(matches))
+	files := make([]indexedFile, 0, len(matches))
+	for _, path := range matches {
+		key, err := filepath.Rel(rh.outputDir, path)
+		if err != nil {
+			key = path
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		seen[key] = true
+
+		header, ok := rh.index.Files[key]
+		if !ok || header.Size != info.Size() || !header.ModTime.Equal(info.ModTime()) {
+			header, err = rh.readHeader(path, info)
+			if err != nil {
+				rh.logger.Debug("Failed to load messenger file %s: %v", path, err)
+				delete(rh.index.Files, key)
+				continue
+			}
+			rh.index.Files[key] = header
+			changed = true
+		}
+		files = append(files, indexedFile{Path: path, messageHeader: header})
+	}
+
+	for _, path := range jsonlMatches {
+		key, err := filepath.Rel(rh.outputDir, path)
+		if err != nil {
+			key = path
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		seen[key] = true
+
+		entry, ok := rh.index.JSONL[key]
+		if !ok || entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+			entry, err = rh.readJSONLHeaders(path, info, entry)
+			if err != nil {
+				rh.logger.Debug("Failed to load messenger file %s: %v", path, err)
+				delete(rh.index.JSONL, key)
+				continue
+			}
+			rh.index.JSONL[key] = entry
+			changed = true
+		}
+		for _, header := range entry.Messages {
+			files = append(files, indexedFile{Path: jsonlLocation(path, header.Line), messageHeader: header})
+		}
+	}
+
+	for key := range rh.index.Files {
+		if !seen[key] {
+			delete(rh.index.Files, key)
+			changed = true
+		}
+	}
+	for key := range rh.index.JSONL {
+		if !seen[key] {
+			delete(rh.index.JSONL, key)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := rh.saveIndex(); err != nil {
+			rh.logger.Debug("Failed to save message index: %v", err)
+		}
+	}
+	return files, nil
+}
+
+// readHeader parses the header of a messenger file
+func (rh *ResponseHandler) readHeader(path string, info os.FileInfo) (*messageHeader, error) {
+	message, err := rh.loadMessengerMessage(path)
+	if err != nil {
+		return nil, err
+	}
+	return headerOf(message, info.ModTime(), info.Size()), nil
+}
+
+// headerOf returns the header of a message
+func headerOf(message *types.MessengerMessage, modTime time.Time, size int64) *messageHeader {
+	header := &messageHeader{
+		ModTime:   modTime,
+		Size:      size,
+		SessionID: message.SessionID,
+		Type:      message.Type,
+	}
+	// Only pending actions are listed with their text
+	if message.Type == "action_needed" {
+		header.Title, header.Message = message.Title, message.Message
+		header.Risk, _ = message.Context["risk"].(string)
+		header.SessionLabel, _ = message.Context["session_label"].(string)
+		header.SessionNote, _ = message.Context["session_note"].(string)
+	}
+	return header
+}
+
+// readJSONLHeaders parses the headers of the messages a jsonl messenger file gained since it
+// was indexed as entry (nil when it wasn't). The file is only appended to, so reading goes
+// on from the end of the last line read; one that was rewritten or replaced since is read
+// from the start. An incomplete last line is left for the next call. A message's time is its
+// timestamp, or the file's modification time when it has none.
+func (rh *ResponseHandler) readJSONLHeaders(path string, info os.FileInfo, entry *jsonlFile) (*jsonlFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	if entry == nil || info.Size() < entry.Size || !endsLine(file, entry.Size) {
+		entry = &jsonlFile{}
+	} else {
+		entry = &jsonlFile{Size: entry.Size, Lines: entry.Lines, Messages: entry.Messages}
+	}
+	entry.ModTime = info.ModTime()
+	if _, err := file.Seek(entry.Size, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	start, startLines := entry.Size, entry.Lines
+	reader := jsonl.NewReader(file)
+	for {
+		line, err := reader.Next()
+		if err == io.EOF || reader.Partial() {
+			return entry, nil
+		}
+		entry.Size, entry.Lines = start+reader.Offset(), startLines+reader.Line()
+		if errors.Is(err, jsonl.ErrLineTooLong) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		var message types.MessengerMessage
+		if err := json.Unmarshal(line, &message); err != nil {
+			rh.logger.Debug("Skipping line %d of %s: %v", entry.Lines, path, err)
+			continue
+		}
+		modTime, err := time.Parse(time.RFC3339Nano, message.Timestamp)
+		if err != nil {
+			modTime = info.ModTime()
+		}
+		header := headerOf(&message, modTime, int64(len(line)))
+		header.Line = entry.Lines
+		entry.Messages = append(entry.Messages, header)
+	}
+}
+
+// endsLine reports whether the byte before offset in a file is a line ending, as it is at
+// the end of the last line read from a file that was only appended to since
+func endsLine(file *os.File, offset int64) bool {
+	if offset == 0 {
+		return true
+	}
+	var last [1]byte
+	_, err := file.ReadAt(last[:], offset-1)
+	return err == nil && last[0] == '\n'
+}
+
+// jsonlLocation returns the location of the message on a line of a jsonl messenger file
+func jsonlLocation(path string, line int) string {
+	return path + ":" + strconv.Itoa(line)
+}
+
+// splitJSONLLocation returns the file and line of a jsonl message location, and false for
+// the path of a messenger JSON file
+func splitJSONLLocation(location string) (string, int, bool) {
+	i := strings.LastIndexByte(location, ':')
+	if i < 0 || !strings.HasSuffix(location[:i], ".jsonl") {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(location[i+1:])
+	if err != nil || line < 1 {
+		return "", 0, false
+	}
+	return location[:i], line, true
+}
+
+// loadJSONLMessage loads the message on a line of a jsonl messenger file
+func (rh *ResponseHandler) loadJSONLMessage(path string, line int) (*types.MessengerMessage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+
+	reader := jsonl.NewReader(file)
+	for {
+		data, err := reader.Next()
+		if err == io.EOF || reader.Line() > line {
+			return nil, fmt.Errorf("no message on line %d of %s", line, path)
+		}
+		if reader.Line() < line {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		var message types.MessengerMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		return &message, nil
+	}
+}
+
+// loadIndex reads the saved index, starting an empty one if there is none or it can't be read
+func (rh *ResponseHandler) loadIndex() *messageIndex {
+	index := &messageIndex{}
+	if data, err := os.ReadFile(rh.indexPath()); err == nil {
+		if err := json.Unmarshal(data, index); err != nil {
+			rh.logger.Debug("Ignoring unreadable message index: %v", err)
+			index = &messageIndex{}
+		}
+	}
+	if index.Files == nil {
+		index.Files = make(map[string]*messageHeader)
+	}
+	if index.JSONL == nil {
+		index.JSONL = make(map[string]*jsonlFile)
+	}
+	return index
+}
+
+// saveIndex writes the index to the output directory
+func (rh *ResponseHandler) saveIndex() error {
+	data, err := json.Marshal(rh.index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message index: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(rh.indexPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write message index: %w", err)
+	}
+	return nil
+}
+
+// indexPath returns the location of the message index
+func (rh *ResponseHandler) indexPath() string {
+	return filepath.Join(rh.outputDir, indexFileName)
+}
@@ -2,20 +2,42 @@ package responder
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/redact"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/risk"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
 )
 
+// ErrNoPendingAction is returned when no notification exists for a session
+var ErrNoPendingAction = errors.New("no pending action found")
+
+// ErrConfirmationRequired is returned when a high-risk action is approved without its
+// confirmation code, and ErrWrongConfirmationCode when the code doesn't match
+var (
+	ErrConfirmationRequired  = errors.New("approving this high-risk action requires the confirmation code from the notification")
+	ErrWrongConfirmationCode = errors.New("wrong or already used confirmation code")
+)
+
 // ResponseHandler handles user responses from messenger apps and executes actions
 type ResponseHandler struct {
 	outputDir string
 	logger    *logger.Logger
+	display   formatter.Options
+	quiet     bool
+
+	indexMu sync.Mutex
+	index   *messageIndex // Headers of the messenger files, loaded on first use
 }
 
 // SessionStatus contains information about a specific session
@@ -24,8 +46,11 @@ type SessionStatus struct {
 	Status        string                 `json:"status"`
 	CreatedAt     time.Time             `json:"created_at"`
 	LastAction    string                 `json:"last_action,omitempty"`
+	LastReason    string                 `json:"last_reason,omitempty"`
 	Context       map[string]interface{} `json:"context,omitempty"`
 	MessengerFile string                 `json:"messenger_file,omitempty"`
+	Tags          []string               `json:"tags,omitempty"`
+	Notes         []session.Note         `json:"notes,omitempty"`
 }
 
 // PendingAction represents a pending action that needs user response
@@ -36,6 +61,10 @@ type PendingAction struct {
 	Message       string    `json:"message"`
 	CreatedAt     time.Time `json:"created_at"`
 	MessengerFile string    `json:"messenger_file"`
+	Risk          string    `json:"risk,omitempty"`          // Why approving takes a confirmation code
+	SessionLabel  string    `json:"session_label,omitempty"` // Name, or branch and start time, of the session
+	SessionTags   []string  `json:"session_tags,omitempty"`
+	SessionNote   string    `json:"session_note,omitempty"` // Latest note left on the session
 }
 
 // NewResponseHandler creates a new response handler
@@ -46,13 +75,41 @@ func NewResponseHandler(outputDir string, logger *logger.Logger) *ResponseHandle
 
 	return &ResponseHandler{
 		outputDir: outputDir,
-		logger:    logger,
+		logger:    logger.Component("responder"),
+		display:   formatter.DefaultOptions(),
 	}
 }
 
+// SetDisplayOptions sets how times are shown when displaying session information
+func (rh *ResponseHandler) SetDisplayOptions(options formatter.Options) {
+	rh.display = options
+}
+
+// SetQuiet stops the info action printing session information, for callers that
+// show the session themselves
+func (rh *ResponseHandler) SetQuiet(quiet bool) {
+	rh.quiet = quiet
+}
+
 // HandleResponse processes a user response (approve, reject, etc.)
 func (rh *ResponseHandler) HandleResponse(sessionID, action string) error {
-	rh.logger.Info("Processing response for session %s: %s", sessionID, action)
+	return rh.HandleResponseWithReason(sessionID, action, "")
+}
+
+// HandleResponseWithReason processes a user response, recording why it was given
+func (rh *ResponseHandler) HandleResponseWithReason(sessionID, action, reason string) error {
+	return rh.HandleConfirmedResponse(sessionID, action, reason, "")
+}
+
+// HandleConfirmedResponse processes a user response with the confirmation code that
+// approving a high-risk action takes
+func (rh *ResponseHandler) HandleConfirmedResponse(sessionID, action, reason, code string) error {
+	rh.logger.With("session_id", sessionID, "action", action).Info("Processing response for session %s: %s", sessionID, action)
+
+	sessionID, err := rh.ResolveSession(sessionID)
+	if err != nil {
+		return err
+	}
 
 	// Find the messenger file for this session
 	messengerFile, err := rh.findMessengerFile(sessionID)
@@ -61,38 +118,54 @@ func (rh *ResponseHandler) HandleResponse(sessionID, action string) error {
 	}
 
 	// Load the messenger message
-	message, err := rh.loadMessengerMessage(messengerFile)
+	message, err := rh.loadMessengerMessage(messengerFile.Path)
 	if err != nil {
 		return fmt.Errorf("failed to load messenger message: %w", err)
 	}
 
-	// Validate the action
-	if !rh.isValidAction(message, action) {
-		return fmt.Errorf("invalid action '%s' for this message type", action)
+	// Validate the action against those the message offers
+	handler, err := rh.actionFor(message, action)
+	if err != nil {
+		return err
+	}
+
+	// High-risk actions can only be approved with the one-time code sent with the notification
+	confirmed := false
+	if reason, _ := message.Context["risk"].(string); reason != "" && action == "approve" {
+		if code == "" {
+			return fmt.Errorf("%w (%s)", ErrConfirmationRequired, reason)
+		}
+		if !risk.NewCodes(rh.outputDir).Claim(sessionID, message.MessageID, code) {
+			rh.logger.With("session_id", sessionID).Warn("Wrong confirmation code for session %s", sessionID)
+			return ErrWrongConfirmationCode
+		}
+		confirmed = true
 	}
 
 	// Execute the action
-	return rh.executeAction(sessionID, action, message, messengerFile)
+	return rh.executeAction(sessionID, action, reason, confirmed, handler, message)
 }
 
 // ExecuteAction executes the approved action by interfacing with Claude Code
 func (rh *ResponseHandler) ExecuteAction(sessionID, action string, message *types.MessengerMessage) error {
 	rh.logger.Info("Executing action %s for session %s", action, sessionID)
 
-	switch action {
-	case "approve":
-		return rh.executeApproval(sessionID, message)
-	case "reject":
-		return rh.executeRejection(sessionID, message)
-	default:
-		return fmt.Errorf("unsupported action: %s", action)
+	handler, err := rh.actionFor(message, action)
+	if err != nil {
+		return err
 	}
+	return handler.execute(rh, sessionID, message)
 }
 
 // GetSessionStatus retrieves status information for a specific session
 func (rh *ResponseHandler) GetSessionStatus(sessionID string) (*SessionStatus, error) {
 	rh.logger.Debug("Getting status for session: %s", sessionID)
 
+	sessionID, err := rh.ResolveSession(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
 	// Find the messenger file for this session
 	messengerFile, err := rh.findMessengerFile(sessionID)
 	if err != nil {
@@ -100,31 +173,28 @@ func (rh *ResponseHandler) GetSessionStatus(sessionID string) (*SessionStatus, e
 	}
 
 	// Load the messenger message
-	message, err := rh.loadMessengerMessage(messengerFile)
+	message, err := rh.loadMessengerMessage(messengerFile.Path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load session data: %w", err)
 	}
 
-	// Get file info for creation time
-	fileInfo, err := os.Stat(messengerFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get file info: %w", err)
-	}
-
 	status := &SessionStatus{
 		SessionID:     sessionID,
 		Status:        rh.determineStatus(message),
-		CreatedAt:     fileInfo.ModTime(),
-		MessengerFile: messengerFile,
-		Context:       message.Context,
+		CreatedAt:     messengerFile.ModTime,
+		MessengerFile: messengerFile.Path,
+		Context:       risk.WithoutCodes(message.Context),
+	}
+	if record, err := session.NewRegistry(rh.outputDir).Get(sessionID); err == nil && record != nil {
+		status.Tags, status.Notes = record.Tags, record.Notes
 	}
 
 	// Check if there's been any action on this session
-	responseFile := rh.getResponseFilePath(sessionID)
-	if rh.fileExists(responseFile) {
+	if responseFile, ok := rh.findResponseFile(sessionID); ok {
 		responseData, err := rh.loadResponseData(responseFile)
 		if err == nil {
-			status.LastAction = responseData["action"].(string)
+			status.LastAction, _ = responseData["action"].(string)
+			status.LastReason, _ = responseData["reason"].(string)
 		}
 	}
 
@@ -137,87 +207,132 @@ func (rh *ResponseHandler) ListPendingActions() ([]*PendingAction, error) {
 
 	var pendingActions []*PendingAction
 
-	// Scan messenger output directory for notification files
-	pattern := filepath.Join(rh.outputDir, "messenger-notification-*.json")
-	matches, err := filepath.Glob(pattern)
+	// Scan the messenger output directory and its layout subdirectories for notification files
+	files, err := rh.messengerFiles()
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan for messenger files: %w", err)
 	}
 
-	for _, file := range matches {
-		// Load the message
-		message, err := rh.loadMessengerMessage(file)
-		if err != nil {
-			rh.logger.Debug("Failed to load messenger file %s: %v", file, err)
-			continue
+	// Labels, tags and notes from the registry follow later changes; older messages fall
+	// back to their own
+	records := make(map[string]session.Record)
+	if list, err := session.NewRegistry(rh.outputDir).List(); err == nil {
+		for _, record := range list {
+			records[record.ID] = record
 		}
+	}
 
+	for _, file := range files {
 		// Check if this is a pending action (action_needed type)
-		if message.Type == "action_needed" {
-			sessionID := message.SessionID
-			
-			// Check if already responded to
-			responseFile := rh.getResponseFilePath(sessionID)
-			if rh.fileExists(responseFile) {
-				continue // Already handled
-			}
-
-			// Get file creation time
-			fileInfo, err := os.Stat(file)
-			if err != nil {
-				continue
-			}
+		if file.kind() != "notification" || file.Type != "action_needed" {
+			continue
+		}
+		sessionID := file.SessionID
 
-			pendingAction := &PendingAction{
-				SessionID:     sessionID,
-				Type:          message.Type,
-				Title:         message.Title,
-				Message:       message.Message,
-				CreatedAt:     fileInfo.ModTime(),
-				MessengerFile: file,
-			}
+		// Check if already responded to
+		if _, ok := rh.findResponseFile(sessionID); ok {
+			continue // Already handled
+		}
 
-			pendingActions = append(pendingActions, pendingAction)
+		pendingAction := &PendingAction{
+			SessionID:     sessionID,
+			Type:          file.Type,
+			Title:         file.Title,
+			Message:       file.Message,
+			CreatedAt:     file.ModTime,
+			MessengerFile: file.Path,
+			Risk:          file.Risk,
+		}
+		if record, ok := records[sessionID]; ok {
+			pendingAction.SessionLabel = record.Label()
+			pendingAction.SessionTags, pendingAction.SessionNote = record.Tags, record.LastNote()
+		} else {
+			pendingAction.SessionLabel, pendingAction.SessionNote = file.SessionLabel, file.SessionNote
 		}
+
+		pendingActions = append(pendingActions, pendingAction)
 	}
 
 	return pendingActions, nil
 }
 
-// findMessengerFile finds the messenger JSON file for a given session ID
-func (rh *ResponseHandler) findMessengerFile(sessionID string) (string, error) {
-	// Try different patterns to find the file
-	patterns := []string{
-		fmt.Sprintf("messenger-notification-%s-*.json", sessionID[:8]),
-		fmt.Sprintf("messenger-stop-%s-*.json", sessionID[:8]),
-		fmt.Sprintf("messenger-*-%s-*.json", sessionID[:8]),
+// FindMessage returns the messenger message --respond and --status act on for a session,
+// and the file it is in
+func (rh *ResponseHandler) FindMessage(sessionID string) (*types.MessengerMessage, string, error) {
+	sessionID, err := rh.ResolveSession(sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+	messengerFile, err := rh.findMessengerFile(sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+	message, err := rh.loadMessengerMessage(messengerFile.Path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load messenger message: %w", err)
+	}
+	return message, messengerFile.Path, nil
+}
+
+// PendingMessage loads the full message of a pending action, with its context and attachments
+func (rh *ResponseHandler) PendingMessage(action *PendingAction) (*types.MessengerMessage, error) {
+	return rh.loadMessengerMessage(action.MessengerFile)
+}
+
+// ResolveSession returns the full ID of the session a prefix stands for, among the sessions
+// in the registry and those with messenger files from before it. A prefix matching several
+// sessions gives a *session.AmbiguousError listing them.
+func (rh *ResponseHandler) ResolveSession(prefix string) (string, error) {
+	sessionIDs, err := session.NewRegistry(rh.outputDir).IDs()
+	if err != nil {
+		rh.logger.Warn("Failed to read session registry: %v", err)
 	}
 
-	for _, pattern := range patterns {
-		fullPattern := filepath.Join(rh.outputDir, pattern)
-		matches, err := filepath.Glob(fullPattern)
-		if err != nil {
-			continue
+	// The message index holds the full session ID of every messenger file
+	files, err := rh.messengerFiles()
+	if err != nil {
+		return "", fmt.Errorf("%w for session ID: %s", ErrNoPendingAction, prefix)
+	}
+	for _, file := range files {
+		if file.SessionID != "" && strings.HasPrefix(file.SessionID, strings.TrimSpace(prefix)) {
+			sessionIDs = append(sessionIDs, file.SessionID)
 		}
+	}
 
-		for _, match := range matches {
-			// Verify this file contains the correct session ID
-			message, err := rh.loadMessengerMessage(match)
-			if err != nil {
-				continue
-			}
+	sessionID, err := session.Resolve(prefix, sessionIDs)
+	if errors.Is(err, session.ErrNotFound) {
+		return "", fmt.Errorf("%w for session ID: %s", ErrNoPendingAction, prefix)
+	}
+	return sessionID, err
+}
 
-			if strings.HasPrefix(message.SessionID, sessionID) || strings.HasPrefix(sessionID, message.SessionID) {
-				return match, nil
+// findMessengerFile finds the messenger file, or jsonl message, for a full session ID. Files
+// are matched on the session ID inside them, so files named after the first 8 characters of
+// the ID, from before names carried all of it, are found too.
+func (rh *ResponseHandler) findMessengerFile(sessionID string) (indexedFile, error) {
+	files, err := rh.messengerFiles()
+	if err != nil {
+		return indexedFile{}, fmt.Errorf("failed to scan for messenger files: %w", err)
+	}
+
+	// Notifications are preferred over stop messages, and those over any other message
+	for _, kind := range []string{"notification", "stop", ""} {
+		for _, file := range files {
+			if file.SessionID == sessionID && (kind == "" || file.kind() == kind) {
+				return file, nil
 			}
 		}
 	}
 
-	return "", fmt.Errorf("no messenger file found for session ID: %s", sessionID)
+	return indexedFile{}, fmt.Errorf("%w for session ID: %s", ErrNoPendingAction, sessionID)
 }
 
-// loadMessengerMessage loads a messenger message from a JSON file
+// loadMessengerMessage loads a messenger message from a JSON file, or from its location in a
+// jsonl file
 func (rh *ResponseHandler) loadMessengerMessage(filePath string) (*types.MessengerMessage, error) {
+	if path, line, ok := splitJSONLLocation(filePath); ok {
+		return rh.loadJSONLMessage(path, line)
+	}
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
@@ -231,45 +346,25 @@ func (rh *ResponseHandler) loadMessengerMessage(filePath string) (*types.Messeng
 	return &message, nil
 }
 
-// isValidAction checks if the given action is valid for the message
-func (rh *ResponseHandler) isValidAction(message *types.MessengerMessage, action string) bool {
-	// For action_needed messages, check if the action is in the available actions
-	if message.Type == "action_needed" && len(message.Actions) > 0 {
-		for _, msgAction := range message.Actions {
-			if msgAction.Type == action {
-				return true
-			}
+// executeAction performs the actual action execution
+func (rh *ResponseHandler) executeAction(sessionID, action, reason string, confirmed bool, handler actionHandler, message *types.MessengerMessage) error {
+	// Record decisions; looking at a request leaves it pending and its earlier response intact
+	if handler.decides {
+		if err := rh.recordResponse(sessionID, action, reason, confirmed, message); err != nil {
+			return fmt.Errorf("failed to record response: %w", err)
+		}
+		requested, _ := time.Parse(time.RFC3339Nano, message.Timestamp)
+		if err := session.NewRegistry(rh.outputDir).RecordResponse(sessionID, action, requested); err != nil {
+			rh.logger.Warn("Failed to update session registry: %v", err)
 		}
-		return false
 	}
 
-	// For other message types, only basic actions are allowed
-	return action == "approve" || action == "reject" || action == "info"
-}
-
-// executeAction performs the actual action execution
-func (rh *ResponseHandler) executeAction(sessionID, action string, message *types.MessengerMessage, messengerFile string) error {
-	// Record the response
-	if err := rh.recordResponse(sessionID, action, message); err != nil {
-		return fmt.Errorf("failed to record response: %w", err)
-	}
-
-	// Execute the specific action
-	switch action {
-	case "approve":
-		return rh.executeApproval(sessionID, message)
-	case "reject":
-		return rh.executeRejection(sessionID, message)
-	case "info":
-		return rh.showInfo(sessionID, message)
-	default:
-		return fmt.Errorf("unknown action: %s", action)
-	}
+	return handler.execute(rh, sessionID, message)
 }
 
 // executeApproval handles approval actions
 func (rh *ResponseHandler) executeApproval(sessionID string, message *types.MessengerMessage) error {
-	rh.logger.Info("Executing approval for session %s", sessionID)
+	rh.logger.With("session_id", sessionID, "action", "approve").Info("Executing approval for session %s", sessionID)
 
 	// TODO: Interface with Claude Code to execute the approved action
 	// This would involve:
@@ -284,7 +379,7 @@ func (rh *ResponseHandler) executeApproval(sessionID string, message *types.Mess
 
 // executeRejection handles rejection actions
 func (rh *ResponseHandler) executeRejection(sessionID string, message *types.MessengerMessage) error {
-	rh.logger.Info("Executing rejection for session %s", sessionID)
+	rh.logger.With("session_id", sessionID, "action", "reject").Info("Executing rejection for session %s", sessionID)
 
 	// TODO: Interface with Claude Code to reject the action
 	// This might involve sending a signal to Claude Code that the action was rejected
@@ -296,17 +391,20 @@ func (rh *ResponseHandler) executeRejection(sessionID string, message *types.Mes
 // showInfo displays information about the session
 func (rh *ResponseHandler) showInfo(sessionID string, message *types.MessengerMessage) error {
 	rh.logger.Info("Showing info for session %s", sessionID)
+	if rh.quiet {
+		return nil
+	}
 
 	fmt.Printf("📋 Session Information: %s\n", sessionID)
 	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 	fmt.Printf("Type:     %s\n", message.Type)
 	fmt.Printf("Title:    %s\n", message.Title)
 	fmt.Printf("Message:  %s\n", message.Message)
-	fmt.Printf("Time:     %s\n", message.Timestamp)
+	fmt.Printf("Time:     %s\n", rh.display.FormatTimestamp(message.Timestamp))
 
 	if message.Context != nil {
 		fmt.Printf("Context:\n")
-		for key, value := range message.Context {
+		for key, value := range risk.WithoutCodes(message.Context) {
 			fmt.Printf("  %s: %v\n", key, value)
 		}
 	}
@@ -314,8 +412,36 @@ func (rh *ResponseHandler) showInfo(sessionID string, message *types.MessengerMe
 	return nil
 }
 
+// showReview shows what to review before deciding a Write or Edit request: the target file,
+// the command the notification suggests for reviewing it and the attached content
+func (rh *ResponseHandler) showReview(sessionID string, message *types.MessengerMessage) error {
+	rh.logger.Info("Showing review for session %s", sessionID)
+	if rh.quiet {
+		return nil
+	}
+
+	fmt.Printf("✏️  Review: %s\n", sessionID)
+	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	fmt.Printf("Title:    %s\n", message.Title)
+	if target, ok := message.Context["target_file"].(string); ok {
+		fmt.Printf("File:     %s\n", target)
+	}
+	for _, action := range message.Actions {
+		if action.Type == "modify" && action.Command != "" {
+			fmt.Printf("Review:   %s\n", action.Command)
+		}
+	}
+	for _, attachment := range message.Attachments {
+		if attachment.Path != "" {
+			fmt.Printf("Content:  %s\n", attachment.Path)
+		}
+	}
+
+	return nil
+}
+
 // recordResponse records the user's response for tracking
-func (rh *ResponseHandler) recordResponse(sessionID, action string, message *types.MessengerMessage) error {
+func (rh *ResponseHandler) recordResponse(sessionID, action, reason string, confirmed bool, message *types.MessengerMessage) error {
 	responseFile := rh.getResponseFilePath(sessionID)
 
 	response := map[string]interface{}{
@@ -324,6 +450,13 @@ func (rh *ResponseHandler) recordResponse(sessionID, action string, message *typ
 		"timestamp":  time.Now().Format(time.RFC3339),
 		"message_type": message.Type,
 		"message_title": message.Title,
+		"message_timestamp": message.Timestamp,
+	}
+	if reason != "" {
+		response["reason"] = redact.String(reason)
+	}
+	if confirmed {
+		response["confirmed"] = true
 	}
 
 	data, err := json.MarshalIndent(response, "", "  ")
@@ -337,7 +470,7 @@ func (rh *ResponseHandler) recordResponse(sessionID, action string, message *typ
 		return fmt.Errorf("failed to create responses directory: %w", err)
 	}
 
-	if err := os.WriteFile(responseFile, data, 0644); err != nil {
+	if err := atomicfile.WriteFile(responseFile, data, 0644); err != nil {
 		return fmt.Errorf("failed to write response file: %w", err)
 	}
 
@@ -346,10 +479,28 @@ func (rh *ResponseHandler) recordResponse(sessionID, action string, message *typ
 
 // getResponseFilePath returns the path for storing response data
 func (rh *ResponseHandler) getResponseFilePath(sessionID string) string {
-	filename := fmt.Sprintf("response-%s.json", sessionID[:8])
+	filename := fmt.Sprintf("response-%s.json", session.FileName(sessionID))
 	return filepath.Join(rh.outputDir, "responses", filename)
 }
 
+// findResponseFile returns the response file recorded for a session and whether there is
+// one. Responses from before file names carried the full session ID are named after its first
+// 8 characters, and only count when they are for this session.
+func (rh *ResponseHandler) findResponseFile(sessionID string) (string, bool) {
+	responseFile := rh.getResponseFilePath(sessionID)
+	if rh.fileExists(responseFile) {
+		return responseFile, true
+	}
+
+	legacyFile := filepath.Join(rh.outputDir, "responses", fmt.Sprintf("response-%s.json", session.Short(sessionID)))
+	if legacyFile != responseFile {
+		if response, err := rh.loadResponseData(legacyFile); err == nil && response["session_id"] == sessionID {
+			return legacyFile, true
+		}
+	}
+	return responseFile, false
+}
+
 // loadResponseData loads response data from file
 func (rh *ResponseHandler) loadResponseData(filePath string) (map[string]interface{}, error) {
 	data, err := os.ReadFile(filePath)
@@ -0,0 +1,152 @@
+package risk
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
+)
+
+// CodesDirName is the directory (inside the output directory) holding the key confirmation
+// codes are checked with and the HMAC of each code not used yet; only its owner can read it
+const CodesDirName = ".confirmation-codes"
+
+const (
+	keyFileName   = "key"
+	codeRetention = 7 * 24 * time.Hour // Unused codes are dropped after this
+)
+
+// legacyCodeKeys are context keys in which older messages held a confirmation code or its
+// unkeyed hash
+var legacyCodeKeys = []string{"confirmation_code", "confirmation_code_sha256"}
+
+// Codes stores confirmation codes apart from the messages, as an HMAC under a key that
+// never leaves the output directory, so neither the message files nor anything listing them
+// reveal a code or let it be guessed offline
+type Codes struct {
+	dir string
+}
+
+// NewCodes returns the confirmation codes kept in an output directory
+func NewCodes(outputDir string) *Codes {
+	return &Codes{dir: filepath.Join(outputDir, CodesDirName)}
+}
+
+// Add stores the code for a message, dropping codes older than codeRetention
+func (c *Codes) Add(sessionID, messageID, code string) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create confirmation code directory: %w", err)
+	}
+	key, err := c.key(true)
+	if err != nil {
+		return err
+	}
+	c.prune(time.Now())
+	mac := c.mac(key, sessionID, messageID, code)
+	if err := atomicfile.WriteFile(c.codePath(messageID), []byte(mac), 0600); err != nil {
+		return fmt.Errorf("failed to store confirmation code: %w", err)
+	}
+	return nil
+}
+
+// Claim checks a code supplied for a message and, when it matches, removes it so it can't
+// approve anything again
+func (c *Codes) Claim(sessionID, messageID, supplied string) bool {
+	if messageID == "" || supplied == "" {
+		return false
+	}
+	key, err := c.key(false)
+	if err != nil {
+		return false
+	}
+	expected, err := os.ReadFile(c.codePath(messageID))
+	if err != nil {
+		return false
+	}
+	if !hmac.Equal(expected, []byte(c.mac(key, sessionID, messageID, supplied))) {
+		return false
+	}
+	// Only one of two approvals racing with the same code removes the file
+	return os.Remove(c.codePath(messageID)) == nil
+}
+
+// codePath returns the file holding a message's code; message IDs are hex
+func (c *Codes) codePath(messageID string) string {
+	return filepath.Join(c.dir, filepath.Base(messageID)+".code")
+}
+
+// mac returns the HMAC a code is stored as, bound to its session and message
+func (c *Codes) mac(key []byte, sessionID, messageID, code string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(sessionID + ":" + messageID + ":" + strings.TrimSpace(code)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// key returns the HMAC key, creating it first when create is set. A new key is linked into
+// place so processes creating it at once end up with the same one.
+func (c *Codes) key(create bool) ([]byte, error) {
+	path := filepath.Join(c.dir, keyFileName)
+	key, err := os.ReadFile(path)
+	if err == nil {
+		return key, nil
+	}
+	if !os.IsNotExist(err) || !create {
+		return nil, fmt.Errorf("failed to read confirmation code key: %w", err)
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to create confirmation code key: %w", err)
+	}
+	temp := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := atomicfile.WriteFile(temp, key, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write confirmation code key: %w", err)
+	}
+	defer os.Remove(temp)
+	if err := os.Link(temp, path); err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("failed to write confirmation code key: %w", err)
+	}
+	if key, err = os.ReadFile(path); err != nil {
+		return nil, fmt.Errorf("failed to read confirmation code key: %w", err)
+	}
+	return key, nil
+}
+
+// prune removes codes stored more than codeRetention before now
+func (c *Codes) prune(now time.Time) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".code") {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && now.Sub(info.ModTime()) > codeRetention {
+			os.Remove(filepath.Join(c.dir, entry.Name()))
+		}
+	}
+}
+
+// WithoutCodes returns a message context without the confirmation codes or hashes older
+// messages held, for showing it to readers of the message
+func WithoutCodes(context map[string]interface{}) map[string]interface{} {
+	if !slices.ContainsFunc(legacyCodeKeys, func(key string) bool { _, ok := context[key]; return ok }) {
+		return context
+	}
+	stripped := maps.Clone(context)
+	for _, key := range legacyCodeKeys {
+		delete(stripped, key)
+	}
+	return stripped
+}
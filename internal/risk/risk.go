@@ -0,0 +1,184 @@
+// Package risk flags tool requests dangerous enough that approving them takes a one-time
+// confirmation code, so they can't be approved with an accidental tap
+package risk
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// codeDigits is the length of a confirmation code
+const codeDigits = 6
+
+// Pattern is a named regular expression matching a dangerous shell command
+type Pattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+}
+
+// BuiltinCommands are the Bash commands that need a confirmation code
+var BuiltinCommands = []Pattern{
+	{"recursive_delete", regexp.MustCompile(`\brm\s+(-\S+\s+)*-[a-zA-Z]*[rR]`)},
+	{"force_push", regexp.MustCompile(`\bgit\s+push\b.*\s(--force|--force-with-lease|-f)\b`)},
+	{"discard_changes", regexp.MustCompile(`\bgit\s+(reset\s+--hard|clean\s+-[a-zA-Z]*f)`)},
+	{"drop_data", regexp.MustCompile(`(?i)\b(drop\s+(table|database|schema)|truncate\s+table)\b`)},
+	{"disk_write", regexp.MustCompile(`\b(mkfs(\.\w+)?|dd\s+.*\bof=/dev/)`)},
+	{"recursive_permissions", regexp.MustCompile(`\bch(mod|own)\s+-R\b`)},
+	{"pipe_to_shell", regexp.MustCompile(`\b(curl|wget)\b[^|]*\|\s*(sudo\s+)?(ba|z)?sh\b`)},
+	{"sudo", regexp.MustCompile(`(^|[;&|]\s*)sudo\b`)},
+	{"destroy_infrastructure", regexp.MustCompile(`\b(terraform\s+destroy|kubectl\s+delete|helm\s+uninstall)\b`)},
+	{"shutdown", regexp.MustCompile(`(^|[;&|]\s*)(sudo\s+)?(shutdown|reboot|poweroff)\b`)},
+}
+
+// BuiltinPaths are the files that need a confirmation code before Claude writes them. A
+// pattern without a slash matches file names, one ending in /** everything under a
+// directory, and any other pattern the full path.
+var BuiltinPaths = []string{
+	".env",
+	".env.*",
+	"*.pem",
+	"*.key",
+	"id_rsa*",
+	".git/**",
+	".ssh/**",
+	".github/workflows/**",
+	"/etc/**",
+}
+
+// Policy decides which requests need a confirmation code
+type Policy struct {
+	commands []Pattern
+	paths    []string
+}
+
+// New creates a policy for the built-in commands and paths plus custom name -> expression
+// command patterns and path patterns
+func New(commands map[string]string, paths []string) (*Policy, error) {
+	p := &Policy{
+		commands: append([]Pattern(nil), BuiltinCommands...),
+		paths:    append(append([]string(nil), BuiltinPaths...), paths...),
+	}
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := regexp.Compile(commands[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid dangerous command pattern %s: %w", name, err)
+		}
+		p.commands = append(p.commands, Pattern{Name: name, Regexp: re})
+	}
+	for _, pattern := range paths {
+		if _, err := filepath.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+			return nil, fmt.Errorf("invalid protected path %q: %w", pattern, err)
+		}
+	}
+	return p, nil
+}
+
+// Assess returns why a tool request is dangerous, or "" when it isn't. context is the
+// message context with tool_name, command, target_file and cwd.
+func (p *Policy) Assess(context map[string]interface{}) string {
+	tool := strings.ToLower(fmt.Sprint(context["tool_name"]))
+	switch tool {
+	case "bash":
+		command, _ := context["command"].(string)
+		for _, pattern := range p.commands {
+			if pattern.Regexp.MatchString(command) {
+				return fmt.Sprintf("dangerous command (%s)", pattern.Name)
+			}
+		}
+	case "write", "edit", "multiedit", "notebookedit":
+		target, _ := context["target_file"].(string)
+		if target == "" {
+			return ""
+		}
+		if cwd, _ := context["cwd"].(string); !filepath.IsAbs(target) && cwd != "" {
+			target = filepath.Join(cwd, target)
+		}
+		for _, pattern := range p.paths {
+			if matchPath(pattern, filepath.Clean(target)) {
+				return fmt.Sprintf("write to protected path (%s)", pattern)
+			}
+		}
+	}
+	return ""
+}
+
+// matchPath reports whether path matches a protected path pattern
+func matchPath(pattern, path string) bool {
+	if strings.HasPrefix(pattern, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			pattern = filepath.Join(home, pattern[2:])
+		}
+	}
+
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		if filepath.IsAbs(dir) {
+			return strings.HasPrefix(path, filepath.Clean(dir)+string(filepath.Separator))
+		}
+		// A relative directory matches wherever it appears in the path
+		return strings.Contains("/"+filepath.ToSlash(path), "/"+filepath.ToSlash(dir)+"/")
+	}
+	if !strings.Contains(pattern, "/") {
+		matched, _ := filepath.Match(pattern, filepath.Base(path))
+		return matched
+	}
+	matched, _ := filepath.Match(pattern, path)
+	return matched
+}
+
+// NewCode returns a random confirmation code
+func NewCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", codeDigits, n.Int64()), nil
+}
+
+var (
+	mu      sync.RWMutex
+	current *Policy // nil when confirmation codes are off
+)
+
+func init() {
+	current, _ = New(nil, nil)
+}
+
+// Configure turns confirmation codes on or off, adding custom command and path patterns to
+// the built-in ones
+func Configure(enabled bool, commands map[string]string, paths []string) error {
+	var p *Policy
+	if enabled {
+		var err error
+		if p, err = New(commands, paths); err != nil {
+			return err
+		}
+	}
+	mu.Lock()
+	current = p
+	mu.Unlock()
+	return nil
+}
+
+// Assess checks a tool request against the configured policy
+func Assess(context map[string]interface{}) string {
+	mu.RLock()
+	p := current
+	mu.RUnlock()
+	if p == nil {
+		return ""
+	}
+	return p.Assess(context)
+}
@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name secrets are stored under in the OS keyring
+const keyringService = "claudetogo"
+
+// Names are the messenger settings that can be stored in the keyring
+var Names = []string{
+	"slack_token", "telegram_token", "webhook_url",
+	"response_api_secret", "slack_signing_secret", "telegram_secret_token",
+	"forward_token", "sentry_dsn",
+}
+
+// ErrNotFound is returned when a secret has not been stored
+var ErrNotFound = errors.New("secret not found in keyring")
+
+// Set stores a secret in the OS keyring (macOS Keychain, libsecret/Secret Service on Linux,
+// Windows Credential Manager)
+func Set(name, value string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("secret value must not be empty")
+	}
+	if err := keyring.Set(keyringService, name, value); err != nil {
+		return fmt.Errorf("failed to store %s in keyring: %w", name, err)
+	}
+	return nil
+}
+
+// Get retrieves a secret from the OS keyring, returning ErrNotFound if it isn't stored
+func Get(name string) (string, error) {
+	if err := checkName(name); err != nil {
+		return "", err
+	}
+	value, err := keyring.Get(keyringService, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from keyring: %w", name, err)
+	}
+	return value, nil
+}
+
+// Delete removes a secret from the OS keyring
+func Delete(name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	err := keyring.Delete(keyringService, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s from keyring: %w", name, err)
+	}
+	return nil
+}
+
+// checkName rejects names that don't correspond to a messenger setting
+func checkName(name string) error {
+	for _, known := range Names {
+		if name == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown secret %q (expected one of: %s)", name, strings.Join(Names, ", "))
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// role is what an API token or chat user may do; each role includes the ones below it
+type role int
+
+const (
+	roleNone     role = iota
+	roleViewer        // List pending actions and read session status
+	roleApprover      // Respond to a session
+	roleAdmin         // Respond to every pending action at once
+)
+
+// roleNames maps the roles in the messenger config to their values
+var roleNames = map[string]role{
+	"viewer":   roleViewer,
+	"approver": roleApprover,
+	"admin":    roleAdmin,
+}
+
+func (r role) String() string {
+	for name, value := range roleNames {
+		if value == r {
+			return name
+		}
+	}
+	return "none"
+}
+
+// accessEnabled reports whether roles are enforced: once any API token or chat user has a
+// role, everyone else has none
+func (a ResponseAuth) accessEnabled() bool {
+	return len(a.APITokens) > 0 || len(a.ChatRoles) > 0
+}
+
+// authorize checks that a request has at least role need, returning the HTTP status to
+// reject it with. chatUser identifies a Slack or Telegram user ("slack:<user ID>" or
+// "telegram:<user ID>"); other requests are identified by their bearer token.
+func (v *requestVerifier) authorize(r *http.Request, chatUser string, need role) (int, error) {
+	v.mu.Lock()
+	auth := v.auth
+	v.mu.Unlock()
+
+	if !auth.accessEnabled() {
+		return http.StatusOK, nil
+	}
+
+	var have role
+	var who string
+	if chatUser != "" {
+		have, who = roleNames[auth.ChatRoles[chatUser]], chatUser
+	} else {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			return http.StatusUnauthorized, fmt.Errorf("an API token is required (Authorization: Bearer <token>)")
+		}
+		for known, name := range auth.APITokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(known)) == 1 {
+				have = roleNames[name]
+			}
+		}
+		if have == roleNone {
+			return http.StatusUnauthorized, fmt.Errorf("unknown API token")
+		}
+		who = "API token"
+	}
+
+	if have < need {
+		return http.StatusForbidden, fmt.Errorf("%s has role %s; %s is required", who, have, need)
+	}
+	return http.StatusOK, nil
+}
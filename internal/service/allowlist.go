@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/channels"
+)
+
+// emailCacheTTL is how long a Slack user's looked-up email address is trusted
+const emailCacheTTL = time.Hour
+
+// ChatAllowlist limits who can respond from each chat service. An empty list lets anyone
+// in the chat respond.
+type ChatAllowlist struct {
+	Telegram   []string // Telegram user IDs
+	Slack      []string // Slack user IDs or email addresses
+	SlackToken string   // Bot token used to look up Slack users' email addresses
+}
+
+// chatGate checks chat users against the allowlists
+type chatGate struct {
+	mu        sync.Mutex // guards allowlist and emails
+	allowlist ChatAllowlist
+	emails    map[string]cachedEmail // Slack user ID -> email address
+	client    *http.Client
+}
+
+// cachedEmail is a looked-up email address and when it was looked up
+type cachedEmail struct {
+	email   string
+	fetched time.Time
+}
+
+// newChatGate creates a gate for allowlist
+func newChatGate(allowlist ChatAllowlist) *chatGate {
+	return &chatGate{
+		allowlist: allowlist,
+		emails:    make(map[string]cachedEmail),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// setAllowlist changes the allowed users
+func (g *chatGate) setAllowlist(allowlist ChatAllowlist) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.allowlist = allowlist
+}
+
+// allowed reports whether chatUser ("slack:<user ID>" or "telegram:<user ID>") may respond
+func (g *chatGate) allowed(ctx context.Context, chatUser string) (bool, error) {
+	g.mu.Lock()
+	allowlist := g.allowlist
+	g.mu.Unlock()
+
+	service, userID, _ := strings.Cut(chatUser, ":")
+	var allowed []string
+	switch service {
+	case "telegram":
+		allowed = allowlist.Telegram
+	case "slack":
+		allowed = allowlist.Slack
+	}
+	if len(allowed) == 0 {
+		return true, nil
+	}
+
+	needEmail := false
+	for _, entry := range allowed {
+		if entry == userID {
+			return true, nil
+		}
+		needEmail = needEmail || strings.Contains(entry, "@")
+	}
+	if service != "slack" || !needEmail {
+		return false, nil
+	}
+
+	email, err := g.slackEmail(ctx, allowlist.SlackToken, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range allowed {
+		if strings.EqualFold(entry, email) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// slackEmail returns a Slack user's email address, from the cache when it was looked up recently
+func (g *chatGate) slackEmail(ctx context.Context, token, userID string) (string, error) {
+	if token == "" {
+		return "", fmt.Errorf("integrations.slack_token is needed to look up Slack users' email addresses")
+	}
+
+	g.mu.Lock()
+	cached, ok := g.emails[userID]
+	g.mu.Unlock()
+	if ok && time.Since(cached.fetched) < emailCacheTTL {
+		return cached.email, nil
+	}
+
+	email, err := channels.SlackUserEmail(ctx, g.client, token, userID)
+	if err != nil {
+		return "", err
+	}
+	g.mu.Lock()
+	g.emails[userID] = cachedEmail{email: email, fetched: time.Now()}
+	g.mu.Unlock()
+	return email, nil
+}
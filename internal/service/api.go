@@ -0,0 +1,469 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/hooks"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/jsonl"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/responder"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/timeline"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// ResponseAPI serves pending actions and accepts responses over HTTP, so messenger
+// integrations can answer Claude without shelling out to `claudetogo --respond`
+type ResponseAPI struct {
+	addr       string
+	outputDir  string
+	eventsFile string // Hook events read for session timelines
+	tls        TLSConfig
+	responder  *responder.ResponseHandler
+	verifier   *requestVerifier
+	gate       *chatGate                     // Chat users allowed to respond (nil = anyone)
+	alert      func(*types.MessengerMessage) // Receives unauthorized attempts, if set
+	profiling  bool                          // Serve Go profiles at /debug/pprof/
+	logger     *logger.Logger
+	mu         sync.Mutex // serializes appends of forwarded events to the events file
+}
+
+// maxResponseSize bounds the body of a response request
+const maxResponseSize = 64 * 1024
+
+// respondRequest is the body of POST /api/respond
+type respondRequest struct {
+	SessionID string `json:"session_id"`
+	Action    string `json:"action"`
+	Code      string `json:"code,omitempty"` // Confirmation code, to approve a high-risk action
+
+	chatUser string // Slack or Telegram user who pressed the button
+}
+
+// respondAllRequest is the body of POST /api/respond-all
+type respondAllRequest struct {
+	Action string `json:"action"`
+}
+
+// respondAllResult reports which pending actions POST /api/respond-all answered
+type respondAllResult struct {
+	Action    string            `json:"action"`
+	Responded []string          `json:"responded"`
+	Failed    map[string]string `json:"failed,omitempty"` // Session ID -> error
+}
+
+// slackInteraction is the payload Slack posts when a button is clicked. The button's
+// action_id is the action and its value the session ID.
+type slackInteraction struct {
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// telegramUpdate is the update Telegram posts to a webhook. Callback data from an inline
+// keyboard button is "<action>:<session ID>".
+type telegramUpdate struct {
+	CallbackQuery *struct {
+		From struct {
+			ID int64 `json:"id"`
+		} `json:"from"`
+		Data string `json:"data"`
+	} `json:"callback_query"`
+}
+
+// NewResponseAPI creates a response API listening on addr (over HTTPS when tls is enabled),
+// answering from outputDir and accepting only responses verifier lets through
+func NewResponseAPI(addr, outputDir string, tls TLSConfig, verifier *requestVerifier, logger *logger.Logger) *ResponseAPI {
+	return &ResponseAPI{
+		addr:      addr,
+		outputDir: outputDir,
+		tls:       tls,
+		responder: responder.NewResponseHandler(outputDir, logger),
+		verifier:  verifier,
+		logger:    logger,
+	}
+}
+
+// Run serves the API until ctx is cancelled
+func (a *ResponseAPI) Run(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/health", a.handleHealth)
+	mux.HandleFunc("GET /api/pending", a.restrict(roleViewer, a.handlePending))
+	mux.HandleFunc("GET /api/sessions/{id}", a.restrict(roleViewer, a.handleSession))
+	mux.HandleFunc("GET /api/sessions/{id}/timeline", a.restrict(roleViewer, a.handleTimeline))
+	mux.HandleFunc("POST /api/respond", a.handleRespond)
+	mux.HandleFunc("POST /api/respond-all", a.handleRespondAll)
+	mux.HandleFunc("POST /api/events", a.handleEvents)
+	if a.profiling {
+		// Profiles reveal memory contents, so they take the highest role
+		mux.HandleFunc("GET /debug/pprof/", a.restrict(roleAdmin, pprof.Index))
+		mux.HandleFunc("GET /debug/pprof/cmdline", a.restrict(roleAdmin, pprof.Cmdline))
+		mux.HandleFunc("GET /debug/pprof/profile", a.restrict(roleAdmin, pprof.Profile))
+		mux.HandleFunc("GET /debug/pprof/symbol", a.restrict(roleAdmin, pprof.Symbol))
+		mux.HandleFunc("POST /debug/pprof/symbol", a.restrict(roleAdmin, pprof.Symbol))
+		mux.HandleFunc("GET /debug/pprof/trace", a.restrict(roleAdmin, pprof.Trace))
+		a.logger.Info("Serving profiles at /debug/pprof/")
+	}
+
+	server := &http.Server{
+		Addr:              a.addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	scheme := "http"
+	var tlsConfig *tls.Config
+	if a.tls.Enabled() {
+		config, certFile, err := a.tls.serverConfig(a.outputDir)
+		if err != nil {
+			return err
+		}
+		tlsConfig, scheme = config, "https"
+		a.logger.Info("Response API certificate: %s (SHA-256 %s)", certFile, fingerprint(config.Certificates[0].Certificate[0]))
+		if config.ClientCAs != nil {
+			a.logger.Info("Response API requires client certificates signed by %s", a.tls.ClientCAFile)
+		}
+	}
+
+	listener, err := net.Listen("tcp", a.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", a.addr, err)
+	}
+
+	// The API approves tool use, so it should not be reachable from other machines by accident
+	if tcpAddr, ok := listener.Addr().(*net.TCPAddr); ok && !tcpAddr.IP.IsLoopback() {
+		if !a.verifier.authenticated() && a.tls.ClientCAFile == "" {
+			a.logger.Warn("Response API is listening on non-loopback address %s without authentication", listener.Addr())
+		}
+		if tlsConfig == nil {
+			a.logger.Warn("Response API is listening on non-loopback address %s without TLS", listener.Addr())
+		}
+	}
+	a.logger.Info("Response API listening on %s://%s", scheme, listener.Addr())
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("response API failed: %w", err)
+	}
+	return nil
+}
+
+// handleHealth reports that the service is up
+func (a *ResponseAPI) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handlePending lists actions waiting for a response
+func (a *ResponseAPI) handlePending(w http.ResponseWriter, r *http.Request) {
+	pending, err := a.responder.ListPendingActions()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ingestError{Error: err.Error()})
+		return
+	}
+	if pending == nil {
+		pending = []*responder.PendingAction{}
+	}
+	writeJSON(w, http.StatusOK, pending)
+}
+
+// handleSession returns the status of one session
+func (a *ResponseAPI) handleSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("id")
+	if len(sessionID) < 8 {
+		writeJSON(w, http.StatusBadRequest, ingestError{Error: "session ID must be at least 8 characters"})
+		return
+	}
+
+	status, err := a.responder.GetSessionStatus(sessionID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, ingestError{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleTimeline answers GET /api/sessions/{id}/timeline with the session's activity timeline
+func (a *ResponseAPI) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("id")
+	if len(sessionID) < 8 {
+		writeJSON(w, http.StatusBadRequest, ingestError{Error: "session ID must be at least 8 characters"})
+		return
+	}
+	if fullID, err := a.responder.ResolveSession(sessionID); err == nil {
+		sessionID = fullID
+	}
+
+	tl, err := timeline.Build(sessionID, timeline.Sources{OutputDir: a.outputDir, EventsFile: a.eventsFile})
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, ingestError{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, tl)
+}
+
+// handleEvents accepts a hook event forwarded by `claudetogo --hook --forward`, appends it to
+// the events file and replies with the hook response
+func (a *ResponseAPI) handleEvents(w http.ResponseWriter, r *http.Request) {
+	body, _, ok := a.readSigned(w, r, jsonl.MaxLineSize())
+	if !ok {
+		return
+	}
+	if !a.authorize(w, r, "", roleApprover) {
+		return
+	}
+
+	var event types.ClaudeHookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		writeJSON(w, http.StatusBadRequest, ingestError{Error: fmt.Sprintf("invalid hook event: %v", err)})
+		return
+	}
+	if err := hooks.Validate(&event); err != nil {
+		writeJSON(w, http.StatusBadRequest, ingestError{Error: fmt.Sprintf("invalid hook event: %v", err)})
+		return
+	}
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().Format(time.RFC3339)
+	}
+
+	a.mu.Lock()
+	err := hooks.SaveEvent(event, types.Config{LogFile: a.eventsFile}, a.logger)
+	a.mu.Unlock()
+	if err != nil {
+		a.logger.WithEvent(event).Error("Failed to save forwarded event: %v", err)
+		writeJSON(w, http.StatusInternalServerError, ingestError{Error: err.Error()})
+		return
+	}
+
+	a.logger.WithEvent(event).Debug("Response API: received %s event from %s", event.HookEventName, r.RemoteAddr)
+	writeJSON(w, http.StatusOK, hooks.ProcessEvent(event, a.logger))
+}
+
+// restrict wraps a handler so only API tokens with at least role need can call it
+func (a *ResponseAPI) restrict(need role, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.authorize(w, r, "", need) {
+			handler(w, r)
+		}
+	}
+}
+
+// authorize checks a request's role, answering it with an error if the role is too low
+func (a *ResponseAPI) authorize(w http.ResponseWriter, r *http.Request, chatUser string, need role) bool {
+	status, err := a.verifier.authorize(r, chatUser, need)
+	if err != nil {
+		a.logger.Warn("Response API: denied %s %s from %s: %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+		writeJSON(w, status, ingestError{Error: err.Error()})
+		return false
+	}
+	return true
+}
+
+// readSigned reads a request body and verifies its signature, answering the request with an
+// error if either fails
+func (a *ResponseAPI) readSigned(w http.ResponseWriter, r *http.Request, limit int64) ([]byte, string, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ingestError{Error: fmt.Sprintf("invalid request: %v", err)})
+		return nil, "", false
+	}
+
+	source, err := a.verifier.verify(r, body, time.Now())
+	if err != nil {
+		a.logger.Warn("Response API: rejected request from %s: %v", r.RemoteAddr, err)
+		writeJSON(w, http.StatusUnauthorized, ingestError{Error: err.Error()})
+		return nil, "", false
+	}
+	return body, source, true
+}
+
+// handleRespond executes an action for a session, from a ClaudeToGo request, a Slack button
+// or a Telegram inline keyboard button
+func (a *ResponseAPI) handleRespond(w http.ResponseWriter, r *http.Request) {
+	body, source, ok := a.readSigned(w, r, maxResponseSize)
+	if !ok {
+		return
+	}
+
+	req, err := decodeResponse(source, body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ingestError{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+	if req == nil {
+		// Telegram posts every update to the webhook, not only button presses
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+		return
+	}
+	if len(req.SessionID) < 8 || req.Action == "" {
+		writeJSON(w, http.StatusBadRequest, ingestError{Error: "session_id (at least 8 characters) and action are required"})
+		return
+	}
+	if req.chatUser != "" && a.gate != nil {
+		allowed, err := a.gate.allowed(r.Context(), req.chatUser)
+		if err != nil {
+			a.logger.Warn("Response API: failed to check %s against the allowlist: %v", req.chatUser, err)
+		}
+		if !allowed {
+			a.rejectChatUser(w, r, req)
+			return
+		}
+	}
+	if !a.authorize(w, r, req.chatUser, roleApprover) {
+		return
+	}
+
+	if err := a.responder.HandleConfirmedResponse(req.SessionID, req.Action, "", req.Code); err != nil {
+		status := http.StatusUnprocessableEntity
+		switch {
+		case errors.Is(err, responder.ErrConfirmationRequired):
+			status = http.StatusPreconditionRequired
+		case errors.Is(err, responder.ErrWrongConfirmationCode):
+			status = http.StatusForbidden
+		}
+		writeJSON(w, status, ingestError{Error: err.Error()})
+		return
+	}
+
+	a.logger.With("session_id", req.SessionID, "action", req.Action, "source", source).Info("Response API: %s for session %s", req.Action, req.SessionID)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "session_id": req.SessionID, "action": req.Action})
+}
+
+// rejectChatUser refuses a response from a chat user who isn't on the allowlist, logging the
+// attempt and alerting the chat about it
+func (a *ResponseAPI) rejectChatUser(w http.ResponseWriter, r *http.Request, req *respondRequest) {
+	a.logger.With("chat_user", req.chatUser, "session_id", req.SessionID, "action", req.Action).
+		Warn("Response API: %s is not allowed to respond (tried to %s session %s)", req.chatUser, req.Action, req.SessionID)
+	writeJSON(w, http.StatusForbidden, ingestError{Error: fmt.Sprintf("%s is not allowed to respond", req.chatUser)})
+
+	if a.alert == nil {
+		return
+	}
+	now := time.Now()
+	a.alert(&types.MessengerMessage{
+		Type:      "alert",
+		Title:     "🚫 Unauthorized response attempt",
+		Message:   fmt.Sprintf("%s tried to %s session %s but is not on the allowlist.", req.chatUser, req.Action, req.SessionID),
+		Priority:  "high",
+		Timestamp: now.Format(time.RFC3339),
+		MessageID: fmt.Sprintf("unauthorized-%d", now.UnixNano()),
+		Context: map[string]interface{}{
+			"chat_user":   req.chatUser,
+			"action":      req.Action,
+			"session_id":  req.SessionID,
+			"remote_addr": r.RemoteAddr,
+		},
+	})
+}
+
+// handleRespondAll executes an action for every pending action, such as approving them all
+func (a *ResponseAPI) handleRespondAll(w http.ResponseWriter, r *http.Request) {
+	body, _, ok := a.readSigned(w, r, maxResponseSize)
+	if !ok {
+		return
+	}
+
+	var req respondAllRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ingestError{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+	if req.Action == "" {
+		writeJSON(w, http.StatusBadRequest, ingestError{Error: "action is required"})
+		return
+	}
+	if !a.authorize(w, r, "", roleAdmin) {
+		return
+	}
+
+	pending, err := a.responder.ListPendingActions()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ingestError{Error: err.Error()})
+		return
+	}
+
+	result := respondAllResult{Action: req.Action, Responded: []string{}}
+	for _, action := range pending {
+		if err := a.responder.HandleResponse(action.SessionID, req.Action); err != nil {
+			if result.Failed == nil {
+				result.Failed = make(map[string]string)
+			}
+			result.Failed[action.SessionID] = err.Error()
+			continue
+		}
+		result.Responded = append(result.Responded, action.SessionID)
+	}
+
+	a.logger.With("action", req.Action).Info("Response API: %s for %d pending action(s), %d failed", req.Action, len(result.Responded), len(result.Failed))
+	writeJSON(w, http.StatusOK, result)
+}
+
+// decodeResponse reads the session and action from a request body in the format of its
+// source. It returns nil for a Telegram update that isn't a button press.
+func decodeResponse(source string, body []byte) (*respondRequest, error) {
+	switch source {
+	case sourceSlack:
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		var interaction slackInteraction
+		if err := json.Unmarshal([]byte(form.Get("payload")), &interaction); err != nil {
+			return nil, fmt.Errorf("invalid Slack payload: %w", err)
+		}
+		if len(interaction.Actions) == 0 {
+			return nil, fmt.Errorf("Slack payload has no actions")
+		}
+		action := interaction.Actions[0]
+		return &respondRequest{SessionID: action.Value, Action: action.ActionID, chatUser: "slack:" + interaction.User.ID}, nil
+
+	case sourceTelegram:
+		var update telegramUpdate
+		if err := json.Unmarshal(body, &update); err != nil {
+			return nil, err
+		}
+		if update.CallbackQuery == nil {
+			return nil, nil
+		}
+		action, sessionID, _ := strings.Cut(update.CallbackQuery.Data, ":")
+		chatUser := "telegram:" + strconv.FormatInt(update.CallbackQuery.From.ID, 10)
+		return &respondRequest{SessionID: sessionID, Action: action, chatUser: chatUser}, nil
+	}
+
+	var req respondRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
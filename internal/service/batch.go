@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// batchDetailLength is how many characters of each batched message's text its detail line keeps
+const batchDetailLength = 300
+
+// routeKey groups messages that are delivered to the same destinations
+type routeKey struct{ project, owner string }
+
+// routeKeyOf returns the group a message is delivered with
+func routeKeyOf(message *types.MessengerMessage) routeKey {
+	project, _ := message.Context["project"].(string)
+	owner, _ := message.Context["owner"].(string)
+	return routeKey{project, owner}
+}
+
+// batcher holds messages arriving within the batch window, so each group of destinations
+// gets one notification for them instead of one per message
+type batcher struct {
+	order []routeKey // Groups in the order their first message arrived
+	held  map[routeKey][]*types.MessengerMessage
+}
+
+// add holds a message and returns its group and how many messages the group now holds
+func (b *batcher) add(message *types.MessengerMessage) (routeKey, int) {
+	if b.held == nil {
+		b.held = make(map[routeKey][]*types.MessengerMessage)
+	}
+	key := routeKeyOf(message)
+	if _, ok := b.held[key]; !ok {
+		b.order = append(b.order, key)
+	}
+	b.held[key] = append(b.held[key], message)
+	return key, len(b.held[key])
+}
+
+// take returns the messages held for one group and forgets them
+func (b *batcher) take(key routeKey) []*types.MessengerMessage {
+	held := b.held[key]
+	delete(b.held, key)
+	for i, k := range b.order {
+		if k == key {
+			b.order = append(b.order[:i], b.order[i+1:]...)
+			break
+		}
+	}
+	return held
+}
+
+// groups returns the groups holding messages, oldest first
+func (b *batcher) groups() []routeKey {
+	return append([]routeKey(nil), b.order...)
+}
+
+// batchSummary combines the messages of one group that arrived within the batch window into
+// one notification: a summary of what happened, with a detail line per message that chat
+// services can show collapsed
+func batchSummary(held []*types.MessengerMessage, key routeKey, now time.Time) *types.MessengerMessage {
+	sessions := make(map[string]int)
+	ids := make([]string, 0, len(held))
+	details := make([]string, 0, len(held))
+	for _, message := range held {
+		sessions[message.SessionID]++
+		ids = append(ids, message.MessageID)
+		detail := message.Title
+		if text := strings.TrimSpace(message.Message); text != "" {
+			detail += ": " + formatter.Truncate(strings.Join(strings.Fields(text), " "), batchDetailLength)
+		}
+		details = append(details, detail)
+	}
+
+	var text strings.Builder
+	if len(sessions) == 1 {
+		fmt.Fprintf(&text, "%d updates from session %s", len(held), session.Short(held[0].SessionID))
+	} else {
+		fmt.Fprintf(&text, "%d updates from %d sessions", len(held), len(sessions))
+	}
+	for i, message := range held {
+		if i == floodSummaryTitles {
+			fmt.Fprintf(&text, "\n…and %d more", len(held)-floodSummaryTitles)
+			break
+		}
+		fmt.Fprintf(&text, "\n• %s", message.Title)
+	}
+
+	// The batch is as urgent as its most urgent message
+	rank := map[string]int{"low": 1, "medium": 2, "high": 3}
+	priority := "low"
+	for _, message := range held {
+		if rank[message.Priority] > rank[priority] {
+			priority = message.Priority
+		}
+	}
+
+	summary := &types.MessengerMessage{
+		Type:      "batch",
+		Title:     fmt.Sprintf("📦 %d notifications", len(held)),
+		Message:   text.String(),
+		Priority:  priority,
+		Timestamp: now.Format(time.RFC3339),
+		MessageID: fmt.Sprintf("batch-%d", now.UnixNano()),
+		Context: map[string]interface{}{
+			"batched":     len(held),
+			"message_ids": ids,
+			"sessions":    sessions,
+			"details":     details,
+		},
+	}
+	if key.project != "" {
+		summary.Title = fmt.Sprintf("📦 %d notifications from %s", len(held), key.project)
+		summary.Context["project"] = key.project
+	}
+	if key.owner != "" {
+		summary.Context["owner"] = key.owner
+	}
+	if len(sessions) == 1 {
+		summary.SessionID = held[0].SessionID
+		summary.ThreadID = held[0].ThreadID
+	}
+	return summary
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/channels"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// deliveryQueueSize is how many messages may wait for delivery before new ones are dropped
+const deliveryQueueSize = 100
+
+// shutdownFlushTimeout bounds delivering the batched and flood-held messages on shutdown
+const shutdownFlushTimeout = 10 * time.Second
+
+// DeliveryConfig configures delivery of new messages to a webhook, a Telegram chat and/or a
+// Slack channel; a destination is used when all its settings are present
+type DeliveryConfig struct {
+	WebhookURL     string
+	Headers        map[string]string
+	WebhookKey     string // PEM public key file the webhook payload is encrypted for (empty = plain JSON)
+	TelegramToken  string
+	TelegramChatID string
+	SlackToken     string
+	SlackChannel   string
+	RetryAttempts  int
+	RetryInterval  time.Duration
+	Timeout        time.Duration
+	RateLimits     map[string]int // Messages per minute by channel name (missing or 0 = unlimited)
+	FloodThreshold int            // Messages within FloodWindow before the rest are summarized (0 = off)
+	FloodWindow    time.Duration
+	BatchWindow    time.Duration           // Messages within this window are sent as one notification (0 = off)
+	BatchMaxSize   int                     // Messages in a batch before it is sent early (0 = no limit)
+	Projects       map[string]ProjectRoute // Destinations of messages by project name
+	Users          map[string]UserRoute    // Chats of the users owning sessions, by user name
+	Escalation     UserRoute               // With Users set: chats for messages no user owns (empty = global settings)
+	Threads        *channels.Threads       // Thread roots for Telegram and Slack (nil = messages aren't threaded); kept across SetConfig
+}
+
+// UserRoute sends the messages of one user's sessions to their own chats; empty fields keep
+// the project's or global settings
+type UserRoute struct {
+	TelegramChatID string
+	SlackChannel   string
+}
+
+// ProjectRoute sends one project's messages to a subset of the channels and/or other
+// chats; empty fields keep the global settings
+type ProjectRoute struct {
+	Channels       []string // Channel names to use (empty = all configured)
+	WebhookURL     string
+	TelegramChatID string
+	SlackChannel   string
+}
+
+// Channels returns the configured destinations
+func (c DeliveryConfig) Channels() []channels.Channel {
+	var targets []channels.Channel
+	if c.WebhookURL != "" {
+		targets = append(targets, &channels.Webhook{URL: c.WebhookURL, Headers: c.Headers, PublicKey: c.WebhookKey})
+	}
+	if c.TelegramToken != "" && c.TelegramChatID != "" {
+		targets = append(targets, &channels.Telegram{Token: c.TelegramToken, ChatID: c.TelegramChatID, Threads: c.Threads})
+	}
+	if c.SlackToken != "" && c.SlackChannel != "" {
+		targets = append(targets, &channels.Slack{Token: c.SlackToken, Channel: c.SlackChannel, Threads: c.Threads})
+	}
+	return targets
+}
+
+// ChannelsFor returns the destinations of a message, following its project's route and then
+// its owner's: a message of a user's session goes to that user's chats, one no user owns to
+// the escalation chats
+func (c DeliveryConfig) ChannelsFor(message *types.MessengerMessage) []channels.Channel {
+	project, _ := message.Context["project"].(string)
+	route, ok := c.Projects[project]
+	if ok {
+		if route.WebhookURL != "" {
+			c.WebhookURL = route.WebhookURL
+		}
+		if route.TelegramChatID != "" {
+			c.TelegramChatID = route.TelegramChatID
+		}
+		if route.SlackChannel != "" {
+			c.SlackChannel = route.SlackChannel
+		}
+	}
+
+	if len(c.Users) > 0 {
+		owner, _ := message.Context["owner"].(string)
+		user, owned := c.Users[owner]
+		if !owned {
+			user = c.Escalation
+		}
+		if user.TelegramChatID != "" {
+			c.TelegramChatID = user.TelegramChatID
+		}
+		if user.SlackChannel != "" {
+			c.SlackChannel = user.SlackChannel
+		}
+	}
+
+	targets := c.Channels()
+	if !ok || len(route.Channels) == 0 {
+		return targets
+	}
+
+	var routed []channels.Channel
+	for _, channel := range targets {
+		if slices.Contains(route.Channels, channel.Name()) {
+			routed = append(routed, channel)
+		}
+	}
+	return routed
+}
+
+// Deliverer sends messenger messages to the configured channels from a queue, retrying failures
+type Deliverer struct {
+	mu       sync.Mutex // guards config and client, which change on reload
+	config   DeliveryConfig
+	client   *http.Client
+	queue    chan *types.MessengerMessage
+	limiter  *rateLimiter
+	flood    floodGuard // Only used by Run
+	batch    batcher    // Only used by Run
+	logger   *logger.Logger
+	onResult func(message *types.MessengerMessage, err error, took time.Duration)
+}
+
+// NewDeliverer creates a deliverer. onResult, if set, is called after each delivery attempt completes.
+func NewDeliverer(config DeliveryConfig, logger *logger.Logger, onResult func(message *types.MessengerMessage, err error, took time.Duration)) *Deliverer {
+	d := &Deliverer{
+		queue:    make(chan *types.MessengerMessage, deliveryQueueSize),
+		limiter:  newRateLimiter(),
+		logger:   logger,
+		onResult: onResult,
+	}
+	d.SetConfig(config)
+	return d
+}
+
+// SetConfig changes where and how messages are delivered; queued messages use the new settings
+func (d *Deliverer) SetConfig(config DeliveryConfig) {
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.RetryAttempts < 0 {
+		config.RetryAttempts = 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if config.Threads == nil {
+		config.Threads = d.config.Threads
+	}
+	d.config = config
+	d.client = &http.Client{Timeout: config.Timeout}
+}
+
+// settings returns the current delivery configuration and client
+func (d *Deliverer) settings() (DeliveryConfig, *http.Client) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.config, d.client
+}
+
+// Enqueue queues a message for delivery without blocking; the message is dropped if the queue is full
+func (d *Deliverer) Enqueue(message *types.MessengerMessage) {
+	select {
+	case d.queue <- message:
+	default:
+		err := fmt.Errorf("delivery queue full, dropped message %s", message.MessageID)
+		d.logger.Error("%v", err)
+		if d.onResult != nil {
+			d.onResult(message, err, 0)
+		}
+	}
+}
+
+// QueueDepth returns how many messages are waiting for delivery
+func (d *Deliverer) QueueDepth() int {
+	return len(d.queue)
+}
+
+// Run delivers queued messages until ctx is cancelled
+func (d *Deliverer) Run(ctx context.Context) error {
+	config, _ := d.settings()
+	var names []string
+	for _, channel := range config.Channels() {
+		names = append(names, channel.Name())
+	}
+	d.logger.Info("Delivering messages to %s", strings.Join(names, ", "))
+
+	// flush fires at the end of a flood window, when held messages are sent as a summary;
+	// flushBatch at the end of a batch window, when batched messages are sent
+	var flush, flushBatch <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			d.flushPending()
+			return nil
+		case message := <-d.queue:
+			config, _ := d.settings()
+			if !d.flood.admit(message, config.FloodThreshold, config.FloodWindow, time.Now()) {
+				if flush == nil {
+					d.logger.Warn("More than %d messages in %v, holding the rest for a summary", config.FloodThreshold, config.FloodWindow)
+					flush = time.After(config.FloodWindow)
+				}
+				continue
+			}
+			// Messages waiting for a response are never batched, so their actions stay usable
+			if config.BatchWindow <= 0 || message.Type == "action_needed" {
+				d.send(ctx, message)
+				continue
+			}
+			key, size := d.batch.add(message)
+			if config.BatchMaxSize > 0 && size >= config.BatchMaxSize {
+				d.sendBatch(ctx, key)
+			}
+			if flushBatch == nil {
+				flushBatch = time.After(config.BatchWindow)
+			}
+		case <-flush:
+			flush = nil
+			d.sendHeld(ctx)
+		case <-flushBatch:
+			flushBatch = nil
+			for _, key := range d.batch.groups() {
+				d.sendBatch(ctx, key)
+			}
+		}
+	}
+}
+
+// flushPending delivers the batched and flood-held messages when Run stops; ctx is already
+// cancelled then, so they get a fresh, short one
+func (d *Deliverer) flushPending() {
+	if len(d.batch.groups()) == 0 && len(d.flood.held) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownFlushTimeout)
+	defer cancel()
+	for _, key := range d.batch.groups() {
+		d.sendBatch(ctx, key)
+	}
+	d.sendHeld(ctx)
+}
+
+// sendBatch delivers the messages batched for one group: a lone message as it is, several
+// as one notification
+func (d *Deliverer) sendBatch(ctx context.Context, key routeKey) {
+	held := d.batch.take(key)
+	if len(held) == 1 {
+		d.send(ctx, held[0])
+		return
+	}
+	d.sendCombined(ctx, held, batchSummary(held, key, time.Now()))
+}
+
+// send delivers one message and reports the result
+func (d *Deliverer) send(ctx context.Context, message *types.MessengerMessage) {
+	started := time.Now()
+	err := d.deliver(ctx, message)
+	took := time.Since(started)
+	if err != nil {
+		d.logger.Error("Failed to deliver message %s: %v", message.MessageID, err)
+	} else {
+		d.logger.Debug("Delivered message %s in %v", message.MessageID, took.Round(time.Millisecond))
+	}
+	if d.onResult != nil {
+		d.onResult(message, err, took)
+	}
+}
+
+// sendHeld delivers the messages held during a flood, one summary per project and owner so
+// each goes where those messages are routed
+func (d *Deliverer) sendHeld(ctx context.Context) {
+	var groups []routeKey
+	byGroup := make(map[routeKey][]*types.MessengerMessage)
+	for _, message := range d.flood.release() {
+		key := routeKeyOf(message)
+		if _, seen := byGroup[key]; !seen {
+			groups = append(groups, key)
+		}
+		byGroup[key] = append(byGroup[key], message)
+	}
+	for _, key := range groups {
+		d.sendSummary(ctx, key.project, key.owner, byGroup[key])
+	}
+}
+
+// sendSummary delivers held messages: a lone message as it is, several as one summary whose
+// result is reported for each of them
+func (d *Deliverer) sendSummary(ctx context.Context, project, owner string, held []*types.MessengerMessage) {
+	if len(held) == 1 {
+		d.send(ctx, held[0])
+		return
+	}
+
+	config, _ := d.settings()
+	summary := floodSummary(held, project, config.FloodWindow, time.Now())
+	if owner != "" {
+		summary.Context["owner"] = owner
+	}
+	d.sendCombined(ctx, held, summary)
+}
+
+// sendCombined delivers one message standing in for several and reports its result for each
+// of them
+func (d *Deliverer) sendCombined(ctx context.Context, held []*types.MessengerMessage, combined *types.MessengerMessage) {
+	started := time.Now()
+	err := d.deliver(ctx, combined)
+	took := time.Since(started)
+	if err != nil {
+		d.logger.Error("Failed to deliver %s of %d messages: %v", combined.Type, len(held), err)
+	} else {
+		d.logger.Info("Delivered %d messages as one %s", len(held), combined.Type)
+	}
+	if d.onResult != nil {
+		for _, message := range held {
+			d.onResult(message, err, took)
+		}
+	}
+}
+
+// deliver sends a message to every channel, retrying the channels that failed up to
+// RetryAttempts times
+func (d *Deliverer) deliver(ctx context.Context, message *types.MessengerMessage) error {
+	config, client := d.settings()
+	pending := config.ChannelsFor(message)
+	if len(pending) == 0 {
+		project, _ := message.Context["project"].(string)
+		return fmt.Errorf("no configured channel for project %s", project)
+	}
+
+	message = withConfirmationCode(message)
+	var errs []error
+	for attempt := 0; attempt <= config.RetryAttempts && len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(config.RetryInterval * time.Duration(attempt)):
+			}
+		}
+
+		var failed []channels.Channel
+		errs = nil
+		for _, channel := range pending {
+			if err := d.wait(ctx, channel.Name(), config.RateLimits[channel.Name()]); err != nil {
+				return err
+			}
+			if err := channel.Send(ctx, client, message); err != nil {
+				d.logger.Debug("Delivery attempt %d to %s failed: %v", attempt+1, channel.Name(), err)
+				failed = append(failed, channel)
+				errs = append(errs, fmt.Errorf("%s: %w", channel.Name(), err))
+			}
+		}
+		pending = failed
+	}
+
+	return errors.Join(errs...)
+}
+
+// withConfirmationCode returns the message as it is sent: a high-risk request's one-time
+// code, which the stored message leaves out, is added to its text
+func withConfirmationCode(message *types.MessengerMessage) *types.MessengerMessage {
+	if message.ConfirmationCode == "" {
+		return message
+	}
+	sent := *message
+	sent.Message += "\nConfirmation code: " + message.ConfirmationCode
+	return &sent
+}
+
+// wait blocks until the channel's rate limit allows another message
+func (d *Deliverer) wait(ctx context.Context, channel string, perMinute int) error {
+	delay := d.limiter.reserve(channel, perMinute, time.Now())
+	if delay <= 0 {
+		return nil
+	}
+	d.logger.Debug("Rate limit of %d/min reached for %s, waiting %v", perMinute, channel, delay.Round(time.Millisecond))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// HeartbeatConfig configures the service heartbeat and stall detection
+type HeartbeatConfig struct {
+	Interval       time.Duration // How often the status file is refreshed and PingURL is called
+	PingURL        string        // Optional URL requested on every heartbeat (e.g. an uptime monitor)
+	StallThreshold time.Duration // Alert when sessions stay active this long without hook events (0 = off)
+	TranscriptsDir string        // Where Claude Code keeps session transcripts (<dir>/<project>/<session>.jsonl)
+}
+
+// Heartbeat periodically proves the service is alive and watches for Claude sessions that
+// keep running while no hook events arrive, which usually means the hooks are misconfigured
+type Heartbeat struct {
+	mu      sync.Mutex // guards config, which changes on reload
+	config  HeartbeatConfig
+	watcher *EventWatcher
+	alert   func(*types.MessengerMessage)
+	client  *http.Client
+	logger  *logger.Logger
+
+	started      time.Time
+	stalledSince time.Time
+}
+
+// NewHeartbeat creates a heartbeat for the watcher's events file. alert, if set, receives stall alerts.
+func NewHeartbeat(config HeartbeatConfig, watcher *EventWatcher, alert func(*types.MessengerMessage), logger *logger.Logger) *Heartbeat {
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+	config.TranscriptsDir = ExpandHome(config.TranscriptsDir)
+
+	return &Heartbeat{
+		config:  config,
+		watcher: watcher,
+		alert:   alert,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		logger:  logger,
+		started: time.Now(),
+	}
+}
+
+// SetConfig changes the ping URL, stall threshold and transcripts directory; the interval is kept
+func (h *Heartbeat) SetConfig(config HeartbeatConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	config.Interval = h.config.Interval
+	config.TranscriptsDir = ExpandHome(config.TranscriptsDir)
+	h.config = config
+}
+
+// Run beats every interval until ctx is cancelled
+func (h *Heartbeat) Run(ctx context.Context) error {
+	h.mu.Lock()
+	interval := h.config.Interval
+	h.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	h.beat(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			h.beat(ctx)
+		}
+	}
+}
+
+// beat checks for a stall, records the heartbeat and pings the configured URL
+func (h *Heartbeat) beat(ctx context.Context) {
+	h.mu.Lock()
+	config := h.config
+	h.mu.Unlock()
+
+	now := time.Now()
+	lastEvent := modTime(h.watcher.eventsFile)
+
+	if config.StallThreshold > 0 {
+		h.checkStall(config, now, lastEvent)
+	} else {
+		h.stalledSince = time.Time{}
+	}
+
+	h.watcher.recordHeartbeat(now, config.Interval, lastEvent, h.stalledSince)
+
+	if config.PingURL != "" {
+		if err := h.ping(ctx, config.PingURL); err != nil {
+			h.logger.Error("Heartbeat ping failed: %v", err)
+		}
+	}
+}
+
+// checkStall alerts once when sessions are active but no hook event has arrived for
+// longer than the threshold, and notes when events start arriving again
+func (h *Heartbeat) checkStall(config HeartbeatConfig, now, lastEvent time.Time) {
+	transcript, lastActivity := LatestTranscript(config.TranscriptsDir)
+
+	// A session counts as active while its transcript is still being written
+	// Events before the service started can't be observed, so the stall is measured from the later of the two
+	baseline := lastEvent
+	if baseline.Before(h.started) {
+		baseline = h.started
+	}
+	active := !lastActivity.IsZero() && now.Sub(lastActivity) < config.StallThreshold
+	stalled := active && lastActivity.Sub(baseline) > config.StallThreshold
+
+	switch {
+	case stalled && h.stalledSince.IsZero():
+		h.stalledSince = now
+		h.raiseStallAlert(now, lastEvent, lastActivity, transcript)
+	case !stalled && !h.stalledSince.IsZero():
+		h.logger.Info("Hook events are arriving again after a stall of %v", now.Sub(h.stalledSince).Round(time.Second))
+		h.stalledSince = time.Time{}
+	}
+}
+
+// raiseStallAlert logs a stall and sends it through the alert channel
+func (h *Heartbeat) raiseStallAlert(now, lastEvent, lastActivity time.Time, transcript string) {
+	since := "never"
+	if !lastEvent.IsZero() {
+		since = lastEvent.Format(time.RFC3339)
+	}
+	text := fmt.Sprintf("Claude sessions are active (transcript %s updated %s) but no hook events have been received since %s. "+
+		"Check the hooks in ~/.claude/settings.json.", filepath.Base(transcript), lastActivity.Format(time.RFC3339), since)
+
+	h.logger.Warn("Possible hook stall: %s", text)
+
+	if h.alert == nil {
+		return
+	}
+	h.alert(&types.MessengerMessage{
+		Type:      "alert",
+		Title:     "⚠️ No hook events received",
+		Message:   text,
+		Priority:  "high",
+		Timestamp: now.Format(time.RFC3339),
+		MessageID: fmt.Sprintf("stall-%d", now.Unix()),
+		Context: map[string]interface{}{
+			"events_file":     h.watcher.eventsFile,
+			"last_hook_event": since,
+			"last_activity":   lastActivity.Format(time.RFC3339),
+			"transcript_path": transcript,
+		},
+	})
+}
+
+// ping requests the heartbeat URL
+func (h *Heartbeat) ping(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "claudetogo")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("heartbeat URL returned %s", resp.Status)
+	}
+	return nil
+}
+
+// LatestTranscript returns the most recently written transcript under dir and its modification time
+func LatestTranscript(dir string) (string, time.Time) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*", "*.jsonl"))
+	if err != nil {
+		return "", time.Time{}
+	}
+
+	var latest string
+	var latestTime time.Time
+	for _, path := range matches {
+		if t := modTime(path); t.After(latestTime) {
+			latest, latestTime = path, t
+		}
+	}
+	return latest, latestTime
+}
+
+// modTime returns a file's modification time, or the zero time if it can't be read
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
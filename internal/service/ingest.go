@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/hooks"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// ingestTimeout bounds how long a single hook connection may take
+const ingestTimeout = 10 * time.Second
+
+// IngestServer accepts hook events on a local Unix socket, appends them to the events
+// file and replies with the hook response, so hooks can hand events to a running service
+type IngestServer struct {
+	socketPath string
+	config     types.Config
+	logger     *logger.Logger
+	mu         sync.Mutex // serializes appends to the events file
+}
+
+// ingestError is written back to the client when an event can't be accepted
+type ingestError struct {
+	Error string `json:"error"`
+}
+
+// NewIngestServer creates an ingest server writing events to eventsFile
+func NewIngestServer(socketPath, eventsFile string, logger *logger.Logger) *IngestServer {
+	return &IngestServer{
+		socketPath: socketPath,
+		config:     types.Config{LogFile: eventsFile},
+		logger:     logger,
+	}
+}
+
+// Run listens for hook events until ctx is cancelled
+func (s *IngestServer) Run(ctx context.Context) error {
+	// A socket left behind by a previous run would make Listen fail
+	if err := os.Remove(s.socketPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", s.socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.socketPath, err)
+	}
+	defer os.Remove(s.socketPath)
+
+	// Only the owning user may submit events
+	if err := os.Chmod(s.socketPath, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+
+	s.logger.Info("Accepting hook events on %s", s.socketPath)
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			s.logger.Error("Failed to accept hook connection: %v", err)
+			continue
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle reads one hook event from a connection and writes back the hook response
+func (s *IngestServer) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ingestTimeout))
+
+	encoder := json.NewEncoder(conn)
+
+	var event types.ClaudeHookEvent
+	if err := json.NewDecoder(conn).Decode(&event); err != nil {
+		encoder.Encode(ingestError{Error: fmt.Sprintf("invalid hook event: %v", err)})
+		return
+	}
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().Format(time.RFC3339)
+	}
+
+	s.mu.Lock()
+	err := hooks.SaveEvent(event, s.config, s.logger)
+	s.mu.Unlock()
+	if err != nil {
+		s.logger.WithEvent(event).Error("Failed to save ingested event: %v", err)
+		encoder.Encode(ingestError{Error: err.Error()})
+		return
+	}
+
+	// Decide how Claude Code should proceed
+	response := hooks.ProcessEvent(event, s.logger)
+	if err := encoder.Encode(response); err != nil {
+		s.logger.Debug("Failed to send hook response: %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// rateWindow is how far back events per second are averaged
+const rateWindow = time.Minute
+
+// Stages lists the stages whose latency is measured, in the order an event goes through them
+var Stages = []string{"transcript_read", "extraction", "formatting", "write", "delivery"}
+
+// Metrics collects the service's rates, counters and latencies. It is kept in memory and
+// published in the status file, so `claudetogo service status` shows it without any
+// metrics endpoint.
+type Metrics struct {
+	mu             sync.Mutex
+	buckets        [60]int   // Events per second over the rate window, indexed by Unix second
+	bucketSecond   [60]int64 // The Unix second each bucket counts
+	eventsTotal    int
+	deliveryErrors int
+	responses      int
+	latencyTotal   time.Duration
+	latencyMax     time.Duration
+	latencyLast    time.Duration
+	responsesSince time.Time // Response files written after this are not yet counted
+	stages         map[string]*stageLatency
+}
+
+// stageLatency accumulates the time events spent in one stage
+type stageLatency struct {
+	count int
+	total time.Duration
+	max   time.Duration
+}
+
+// StageLatency is the average and longest time events spent in one stage
+type StageLatency struct {
+	Events int    `json:"events"`
+	Avg    string `json:"avg"`
+	Max    string `json:"max"`
+}
+
+// MetricsSnapshot is the state of the metrics at one moment, as written to the status file
+type MetricsSnapshot struct {
+	EventsPerSecond     float64 `json:"events_per_second"` // Averaged over the last minute
+	EventsTotal         int     `json:"events_total"`
+	DeliveryErrors      int     `json:"delivery_errors"`
+	QueueDepth          int     `json:"queue_depth"`                    // Events not yet processed plus messages waiting for delivery
+	Responses           int     `json:"responses"`                      // Approvals and rejections since start
+	ApprovalLatencyAvg  string  `json:"approval_latency_avg,omitempty"` // From notification to response
+	ApprovalLatencyMax  string  `json:"approval_latency_max,omitempty"`
+	ApprovalLatencyLast string  `json:"approval_latency_last,omitempty"`
+
+	Stages map[string]StageLatency `json:"stages,omitempty"` // Per processing stage, keyed by the names in Stages
+}
+
+// NewMetrics creates metrics counting from now
+func NewMetrics() *Metrics {
+	return &Metrics{responsesSince: time.Now(), stages: make(map[string]*stageLatency)}
+}
+
+// RecordStage counts the time one event spent in a stage (one of Stages)
+func (m *Metrics) RecordStage(stage string, took time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	latency := m.stages[stage]
+	if latency == nil {
+		latency = &stageLatency{}
+		m.stages[stage] = latency
+	}
+	latency.count++
+	latency.total += took
+	if took > latency.max {
+		latency.max = took
+	}
+}
+
+// RecordEvents counts events turned into messages
+func (m *Metrics) RecordEvents(n int, at time.Time) {
+	if n <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	second := at.Unix()
+	i := second % int64(len(m.buckets))
+	if m.bucketSecond[i] != second {
+		m.bucketSecond[i], m.buckets[i] = second, 0
+	}
+	m.buckets[i] += n
+	m.eventsTotal += n
+}
+
+// RecordDeliveryError counts a message a channel did not accept
+func (m *Metrics) RecordDeliveryError() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveryErrors++
+}
+
+// RecordResponse counts a response and how long after its notification it came
+func (m *Metrics) RecordResponse(latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses++
+	m.latencyTotal += latency
+	m.latencyLast = latency
+	if latency > m.latencyMax {
+		m.latencyMax = latency
+	}
+}
+
+// ScanResponses counts the approvals and rejections written to the responses directory since
+// the last scan, whichever process wrote them (the CLI, a chat bridge or the response API)
+func (m *Metrics) ScanResponses(outputDir string) {
+	m.mu.Lock()
+	since := m.responsesSince
+	m.mu.Unlock()
+
+	matches, _ := filepath.Glob(filepath.Join(outputDir, "responses", "response-*.json"))
+	latest := since
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().After(since) {
+			continue
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		if latency, ok := responseLatency(path); ok {
+			m.RecordResponse(latency)
+		}
+	}
+
+	m.mu.Lock()
+	m.responsesSince = latest
+	m.mu.Unlock()
+}
+
+// Snapshot returns the metrics as of now; queueDepth is the work waiting at that moment
+func (m *Metrics) Snapshot(now time.Time, queueDepth int) MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recent := 0
+	oldest := now.Add(-rateWindow).Unix()
+	for i, second := range m.bucketSecond {
+		if second > oldest && second <= now.Unix() {
+			recent += m.buckets[i]
+		}
+	}
+
+	snapshot := MetricsSnapshot{
+		EventsPerSecond: float64(recent) / rateWindow.Seconds(),
+		EventsTotal:     m.eventsTotal,
+		DeliveryErrors:  m.deliveryErrors,
+		QueueDepth:      queueDepth,
+		Responses:       m.responses,
+	}
+	if m.responses > 0 {
+		snapshot.ApprovalLatencyAvg = (m.latencyTotal / time.Duration(m.responses)).Round(time.Second).String()
+		snapshot.ApprovalLatencyMax = m.latencyMax.Round(time.Second).String()
+		snapshot.ApprovalLatencyLast = m.latencyLast.Round(time.Second).String()
+	}
+	if len(m.stages) > 0 {
+		snapshot.Stages = make(map[string]StageLatency, len(m.stages))
+		for stage, latency := range m.stages {
+			snapshot.Stages[stage] = StageLatency{
+				Events: latency.count,
+				Avg:    (latency.total / time.Duration(latency.count)).Round(time.Microsecond).String(),
+				Max:    latency.max.Round(time.Microsecond).String(),
+			}
+		}
+	}
+	return snapshot
+}
+
+// responseLatency reads how long after its notification a response file's approval or
+// rejection was given. Info requests and files without the notification time don't count.
+func responseLatency(path string) (time.Duration, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	var response struct {
+		Action           string `json:"action"`
+		Timestamp        string `json:"timestamp"`
+		MessageTimestamp string `json:"message_timestamp"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil || (response.Action != "approve" && response.Action != "reject") {
+		return 0, false
+	}
+	answered, err := time.Parse(time.RFC3339, response.Timestamp)
+	if err != nil {
+		return 0, false
+	}
+	notified, err := time.Parse(time.RFC3339, response.MessageTimestamp)
+	if err != nil || answered.Before(notified) {
+		return 0, false
+	}
+	return answered.Sub(notified), true
+}
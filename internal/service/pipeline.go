@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/channels"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// Components selects which parts of the pipeline the service runs
+type Components struct {
+	Watcher        bool             // Process new events from the events file into messages
+	IngestSocket   string           // Unix socket hooks can send events to (empty = disabled)
+	Delivery       *DeliveryConfig  // Post new messages to a webhook (nil = disabled)
+	ResponseAPI    string           // Address of the HTTP response API (empty = disabled)
+	ResponseAPITLS TLSConfig        // HTTPS for the response API
+	Profiling      bool             // Serve Go profiles at /debug/pprof/ on the response API
+	Heartbeat      *HeartbeatConfig // Heartbeat and stall detection (nil = disabled)
+}
+
+// Names lists the enabled components, in the order they are started
+func (c Components) Names() []string {
+	c = c.withDefaults()
+
+	var names []string
+	if c.IngestSocket != "" {
+		names = append(names, "ingest")
+	}
+	if c.Watcher {
+		names = append(names, "watcher")
+	}
+	if c.Delivery != nil {
+		names = append(names, "delivery")
+	}
+	if c.ResponseAPI != "" {
+		names = append(names, "api")
+	}
+	if c.Heartbeat != nil {
+		names = append(names, "heartbeat")
+	}
+	return names
+}
+
+// withDefaults enables the watcher when no component was selected, matching the original service
+func (c Components) withDefaults() Components {
+	if !c.Watcher && c.IngestSocket == "" && c.Delivery == nil && c.ResponseAPI == "" && c.Heartbeat == nil {
+		c.Watcher = true
+	}
+	return c
+}
+
+// componentNames lists the components the service runs, including config reloading
+func (c WatcherConfig) componentNames() []string {
+	names := c.Components.Names()
+	if len(c.ConfigFiles) > 0 && c.Reload != nil {
+		names = append(names, "reload")
+	}
+	return names
+}
+
+// runComponents starts every enabled component and waits for them to finish. The first
+// component to fail stops the others; with AutoRestart, failed components are restarted instead.
+func runComponents(ctx context.Context, config WatcherConfig, watcher *EventWatcher) error {
+	components := config.Components.withDefaults()
+	if components.Delivery != nil && !components.Watcher {
+		return fmt.Errorf("delivery requires the watcher component")
+	}
+
+	run := make(map[string]func(ctx context.Context) error)
+	if components.IngestSocket != "" {
+		run["ingest"] = NewIngestServer(components.IngestSocket, watcher.eventsFile, config.Logger.Component("ingest")).Run
+	}
+	if components.Watcher {
+		run["watcher"] = watcher.Start
+	}
+	var alert func(*types.MessengerMessage)
+	var deliverer *Deliverer
+	if components.Delivery != nil {
+		delivery := *components.Delivery
+		delivery.Threads = channels.NewThreads(filepath.Join(watcher.outputDir, channels.ThreadsFileName))
+		deliverer = NewDeliverer(delivery, config.Logger.Component("delivery"), func(message *types.MessengerMessage, err error, took time.Duration) {
+			watcher.recordDelivery(message, err, took, deliverer.QueueDepth())
+		})
+		watcher.processor.SetMessageHandler(deliverer.Enqueue)
+		alert = deliverer.Enqueue
+		run["delivery"] = deliverer.Run
+	}
+	if components.ResponseAPI != "" {
+		api := NewResponseAPI(components.ResponseAPI, watcher.outputDir, components.ResponseAPITLS, watcher.verifier, config.Logger.Component("api"))
+		api.gate, api.alert = watcher.gate, alert
+		api.eventsFile = watcher.eventsFile
+		api.profiling = components.Profiling
+		run["api"] = api.Run
+	}
+	var heartbeat *Heartbeat
+	if components.Heartbeat != nil {
+		heartbeat = NewHeartbeat(*components.Heartbeat, watcher, alert, config.Logger.Component("heartbeat"))
+		run["heartbeat"] = heartbeat.Run
+	}
+	if len(config.ConfigFiles) > 0 && config.Reload != nil {
+		reloader := &configReloader{current: config, watcher: watcher, deliverer: deliverer, heartbeat: heartbeat}
+		run["reload"] = reloader.Run
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var supervisor *Supervisor
+	if config.AutoRestart {
+		supervisor = NewSupervisor(config.Logger, watcher.recordRestart)
+		supervisor.onPanic = watcher.reporter.ReportPanic
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for _, name := range config.componentNames() {
+		name, component := name, run[name]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Without a supervisor a panic still ends the service, but is reported first
+			defer func() {
+				if r := recover(); r != nil {
+					watcher.reporter.ReportPanic(name, r, callers(4))
+					panic(r)
+				}
+			}()
+
+			var err error
+			if supervisor != nil {
+				err = supervisor.Run(ctx, name, component)
+			} else {
+				err = component(ctx)
+			}
+
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("%s: %w", name, err)
+					cancel()
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+	return firstErr
+}
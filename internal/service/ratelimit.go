@@ -0,0 +1,155 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+const (
+	// rateBurst is how many messages a rate-limited channel may send back to back
+	rateBurst = 3
+	// floodSummaryTitles is how many held messages a flood summary lists by title
+	floodSummaryTitles = 10
+)
+
+// rateLimiter spaces out messages to each channel with a token bucket per channel name
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket holds the messages a channel may still send and when it was last refilled
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newRateLimiter creates a limiter with full buckets
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: make(map[string]*bucket)}
+}
+
+// reserve takes a message from the channel's bucket and returns how long the caller must
+// wait before sending it. perMinute <= 0 means the channel is not limited.
+func (l *rateLimiter) reserve(channel string, perMinute int, now time.Time) time.Duration {
+	if perMinute <= 0 {
+		return 0
+	}
+	capacity := math.Min(rateBurst, float64(perMinute))
+	perSecond := float64(perMinute) / 60
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[channel]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		l.buckets[channel] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*perSecond)
+	b.updated = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / perSecond * float64(time.Second))
+}
+
+// floodGuard decides which messages are held back during a burst, to be sent as one summary
+type floodGuard struct {
+	recent []time.Time               // Arrival times within the flood window
+	held   []*types.MessengerMessage // Messages waiting for the summary
+}
+
+// admit records a message's arrival and reports whether it should be sent now. Once more than
+// threshold messages arrive within window, the rest are held until the summary is sent.
+// Messages waiting for a response are never held, so their actions stay usable.
+func (f *floodGuard) admit(message *types.MessengerMessage, threshold int, window time.Duration, now time.Time) bool {
+	cutoff := now.Add(-window)
+	kept := f.recent[:0]
+	for _, arrived := range f.recent {
+		if arrived.After(cutoff) {
+			kept = append(kept, arrived)
+		}
+	}
+	f.recent = append(kept, now)
+
+	if threshold <= 0 || message.Type == "action_needed" {
+		return true
+	}
+	if len(f.held) == 0 && len(f.recent) <= threshold {
+		return true
+	}
+	f.held = append(f.held, message)
+	return false
+}
+
+// release returns the held messages and forgets them
+func (f *floodGuard) release() []*types.MessengerMessage {
+	held := f.held
+	f.held = nil
+	return held
+}
+
+// floodSummary combines held messages of one project (empty = unlabelled) into one, listing
+// how many came from each session and the first titles
+func floodSummary(held []*types.MessengerMessage, project string, window time.Duration, now time.Time) *types.MessengerMessage {
+	sessions := make(map[string]int)
+	ids := make([]string, 0, len(held))
+	for _, message := range held {
+		sessions[message.SessionID]++
+		ids = append(ids, message.MessageID)
+	}
+
+	var text strings.Builder
+	for i, message := range held {
+		if i == floodSummaryTitles {
+			fmt.Fprintf(&text, "…and %d more\n", len(held)-floodSummaryTitles)
+			break
+		}
+		fmt.Fprintf(&text, "• %s\n", message.Title)
+	}
+	if len(sessions) > 1 {
+		names := make([]string, 0, len(sessions))
+		for id := range sessions {
+			names = append(names, id)
+		}
+		sort.Slice(names, func(i, j int) bool { return sessions[names[i]] > sessions[names[j]] })
+		parts := make([]string, len(names))
+		for i, id := range names {
+			parts[i] = fmt.Sprintf("%s (%d)", session.Short(id), sessions[id])
+		}
+		fmt.Fprintf(&text, "\nSessions: %s\n", strings.Join(parts, ", "))
+	}
+	text.WriteString("\nThe full messages are in the output directory.")
+
+	summary := &types.MessengerMessage{
+		Type:      "summary",
+		Title:     fmt.Sprintf("🌊 %d new notifications in %v", len(held), window),
+		Message:   text.String(),
+		Priority:  "low",
+		Timestamp: now.Format(time.RFC3339),
+		MessageID: fmt.Sprintf("flood-%d", now.UnixNano()),
+		Context: map[string]interface{}{
+			"coalesced":   len(held),
+			"window":      window.String(),
+			"message_ids": ids,
+			"sessions":    sessions,
+		},
+	}
+	if project != "" {
+		summary.Title = fmt.Sprintf("🌊 %d new notifications from %s in %v", len(held), project, window)
+		summary.Context["project"] = project
+	}
+	if len(sessions) == 1 {
+		summary.SessionID = held[0].SessionID
+		summary.ThreadID = held[0].ThreadID
+	}
+	return summary
+}
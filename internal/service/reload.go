@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/filewatch"
+)
+
+// reloadDebounce lets an editor finish writing the config before it is read
+const reloadDebounce = 250 * time.Millisecond
+
+// ReloadFunc reloads the service configuration, returning it with a description of each changed setting
+type ReloadFunc func() (WatcherConfig, []string, error)
+
+// configReloader watches the messenger config file and applies changed settings to running components
+type configReloader struct {
+	current   WatcherConfig
+	watcher   *EventWatcher
+	deliverer *Deliverer
+	heartbeat *Heartbeat
+}
+
+// Run reloads the configuration whenever the file changes, until ctx is cancelled
+func (r *configReloader) Run(ctx context.Context) error {
+	logger := r.current.Logger.Component("reload")
+	logger.Info("Reloading configuration when %s changes", strings.Join(r.current.ConfigFiles, ", "))
+
+	changed := make(chan struct{}, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			}
+
+			// Coalesce the burst of writes an editor makes when saving
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reloadDebounce):
+			}
+			select {
+			case <-changed:
+			default:
+			}
+
+			r.reload()
+		}
+	}()
+
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	// Watch every layer; the first watch to fail stops the reloader
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, len(r.current.ConfigFiles))
+	opts := filewatch.Options{PollInterval: 2 * time.Second}
+	for _, file := range r.current.ConfigFiles {
+		go func(file string) {
+			errs <- filewatch.Watch(watchCtx, file, opts, logger, notify)
+		}(file)
+	}
+
+	err := <-errs
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// reload loads the new configuration and applies what can change while running
+func (r *configReloader) reload() {
+	logger := r.current.Logger.Component("reload")
+
+	next, changes, err := r.current.Reload()
+	if err != nil {
+		logger.Warn("Failed to reload configuration, keeping current settings: %v", err)
+		return
+	}
+	if len(changes) == 0 {
+		logger.Debug("Configuration file changed, but no settings differ")
+		return
+	}
+
+	logger.Info("Configuration reloaded, %d setting(s) changed:", len(changes))
+	for _, change := range changes {
+		logger.Info("  %s", change)
+	}
+
+	r.watcher.applySettings(next)
+	if r.deliverer != nil && next.Components.Delivery != nil {
+		r.deliverer.SetConfig(*next.Components.Delivery)
+	}
+	if r.heartbeat != nil && next.Components.Heartbeat != nil {
+		r.heartbeat.SetConfig(*next.Components.Heartbeat)
+	}
+
+	if next.ConfigFiles != nil && !reflect.DeepEqual(r.current.ConfigFiles, next.ConfigFiles) {
+		logger.Warn("The included config files changed; restart the service to watch %s", strings.Join(next.ConfigFiles, ", "))
+	}
+	for _, setting := range restartRequired(r.current, next) {
+		logger.Warn("Changing %s requires restarting the service", setting)
+	}
+
+	// Settings that need a restart keep their running values
+	next.Components = r.current.Components.withLive(next.Components)
+	next.StatusFile, next.PidFile, next.AutoRestart = r.current.StatusFile, r.current.PidFile, r.current.AutoRestart
+	next.LogFile, next.LogTarget = r.current.LogFile, r.current.LogTarget
+	next.ConfigFiles, next.Reload, next.Logger = r.current.ConfigFiles, r.current.Reload, r.current.Logger
+	r.current = next
+}
+
+// withLive returns the running components with the live-reloadable settings taken from next
+func (c Components) withLive(next Components) Components {
+	if c.Delivery != nil && next.Delivery != nil {
+		delivery := *next.Delivery
+		c.Delivery = &delivery
+	}
+	if c.Heartbeat != nil && next.Heartbeat != nil {
+		heartbeat := *next.Heartbeat
+		heartbeat.Interval = c.Heartbeat.Interval
+		c.Heartbeat = &heartbeat
+	}
+	return c
+}
+
+// restartRequired names the changed settings that are only read when the service starts
+func restartRequired(current, next WatcherConfig) []string {
+	var settings []string
+	if current.StatusFile != next.StatusFile {
+		settings = append(settings, "service.status_file")
+	}
+	if current.PidFile != next.PidFile {
+		settings = append(settings, "service.pid_file")
+	}
+	if current.LogFile != next.LogFile {
+		settings = append(settings, "service.log_file")
+	}
+	if current.LogTarget != next.LogTarget {
+		settings = append(settings, "service.log_target")
+	}
+	if current.AutoRestart != next.AutoRestart {
+		settings = append(settings, "service.auto_restart")
+	}
+	if !reflect.DeepEqual(current.Components.Names(), next.Components.Names()) {
+		settings = append(settings, "service.components")
+	}
+	if current.Components.IngestSocket != next.Components.IngestSocket && next.Components.IngestSocket != "" {
+		settings = append(settings, "service.components.ingest_socket")
+	}
+	if current.Components.ResponseAPI != next.Components.ResponseAPI && next.Components.ResponseAPI != "" {
+		settings = append(settings, "service.components.response_api")
+	}
+	if current.Components.ResponseAPITLS != next.Components.ResponseAPITLS {
+		settings = append(settings, "service.api_tls")
+	}
+	if current.Components.Heartbeat != nil && next.Components.Heartbeat != nil &&
+		current.Components.Heartbeat.Interval != next.Components.Heartbeat.Interval {
+		settings = append(settings, "service.heartbeat_interval")
+	}
+	return settings
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/version"
+)
+
+// reportTimeout limits each request to Sentry or the error webhook
+const reportTimeout = 10 * time.Second
+
+// modulePath marks the stack frames that are ClaudeToGo's own code
+const modulePath = "github.com/riaanpieterse81/ClaudeToGo/"
+
+// ErrorReportingConfig configures where the service reports panics and repeated failures
+type ErrorReportingConfig struct {
+	SentryDSN        string // Sentry project DSN (empty = disabled)
+	WebhookURL       string // URL error reports are posted to as JSON (empty = disabled)
+	FailureThreshold int    // Consecutive failures of one kind before they are reported
+}
+
+// ErrorReport is what the service reports: a panic, or a failure that keeps happening
+type ErrorReport struct {
+	Level     string                 `json:"level"` // "fatal" for panics, "error" for failures
+	Kind      string                 `json:"kind"`  // panic, processing or delivery
+	Component string                 `json:"component,omitempty"`
+	Message   string                 `json:"message"`
+	Failures  int                    `json:"failures,omitempty"` // Consecutive failures so far
+	Context   map[string]interface{} `json:"context,omitempty"`
+	Stack     string                 `json:"stack,omitempty"`
+	Host      string                 `json:"host"`
+	Version   string                 `json:"version"`
+	Timestamp time.Time              `json:"timestamp"`
+
+	frames []runtime.Frame
+}
+
+// ErrorReporter sends panics and repeated processing and delivery failures to Sentry and/or
+// an error webhook, with a stack trace and the event they happened on. Failures are reported
+// once a kind has failed FailureThreshold times in a row, and again after it recovers.
+type ErrorReporter struct {
+	mu       sync.Mutex // guards config and failures, which change on reload and per failure
+	config   ErrorReportingConfig
+	failures map[string]int
+	client   *http.Client
+	logger   *logger.Logger
+}
+
+// NewErrorReporter creates an error reporter; it reports nothing until a destination is configured
+func NewErrorReporter(config ErrorReportingConfig, logger *logger.Logger) *ErrorReporter {
+	r := &ErrorReporter{
+		failures: make(map[string]int),
+		client:   &http.Client{Timeout: reportTimeout},
+		logger:   logger,
+	}
+	r.SetConfig(config)
+	return r
+}
+
+// SetConfig changes the destinations and threshold
+func (r *ErrorReporter) SetConfig(config ErrorReportingConfig) {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 1
+	}
+	r.mu.Lock()
+	r.config = config
+	r.mu.Unlock()
+}
+
+// Enabled reports whether any destination is configured
+func (r *ErrorReporter) Enabled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.config.SentryDSN != "" || r.config.WebhookURL != ""
+}
+
+// ReportPanic reports a component's panic with the stack it panicked on. It waits for the
+// report to be sent, since the process may be about to exit.
+func (r *ErrorReporter) ReportPanic(component string, value interface{}, stack []uintptr) {
+	if !r.Enabled() {
+		return
+	}
+	report := r.newReport("fatal", "panic", fmt.Sprintf("panic in %s: %v", component, value), stack)
+	report.Component = component
+	r.send(report)
+}
+
+// Failure counts a failure of kind (processing or delivery) and reports it in the background
+// once the kind has failed FailureThreshold times in a row. context describes the event or
+// message the failure happened on.
+func (r *ErrorReporter) Failure(kind string, err error, context map[string]interface{}) {
+	r.mu.Lock()
+	r.failures[kind]++
+	failures, threshold := r.failures[kind], r.config.FailureThreshold
+	r.mu.Unlock()
+
+	if failures != threshold || !r.Enabled() {
+		return
+	}
+	report := r.newReport("error", kind, fmt.Sprintf("%s failed %d time(s) in a row: %v", kind, failures, err), callers(3))
+	report.Failures = failures
+	report.Context = make(map[string]interface{}, len(context))
+	for key, value := range context {
+		if value != "" {
+			report.Context[key] = value
+		}
+	}
+	go r.send(report)
+}
+
+// Success ends a run of failures of kind, so the next run is reported again
+func (r *ErrorReporter) Success(kind string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.failures, kind)
+}
+
+// newReport creates a report of the running build on this host
+func (r *ErrorReporter) newReport(level, kind, message string, stack []uintptr) *ErrorReport {
+	host, _ := os.Hostname()
+	report := &ErrorReport{
+		Level:     level,
+		Kind:      kind,
+		Message:   message,
+		Host:      host,
+		Version:   version.Get().Version,
+		Timestamp: time.Now().UTC(),
+	}
+
+	frames := runtime.CallersFrames(stack)
+	var lines strings.Builder
+	for {
+		frame, more := frames.Next()
+		report.frames = append(report.frames, frame)
+		fmt.Fprintf(&lines, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	report.Stack = lines.String()
+	return report
+}
+
+// send delivers a report to every configured destination, logging failures
+func (r *ErrorReporter) send(report *ErrorReport) {
+	r.mu.Lock()
+	config := r.config
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+
+	if config.SentryDSN != "" {
+		if err := r.sendSentry(ctx, config.SentryDSN, report); err != nil {
+			r.logger.Warn("Failed to report error to Sentry: %v", err)
+		}
+	}
+	if config.WebhookURL != "" {
+		if err := r.post(ctx, config.WebhookURL, nil, report); err != nil {
+			r.logger.Warn("Failed to report error to the error webhook: %v", err)
+		}
+	}
+}
+
+// sendSentry sends a report to Sentry's store endpoint as an event with an exception
+func (r *ErrorReporter) sendSentry(ctx context.Context, dsn string, report *ErrorReport) error {
+	endpoint, key, err := ParseSentryDSN(dsn)
+	if err != nil {
+		return err
+	}
+
+	// Sentry lists frames oldest first and uses in_app to highlight the project's own code
+	frames := make([]map[string]interface{}, 0, len(report.frames))
+	for i := len(report.frames) - 1; i >= 0; i-- {
+		frame := report.frames[i]
+		frames = append(frames, map[string]interface{}{
+			"function": frame.Function,
+			"abs_path": frame.File,
+			"filename": frame.File,
+			"lineno":   frame.Line,
+			"in_app":   strings.HasPrefix(frame.Function, modulePath),
+		})
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	event := map[string]interface{}{
+		"event_id":    hex.EncodeToString(id),
+		"timestamp":   report.Timestamp.Format(time.RFC3339),
+		"platform":    "go",
+		"level":       report.Level,
+		"logger":      "claudetogo",
+		"server_name": report.Host,
+		"release":     "claudetogo@" + report.Version,
+		"message":     map[string]string{"formatted": report.Message},
+		"tags":        map[string]string{"kind": report.Kind, "component": report.Component},
+		"extra":       report.Context,
+		"exception": map[string]interface{}{
+			"values": []map[string]interface{}{{
+				"type":       report.Kind,
+				"value":      report.Message,
+				"stacktrace": map[string]interface{}{"frames": frames},
+			}},
+		},
+	}
+
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=claudetogo/%s, sentry_key=%s", report.Version, key)
+	return r.post(ctx, endpoint, map[string]string{"X-Sentry-Auth": auth}, event)
+}
+
+// post sends body as JSON to url
+func (r *ErrorReporter) post(ctx context.Context, url string, headers map[string]string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "claudetogo")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+// ParseSentryDSN returns the store endpoint and public key of a Sentry DSN such as
+// https://<key>@o0.ingest.sentry.io/<project>
+func ParseSentryDSN(dsn string) (endpoint, key string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid Sentry DSN: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" || u.User == nil || u.User.Username() == "" {
+		return "", "", fmt.Errorf("invalid Sentry DSN: expected https://<key>@<host>/<project>")
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	project := path[slash+1:]
+	if project == "" {
+		return "", "", fmt.Errorf("invalid Sentry DSN: no project ID")
+	}
+	endpoint = fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, path[:slash], project)
+	return endpoint, u.User.Username(), nil
+}
+
+// callers returns the calling goroutine's stack, skipping skip frames
+func callers(skip int) []uintptr {
+	stack := make([]uintptr, 64)
+	return stack[:runtime.Callers(skip, stack)]
+}
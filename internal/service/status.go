@@ -0,0 +1,128 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
+)
+
+// defaultStatusFileName is the status file kept in the output directory when none is configured
+const defaultStatusFileName = ".watcher-status"
+
+// ServiceStatus is the content of the status file the running service keeps up to date
+type ServiceStatus struct {
+	Service      string    `json:"service"`
+	Status       string    `json:"status"`
+	PID          int       `json:"pid"`
+	Started      time.Time `json:"started"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	EventsFile   string    `json:"events_file"`
+	OutputDir    string    `json:"output_dir"`
+	PollInterval string    `json:"poll_interval"`
+
+	EventsProcessed int            `json:"events_processed"`     // Events turned into messages since start
+	LastEvent       *EventInfo     `json:"last_event,omitempty"` // Most recently processed event
+	Backlog         int            `json:"backlog"`              // Events in the events file not yet processed
+	FailedEvents    int            `json:"failed_events"`        // Events currently in quarantine
+	LastError       string         `json:"last_error,omitempty"` // Most recent processing error
+	LastErrorAt     *time.Time     `json:"last_error_at,omitempty"`
+	Restarts        map[string]int `json:"restarts,omitempty"` // Component restarts by the supervisor
+
+	Components        []string `json:"components,omitempty"`         // Pipeline components the service runs
+	MessagesDelivered int      `json:"messages_delivered,omitempty"` // Messages posted to the webhook
+	DeliveryFailures  int      `json:"delivery_failures,omitempty"`  // Messages that could not be delivered
+	DeliveryQueue     int      `json:"delivery_queue,omitempty"`     // Messages waiting for delivery
+
+	Metrics *MetricsSnapshot `json:"metrics,omitempty"` // Rates, counters and latencies since start
+
+	HeartbeatInterval string     `json:"heartbeat_interval,omitempty"`
+	HeartbeatAt       *time.Time `json:"heartbeat_at,omitempty"`    // Last heartbeat; stale when the service hangs
+	LastHookEvent     *time.Time `json:"last_hook_event,omitempty"` // Last write to the events file
+	StalledSince      *time.Time `json:"stalled_since,omitempty"`   // Sessions active without hook events since
+}
+
+// EventInfo identifies a processed event
+type EventInfo struct {
+	SessionID   string    `json:"session_id"`
+	Type        string    `json:"type"`
+	Timestamp   string    `json:"timestamp"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// StatusFilePath returns the status file location: the configured path, or .watcher-status in outputDir
+func StatusFilePath(statusFile, outputDir string) string {
+	if statusFile != "" {
+		return statusFile
+	}
+	return filepath.Join(outputDir, defaultStatusFileName)
+}
+
+// ReadStatus loads the status file written by a running service
+func ReadStatus(path string) (*ServiceStatus, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var status ServiceStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse status file: %w", err)
+	}
+
+	return &status, nil
+}
+
+// IsRunning reports whether the process that wrote the status file is still alive
+func (s *ServiceStatus) IsRunning() bool {
+	if s.PID <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(s.PID)
+	if err != nil {
+		return false
+	}
+	// Signal 0 checks for existence without affecting the process
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// HeartbeatOverdue reports whether the service has missed several heartbeats, which
+// means it is hung even though its process is alive
+func (s *ServiceStatus) HeartbeatOverdue() bool {
+	if s.HeartbeatAt == nil || s.HeartbeatInterval == "" {
+		return false
+	}
+	interval, err := time.ParseDuration(s.HeartbeatInterval)
+	if err != nil || interval <= 0 {
+		return false
+	}
+	return time.Since(*s.HeartbeatAt) > 3*interval
+}
+
+// Uptime returns how long the service has been running
+func (s *ServiceStatus) Uptime() time.Duration {
+	return time.Since(s.Started).Round(time.Second)
+}
+
+// writeStatus atomically replaces the status file
+func writeStatus(path string, status *ServiceStatus) error {
+	status.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal status: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create status directory: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write status file: %w", err)
+	}
+	return nil
+}
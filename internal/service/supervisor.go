@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
+)
+
+const (
+	// minRestartBackoff is the delay before the first restart of a failed component
+	minRestartBackoff = time.Second
+	// maxRestartBackoff caps the delay between restarts of a repeatedly failing component
+	maxRestartBackoff = time.Minute
+	// stableRunTime is how long a component must run before its backoff is reset
+	stableRunTime = time.Minute
+)
+
+// Supervisor runs service components, recovering panics and restarting failed components with backoff
+type Supervisor struct {
+	logger    *logger.Logger
+	onRestart func(name string, restarts int, err error)
+	onPanic   func(name string, value interface{}, stack []uintptr) // Set to report panics
+
+	mu       sync.Mutex
+	restarts map[string]int
+}
+
+// NewSupervisor creates a supervisor. onRestart, if set, is called before each restart.
+func NewSupervisor(logger *logger.Logger, onRestart func(name string, restarts int, err error)) *Supervisor {
+	return &Supervisor{
+		logger:    logger,
+		onRestart: onRestart,
+		restarts:  make(map[string]int),
+	}
+}
+
+// Run runs a component until ctx is cancelled, restarting it whenever it returns or panics
+func (s *Supervisor) Run(ctx context.Context, name string, component func(ctx context.Context) error) error {
+	backoff := minRestartBackoff
+
+	for {
+		started := time.Now()
+		err := s.runRecovered(ctx, name, component)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("component exited unexpectedly")
+		}
+
+		// A component that ran for a while before failing starts again from the shortest delay
+		if time.Since(started) >= stableRunTime {
+			backoff = minRestartBackoff
+		}
+
+		s.mu.Lock()
+		s.restarts[name]++
+		restarts := s.restarts[name]
+		s.mu.Unlock()
+
+		s.logger.Error("Service component %s failed: %v (restart %d in %v)", name, err, restarts, backoff)
+		if s.onRestart != nil {
+			s.onRestart(name, restarts, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxRestartBackoff {
+			backoff = maxRestartBackoff
+		}
+	}
+}
+
+// Restarts returns how many times each component has been restarted
+func (s *Supervisor) Restarts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	restarts := make(map[string]int, len(s.restarts))
+	for name, count := range s.restarts {
+		restarts[name] = count
+	}
+	return restarts
+}
+
+// runRecovered runs a component, converting a panic into an error and logging its stack
+func (s *Supervisor) runRecovered(ctx context.Context, name string, component func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Service component %s panicked: %v\n%s", name, r, debug.Stack())
+			if s.onPanic != nil {
+				s.onPanic(name, r, callers(4))
+			}
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return component(ctx)
+}
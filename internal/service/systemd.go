@@ -0,0 +1,205 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SystemdUnitName is the name of the user-level systemd unit installed by `service install`
+const SystemdUnitName = "claudetogo.service"
+
+// SystemdUnitOptions describes how the installed service should run
+type SystemdUnitOptions struct {
+	ExecPath        string            // Absolute path to the claudetogo binary
+	WorkingDir      string            // Directory relative paths are resolved against
+	EventsFile      string
+	OutputDir       string
+	MessengerConfig string            // Optional messenger configuration file
+	Interval        time.Duration     // Service poll interval
+	Environment     map[string]string // Written to the unit's environment file
+}
+
+// SystemdUnitPath returns where the user-level unit file is installed
+func SystemdUnitPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "systemd", "user", SystemdUnitName), nil
+}
+
+// SystemdEnvironmentPath returns the environment file referenced by the unit. It is kept
+// separate from the unit (and private to the user) because it may contain tokens.
+func SystemdEnvironmentPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "claudetogo", "service.env"), nil
+}
+
+// GenerateSystemdUnit renders the unit file for the given options
+func GenerateSystemdUnit(opts SystemdUnitOptions, environmentFile string) string {
+	args := []string{
+		opts.ExecPath,
+		"--service",
+		"--events-file", opts.EventsFile,
+		"--output-dir", opts.OutputDir,
+	}
+	if opts.Interval > 0 {
+		args = append(args, "--service-interval", opts.Interval.String())
+	}
+	if opts.MessengerConfig != "" {
+		args = append(args, "--messenger-config", opts.MessengerConfig)
+	}
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = systemdQuote(arg)
+	}
+
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=ClaudeToGo event processing service\n")
+	b.WriteString("After=network-online.target\n")
+	b.WriteString("\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(opts.WorkingDir))
+	fmt.Fprintf(&b, "EnvironmentFile=-%s\n", environmentFile)
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(quoted, " "))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n")
+	b.WriteString("\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+
+	return b.String()
+}
+
+// InstallSystemdUnit writes the unit and its environment file and reloads systemd.
+// It returns the path of the installed unit.
+func InstallSystemdUnit(opts SystemdUnitOptions) (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", fmt.Errorf("systemd services are only supported on Linux")
+	}
+
+	unitPath, err := SystemdUnitPath()
+	if err != nil {
+		return "", err
+	}
+	envPath, err := SystemdEnvironmentPath()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(envPath), 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(envPath, []byte(renderEnvironment(opts.Environment)), 0600); err != nil {
+		return "", fmt.Errorf("failed to write environment file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(unitPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create systemd user directory: %w", err)
+	}
+	if err := os.WriteFile(unitPath, []byte(GenerateSystemdUnit(opts, envPath)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write unit file: %w", err)
+	}
+
+	if err := Systemctl("daemon-reload"); err != nil {
+		return unitPath, err
+	}
+
+	return unitPath, nil
+}
+
+// UninstallSystemdUnit stops and disables the service and removes its unit and environment files
+func UninstallSystemdUnit() error {
+	unitPath, err := SystemdUnitPath()
+	if err != nil {
+		return err
+	}
+	envPath, err := SystemdEnvironmentPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(unitPath); os.IsNotExist(err) {
+		return fmt.Errorf("service is not installed (%s not found)", unitPath)
+	}
+
+	// The unit may already be stopped or disabled; removal continues regardless
+	_ = Systemctl("disable", "--now", SystemdUnitName)
+
+	if err := os.Remove(unitPath); err != nil {
+		return fmt.Errorf("failed to remove unit file: %w", err)
+	}
+	if err := os.Remove(envPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove environment file: %w", err)
+	}
+
+	return Systemctl("daemon-reload")
+}
+
+// Systemctl runs `systemctl --user` with the given arguments, passing its output through
+func Systemctl(args ...string) error {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("systemctl --user %s failed: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// SystemdState returns the unit's active state (e.g. "active", "inactive", "failed"),
+// or false when the unit is not installed or systemd can't be queried
+func SystemdState() (string, bool) {
+	unitPath, err := SystemdUnitPath()
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(unitPath); err != nil {
+		return "", false
+	}
+
+	// is-active exits non-zero for inactive units, but still prints the state
+	output, _ := exec.Command("systemctl", "--user", "is-active", SystemdUnitName).Output()
+	state := strings.TrimSpace(string(output))
+	if state == "" {
+		return "", false
+	}
+	return state, true
+}
+
+// renderEnvironment formats variables as a systemd environment file, sorted for stable output
+func renderEnvironment(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("# Environment for claudetogo.service (generated by `claudetogo service install`)\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", key, systemdQuote(env[key]))
+	}
+	return b.String()
+}
+
+// systemdQuote quotes a value for a unit or environment file when it contains spaces or quotes
+func systemdQuote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\"'\\") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(value) + `"`
+}
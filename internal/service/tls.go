@@ -0,0 +1,153 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// selfSignedCertFile and selfSignedKeyFile hold the generated certificate in the output directory
+	selfSignedCertFile = ".api-cert.pem"
+	selfSignedKeyFile  = ".api-key.pem"
+	// selfSignedValidity is how long a generated certificate is valid
+	selfSignedValidity = 365 * 24 * time.Hour
+	// selfSignedRenewal is how long before expiry a generated certificate is replaced
+	selfSignedRenewal = 30 * 24 * time.Hour
+)
+
+// TLSConfig configures HTTPS for the response API
+type TLSConfig struct {
+	CertFile     string // PEM certificate (chain) the API serves
+	KeyFile      string // PEM private key of CertFile
+	ClientCAFile string // Require client certificates signed by this PEM CA (empty = no client certificates)
+	SelfSigned   bool   // Generate a self-signed certificate when CertFile is empty
+}
+
+// Enabled reports whether the API is served over HTTPS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.SelfSigned
+}
+
+// serverConfig loads the certificate and client CA, generating a self-signed certificate in
+// dir when asked to. It also returns the certificate file clients should trust.
+func (c TLSConfig) serverConfig(dir string) (*tls.Config, string, error) {
+	certFile, keyFile := ExpandHome(c.CertFile), ExpandHome(c.KeyFile)
+	if certFile == "" {
+		certFile, keyFile = filepath.Join(dir, selfSignedCertFile), filepath.Join(dir, selfSignedKeyFile)
+		if err := ensureSelfSigned(certFile, keyFile); err != nil {
+			return nil, "", fmt.Errorf("failed to create self-signed certificate: %w", err)
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.ClientCAFile != "" {
+		data, err := os.ReadFile(ExpandHome(c.ClientCAFile))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, "", fmt.Errorf("no certificates found in client CA %s", c.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, certFile, nil
+}
+
+// ensureSelfSigned generates a certificate for this machine's names and addresses, unless a
+// generated one exists that isn't about to expire
+func ensureSelfSigned(certFile, keyFile string) error {
+	if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil {
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil && time.Until(leaf.NotAfter) > selfSignedRenewal {
+			return nil
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	host, _ := os.Hostname()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "ClaudeToGo response API", Organization: []string{"ClaudeToGo"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+	}
+	if host != "" {
+		template.DNSNames = append(template.DNSNames, host)
+		if !strings.Contains(host, ".") {
+			template.DNSNames = append(template.DNSNames, host+".local")
+		}
+	}
+	// Every local address, so LAN clients can connect by IP
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				template.IPAddresses = append(template.IPAddresses, ipNet.IP)
+			}
+		}
+	}
+	if len(template.IPAddresses) == 0 {
+		template.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(certFile), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
+
+// fingerprint returns the SHA-256 fingerprint of a certificate, as shown by browsers and openssl
+func fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// signatureTolerance is how far a signed request's timestamp may be from now
+	signatureTolerance = 5 * time.Minute
+	// telegramReplayWindow is how long Telegram update IDs are remembered; Telegram updates
+	// carry no signed timestamp, so replays are only caught by their ID
+	telegramReplayWindow = 24 * time.Hour
+)
+
+// Request sources told apart by their signature headers
+const (
+	sourceUnsigned = "unsigned"
+	sourceHMAC     = "hmac"
+	sourceSlack    = "slack"
+	sourceTelegram = "telegram"
+)
+
+// errUnsigned is returned for a request without a signature when one is required
+var errUnsigned = errors.New("request is not signed")
+
+// ResponseAuth holds the secrets inbound responses are verified with and the roles of the
+// API tokens and chat users sending them. When any secret is set, POST /api/respond only
+// accepts requests signed with one of them.
+type ResponseAuth struct {
+	Secret              string            // HMAC-SHA256 key for X-ClaudeToGo-Signature
+	SlackSigningSecret  string            // Slack app signing secret for X-Slack-Signature
+	TelegramSecretToken string            // secret_token given to Telegram's setWebhook
+	APITokens           map[string]string // Bearer token -> viewer, approver or admin
+	ChatRoles           map[string]string // "slack:<user ID>" or "telegram:<user ID>" -> role
+}
+
+// required reports whether requests must be signed
+func (a ResponseAuth) required() bool {
+	return a.Secret != "" || a.SlackSigningSecret != "" || a.TelegramSecretToken != ""
+}
+
+// requestVerifier checks the signatures of inbound responses and remembers the ones it
+// accepted, so a captured request can't be sent again
+type requestVerifier struct {
+	mu   sync.Mutex // guards auth and seen, which change on reload and per request
+	auth ResponseAuth
+	seen map[string]time.Time // Accepted signatures and update IDs, with when they expire
+}
+
+// newRequestVerifier creates a verifier for auth
+func newRequestVerifier(auth ResponseAuth) *requestVerifier {
+	return &requestVerifier{auth: auth, seen: make(map[string]time.Time)}
+}
+
+// setAuth changes the secrets requests are verified with
+func (v *requestVerifier) setAuth(auth ResponseAuth) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.auth = auth
+}
+
+// authenticated reports whether requests must currently be signed or carry an API token
+func (v *requestVerifier) authenticated() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.auth.required() || v.auth.accessEnabled()
+}
+
+// verify checks a request's signature against body and returns where it came from. Requests
+// without a signature are accepted only while no secret is configured.
+func (v *requestVerifier) verify(r *http.Request, body []byte, now time.Time) (string, error) {
+	v.mu.Lock()
+	auth := v.auth
+	v.mu.Unlock()
+
+	switch {
+	case r.Header.Get("X-Slack-Signature") != "":
+		if auth.SlackSigningSecret == "" {
+			return "", fmt.Errorf("Slack requests are not accepted: no Slack signing secret is configured")
+		}
+		timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+		if err := checkTimestamp(timestamp, now); err != nil {
+			return "", err
+		}
+		signature := r.Header.Get("X-Slack-Signature")
+		expected := "v0=" + sign(auth.SlackSigningSecret, "v0:"+timestamp+":", body)
+		if !hmac.Equal([]byte(signature), []byte(expected)) {
+			return "", fmt.Errorf("invalid Slack signature")
+		}
+		return sourceSlack, v.once("slack:"+signature, now.Add(signatureTolerance), now)
+
+	case r.Header.Get("X-Telegram-Bot-Api-Secret-Token") != "":
+		if auth.TelegramSecretToken == "" {
+			return "", fmt.Errorf("Telegram requests are not accepted: no Telegram secret token is configured")
+		}
+		token := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(auth.TelegramSecretToken)) != 1 {
+			return "", fmt.Errorf("invalid Telegram secret token")
+		}
+		var update struct {
+			UpdateID *int64 `json:"update_id"`
+		}
+		if err := json.Unmarshal(body, &update); err != nil || update.UpdateID == nil {
+			return "", fmt.Errorf("invalid Telegram update: no update_id")
+		}
+		key := "telegram:" + strconv.FormatInt(*update.UpdateID, 10)
+		return sourceTelegram, v.once(key, now.Add(telegramReplayWindow), now)
+
+	case r.Header.Get("X-ClaudeToGo-Signature") != "":
+		if auth.Secret == "" {
+			return "", fmt.Errorf("signed requests are not accepted: no response API secret is configured")
+		}
+		timestamp := r.Header.Get("X-ClaudeToGo-Timestamp")
+		if err := checkTimestamp(timestamp, now); err != nil {
+			return "", err
+		}
+		signature := r.Header.Get("X-ClaudeToGo-Signature")
+		expected := "sha256=" + sign(auth.Secret, timestamp+".", body)
+		if !hmac.Equal([]byte(signature), []byte(expected)) {
+			return "", fmt.Errorf("invalid signature")
+		}
+		return sourceHMAC, v.once("hmac:"+signature, now.Add(signatureTolerance), now)
+	}
+
+	if auth.required() {
+		return "", errUnsigned
+	}
+	return sourceUnsigned, nil
+}
+
+// once records key until expires, failing if it was already recorded: the request is a replay
+func (v *requestVerifier) once(key string, expires, now time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for seen, until := range v.seen {
+		if now.After(until) {
+			delete(v.seen, seen)
+		}
+	}
+	if _, ok := v.seen[key]; ok {
+		return fmt.Errorf("request was already received (replayed)")
+	}
+	v.seen[key] = expires
+	return nil
+}
+
+// checkTimestamp accepts a Unix timestamp header within signatureTolerance of now
+func checkTimestamp(header string, now time.Time) error {
+	seconds, err := strconv.ParseInt(header, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid request timestamp")
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return fmt.Errorf("request timestamp is more than %v from now", signatureTolerance)
+	}
+	return nil
+}
+
+// sign returns the hex HMAC-SHA256 of prefix followed by body
+func sign(secret, prefix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(prefix))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testAuth configures every kind of signature
+var testAuth = ResponseAuth{
+	Secret:              "hmac-secret",
+	SlackSigningSecret:  "slack-secret",
+	TelegramSecretToken: "telegram-token",
+}
+
+// signedRequest returns a POST request with the given headers
+func signedRequest(headers map[string]string) *http.Request {
+	r, _ := http.NewRequest(http.MethodPost, "/api/respond", nil)
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	return r
+}
+
+// hmacHeaders signs body as a ClaudeToGo client does at timestamp
+func hmacHeaders(secret string, timestamp time.Time, body []byte) map[string]string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return map[string]string{
+		"X-ClaudeToGo-Timestamp": ts,
+		"X-ClaudeToGo-Signature": "sha256=" + sign(secret, ts+".", body),
+	}
+}
+
+// slackHeaders signs body as Slack does at timestamp
+func slackHeaders(secret string, timestamp time.Time, body []byte) map[string]string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return map[string]string{
+		"X-Slack-Request-Timestamp": ts,
+		"X-Slack-Signature":         "v0=" + sign(secret, "v0:"+ts+":", body),
+	}
+}
+
+// without returns headers with one of them left out
+func without(headers map[string]string, name string) map[string]string {
+	copied := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if k != name {
+			copied[k] = v
+		}
+	}
+	return copied
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	stale := now.Add(-signatureTolerance - time.Second)
+	body := []byte(`{"session_id":"abc","action":"approve"}`)
+	update := []byte(`{"update_id":42,"callback_query":{"data":"approve:abc"}}`)
+	telegram := map[string]string{"X-Telegram-Bot-Api-Secret-Token": "telegram-token"}
+
+	tests := []struct {
+		name    string
+		auth    ResponseAuth
+		headers map[string]string
+		body    []byte
+		source  string
+		wantErr string
+	}{
+		{name: "hmac valid", auth: testAuth, headers: hmacHeaders("hmac-secret", now, body), body: body, source: sourceHMAC},
+		{name: "hmac wrong secret", auth: testAuth, headers: hmacHeaders("other", now, body), body: body, wantErr: "invalid signature"},
+		{name: "hmac other body", auth: testAuth, headers: hmacHeaders("hmac-secret", now, body), body: []byte(`{}`), wantErr: "invalid signature"},
+		{name: "hmac stale timestamp", auth: testAuth, headers: hmacHeaders("hmac-secret", stale, body), body: body, wantErr: "more than"},
+		{name: "hmac future timestamp", auth: testAuth, headers: hmacHeaders("hmac-secret", now.Add(signatureTolerance+time.Second), body), body: body, wantErr: "more than"},
+		{name: "hmac missing timestamp", auth: testAuth, headers: without(hmacHeaders("hmac-secret", now, body), "X-ClaudeToGo-Timestamp"), body: body, wantErr: "missing or invalid request timestamp"},
+		{name: "hmac missing signature", auth: testAuth, headers: without(hmacHeaders("hmac-secret", now, body), "X-ClaudeToGo-Signature"), body: body, wantErr: errUnsigned.Error()},
+		{name: "hmac not configured", auth: ResponseAuth{SlackSigningSecret: "slack-secret"}, headers: hmacHeaders("hmac-secret", now, body), body: body, wantErr: "no response API secret"},
+
+		{name: "slack valid", auth: testAuth, headers: slackHeaders("slack-secret", now, body), body: body, source: sourceSlack},
+		{name: "slack wrong secret", auth: testAuth, headers: slackHeaders("other", now, body), body: body, wantErr: "invalid Slack signature"},
+		{name: "slack stale timestamp", auth: testAuth, headers: slackHeaders("slack-secret", stale, body), body: body, wantErr: "more than"},
+		{name: "slack missing timestamp", auth: testAuth, headers: without(slackHeaders("slack-secret", now, body), "X-Slack-Request-Timestamp"), body: body, wantErr: "missing or invalid request timestamp"},
+		{name: "slack missing signature", auth: testAuth, headers: without(slackHeaders("slack-secret", now, body), "X-Slack-Signature"), body: body, wantErr: errUnsigned.Error()},
+		{name: "slack not configured", auth: ResponseAuth{Secret: "hmac-secret"}, headers: slackHeaders("slack-secret", now, body), body: body, wantErr: "no Slack signing secret"},
+
+		{name: "telegram valid", auth: testAuth, headers: telegram, body: update, source: sourceTelegram},
+		{name: "telegram wrong token", auth: testAuth, headers: map[string]string{"X-Telegram-Bot-Api-Secret-Token": "other"}, body: update, wantErr: "invalid Telegram secret token"},
+		{name: "telegram missing update_id", auth: testAuth, headers: telegram, body: []byte(`{"callback_query":{}}`), wantErr: "no update_id"},
+		{name: "telegram missing token", auth: testAuth, headers: nil, body: update, wantErr: errUnsigned.Error()},
+		{name: "telegram not configured", auth: ResponseAuth{Secret: "hmac-secret"}, headers: telegram, body: update, wantErr: "no Telegram secret token"},
+
+		{name: "unsigned without secrets", auth: ResponseAuth{}, headers: nil, body: body, source: sourceUnsigned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newRequestVerifier(tt.auth)
+			source, err := v.verify(signedRequest(tt.headers), tt.body, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("verify() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verify() error = %v", err)
+			}
+			if source != tt.source {
+				t.Errorf("verify() source = %q, want %q", source, tt.source)
+			}
+		})
+	}
+}
+
+func TestVerifyReplay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"session_id":"abc","action":"approve"}`)
+	update := []byte(`{"update_id":42}`)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    []byte
+		replay  time.Time // When the request is sent again
+	}{
+		{name: "hmac", headers: hmacHeaders("hmac-secret", now, body), body: body, replay: now.Add(time.Minute)},
+		{name: "slack", headers: slackHeaders("slack-secret", now, body), body: body, replay: now.Add(time.Minute)},
+		{name: "telegram", headers: map[string]string{"X-Telegram-Bot-Api-Secret-Token": "telegram-token"}, body: update, replay: now.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newRequestVerifier(testAuth)
+			if _, err := v.verify(signedRequest(tt.headers), tt.body, now); err != nil {
+				t.Fatalf("first verify() error = %v", err)
+			}
+			_, err := v.verify(signedRequest(tt.headers), tt.body, tt.replay)
+			if err == nil || !strings.Contains(err.Error(), "replayed") {
+				t.Fatalf("replayed verify() error = %v, want a replay error", err)
+			}
+		})
+	}
+}
+
+func TestVerifyTelegramReplayWindow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	update := []byte(`{"update_id":7}`)
+	headers := map[string]string{"X-Telegram-Bot-Api-Secret-Token": "telegram-token"}
+
+	v := newRequestVerifier(testAuth)
+	if _, err := v.verify(signedRequest(headers), update, now); err != nil {
+		t.Fatalf("first verify() error = %v", err)
+	}
+	// Update IDs are forgotten once the replay window has passed
+	if _, err := v.verify(signedRequest(headers), update, now.Add(telegramReplayWindow+time.Second)); err != nil {
+		t.Fatalf("verify() after the replay window error = %v", err)
+	}
+}
@@ -2,33 +2,66 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"reflect"
+	"sync"
 	"time"
 
+	"github.com/riaanpieterse81/ClaudeToGo/internal/filewatch"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/formatter"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/jsonl"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/logger"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/processor"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
 )
 
 // EventWatcher monitors claude-events.jsonl for new events and processes them automatically
 type EventWatcher struct {
-	eventsFile     string
-	outputDir      string
-	processor      *processor.EventProcessor
-	lastProcessed  time.Time
-	pollInterval   time.Duration
-	logger         *logger.Logger
-	lastFileSize   int64
-	lastEventCount int
+	eventsFile    string
+	outputDir     string
+	processor     *processor.EventProcessor
+	lastProcessed time.Time
+	pollInterval  time.Duration
+	usePolling    bool
+	logger        *logger.Logger
+	tail          *processor.Tail // Where reading the events file left off
+	statusFile    string
+	status        *ServiceStatus
+	statusMu      sync.Mutex // guards status, which every service component updates
+	metrics       *Metrics
+	reporter      *ErrorReporter
+	verifier      *requestVerifier
+	gate          *chatGate
+	settingsMu    sync.Mutex // held while processing, so reloaded settings apply between batches
+	initialized   bool
 }
 
 // WatcherConfig contains configuration for the event watcher
 type WatcherConfig struct {
-	EventsFile   string
-	OutputDir    string
-	PollInterval time.Duration
-	Logger       *logger.Logger
+	EventsFile     string
+	OutputDir      string
+	FileFormat     string
+	RotateSize     int64
+	Layout         string                   // Subdirectory template for json message files (empty = flat)
+	MaxLineSize    int64                    // Longest event and transcript line read; longer ones are skipped
+	Debounce       map[string]time.Duration // Window in which repeats of an event are dropped, by hook event name
+	Formatting     formatter.Options
+	PollInterval   time.Duration        // Poll interval, used when file system notifications are unavailable
+	UsePolling     bool                 // Poll instead of using file system notifications (network filesystems)
+	StatusFile     string               // Status file path; defaults to .watcher-status in OutputDir
+	PidFile        string               // Optional file the service PID is written to
+	LogFile        string               // Log file the caller opened for the service, if any
+	LogTarget      string               // stderr, syslog or journald, as opened by the caller
+	ErrorReporting ErrorReportingConfig // Where panics and repeated failures are reported
+	ResponseAuth   ResponseAuth         // Secrets responses to the response API must be signed with
+	ChatAllowlist  ChatAllowlist        // Chat users allowed to respond through the response API
+	AutoRestart    bool                 // Recover panics and restart components with backoff when they fail
+	Components     Components           // Pipeline components to run; the zero value runs only the watcher
+	ConfigFiles    []string             // Messenger config files (including layers) watched when Reload is set
+	Reload         ReloadFunc           // Reloads the configuration after one of ConfigFiles changes
+	Logger         *logger.Logger
 }
 
 // NewEventWatcher creates a new event watcher
@@ -44,13 +77,31 @@ func NewEventWatcher(config WatcherConfig) *EventWatcher {
 		config.PollInterval = 2 * time.Second
 	}
 
-	return &EventWatcher{
+	jsonl.SetMaxLineSize(config.MaxLineSize)
+	eventProcessor := processor.NewEventProcessor(config.OutputDir)
+	eventProcessor.SetFileFormat(config.FileFormat, config.RotateSize)
+	if err := eventProcessor.SetLayout(config.Layout); err != nil {
+		config.Logger.Error("Ignoring messenger layout: %v", err)
+	}
+	eventProcessor.SetFormatterOptions(config.Formatting)
+	eventProcessor.SetDebounce(config.Debounce)
+
+	ew := &EventWatcher{
 		eventsFile:   config.EventsFile,
 		outputDir:    config.OutputDir,
-		processor:    processor.NewEventProcessor(config.OutputDir),
+		processor:    eventProcessor,
+		tail:         processor.NewTail(config.EventsFile),
 		pollInterval: config.PollInterval,
-		logger:       config.Logger,
+		usePolling:   config.UsePolling,
+		logger:       config.Logger.Component("watcher"),
+		statusFile:   StatusFilePath(config.StatusFile, config.OutputDir),
+		metrics:      NewMetrics(),
+		reporter:     NewErrorReporter(config.ErrorReporting, config.Logger),
+		verifier:     newRequestVerifier(config.ResponseAuth),
+		gate:         newChatGate(config.ChatAllowlist),
 	}
+	eventProcessor.SetTimingsHandler(ew.recordTimings)
+	return ew
 }
 
 // Start begins monitoring the events file for changes
@@ -58,26 +109,55 @@ func (ew *EventWatcher) Start(ctx context.Context) error {
 	ew.logger.Info("Starting event watcher service...")
 	ew.logger.Info("Watching: %s", ew.eventsFile)
 	ew.logger.Info("Output: %s", ew.outputDir)
-	ew.logger.Info("Poll interval: %v", ew.pollInterval)
+	if ew.usePolling {
+		ew.logger.Info("Poll interval: %v", ew.pollInterval)
+	} else {
+		ew.logger.Info("Using file system notifications (poll fallback: %v)", ew.pollInterval)
+	}
+
+	// Initialize baseline once, so a restarted watcher picks up where it left off
+	if !ew.initialized {
+		if err := ew.initializeBaseline(); err != nil {
+			return fmt.Errorf("failed to initialize baseline: %w", err)
+		}
+		ew.initialized = true
+	}
+
+	// Metrics decay and responses arrive while the events file is idle, so they are
+	// refreshed on their own, slower, schedule
+	go ew.refreshMetricsEvery(ctx, metricsRefreshInterval)
+
+	check := func() {
+		if err := ew.checkForNewEvents(); err != nil {
+			ew.logger.Error("Error checking for new events: %v", err)
+			// Continue running despite errors
+		}
+	}
 
-	// Initialize baseline
-	if err := ew.initializeBaseline(); err != nil {
-		return fmt.Errorf("failed to initialize baseline: %w", err)
+	// Pick up anything written between the baseline and the watch starting
+	check()
+	opts := filewatch.Options{PollInterval: ew.pollInterval, ForcePolling: ew.usePolling}
+	if err := filewatch.Watch(ctx, ew.eventsFile, opts, ew.logger, check); err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("failed to watch events file: %w", err)
 	}
+	ew.logger.Info("Event watcher service stopped")
+	return nil
+}
+
+// metricsRefreshInterval is how often metrics are refreshed between events
+const metricsRefreshInterval = 5 * time.Second
 
-	ticker := time.NewTicker(ew.pollInterval)
+// refreshMetricsEvery refreshes the metrics every interval until ctx is cancelled
+func (ew *EventWatcher) refreshMetricsEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			ew.logger.Info("Event watcher service stopped")
-			return nil
+			return
 		case <-ticker.C:
-			if err := ew.checkForNewEvents(); err != nil {
-				ew.logger.Error("Error checking for new events: %v", err)
-				// Continue running despite errors
-			}
+			ew.refreshMetrics()
 		}
 	}
 }
@@ -87,28 +167,20 @@ func (ew *EventWatcher) initializeBaseline() error {
 	// Check if events file exists
 	if !ew.fileExists(ew.eventsFile) {
 		ew.logger.Info("Events file does not exist yet: %s", ew.eventsFile)
-		ew.lastFileSize = 0
-		ew.lastEventCount = 0
 		ew.lastProcessed = time.Now()
 		return nil
 	}
 
-	// Get initial file size
-	fileInfo, err := os.Stat(ew.eventsFile)
+	// Events logged while the watcher was stopped are handled now; on the first start, those
+	// already in the file are only recorded as processed
+	count, outputFiles, err := ew.processor.CatchUp(ew.tail)
 	if err != nil {
-		return fmt.Errorf("failed to stat events file: %w", err)
+		ew.logger.Warn("Could not catch up with the events file: %v", err)
 	}
-	ew.lastFileSize = fileInfo.Size()
-
-	// Get initial event count
-	stats, err := ew.processor.GetProcessingStats(ew.eventsFile)
-	if err != nil {
-		ew.logger.Debug("Could not get initial stats: %v", err)
-		ew.lastEventCount = 0
-	} else {
-		ew.lastEventCount = stats.TotalEvents
-		ew.logger.Info("Baseline established: %d events, %d bytes", ew.lastEventCount, ew.lastFileSize)
+	for _, file := range outputFiles {
+		ew.logger.Info("Generated: %s", file)
 	}
+	ew.logger.Info("Baseline established: %d events, %d bytes", count, ew.tail.Offset())
 
 	ew.lastProcessed = time.Now()
 	return nil
@@ -116,37 +188,25 @@ func (ew *EventWatcher) initializeBaseline() error {
 
 // checkForNewEvents checks if there are new events to process
 func (ew *EventWatcher) checkForNewEvents() error {
-	// Check if file exists
-	if !ew.fileExists(ew.eventsFile) {
-		return nil // File doesn't exist yet, that's OK
-	}
+	ew.settingsMu.Lock()
+	defer ew.settingsMu.Unlock()
 
-	// Check file size first (quick check)
-	fileInfo, err := os.Stat(ew.eventsFile)
-	if err != nil {
-		return fmt.Errorf("failed to stat events file: %w", err)
+	// Read only what was appended since the last check; a missing or unchanged file has nothing
+	events, reset, err := ew.processor.ReadTail(ew.tail)
+	if reset {
+		ew.logger.Warn("Events file %s was truncated, rotated or replaced, reading it from the start", ew.eventsFile)
 	}
-
-	currentFileSize := fileInfo.Size()
-	if currentFileSize == ew.lastFileSize {
-		// No change in file size, skip processing
-		return nil
-	}
-
-	// File has changed, check event count
-	stats, err := ew.processor.GetProcessingStats(ew.eventsFile)
 	if err != nil {
-		return fmt.Errorf("failed to get processing stats: %w", err)
+		ew.updateStatus(0, len(events), err)
+		return fmt.Errorf("failed to read new events: %w", err)
 	}
 
-	if stats.TotalEvents > ew.lastEventCount {
-		newEvents := stats.TotalEvents - ew.lastEventCount
-		ew.logger.Info("Detected %d new event(s), processing...", newEvents)
-
-		// Process the new events
-		outputFiles, err := ew.processNewEvents(newEvents)
-		if err != nil {
-			return fmt.Errorf("failed to process new events: %w", err)
+	if len(events) > 0 {
+		ew.logger.Info("Detected %d new event(s), processing...", len(events))
+		// Events seen before (the file was rewritten or compacted) are skipped
+		outputFiles := ew.processor.ProcessNewEvents(events)
+		if err := ew.processor.SaveTail(ew.tail); err != nil {
+			ew.logger.Debug("Could not save events file position: %v", err)
 		}
 
 		// Log results
@@ -154,20 +214,79 @@ func (ew *EventWatcher) checkForNewEvents() error {
 			ew.logger.Info("Generated: %s", file)
 		}
 
-		// Update tracking variables
-		ew.lastEventCount = stats.TotalEvents
-		ew.lastFileSize = currentFileSize
 		ew.lastProcessed = time.Now()
-
 		ew.logger.Info("Successfully processed %d new events", len(outputFiles))
+		ew.updateStatus(len(outputFiles), 0, nil)
 	}
 
 	return nil
 }
 
-// processNewEvents processes the most recent events
-func (ew *EventWatcher) processNewEvents(count int) ([]string, error) {
-	return ew.processor.ProcessLatestEvents(ew.eventsFile, count)
+// applySettings switches the processor to reloaded output and formatting settings
+func (ew *EventWatcher) applySettings(config WatcherConfig) {
+	ew.settingsMu.Lock()
+	defer ew.settingsMu.Unlock()
+
+	ew.processor.SetFileFormat(config.FileFormat, config.RotateSize)
+	if err := ew.processor.SetLayout(config.Layout); err != nil {
+		ew.logger.Error("Ignoring messenger layout: %v", err)
+	}
+	ew.processor.SetFormatterOptions(config.Formatting)
+	ew.processor.SetDebounce(config.Debounce)
+	jsonl.SetMaxLineSize(config.MaxLineSize)
+	ew.reporter.SetConfig(config.ErrorReporting)
+	ew.verifier.setAuth(config.ResponseAuth)
+	ew.gate.setAllowlist(config.ChatAllowlist)
+}
+
+// updateStatus records processing progress in the status file
+func (ew *EventWatcher) updateStatus(processed, backlog int, procErr error) {
+	ew.statusMu.Lock()
+	defer ew.statusMu.Unlock()
+	if ew.status == nil {
+		return
+	}
+
+	ew.status.EventsProcessed += processed
+	ew.status.Backlog = backlog
+	ew.metrics.RecordEvents(processed, time.Now())
+	if event := ew.processor.LastSavedEvent(); event != nil {
+		ew.status.LastEvent = &EventInfo{
+			SessionID:   event.SessionID,
+			Type:        event.HookEventName,
+			Timestamp:   event.Timestamp,
+			ProcessedAt: ew.lastProcessed,
+		}
+	}
+	failed := false
+	// Events quarantined since the last update failed to process
+	for _, event := range ew.processor.TakeQuarantined() {
+		failed = true
+		ew.reporter.Failure("processing", errors.New(event.Error), map[string]interface{}{
+			"session_id":  event.Event.SessionID,
+			"event":       event.Event.HookEventName,
+			"tool":        event.Event.ToolName,
+			"events_file": ew.eventsFile,
+		})
+	}
+	if count, err := ew.processor.QuarantinedCount(); err == nil {
+		ew.status.FailedEvents = count
+	}
+	if procErr != nil {
+		now := time.Now()
+		ew.status.LastError = procErr.Error()
+		ew.status.LastErrorAt = &now
+		failed = true
+		ew.reporter.Failure("processing", procErr, map[string]interface{}{"events_file": ew.eventsFile})
+	}
+	if !failed && processed > 0 {
+		ew.reporter.Success("processing")
+	}
+	ew.snapshotMetrics()
+
+	if err := writeStatus(ew.statusFile, ew.status); err != nil {
+		ew.logger.Debug("Could not update status file: %v", err)
+	}
 }
 
 // GetStats returns current watcher statistics
@@ -220,32 +339,160 @@ func ServiceMode(ctx context.Context, config WatcherConfig) error {
 	}
 
 	// Create a status file to indicate the service is running
-	statusFile := filepath.Join(config.OutputDir, ".watcher-status")
-	if err := watcher.createStatusFile(statusFile); err != nil {
+	if err := watcher.createStatusFile(config.componentNames()); err != nil {
 		config.Logger.Debug("Could not create status file: %v", err)
 	}
+	if config.PidFile != "" {
+		if err := os.WriteFile(config.PidFile, []byte(fmt.Sprintf("%d\n", os.Getpid())), 0644); err != nil {
+			config.Logger.Error("Could not write PID file: %v", err)
+		}
+	}
 
-	// Clean up status file when done
+	// Clean up status and PID files when done
 	defer func() {
-		if err := os.Remove(statusFile); err != nil {
+		if err := os.Remove(watcher.statusFile); err != nil {
 			config.Logger.Debug("Could not remove status file: %v", err)
 		}
+		if config.PidFile != "" {
+			if err := os.Remove(config.PidFile); err != nil {
+				config.Logger.Debug("Could not remove PID file: %v", err)
+			}
+		}
 	}()
 
-	return watcher.Start(ctx)
+	return runComponents(ctx, config, watcher)
+}
+
+// recordRestart notes a component restart in the status file
+func (ew *EventWatcher) recordRestart(name string, restarts int, err error) {
+	ew.statusMu.Lock()
+	defer ew.statusMu.Unlock()
+	if ew.status == nil {
+		return
+	}
+
+	now := time.Now()
+	if ew.status.Restarts == nil {
+		ew.status.Restarts = make(map[string]int)
+	}
+	ew.status.Restarts[name] = restarts
+	ew.status.LastError = err.Error()
+	ew.status.LastErrorAt = &now
+
+	if err := writeStatus(ew.statusFile, ew.status); err != nil {
+		ew.logger.Debug("Could not update status file: %v", err)
+	}
+}
+
+// recordDelivery counts a delivery result in the status file
+func (ew *EventWatcher) recordDelivery(message *types.MessengerMessage, err error, took time.Duration, queued int) {
+	ew.statusMu.Lock()
+	defer ew.statusMu.Unlock()
+	if ew.status == nil {
+		return
+	}
+
+	ew.status.DeliveryQueue = queued
+	if err != nil {
+		now := time.Now()
+		ew.status.DeliveryFailures++
+		ew.status.LastError = err.Error()
+		ew.status.LastErrorAt = &now
+		ew.metrics.RecordDeliveryError()
+		ew.reporter.Failure("delivery", err, map[string]interface{}{
+			"message_id": message.MessageID,
+			"session_id": message.SessionID,
+			"type":       message.Type,
+		})
+	} else {
+		ew.status.MessagesDelivered++
+		ew.metrics.RecordStage("delivery", took)
+		ew.reporter.Success("delivery")
+	}
+	ew.snapshotMetrics()
+
+	if err := writeStatus(ew.statusFile, ew.status); err != nil {
+		ew.logger.Debug("Could not update status file: %v", err)
+	}
+}
+
+// recordTimings logs the time an event spent in each processing stage and adds it to the metrics
+func (ew *EventWatcher) recordTimings(event *types.ClaudeHookEvent, timings processor.StageTimings) {
+	ew.logger.WithEvent(*event).Debug("Stage timings: %s", timings)
+	ew.metrics.RecordStage("transcript_read", timings.TranscriptRead)
+	ew.metrics.RecordStage("extraction", timings.Extraction)
+	ew.metrics.RecordStage("formatting", timings.Formatting)
+	ew.metrics.RecordStage("write", timings.Write)
+}
+
+// refreshMetrics counts new responses and publishes the metrics, writing the status file
+// only when they changed (the event rate decays while no events arrive)
+func (ew *EventWatcher) refreshMetrics() {
+	ew.metrics.ScanResponses(ew.outputDir)
+
+	ew.statusMu.Lock()
+	defer ew.statusMu.Unlock()
+	if ew.status == nil {
+		return
+	}
+	previous := ew.status.Metrics
+	ew.snapshotMetrics()
+	if previous != nil && reflect.DeepEqual(*previous, *ew.status.Metrics) {
+		return
+	}
+	if err := writeStatus(ew.statusFile, ew.status); err != nil {
+		ew.logger.Debug("Could not update status file: %v", err)
+	}
+}
+
+// snapshotMetrics puts the current metrics in the status; statusMu must be held
+func (ew *EventWatcher) snapshotMetrics() {
+	snapshot := ew.metrics.Snapshot(time.Now(), ew.status.Backlog+ew.status.DeliveryQueue)
+	ew.status.Metrics = &snapshot
+}
+
+// recordHeartbeat refreshes the status file with the latest heartbeat
+func (ew *EventWatcher) recordHeartbeat(at time.Time, interval time.Duration, lastHookEvent, stalledSince time.Time) {
+	ew.statusMu.Lock()
+	defer ew.statusMu.Unlock()
+	if ew.status == nil {
+		return
+	}
+
+	ew.status.HeartbeatAt = &at
+	ew.status.HeartbeatInterval = interval.String()
+	ew.status.LastHookEvent = nil
+	if !lastHookEvent.IsZero() {
+		ew.status.LastHookEvent = &lastHookEvent
+	}
+	ew.status.StalledSince = nil
+	if !stalledSince.IsZero() {
+		ew.status.StalledSince = &stalledSince
+	}
+
+	if err := writeStatus(ew.statusFile, ew.status); err != nil {
+		ew.logger.Debug("Could not update status file: %v", err)
+	}
 }
 
 // createStatusFile creates a status file indicating the service is running
-func (ew *EventWatcher) createStatusFile(statusFile string) error {
-	status := fmt.Sprintf(`{
-  "service": "claudetogo-watcher",
-  "status": "running",
-  "started": "%s",
-  "events_file": "%s",
-  "output_dir": "%s",
-  "poll_interval": "%s",
-  "pid": %d
-}`, time.Now().Format(time.RFC3339), ew.eventsFile, ew.outputDir, ew.pollInterval, os.Getpid())
-
-	return os.WriteFile(statusFile, []byte(status), 0644)
-}
\ No newline at end of file
+func (ew *EventWatcher) createStatusFile(components []string) error {
+	ew.statusMu.Lock()
+	defer ew.statusMu.Unlock()
+
+	ew.status = &ServiceStatus{
+		Service:      "claudetogo-watcher",
+		Status:       "running",
+		PID:          os.Getpid(),
+		Started:      time.Now(),
+		EventsFile:   ew.eventsFile,
+		OutputDir:    ew.outputDir,
+		PollInterval: ew.pollInterval.String(),
+		Components:   components,
+	}
+	if count, err := ew.processor.QuarantinedCount(); err == nil {
+		ew.status.FailedEvents = count
+	}
+
+	return writeStatus(ew.statusFile, ew.status)
+}
@@ -0,0 +1,9 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package session
+
+// lockFile does nothing: there is no flock on this platform, so only registryMu serializes
+// updates, within one process
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,499 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/atomicfile"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// RegistryFileName persists the session registry in the output directory
+const RegistryFileName = ".sessions.json"
+
+// maxRecords is how many sessions the registry keeps; those with the oldest last event are
+// dropped beyond it
+const maxRecords = 1000
+
+// Session statuses
+const (
+	StatusActive    = "active"    // Claude is working
+	StatusWaiting   = "waiting"   // Claude asked for permission and nobody has responded yet
+	StatusCompleted = "completed" // Claude finished the task
+	StatusFailed    = "failed"    // Claude stopped with an error
+	StatusEnded     = "ended"     // The Claude Code session was closed
+)
+
+// maxResponses is how many responses a record keeps for the session's timeline
+const maxResponses = 100
+
+// ConcurrentWindow is how recently another session of a project must have had an event to
+// count as running at the same time
+const ConcurrentWindow = time.Hour
+
+// Record is what the registry knows about one Claude session
+type Record struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name,omitempty"` // Assigned with `claudetogo sessions name`
+	Project        string     `json:"project,omitempty"`
+	CWD            string     `json:"cwd,omitempty"`
+	Branch         string     `json:"branch,omitempty"`
+	Status         string     `json:"status"`
+	FirstSeen      time.Time  `json:"first_seen"`
+	LastEvent      time.Time  `json:"last_event"`
+	LastEventType  string     `json:"last_event_type"` // Message type of the last event
+	LastTitle      string     `json:"last_title,omitempty"`
+	LastMessageID  string     `json:"last_message_id,omitempty"`
+	Events         int        `json:"events"`
+	Requests       int        `json:"requests"` // Permission requests (action_needed messages)
+	Approvals      int        `json:"approvals"`
+	Rejections     int        `json:"rejections"`
+	LastResponseAt *time.Time `json:"last_response_at,omitempty"`
+	Started        *time.Time `json:"started,omitempty"` // SessionStart hook event
+	Ended          *time.Time `json:"ended,omitempty"`   // SessionEnd hook event
+	Responses      []Response `json:"responses,omitempty"`
+	Tags           []string   `json:"tags,omitempty"`  // Assigned with `claudetogo sessions tag`
+	Notes          []Note     `json:"notes,omitempty"` // Added with `claudetogo sessions note`
+}
+
+// Response is an approval or rejection given for a session
+type Response struct {
+	Action    string     `json:"action"`
+	At        time.Time  `json:"at"`
+	Requested *time.Time `json:"requested,omitempty"` // When the request being answered was made
+}
+
+// Latency returns how long the request waited for the response, if known
+func (r Response) Latency() (time.Duration, bool) {
+	if r.Requested == nil || r.At.Before(*r.Requested) {
+		return 0, false
+	}
+	return r.At.Sub(*r.Requested), true
+}
+
+// Note is a remark left on a session for whoever triages it
+type Note struct {
+	Text string    `json:"text"`
+	At   time.Time `json:"at"`
+}
+
+// HasTag reports whether the session carries a tag, ignoring case
+func (r Record) HasTag(tag string) bool {
+	for _, have := range r.Tags {
+		if strings.EqualFold(have, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// LastNote returns the text of the session's latest note, or "" without notes
+func (r Record) LastNote() string {
+	if len(r.Notes) == 0 {
+		return ""
+	}
+	return r.Notes[len(r.Notes)-1].Text
+}
+
+// Label names the session for people telling it apart from others of its project: the name
+// it was given, else its branch and start time, e.g. "main 14:05"
+func (r Record) Label() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	start := r.Start().Local()
+	label := start.Format("15:04")
+	if now := time.Now(); start.YearDay() != now.YearDay() || start.Year() != now.Year() {
+		label = start.Format("Jan 2 15:04")
+	}
+	if r.Branch != "" {
+		label = r.Branch + " " + label
+	}
+	return label
+}
+
+// Start returns when the session started: its SessionStart event, else its first event
+func (r Record) Start() time.Time {
+	if r.Started != nil {
+		return *r.Started
+	}
+	return r.FirstSeen
+}
+
+// End returns when the session ended: its SessionEnd event, else its last event
+func (r Record) End() time.Time {
+	if r.Ended != nil {
+		return *r.Ended
+	}
+	return r.LastEvent
+}
+
+// Duration returns how long the session ran from its start to its end
+func (r Record) Duration() time.Duration {
+	if d := r.End().Sub(r.Start()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Registry tracks the lifecycle of Claude sessions in a JSON file, so a session exists
+// independently of the message files written for it
+type Registry struct {
+	path string
+}
+
+// registryMu serializes updates from the processor and responder within one process; a lock
+// on the registry's .lock file serializes them across processes, such as the service and a
+// `claudetogo respond` run
+var registryMu sync.Mutex
+
+// NewRegistry returns the registry kept in an output directory
+func NewRegistry(outputDir string) *Registry {
+	return &Registry{path: filepath.Join(outputDir, RegistryFileName)}
+}
+
+// Path returns the registry file
+func (r *Registry) Path() string {
+	return r.path
+}
+
+// RecordMessage registers the event behind a message: it creates the session on first
+// sight, moves its status along and counts events and permission requests. Reprocessing
+// an event the registry has already seen changes nothing.
+func (r *Registry) RecordMessage(message *types.MessengerMessage) error {
+	if message.SessionID == "" {
+		return nil
+	}
+	at, err := time.Parse(time.RFC3339Nano, message.Timestamp)
+	if err != nil {
+		at = time.Now()
+	}
+
+	return r.update(func(records map[string]*Record) bool {
+		record := records[message.SessionID]
+		if record == nil {
+			record = &Record{ID: message.SessionID, FirstSeen: at}
+			records[message.SessionID] = record
+		} else if at.Before(record.LastEvent) || (at.Equal(record.LastEvent) && message.MessageID == record.LastMessageID) {
+			return false
+		}
+
+		record.LastEvent = at
+		record.LastEventType = message.Type
+		record.LastTitle = message.Title
+		record.LastMessageID = message.MessageID
+		record.Events++
+		if project := messageProject(message); project != "" {
+			record.Project = project
+		}
+		if cwd, _ := message.Context["cwd"].(string); cwd != "" {
+			record.CWD = cwd
+		}
+		if branch, _ := message.Context["git_branch"].(string); branch != "" {
+			record.Branch = branch
+		}
+
+		switch message.Type {
+		case "action_needed":
+			record.Requests++
+			record.Status = StatusWaiting
+		case "completion":
+			record.Status = StatusCompleted
+			if status, _ := message.Context["task_status"].(string); status == "error" {
+				record.Status = StatusFailed
+			}
+		default:
+			record.Status = StatusActive
+		}
+		return true
+	})
+}
+
+// RecordLifecycle registers a SessionStart or SessionEnd hook event, which mark when a
+// session started and ended without producing a message
+func (r *Registry) RecordLifecycle(sessionID, hookEvent, cwd string, at time.Time) error {
+	if sessionID == "" || (hookEvent != "SessionStart" && hookEvent != "SessionEnd") {
+		return nil
+	}
+	return r.update(func(records map[string]*Record) bool {
+		record := records[sessionID]
+		if record == nil {
+			record = &Record{ID: sessionID, FirstSeen: at, LastEvent: at, Status: StatusActive}
+			records[sessionID] = record
+		}
+
+		if hookEvent == "SessionStart" {
+			if record.Started != nil && record.Started.Equal(at) {
+				return false
+			}
+			record.Started = &at
+			if at.Before(record.FirstSeen) {
+				record.FirstSeen = at
+			}
+		} else {
+			if record.Ended != nil && record.Ended.Equal(at) {
+				return false
+			}
+			record.Ended = &at
+			record.Status = StatusEnded
+		}
+
+		record.Events++
+		if !at.Before(record.LastEvent) {
+			record.LastEvent = at
+			record.LastEventType = strings.ToLower(hookEvent)
+		}
+		if record.CWD == "" {
+			record.CWD = cwd
+		}
+		return true
+	})
+}
+
+// RecordResponse counts an approval or rejection of a request made at requested (zero when
+// unknown); a waiting session becomes active again
+func (r *Registry) RecordResponse(sessionID, action string, requested time.Time) error {
+	if action != "approve" && action != "reject" {
+		return nil
+	}
+	return r.update(func(records map[string]*Record) bool {
+		record := records[sessionID]
+		if record == nil {
+			record = &Record{ID: sessionID, FirstSeen: time.Now(), Status: StatusActive}
+			records[sessionID] = record
+		}
+		if action == "approve" {
+			record.Approvals++
+		} else {
+			record.Rejections++
+		}
+		now := time.Now()
+		record.LastResponseAt = &now
+		response := Response{Action: action, At: now}
+		if !requested.IsZero() {
+			response.Requested = &requested
+		}
+		record.Responses = append(record.Responses, response)
+		if len(record.Responses) > maxResponses {
+			record.Responses = record.Responses[len(record.Responses)-maxResponses:]
+		}
+		if record.Status == StatusWaiting {
+			record.Status = StatusActive
+		}
+		return true
+	})
+}
+
+// SetName gives a session a name to label its notifications with; an empty name clears it
+func (r *Registry) SetName(sessionID, name string) error {
+	return r.update(func(records map[string]*Record) bool {
+		recordFor(records, sessionID).Name = name
+		return true
+	})
+}
+
+// Tag adds tags to a session, or removes them when remove is set, and returns the session's
+// tags afterwards. Tags that differ only in case are the same tag.
+func (r *Registry) Tag(sessionID string, tags []string, remove bool) ([]string, error) {
+	var result []string
+	err := r.update(func(records map[string]*Record) bool {
+		record := recordFor(records, sessionID)
+		for _, tag := range tags {
+			switch {
+			case remove:
+				record.Tags = slices.DeleteFunc(record.Tags, func(have string) bool { return strings.EqualFold(have, tag) })
+			case !record.HasTag(tag):
+				record.Tags = append(record.Tags, tag)
+			}
+		}
+		result = slices.Clone(record.Tags)
+		return true
+	})
+	return result, err
+}
+
+// AddNote leaves a note on a session; an empty text removes all of its notes
+func (r *Registry) AddNote(sessionID, text string) error {
+	return r.update(func(records map[string]*Record) bool {
+		record := recordFor(records, sessionID)
+		if text == "" {
+			record.Notes = nil
+		} else {
+			record.Notes = append(record.Notes, Note{Text: text, At: time.Now()})
+		}
+		return true
+	})
+}
+
+// recordFor returns a session's record, registering the session if it isn't yet
+func recordFor(records map[string]*Record, sessionID string) *Record {
+	record := records[sessionID]
+	if record == nil {
+		record = &Record{ID: sessionID, FirstSeen: time.Now(), LastEvent: time.Now(), Status: StatusActive}
+		records[sessionID] = record
+	}
+	return record
+}
+
+// Concurrent reports whether another session of a project was running at a time: it hasn't
+// ended and had an event within ConcurrentWindow before then
+func (r *Registry) Concurrent(sessionID, project string, at time.Time) (bool, error) {
+	if project == "" {
+		return false, nil
+	}
+	records, err := r.List()
+	if err != nil {
+		return false, err
+	}
+	for _, record := range records {
+		if record.ID == sessionID || !strings.EqualFold(record.Project, project) || record.Status == StatusEnded {
+			continue
+		}
+		if record.Start().After(at) {
+			continue
+		}
+		if at.Sub(record.LastEvent) < ConcurrentWindow {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// List returns the registered sessions, most recent event first
+func (r *Registry) List() ([]Record, error) {
+	registryMu.Lock()
+	records, err := r.load()
+	registryMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Record, 0, len(records))
+	for _, record := range records {
+		list = append(list, *record)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].LastEvent.Equal(list[j].LastEvent) {
+			return list[i].LastEvent.After(list[j].LastEvent)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// Get returns the record of a session, or nil when it isn't registered
+func (r *Registry) Get(sessionID string) (*Record, error) {
+	registryMu.Lock()
+	records, err := r.load()
+	registryMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return records[sessionID], nil
+}
+
+// IDs returns the full IDs of all registered sessions
+func (r *Registry) IDs() ([]string, error) {
+	records, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+	return ids, nil
+}
+
+// update applies a change to the registry and saves it when change reports a modification
+func (r *Registry) update(change func(map[string]*Record) bool) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	unlock, err := lockFile(r.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock session registry: %w", err)
+	}
+	defer unlock()
+
+	records, err := r.load()
+	if err != nil {
+		return err
+	}
+	if !change(records) {
+		return nil
+	}
+	prune(records)
+	return r.save(records)
+}
+
+// prune drops the sessions with the oldest last event beyond maxRecords
+func prune(records map[string]*Record) {
+	if len(records) <= maxRecords {
+		return
+	}
+	ids := make([]string, 0, len(records))
+	for id := range records {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return records[ids[i]].LastEvent.Before(records[ids[j]].LastEvent) })
+	for _, id := range ids[:len(ids)-maxRecords] {
+		delete(records, id)
+	}
+}
+
+// load reads the registry; a missing file is an empty registry
+func (r *Registry) load() (map[string]*Record, error) {
+	records := make(map[string]*Record)
+	data, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session registry: %w", err)
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse session registry %s: %w", r.path, err)
+	}
+	return records, nil
+}
+
+// save writes the registry through a temp file so readers never see half of it
+func (r *Registry) save(records map[string]*Record) error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session registry: %w", err)
+	}
+	if err := atomicfile.WriteFile(r.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session registry: %w", err)
+	}
+	return nil
+}
+
+// messageProject names the project a message came from. Messages from before project
+// labels fall back to the repository, else the working directory's name.
+func messageProject(message *types.MessengerMessage) string {
+	if project, _ := message.Context["project"].(string); project != "" {
+		return project
+	}
+	if repo, _ := message.Context["repo_name"].(string); repo != "" {
+		return repo
+	}
+	if project, _ := message.Context["cwd_basename"].(string); project != "." && project != "/" {
+		return project
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+// Package session resolves the session IDs users type, which are usually a prefix of the
+// full ID shown in notifications
+package session
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// shortLength is how many characters of a session ID are shown
+const shortLength = 8
+
+// ErrNotFound is returned when no known session starts with a prefix
+var ErrNotFound = errors.New("no session matches")
+
+// AmbiguousError is returned when a prefix matches more than one session
+type AmbiguousError struct {
+	Prefix     string
+	Candidates []string // Full session IDs, sorted
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("session %q is ambiguous, it matches %d sessions: %s; give more of the ID",
+		e.Prefix, len(e.Candidates), strings.Join(e.Candidates, ", "))
+}
+
+// Resolve returns the full session ID among ids that prefix stands for. An exact match
+// wins over longer IDs that start with it.
+func Resolve(prefix string, ids []string) (string, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return "", fmt.Errorf("session ID cannot be empty")
+	}
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, id := range ids {
+		if id == prefix {
+			return id, nil
+		}
+		if strings.HasPrefix(id, prefix) && !seen[id] {
+			seen[id] = true
+			candidates = append(candidates, id)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("%w %s", ErrNotFound, prefix)
+	case 1:
+		return candidates[0], nil
+	}
+	sort.Strings(candidates)
+	return "", &AmbiguousError{Prefix: prefix, Candidates: candidates}
+}
+
+// Short returns the first 8 characters of a session ID, or the whole ID when it is shorter
+func Short(sessionID string) string {
+	if len(sessionID) > shortLength {
+		return sessionID[:shortLength]
+	}
+	return sessionID
+}
+
+// FileName returns a session ID for use in a file name. File names carry the full ID, so
+// sessions sharing their first characters never share files; characters that would add a
+// path level are replaced.
+func FileName(sessionID string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, sessionID)
+}
+
+// WithLabel follows a session ID with its label, e.g. "1fa8811f (main 14:05)"
+func WithLabel(sessionID, label string) string {
+	if label == "" {
+		return sessionID
+	}
+	return sessionID + " (" + label + ")"
+}
@@ -0,0 +1,200 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/channels"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/secrets"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// chatIDWait is how long the wizard waits for a /start message to the Telegram bot
+const chatIDWait = 2 * time.Minute
+
+// configureChannel asks for a messenger channel, sends a test notification through it and
+// saves it to the config file with delivery enabled. It returns false if the user skipped.
+func configureChannel(configPath string) (bool, error) {
+	fmt.Println("📱 Choose where notifications should be sent:")
+	fmt.Println("  [1] Telegram bot")
+	fmt.Println("  [2] Slack channel")
+	fmt.Println("  [3] Webhook (your own HTTP endpoint)")
+	fmt.Println("  [4] Skip")
+	fmt.Print("Choose [1-4]: ")
+	var choice string
+	fmt.Scanln(&choice)
+	fmt.Println()
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	switch choice {
+	case "1":
+		return setupTelegram(configPath, client)
+	case "2":
+		return setupSlack(configPath, client)
+	case "3":
+		return setupWebhook(configPath, client)
+	default:
+		return false, nil
+	}
+}
+
+// setupTelegram configures a Telegram bot, finding the chat ID from a /start message
+func setupTelegram(configPath string, client *http.Client) (bool, error) {
+	fmt.Println("🤖 Create a bot by messaging @BotFather in Telegram (/newbot) and paste its token.")
+	token := readSecret("   Bot token: ")
+	if token == "" {
+		return false, fmt.Errorf("no bot token given")
+	}
+
+	fmt.Printf("📨 Now open your bot in Telegram and send it /start (waiting up to %v)...\n", chatIDWait)
+	ctx, cancel := context.WithTimeout(context.Background(), chatIDWait)
+	chatID, err := channels.DiscoverTelegramChatID(ctx, client, token)
+	cancel()
+	if err != nil {
+		fmt.Printf("⚠️  Could not find the chat: %v\n", err)
+		fmt.Print("   Enter the chat ID yourself (Enter to cancel): ")
+		fmt.Scanln(&chatID)
+		if chatID == "" {
+			return false, fmt.Errorf("no Telegram chat ID")
+		}
+	} else {
+		fmt.Printf("✓ Found chat %s\n", chatID)
+	}
+
+	channel := &channels.Telegram{Token: token, ChatID: chatID}
+	if !sendTestNotification(channel, client) {
+		return false, nil
+	}
+	if err := saveSecret(configPath, "telegram_token", token); err != nil {
+		return false, err
+	}
+	if err := config.SetValue(configPath, "integrations.telegram_chat_id", chatID); err != nil {
+		return false, err
+	}
+	return true, enableDelivery(configPath)
+}
+
+// setupSlack configures a Slack bot token and channel
+func setupSlack(configPath string, client *http.Client) (bool, error) {
+	fmt.Println("🤖 Create a Slack app with the chat:write scope, install it to your workspace and")
+	fmt.Println("   paste its Bot User OAuth Token (xoxb-...). Invite the bot to the channel first.")
+	token := readSecret("   Bot token: ")
+	if token == "" {
+		return false, fmt.Errorf("no bot token given")
+	}
+	fmt.Print("   Channel (e.g. #claude or a channel ID): ")
+	var slackChannel string
+	fmt.Scanln(&slackChannel)
+	if slackChannel == "" {
+		return false, fmt.Errorf("no Slack channel given")
+	}
+
+	channel := &channels.Slack{Token: token, Channel: slackChannel}
+	if !sendTestNotification(channel, client) {
+		return false, nil
+	}
+	if err := saveSecret(configPath, "slack_token", token); err != nil {
+		return false, err
+	}
+	if err := config.SetValue(configPath, "integrations.slack_channel", slackChannel); err != nil {
+		return false, err
+	}
+	return true, enableDelivery(configPath)
+}
+
+// setupWebhook configures a webhook URL that receives every message as JSON
+func setupWebhook(configPath string, client *http.Client) (bool, error) {
+	fmt.Println("🔗 Every message will be POSTed to this URL as JSON.")
+	fmt.Print("   Webhook URL: ")
+	var url string
+	fmt.Scanln(&url)
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return false, fmt.Errorf("webhook URL must start with http:// or https://")
+	}
+
+	channel := &channels.Webhook{URL: url}
+	if !sendTestNotification(channel, client) {
+		return false, nil
+	}
+	if err := saveSecret(configPath, "webhook_url", url); err != nil {
+		return false, err
+	}
+	return true, enableDelivery(configPath)
+}
+
+// sendTestNotification sends a test message and reports whether to keep the channel
+func sendTestNotification(channel channels.Channel, client *http.Client) bool {
+	message := &types.MessengerMessage{
+		Type:      "completion",
+		Title:     "✅ ClaudeToGo test notification",
+		Message:   "Notifications from Claude Code will arrive here.",
+		Context:   map[string]interface{}{"test": true},
+		Timestamp: time.Now().Format(time.RFC3339),
+		MessageID: fmt.Sprintf("setup-test-%d", time.Now().Unix()),
+	}
+
+	fmt.Printf("📤 Sending a test notification via %s...\n", channel.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := channel.Send(ctx, client, message); err != nil {
+		fmt.Printf("❌ Test notification failed: %v\n", err)
+		fmt.Print("   Save these settings anyway? [y/N]: ")
+		var answer string
+		fmt.Scanln(&answer)
+		return strings.ToLower(answer) == "y" || strings.ToLower(answer) == "yes"
+	}
+
+	fmt.Print("✓ Test notification sent. Did it arrive? [Y/n]: ")
+	var answer string
+	fmt.Scanln(&answer)
+	if strings.ToLower(answer) == "n" || strings.ToLower(answer) == "no" {
+		fmt.Println("✓ Settings not saved; check them and run --setup again")
+		return false
+	}
+	return true
+}
+
+// saveSecret stores a token in the OS keyring, falling back to the config file when no
+// keyring is available
+func saveSecret(configPath, name, value string) error {
+	err := secrets.Set(name, value)
+	if err == nil {
+		fmt.Printf("🔐 Stored %s in the OS keyring\n", name)
+		return nil
+	}
+	fmt.Printf("⚠️  OS keyring unavailable (%v); saving %s to %s\n", err, name, configPath)
+	return config.SetValue(configPath, "integrations."+name, value)
+}
+
+// enableDelivery turns on the delivery component of the service
+func enableDelivery(configPath string) error {
+	if err := config.SetValue(configPath, "service.components.delivery", "true"); err != nil {
+		return fmt.Errorf("failed to enable delivery: %w", err)
+	}
+	return nil
+}
+
+// readSecret prompts for a value without echoing it when stdin is a terminal
+func readSecret(prompt string) string {
+	fmt.Print(prompt)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		value, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(value))
+	}
+
+	var value string
+	fmt.Scanln(&value)
+	return strings.TrimSpace(value)
+}
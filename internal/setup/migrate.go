@@ -0,0 +1,99 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/config"
+)
+
+// migrateExistingConfig offers to upgrade configuration left by an older version: a
+// claudetogo-config.json is moved into the YAML config and deprecated keys are removed.
+// The YAML file is backed up before it is changed.
+func migrateExistingConfig(configPath string, dryRun bool) error {
+	legacy := fileExists(config.LegacyConfigFile)
+	var deprecatedKeys []string
+	if fileExists(configPath) {
+		keys, err := config.DeprecatedKeys(configPath)
+		if err != nil {
+			fmt.Printf("⚠️  Could not check %s for deprecated keys: %v\n", configPath, err)
+		}
+		deprecatedKeys = keys
+	}
+	if !legacy && len(deprecatedKeys) == 0 {
+		return nil
+	}
+
+	fmt.Println("📦 Found configuration from an older version:")
+	if legacy {
+		fmt.Printf("   - %s (its settings now live in %s)\n", config.LegacyConfigFile, configPath)
+	}
+	for _, key := range deprecatedKeys {
+		fmt.Printf("   - %s in %s (deprecated, has no effect)\n", key, configPath)
+	}
+	if dryRun {
+		fmt.Println("🔍 Dry run: these would be migrated, keeping your custom values")
+		fmt.Println()
+		return nil
+	}
+
+	fmt.Print("Migrate them, keeping your custom values? [Y/n]: ")
+	var answer string
+	fmt.Scanln(&answer)
+	if strings.ToLower(answer) == "n" || strings.ToLower(answer) == "no" {
+		fmt.Println("✓ Leaving the existing configuration as it is")
+		fmt.Println()
+		return nil
+	}
+
+	if fileExists(configPath) {
+		backupPath, err := config.BackupFile(configPath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("💾 Backed up %s to %s\n", configPath, backupPath)
+	}
+	if legacy {
+		backupPath, err := config.MigrateLegacyConfig(config.LegacyConfigFile, configPath)
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", config.LegacyConfigFile, err)
+		}
+		fmt.Printf("✅ Moved %s into %s (original kept as %s)\n", config.LegacyConfigFile, configPath, backupPath)
+	}
+	if len(deprecatedKeys) > 0 {
+		removed, err := config.RemoveDeprecatedKeys(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to remove deprecated keys: %w", err)
+		}
+		fmt.Printf("✅ Removed deprecated keys: %s\n", strings.Join(removed, ", "))
+	}
+	fmt.Println()
+	return nil
+}
+
+// currentMonitorSettings returns the monitor settings in effect, so re-running the wizard
+// starts from them rather than from the defaults
+func currentMonitorSettings(configPath string) config.MonitorSettings {
+	msgConfig := config.DefaultMessengerConfig()
+	if fileExists(configPath) {
+		loaded, err := config.LoadMessengerConfig(configPath)
+		if err != nil {
+			fmt.Printf("⚠️  Could not load %s, starting from defaults: %v\n", configPath, err)
+		} else {
+			msgConfig = loaded
+		}
+	}
+	if fileExists(config.LegacyConfigFile) {
+		if err := msgConfig.ApplyLegacyConfig(config.LegacyConfigFile); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+	return msgConfig.Monitor
+}
+
+// fileExists reports whether a file exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,107 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/claude"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/config"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// chooseProjects asks for project directories and whether their hooks go in the shared or
+// the personal settings file. It returns the settings file of each project and the projects
+// to register.
+func chooseProjects() ([]*types.ConfigLocation, []config.ProjectSettings, error) {
+	fmt.Println("\n📂 Enter the project directories, one per line (empty line to finish):")
+	var projects []config.ProjectSettings
+	for {
+		fmt.Printf("  Project %d: ", len(projects)+1)
+		dir := readLine()
+		if dir == "" {
+			break
+		}
+		path, err := projectDir(dir)
+		if err != nil {
+			fmt.Printf("  ⚠️  %v\n", err)
+			continue
+		}
+		if registered(projects, path) {
+			fmt.Printf("  ✓ %s is already in the list\n", path)
+			continue
+		}
+		projects = append(projects, config.ProjectSettings{Name: filepath.Base(path), Path: path})
+	}
+	if len(projects) == 0 {
+		return nil, nil, fmt.Errorf("no project directories given")
+	}
+
+	fmt.Println()
+	fmt.Println("Which settings file should the hooks go in?")
+	fmt.Println("  [1] .claude/settings.json (shared with team, committed to repo)")
+	fmt.Println("  [2] .claude/settings.local.json (personal, not committed)")
+	fmt.Print("Choose [1-2]: ")
+	var choice string
+	fmt.Scanln(&choice)
+	index := 0
+	if choice == "2" {
+		index = 1
+	}
+
+	var locations []*types.ConfigLocation
+	for _, project := range projects {
+		location := claude.ProjectLocations(project.Path)[index]
+		locations = append(locations, &location)
+	}
+	return locations, projects, nil
+}
+
+// projectDir resolves a directory typed by the user to an existing absolute path
+func projectDir(dir string) (string, error) {
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, strings.TrimPrefix(dir, "~"))
+	}
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("%s does not exist", path)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", path)
+	}
+	return path, nil
+}
+
+// registered reports whether a project directory is already in a list
+func registered(projects []config.ProjectSettings, path string) bool {
+	for _, project := range projects {
+		if project.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// readLine reads one line from stdin, so answers may contain spaces. Stdin is read a byte
+// at a time so nothing is buffered away from the fmt.Scanln prompts that follow.
+func readLine() string {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n == 0 || err != nil || buf[0] == '\n' {
+			break
+		}
+		line = append(line, buf[0])
+	}
+	return strings.TrimSpace(string(line))
+}
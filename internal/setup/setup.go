@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/riaanpieterse81/ClaudeToGo/internal/claude"
@@ -11,19 +13,36 @@ import (
 	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
 )
 
-// RunWizard guides the user through interactive setup
-func RunWizard() error {
+// RunWizard guides the user through interactive setup. With dryRun set it asks the same
+// questions but only previews the changes, writing no files.
+func RunWizard(dryRun bool) error {
 	fmt.Println("🎯 Welcome to ClaudeToGo Setup Wizard!")
 	fmt.Println("=====================================")
 	fmt.Println()
+	if dryRun {
+		fmt.Println("🔍 Dry run: changes are previewed, nothing is written.")
+		fmt.Println()
+	}
 	fmt.Println("This wizard will help you configure ClaudeToGo for monitoring")
 	fmt.Println("Claude Code's tool usage through hooks.")
 	fmt.Println()
 
+	configPath := config.FindMessengerConfig()
+	if configPath == "" {
+		configPath = config.DefaultConfigFile
+	}
+
+	// Upgrade configuration from older versions, then start from the settings in effect
+	if err := migrateExistingConfig(configPath, dryRun); err != nil {
+		return err
+	}
+	monitor := currentMonitorSettings(configPath)
+	claude.SetBackupRetention(config.GetMessengerConfigWithDefaults(configPath).Hooks.BackupRetention)
 	configFile := types.ConfigFile{
-		LogFile:      "claude-events.jsonl",
-		PollInterval: "100ms",
-		Verbose:      false,
+		LogFile:      monitor.LogFile,
+		PollInterval: monitor.PollInterval.String(),
+		Verbose:      monitor.Verbose,
+		UsePolling:   monitor.UsePolling,
 	}
 
 	fmt.Println("📋 Configuration Questions:")
@@ -32,7 +51,7 @@ func RunWizard() error {
 	fmt.Println()
 
 	// Ask about log file location
-	fmt.Print("1. Where should events be logged? [claude-events.jsonl]: ")
+	fmt.Printf("1. Where should events be logged? [%s]: ", configFile.LogFile)
 	var logFileInput string
 	fmt.Scanln(&logFileInput)
 	if logFileInput != "" {
@@ -42,10 +61,19 @@ func RunWizard() error {
 	fmt.Println()
 
 	// Ask about verbose logging
-	fmt.Print("2. Enable verbose debug logging? [y/N]: ")
+	verboseDefault := "[y/N]"
+	if configFile.Verbose {
+		verboseDefault = "[Y/n]"
+	}
+	fmt.Printf("2. Enable verbose debug logging? %s: ", verboseDefault)
 	var verboseInput string
 	fmt.Scanln(&verboseInput)
-	configFile.Verbose = strings.ToLower(verboseInput) == "y" || strings.ToLower(verboseInput) == "yes"
+	switch strings.ToLower(verboseInput) {
+	case "y", "yes":
+		configFile.Verbose = true
+	case "n", "no":
+		configFile.Verbose = false
+	}
 	if configFile.Verbose {
 		fmt.Println("✓ Verbose logging enabled")
 	} else {
@@ -53,24 +81,62 @@ func RunWizard() error {
 	}
 	fmt.Println()
 
-	// Save configuration
-	configPath := "claudetogo-config.json"
-	if err := config.Save(configFile, configPath); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+	// Save configuration to the monitor section of the YAML config
+	monitor.LogFile = configFile.LogFile
+	monitor.Verbose = configFile.Verbose
+	if dryRun {
+		fmt.Printf("🔍 Dry run: configuration would be saved to: %s\n", configPath)
+	} else {
+		if err := config.SaveMonitorSettings(configPath, monitor); err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+		fmt.Printf("✅ Configuration saved to: %s\n", configPath)
+		if fileExists(config.LegacyConfigFile) {
+			fmt.Printf("⚠️  %s still overrides these settings; run --config-migrate to retire it\n", config.LegacyConfigFile)
+		}
+	}
+	fmt.Println()
+
+	// Ask about phone notifications; this sends a test message and stores tokens, so a dry
+	// run skips it
+	if dryRun {
+		fmt.Println("3. 🔍 Dry run: skipping notification setup")
+	} else {
+		fmt.Print("3. Send notifications to your phone (Telegram, Slack or a webhook)? [y/N]: ")
+		var channelInput string
+		fmt.Scanln(&channelInput)
+		if strings.ToLower(channelInput) == "y" || strings.ToLower(channelInput) == "yes" {
+			fmt.Println()
+			configured, err := configureChannel(configPath)
+			switch {
+			case err != nil:
+				fmt.Printf("⚠️  Could not set up notifications: %v\n", err)
+				fmt.Println("   Run --setup again, or see the integrations section of the config file.")
+			case configured:
+				fmt.Println("✅ Notifications configured! Run ./claudetogo --service to deliver them.")
+			default:
+				fmt.Println("✓ You can set up notifications later with --setup")
+			}
+		} else {
+			fmt.Println("✓ You can set up notifications later with --setup")
+		}
 	}
-	fmt.Printf("✅ Configuration saved to: %s\n", configPath)
 	fmt.Println()
 
 	// Ask about Claude Code settings.json configuration
-	fmt.Print("3. Would you like to automatically configure Claude Code hooks? [y/N]: ")
+	fmt.Print("4. Would you like to automatically configure Claude Code hooks? [y/N]: ")
 	var configureHooksInput string
 	fmt.Scanln(&configureHooksInput)
 	if strings.ToLower(configureHooksInput) == "y" || strings.ToLower(configureHooksInput) == "yes" {
-		if err := configureHooks(configFile); err != nil {
+		applied, err := configureHooks(configFile, configPath, dryRun)
+		switch {
+		case err != nil:
 			fmt.Printf("⚠️  Could not configure Claude Code hooks automatically: %v\n", err)
 			fmt.Println("   You can configure them manually using the instructions below.")
-		} else {
+		case applied:
 			fmt.Println("✅ Claude Code hooks configured successfully!")
+		default:
+			fmt.Println("✓ settings.json was not changed")
 		}
 	} else {
 		fmt.Println("✓ You can configure Claude Code hooks manually later")
@@ -83,50 +149,114 @@ func RunWizard() error {
 	return nil
 }
 
-// configureHooks automatically configures Claude Code settings.json
-func configureHooks(config types.ConfigFile) error {
+// configureHooks shows the changes hook installation makes to Claude Code settings files and
+// applies them once confirmed, registering the projects hooks were installed in. It reports
+// whether any file was written.
+func configureHooks(configFile types.ConfigFile, configPath string, dryRun bool) (bool, error) {
 	// Ask user to choose configuration location
-	location, err := chooseConfigLocation()
+	locations, projects, err := chooseConfigLocations()
 	if err != nil {
-		return fmt.Errorf("failed to choose configuration location: %w", err)
+		return false, fmt.Errorf("failed to choose configuration location: %w", err)
+	}
+	selection := chooseHookSelection()
+
+	// Hooks run in their project's directory, so projects elsewhere need an absolute events
+	// file for all of them to log to the same one
+	cwd, _ := os.Getwd()
+	for _, project := range projects {
+		if project.Path != cwd && !filepath.IsAbs(configFile.LogFile) {
+			if abs, err := filepath.Abs(configFile.LogFile); err == nil {
+				configFile.LogFile = abs
+				fmt.Printf("✓ All projects will log events to %s\n", abs)
+			}
+			break
+		}
 	}
 
-	return claude.ConfigureHooksAtLocation(config, location)
+	var pending []*types.ConfigLocation
+	planned := make(map[*types.ConfigLocation]*types.ClaudeSettingsConfig)
+	for _, location := range locations {
+		settingsConfig, changes, err := claude.PreviewHooksAtLocation(configFile, location, selection)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", location.Path, err)
+		}
+		preview, err := claude.SettingsDiff(location.Path, settingsConfig)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", location.Path, err)
+		}
+		if preview == "" {
+			fmt.Printf("✓ %s already has the ClaudeToGo hooks\n", location.Path)
+			continue
+		}
+		fmt.Printf("📝 Changes to %s:\n", location.Path)
+		fmt.Print(changes)
+		fmt.Println(preview)
+		fmt.Println()
+		pending = append(pending, location)
+		planned[location] = settingsConfig
+	}
+	if dryRun {
+		if len(pending) > 0 {
+			fmt.Println("🔍 Dry run: not applying these changes")
+		}
+		if len(projects) > 0 {
+			fmt.Printf("🔍 Dry run: %s would be registered in %s\n", projectNames(projects), configPath)
+		}
+		return false, nil
+	}
+
+	if len(pending) > 0 {
+		fmt.Print("Apply these changes? [y/N]: ")
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" && strings.ToLower(answer) != "yes" {
+			return false, nil
+		}
+		for _, location := range pending {
+			if err := claude.ConfigureHooksAtLocation(configFile, location, selection, planned[location]); err != nil {
+				return false, fmt.Errorf("%s: %w", location.Path, err)
+			}
+		}
+	}
+
+	if len(projects) > 0 {
+		if err := config.RegisterProjects(configPath, projects); err != nil {
+			return len(pending) > 0, fmt.Errorf("failed to register projects: %w", err)
+		}
+		fmt.Printf("📋 Registered %s in %s\n", projectNames(projects), configPath)
+	}
+	return len(pending) > 0, nil
 }
 
-// chooseConfigLocation lets user choose between global and project configuration
-func chooseConfigLocation() (*types.ConfigLocation, error) {
+// projectNames lists projects for messages
+func projectNames(projects []config.ProjectSettings) string {
+	names := make([]string, len(projects))
+	for i, project := range projects {
+		names[i] = project.Name
+	}
+	return "project(s) " + strings.Join(names, ", ")
+}
+
+// chooseConfigLocations lets user choose between global configuration, the current project
+// and several project directories. It returns the settings files to install hooks in and the
+// projects to register.
+func chooseConfigLocations() ([]*types.ConfigLocation, []config.ProjectSettings, error) {
 	fmt.Println("\n📁 Choose Claude Code Configuration Location:")
 	fmt.Println("============================================")
 
-	// Detect current working directory
-	cwd, err := os.Getwd()
+	locations, err := claude.SettingsLocations()
 	if err != nil {
-		return nil, fmt.Errorf("could not get current directory: %w", err)
+		return nil, nil, err
 	}
 
-	// Get home directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("could not get user home directory: %w", err)
-	}
-
-	locations := []types.ConfigLocation{
-		{
-			Path:        filepath.Join(homeDir, ".claude", "settings.json"),
-			Description: "Global configuration (affects all projects)",
-			Scope:       "global",
-		},
-		{
-			Path:        filepath.Join(cwd, ".claude", "settings.json"),
-			Description: "Project configuration (shared with team, committed to repo)",
-			Scope:       "project",
-		},
-		{
-			Path:        filepath.Join(cwd, ".claude", "settings.local.json"),
-			Description: "Local project configuration (personal, not committed)",
-			Scope:       "local",
-		},
+	// Corporate policy may only allow hooks in managed settings, or hooks may be disabled
+	cwd, _ := os.Getwd()
+	managed := claude.ManagedSettingsPath()
+	if policy, err := claude.LoadHookPolicy(cwd); err == nil {
+		if reason := policy.Blocks(locations[0]); reason != "" {
+			fmt.Printf("⚠️  Claude Code won't run hooks from the settings files below: %s\n", reason)
+			fmt.Println()
+		}
 	}
 
 	// Show options
@@ -139,21 +269,135 @@ func chooseConfigLocation() (*types.ConfigLocation, error) {
 		fmt.Printf("      Path: %s\n", loc.Path)
 		fmt.Println()
 	}
+	fmt.Println("  [4] Several project directories (project hooks in each)")
+	fmt.Println()
+	choices := "1-4"
+	if fileExists(managed) {
+		fmt.Println("  [5] Enterprise managed settings (needs administrator rights)")
+		fmt.Printf("      Path: %s\n", managed)
+		fmt.Println()
+		choices = "1-5"
+	}
 
-	fmt.Print("Choose location [1-3]: ")
+	fmt.Printf("Choose location [%s]: ", choices)
 	var choice string
 	fmt.Scanln(&choice)
 
 	switch choice {
+	case "2", "3":
+		location := &locations[choice[0]-'1']
+		cwd := filepath.Dir(filepath.Dir(location.Path))
+		return []*types.ConfigLocation{location}, []config.ProjectSettings{{Name: filepath.Base(cwd), Path: cwd}}, nil
+	case "4":
+		return chooseProjects()
+	case "5":
+		if fileExists(managed) {
+			all, err := claude.AllSettingsLocations()
+			if err != nil {
+				return nil, nil, err
+			}
+			return []*types.ConfigLocation{&all[0]}, nil, nil
+		}
+		fmt.Println("✓ Defaulting to global configuration")
+		return []*types.ConfigLocation{&locations[0]}, nil, nil
 	case "1":
-		return &locations[0], nil
-	case "2":
-		return &locations[1], nil
-	case "3":
-		return &locations[2], nil
+		return []*types.ConfigLocation{&locations[0]}, nil, nil
 	default:
 		fmt.Println("✓ Defaulting to global configuration")
-		return &locations[0], nil
+		return []*types.ConfigLocation{&locations[0]}, nil, nil
+	}
+}
+
+// hookDescriptions explains when each hook type runs
+var hookDescriptions = map[string]string{
+	"Stop":             "Claude finished responding",
+	"Notification":     "Claude needs your permission or input",
+	"PreToolUse":       "before each tool call",
+	"PostToolUse":      "after each tool call",
+	"UserPromptSubmit": "when you submit a prompt",
+	"SessionStart":     "when a session starts, for session durations",
+	"SessionEnd":       "when a session ends, for session durations",
+}
+
+// chooseHookSelection lets user choose the hook types to install and, for the per-tool
+// hooks, which tools they fire for
+func chooseHookSelection() claude.HookSelection {
+	fmt.Println("\n🪝 Choose Claude Code Hooks to Install:")
+	fmt.Println("======================================")
+	for i, event := range claude.HookEvents {
+		fmt.Printf("  [%d] %-17s %s\n", i+1, event, hookDescriptions[event])
+	}
+	fmt.Println()
+	fmt.Print("Hooks to install, comma-separated [1,2]: ")
+	var choice string
+	fmt.Scanln(&choice)
+
+	selection := claude.DefaultHookSelection()
+	if choice != "" {
+		var events []string
+		for _, part := range strings.Split(choice, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n < 1 || n > len(claude.HookEvents) {
+				fmt.Printf("⚠️  Ignoring unknown choice %q\n", strings.TrimSpace(part))
+				continue
+			}
+			event := claude.HookEvents[n-1]
+			if !slices.Contains(events, event) {
+				events = append(events, event)
+			}
+		}
+		if len(events) > 0 {
+			selection.Events = events
+		} else {
+			fmt.Println("✓ Defaulting to Stop and Notification")
+		}
+	}
+
+	// Matchers select the tools a hook runs for, so hooks aren't invoked for tools you never
+	// want gated
+	fmt.Println()
+	fmt.Println("Which tools should the hooks fire for?")
+	fmt.Println("  [1] All tools")
+	fmt.Println("  [2] Only Bash, Write and Edit for PreToolUse/PostToolUse")
+	fmt.Println("  [3] Choose a matcher per hook type")
+	fmt.Print("Choose [1-3]: ")
+	var matcherChoice string
+	fmt.Scanln(&matcherChoice)
+	switch matcherChoice {
+	case "2":
+		selection.SetToolMatcher(claude.EditToolsMatcher)
+	case "3":
+		fmt.Println("Matchers are tool name patterns, e.g. Bash, Write|Edit or mcp__.* (* = all tools)")
+		for _, event := range selection.Events {
+			selection.SetMatcher(event, chooseMatcher(event))
+		}
+	}
+
+	var installing []string
+	for _, event := range selection.Events {
+		if matcher := selection.Matcher(event); matcher != "*" {
+			event += " (" + matcher + ")"
+		}
+		installing = append(installing, event)
+	}
+	fmt.Printf("✓ Installing hooks: %s\n", strings.Join(installing, ", "))
+	return selection
+}
+
+// chooseMatcher asks for the matcher of one hook type until a valid one is given
+func chooseMatcher(event string) string {
+	for {
+		fmt.Printf("  %s matcher [*]: ", event)
+		var matcher string
+		fmt.Scanln(&matcher)
+		if matcher == "" {
+			return "*"
+		}
+		if err := claude.ValidateMatcher(matcher); err != nil {
+			fmt.Printf("  ⚠️  %v\n", err)
+			continue
+		}
+		return matcher
 	}
 }
 
@@ -232,6 +476,6 @@ func ShowResults(config types.ConfigFile) {
 	fmt.Println("💡 Tips:")
 	fmt.Println("   - All tool events are logged and allowed (Stage 1: Event collection)")
 	fmt.Println("   - Run with --help to see all available options")
-	fmt.Println("   - Edit claudetogo-config.json to modify settings")
+	fmt.Println("   - Edit claudetogo-messenger.yaml to modify settings")
 	fmt.Println("   - Use --setup again to reconfigure")
 }
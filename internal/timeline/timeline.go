@@ -0,0 +1,182 @@
+// Package timeline puts together what happened in a Claude session, from the session
+// registry, the hook events and the session's transcript
+package timeline
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/processor"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/transcript"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// Entry kinds
+const (
+	KindStart             = "start"
+	KindPrompt            = "prompt"
+	KindToolCall          = "tool_call"
+	KindPermissionRequest = "permission_request"
+	KindApproval          = "approval"
+	KindRejection         = "rejection"
+	KindCompletion        = "completion"
+	KindNote              = "note"
+	KindEnd               = "end"
+)
+
+// Entry is one step of a session's timeline
+type Entry struct {
+	At       time.Time `json:"at"`
+	Kind     string    `json:"kind"`
+	Text     string    `json:"text,omitempty"`
+	Tool     string    `json:"tool,omitempty"`
+	Subagent bool      `json:"subagent,omitempty"`
+}
+
+// Timeline is the activity of one session, oldest entry first
+type Timeline struct {
+	SessionID       string     `json:"session_id"`
+	Project         string     `json:"project,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	Started         time.Time  `json:"started"`
+	Ended           *time.Time `json:"ended,omitempty"` // Unset while the session runs
+	DurationSeconds int64      `json:"duration_seconds"`
+	Transcript      string     `json:"transcript,omitempty"`
+	Tags            []string   `json:"tags,omitempty"`
+	Entries         []Entry    `json:"entries"`
+}
+
+// Sources are where a timeline is read from; any of them may be missing
+type Sources struct {
+	OutputDir      string // Holds the session registry
+	EventsFile     string // Hook events
+	TranscriptsDir string // Searched for the transcript when no event names it
+}
+
+// Build puts together the timeline of a session. The session starts at its SessionStart
+// event, else its first event, and ends at its SessionEnd event; a session that hasn't
+// ended runs until its latest entry.
+func Build(sessionID string, sources Sources) (*Timeline, error) {
+	record, err := session.NewRegistry(sources.OutputDir).Get(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var events []types.ClaudeHookEvent
+	if sources.EventsFile != "" {
+		// A missing events file leaves the registry and transcript to go by
+		events, _ = processor.NewEventProcessor("").SessionEvents(sources.EventsFile, sessionID)
+	}
+
+	tl := &Timeline{SessionID: sessionID, Entries: []Entry{}}
+	var started, ended *time.Time
+	if record != nil {
+		tl.Project, tl.Status, tl.Tags = record.Project, record.Status, record.Tags
+		started, ended = record.Started, record.Ended
+		for _, response := range record.Responses {
+			kind := KindApproval
+			if response.Action == "reject" {
+				kind = KindRejection
+			}
+			tl.Entries = append(tl.Entries, Entry{At: response.At, Kind: kind})
+		}
+	}
+
+	completions := 0
+	for _, event := range events {
+		at, err := time.Parse(time.RFC3339Nano, event.Timestamp)
+		if err != nil {
+			continue
+		}
+		switch event.HookEventName {
+		case "SessionStart":
+			if started == nil || at.Before(*started) {
+				started = &at
+			}
+		case "SessionEnd":
+			if ended == nil || at.After(*ended) {
+				ended = &at
+			}
+		case "Notification":
+			tl.Entries = append(tl.Entries, Entry{At: at, Kind: KindPermissionRequest, Text: event.Message, Tool: event.ToolName})
+		case "Stop":
+			completions++
+			tl.Entries = append(tl.Entries, Entry{At: at, Kind: KindCompletion})
+		}
+		if event.TranscriptPath != "" {
+			tl.Transcript = event.TranscriptPath
+		}
+	}
+	// Without the events file, the registry still knows whether the session completed
+	if completions == 0 && record != nil && (record.Status == session.StatusCompleted || record.Status == session.StatusFailed) {
+		tl.Entries = append(tl.Entries, Entry{At: record.LastEvent, Kind: KindCompletion, Text: record.LastTitle})
+	}
+
+	if tl.Transcript == "" && sources.TranscriptsDir != "" {
+		tl.Transcript, _ = transcript.FindSessionTranscript(sources.TranscriptsDir, sessionID)
+	}
+	if tl.Transcript != "" {
+		entries, err := transcript.NewReader().Entries(tl.Transcript)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transcript: %w", err)
+		}
+		tl.Entries = append(tl.Entries, transcriptEntries(entries)...)
+	}
+
+	sort.SliceStable(tl.Entries, func(i, j int) bool { return tl.Entries[i].At.Before(tl.Entries[j].At) })
+	if started == nil && len(tl.Entries) > 0 {
+		started = &tl.Entries[0].At
+	}
+	if started == nil && record != nil {
+		first := record.Start()
+		started = &first
+	}
+	if started == nil {
+		return nil, fmt.Errorf("no activity found for session %s", sessionID)
+	}
+
+	tl.Started = *started
+	tl.Entries = append([]Entry{{At: *started, Kind: KindStart}}, tl.Entries...)
+	last := tl.Entries[len(tl.Entries)-1].At
+	if ended != nil {
+		tl.Ended, last = ended, *ended
+		tl.Entries = append(tl.Entries, Entry{At: *ended, Kind: KindEnd})
+		tl.Status = session.StatusEnded
+	}
+	if d := last.Sub(tl.Started); d > 0 {
+		tl.DurationSeconds = int64(d.Round(time.Second) / time.Second)
+	}
+
+	// Notes are often left after the session, so they don't count towards its duration
+	if record != nil && len(record.Notes) > 0 {
+		for _, note := range record.Notes {
+			tl.Entries = append(tl.Entries, Entry{At: note.At, Kind: KindNote, Text: note.Text})
+		}
+		sort.SliceStable(tl.Entries, func(i, j int) bool { return tl.Entries[i].At.Before(tl.Entries[j].At) })
+	}
+	return tl, nil
+}
+
+// Duration returns how long the session ran
+func (tl *Timeline) Duration() time.Duration {
+	return time.Duration(tl.DurationSeconds) * time.Second
+}
+
+// transcriptEntries turns the prompts and tool calls of a transcript into timeline entries
+func transcriptEntries(entries []transcript.LogEntry) []Entry {
+	var timeline []Entry
+	for _, entry := range entries {
+		at, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		if err != nil {
+			continue
+		}
+		switch entry.Kind {
+		case "user":
+			timeline = append(timeline, Entry{At: at, Kind: KindPrompt, Text: entry.Text, Subagent: entry.Subagent})
+		case "tool_use":
+			timeline = append(timeline, Entry{At: at, Kind: KindToolCall, Text: entry.Text, Tool: entry.Tool, Subagent: entry.Subagent})
+		}
+	}
+	return timeline
+}
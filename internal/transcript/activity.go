@@ -0,0 +1,52 @@
+package transcript
+
+import (
+	"time"
+)
+
+// Activity is the tool calls and token usage in a transcript over a period
+type Activity struct {
+	ToolCalls    map[string]int // Tool name -> calls
+	InputTokens  int
+	OutputTokens int
+	CacheTokens  int // Cache creation and cache read input tokens
+}
+
+// Activity counts the tool calls and tokens of a transcript's assistant messages written
+// since a time. Subagent messages count too: their tools and tokens are the session's.
+func (r *Reader) Activity(path string, since time.Time) (*Activity, error) {
+	messages, err := r.ParseTranscriptFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &Activity{ToolCalls: make(map[string]int)}
+	// Assistant responses are split across several transcript lines sharing one message ID,
+	// each repeating the same usage, so usage is counted once per message ID
+	seenUsage := make(map[string]bool)
+	for i := range messages {
+		message := &messages[i]
+		if message.Type != "assistant" || messageTime(message).Before(since) {
+			continue
+		}
+		for _, item := range contentItems(message, "tool_use") {
+			if name, _ := item["name"].(string); name != "" {
+				activity.ToolCalls[name]++
+			}
+		}
+		if usage := message.Message.Usage; usage != nil {
+			key := message.Message.ID
+			if key == "" {
+				key = message.UUID
+			}
+			if seenUsage[key] {
+				continue
+			}
+			seenUsage[key] = true
+			activity.InputTokens += usage.InputTokens
+			activity.OutputTokens += usage.OutputTokens
+			activity.CacheTokens += usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+		}
+	}
+	return activity, nil
+}
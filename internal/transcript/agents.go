@@ -0,0 +1,188 @@
+package transcript
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// ToolRequest is a tool call found in a session, with the agent that made it
+type ToolRequest struct {
+	Message  *types.TranscriptMessage
+	ToolUse  *types.ContentItem
+	Subagent bool   // Made by a subagent rather than the main agent
+	Agent    string // Subagent type, when it can be told
+	Task     string // Description of the subagent's task, when it can be told
+}
+
+// ToolMatch is what a hook event tells about the tool call it is for
+type ToolMatch struct {
+	ID    string                 // tool_use ID, given by newer Claude Code versions
+	Name  string                 // Tool name
+	Input map[string]interface{} // Tool input
+}
+
+// FindPendingToolUse returns the tool call a permission request or PreToolUse hook is about.
+// A call with the event's tool_use ID is taken when there is one. Otherwise it is the newest
+// call still waiting for its result, preferring calls with the event's tool name and input,
+// so one of several tools fired in quick succession isn't taken for another.
+// Subagents write to the session transcript as sidechains, or to their own transcripts under
+// <session>/subagents/, and both are searched so a subagent's request isn't mistaken for the
+// main agent's call that started it. When every call has a result, the newest call is returned.
+func (r *Reader) FindPendingToolUse(transcriptPath string, match ToolMatch) (*ToolRequest, error) {
+	pending, newest, err := r.pendingToolUses(transcriptPath)
+	if err != nil {
+		return nil, err
+	}
+	// Tasks started by the main agent, to name the subagent making a request
+	var tasks []ToolRequest
+	for _, request := range pending {
+		if !request.Subagent && isSubagentTool(request.ToolUse.Name) {
+			tasks = append(tasks, request)
+		}
+	}
+
+	subagentFiles, _ := filepath.Glob(filepath.Join(strings.TrimSuffix(transcriptPath, ".jsonl"), "subagents", "*.jsonl"))
+	for _, file := range subagentFiles {
+		subagentPending, _, err := r.pendingToolUses(file)
+		if err != nil {
+			continue
+		}
+		for i := range subagentPending {
+			subagentPending[i].Subagent = true
+		}
+		pending = append(pending, subagentPending...)
+	}
+
+	var request *ToolRequest
+	if match.ID != "" {
+		request = r.findToolUseByID(append([]string{transcriptPath}, subagentFiles...), match.ID)
+	}
+	if request == nil {
+		request = newestRequest(pending, match)
+	}
+	if request == nil {
+		if newest == nil {
+			return nil, fmt.Errorf("no tool use messages found in transcript")
+		}
+		request = newest
+	}
+	if request.Subagent && len(tasks) == 1 {
+		request.Agent, _ = tasks[0].ToolUse.Input["subagent_type"].(string)
+		request.Task, _ = tasks[0].ToolUse.Input["description"].(string)
+	}
+	return request, nil
+}
+
+// pendingToolUses returns the tool calls of a transcript's current turn that have no result
+// yet, newest first, and the newest tool call of the transcript
+func (r *Reader) pendingToolUses(path string) ([]ToolRequest, *ToolRequest, error) {
+	var pending []ToolRequest
+	var newest *ToolRequest
+	answered := make(map[string]bool)
+	err := r.ScanBackward(path, func(message *types.TranscriptMessage) bool {
+		switch message.Type {
+		case "user":
+			for _, result := range contentItems(message, "tool_result") {
+				if id, ok := result["tool_use_id"].(string); ok {
+					answered[id] = true
+				}
+			}
+			// Calls made before the user's last prompt have all finished
+			if !message.IsSidechain && !message.IsMeta && r.PromptText(message) != "" {
+				return newest == nil
+			}
+		case "assistant":
+			items := contentItems(message, "tool_use")
+			for i := len(items) - 1; i >= 0; i-- {
+				request := ToolRequest{Message: message, ToolUse: toolUseFromMap(items[i]), Subagent: message.IsSidechain}
+				if newest == nil {
+					newest = &request
+				}
+				if !answered[request.ToolUse.ID] {
+					pending = append(pending, request)
+				}
+			}
+		}
+		return true
+	})
+	return pending, newest, err
+}
+
+// findToolUseByID returns the tool call with a tool_use ID from the first transcript that has
+// it, reading each from the end
+func (r *Reader) findToolUseByID(paths []string, toolUseID string) *ToolRequest {
+	for i, path := range paths {
+		message, err := r.findLast(path, func(message *types.TranscriptMessage) bool {
+			return message.Type == "assistant" && r.hasToolUseWithID(message, toolUseID)
+		})
+		if err != nil || message == nil {
+			continue
+		}
+		for _, item := range contentItems(message, "tool_use") {
+			if item["id"] == toolUseID {
+				return &ToolRequest{Message: message, ToolUse: toolUseFromMap(item), Subagent: i > 0 || message.IsSidechain}
+			}
+		}
+	}
+	return nil
+}
+
+// newestRequest returns the most recent of several tool calls. Calls with the matched name
+// and input are preferred, then calls with the matched name, then any call.
+func newestRequest(requests []ToolRequest, match ToolMatch) *ToolRequest {
+	filters := []func(*types.ContentItem) bool{
+		func(toolUse *types.ContentItem) bool {
+			return match.Name != "" && match.Input != nil && toolUse.Name == match.Name && reflect.DeepEqual(toolUse.Input, match.Input)
+		},
+		func(toolUse *types.ContentItem) bool {
+			return match.Name != "" && toolUse.Name == match.Name
+		},
+		func(*types.ContentItem) bool { return true },
+	}
+
+	for _, filter := range filters {
+		var newest *ToolRequest
+		var newestTime time.Time
+		for i := range requests {
+			if !filter(requests[i].ToolUse) {
+				continue
+			}
+			t := messageTime(requests[i].Message)
+			if newest == nil || t.After(newestTime) {
+				newest, newestTime = &requests[i], t
+			}
+		}
+		if newest != nil {
+			return newest
+		}
+	}
+	return nil
+}
+
+// messageTime returns when a message was written, or the zero time if it isn't recorded
+func messageTime(message *types.TranscriptMessage) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, message.Timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// isSubagentTool reports whether a tool starts a subagent
+func isSubagentTool(toolName string) bool {
+	return toolName == "Task" || toolName == "Agent"
+}
+
+// toolUseFromMap converts a tool_use content block into a ContentItem
+func toolUseFromMap(itemMap map[string]interface{}) *types.ContentItem {
+	contentItem := &types.ContentItem{Type: "tool_use"}
+	contentItem.ID, _ = itemMap["id"].(string)
+	contentItem.Name, _ = itemMap["name"].(string)
+	contentItem.Input, _ = itemMap["input"].(map[string]interface{})
+	return contentItem
+}
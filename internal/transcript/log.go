@@ -0,0 +1,91 @@
+package transcript
+
+import (
+	"strings"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// LogEntry is one step of a session as `claudetogo log` shows it: a prompt, a reply, a tool
+// call or a tool result
+type LogEntry struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	Kind      string `json:"kind"` // "user", "assistant", "tool_use" or "tool_result"
+	Text      string `json:"text"`
+	Tool      string `json:"tool,omitempty"`
+	Failed    bool   `json:"failed,omitempty"`   // A tool result that is an error
+	Subagent  bool   `json:"subagent,omitempty"` // Written by a subagent
+}
+
+// Tail returns the last n entries of a transcript, oldest first. Messages Claude Code adds for
+// itself are left out; subagent messages are kept and marked.
+func (r *Reader) Tail(path string, n int) ([]LogEntry, error) {
+	var entries []LogEntry
+	err := r.ScanBackward(path, func(message *types.TranscriptMessage) bool {
+		if message.IsMeta {
+			return true
+		}
+		messageEntries := r.logEntries(message)
+		// Entries of one message are in order; collect them newest first like the messages
+		for i := len(messageEntries) - 1; i >= 0 && len(entries) < n; i-- {
+			entries = append(entries, messageEntries[i])
+		}
+		return len(entries) < n
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// Entries returns every entry of a transcript, oldest first, leaving out the messages Claude
+// Code adds for itself
+func (r *Reader) Entries(path string) ([]LogEntry, error) {
+	messages, err := r.ParseTranscriptFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []LogEntry
+	for i := range messages {
+		if messages[i].IsMeta {
+			continue
+		}
+		entries = append(entries, r.logEntries(&messages[i])...)
+	}
+	return entries, nil
+}
+
+// logEntries splits a transcript message into its prompts, replies, tool calls and results
+func (r *Reader) logEntries(message *types.TranscriptMessage) []LogEntry {
+	entry := LogEntry{Timestamp: message.Timestamp, Subagent: message.IsSidechain}
+	var entries []LogEntry
+
+	switch message.Type {
+	case "user":
+		if prompt := r.PromptText(message); prompt != "" {
+			entry.Kind, entry.Text = "user", prompt
+			entries = append(entries, entry)
+		}
+		for _, item := range contentItems(message, "tool_result") {
+			entry.Kind = "tool_result"
+			entry.Text = strings.TrimSpace(r.ToolResultText(&types.ContentItem{Content: item["content"]}))
+			entry.Failed, _ = item["is_error"].(bool)
+			entries = append(entries, entry)
+		}
+	case "assistant":
+		if text := strings.TrimSpace(r.ExtractTextContent(message)); text != "" {
+			entry.Kind, entry.Text = "assistant", text
+			entries = append(entries, entry)
+		}
+		for _, item := range contentItems(message, "tool_use") {
+			toolUse := toolUseFromMap(item)
+			entry.Kind, entry.Tool, entry.Text = "tool_use", toolUse.Name, toolTarget(toolUse.Input)
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -1,78 +1,119 @@
 package transcript
 
 import (
-	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/riaanpieterse81/ClaudeToGo/internal/jsonl"
 	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
 )
 
 // Reader handles reading and parsing Claude Code transcript files
-type Reader struct{}
+type Reader struct {
+	readTime atomic.Int64 // Nanoseconds spent reading transcript files
+}
 
 // NewReader creates a new transcript reader
 func NewReader() *Reader {
 	return &Reader{}
 }
 
+// ReadTime returns the total time spent reading and parsing transcript files so far;
+// the difference between two calls is the time spent in between
+func (r *Reader) ReadTime() time.Duration {
+	return time.Duration(r.readTime.Load())
+}
+
+// timeRead adds the time since start to the read time, deferred by each file read
+func (r *Reader) timeRead(start time.Time) {
+	r.readTime.Add(int64(time.Since(start)))
+}
+
 // ReadLatestMessage reads the last message from a transcript file
 func (r *Reader) ReadLatestMessage(transcriptPath string) (*types.TranscriptMessage, error) {
-	messages, err := r.ParseTranscriptFile(transcriptPath)
+	message, err := r.findLast(transcriptPath, func(*types.TranscriptMessage) bool { return true })
 	if err != nil {
 		return nil, err
 	}
-
-	if len(messages) == 0 {
+	if message == nil {
 		return nil, fmt.Errorf("no messages found in transcript file: %s", transcriptPath)
 	}
-
-	// Return the last message
-	return &messages[len(messages)-1], nil
+	return message, nil
 }
 
-// GetLastAssistantMessage finds the most recent assistant message in the transcript
+// GetLastAssistantMessage finds the most recent assistant message of the main agent in the
+// transcript, skipping subagent (sidechain) messages
 func (r *Reader) GetLastAssistantMessage(transcriptPath string) (*types.TranscriptMessage, error) {
-	messages, err := r.ParseTranscriptFile(transcriptPath)
+	message, err := r.findLast(transcriptPath, func(message *types.TranscriptMessage) bool {
+		return message.Type == "assistant" && !message.IsSidechain
+	})
 	if err != nil {
 		return nil, err
 	}
+	if message == nil {
+		return nil, fmt.Errorf("no assistant messages found in transcript")
+	}
+	return message, nil
+}
 
-	// Search backwards for the last assistant message
-	for i := len(messages) - 1; i >= 0; i-- {
-		if messages[i].Type == "assistant" {
-			return &messages[i], nil
+// GetLastPrompt returns the user's most recent prompt to the main agent
+func (r *Reader) GetLastPrompt(transcriptPath string) (string, error) {
+	var prompt string
+	err := r.ScanBackward(transcriptPath, func(message *types.TranscriptMessage) bool {
+		if message.Type == "user" && !message.IsSidechain && !message.IsMeta {
+			prompt = r.PromptText(message)
 		}
+		return prompt == ""
+	})
+	if err != nil {
+		return "", err
 	}
-
-	return nil, fmt.Errorf("no assistant messages found in transcript")
+	if prompt == "" {
+		return "", fmt.Errorf("no user prompt found in transcript")
+	}
+	return prompt, nil
 }
 
 // GetLastToolUse finds the most recent tool use message from assistant
 func (r *Reader) GetLastToolUse(transcriptPath string) (*types.TranscriptMessage, error) {
-	messages, err := r.ParseTranscriptFile(transcriptPath)
+	message, err := r.findLast(transcriptPath, func(message *types.TranscriptMessage) bool {
+		return message.Type == "assistant" && r.hasToolUse(message)
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	// Search backwards for the last assistant message with tool_use content
-	for i := len(messages) - 1; i >= 0; i-- {
-		if messages[i].Type == "assistant" {
-			if r.hasToolUse(&messages[i]) {
-				return &messages[i], nil
-			}
-		}
+	if message == nil {
+		return nil, fmt.Errorf("no tool use messages found in transcript")
 	}
+	return message, nil
+}
 
-	return nil, fmt.Errorf("no tool use messages found in transcript")
+// findLast returns the newest message matching a condition, or nil if none does, reading
+// the transcript from the end
+func (r *Reader) findLast(transcriptPath string, match func(*types.TranscriptMessage) bool) (*types.TranscriptMessage, error) {
+	var found *types.TranscriptMessage
+	err := r.ScanBackward(transcriptPath, func(message *types.TranscriptMessage) bool {
+		if match(message) {
+			found = message
+			return false
+		}
+		return true
+	})
+	return found, err
 }
 
 // ParseTranscriptFile reads and parses an entire transcript JSONL file
 func (r *Reader) ParseTranscriptFile(path string) ([]types.TranscriptMessage, error) {
+	defer r.timeRead(time.Now())
 	if !r.fileExists(path) {
-		return nil, fmt.Errorf("transcript file does not exist: %s", path)
+		return nil, fmt.Errorf("%w: %s", ErrTranscriptMissing, path)
 	}
 
 	file, err := os.Open(path)
@@ -82,30 +123,28 @@ func (r *Reader) ParseTranscriptFile(path string) ([]types.TranscriptMessage, er
 	defer file.Close()
 
 	var messages []types.TranscriptMessage
-	scanner := bufio.NewScanner(file)
-
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-		
-		// Skip empty lines
-		if line == "" {
+	reader := jsonl.NewReader(file)
+	for {
+		line, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		// Lines over the maximum line size are skipped
+		if errors.Is(err, jsonl.ErrLineTooLong) {
 			continue
 		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading transcript file: %w", err)
+		}
 
 		var message types.TranscriptMessage
-		if err := json.Unmarshal([]byte(line), &message); err != nil {
-			return nil, fmt.Errorf("failed to parse line %d in transcript file %s: %w", lineNum, path, err)
+		if err := json.Unmarshal(line, &message); err != nil {
+			return nil, fmt.Errorf("failed to parse line %d in transcript file %s: %w", reader.Line(), path, err)
 		}
 
 		messages = append(messages, message)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading transcript file: %w", err)
-	}
-
 	return messages, nil
 }
 
@@ -148,26 +187,7 @@ func (r *Reader) ExtractToolUseDetails(message *types.TranscriptMessage) (*types
 		for _, item := range content {
 			if itemMap, ok := item.(map[string]interface{}); ok {
 				if itemType, exists := itemMap["type"]; exists && itemType == "tool_use" {
-					// Convert map back to ContentItem
-					contentItem := &types.ContentItem{
-						Type: "tool_use",
-					}
-					if id, exists := itemMap["id"]; exists {
-						if idStr, ok := id.(string); ok {
-							contentItem.ID = idStr
-						}
-					}
-					if name, exists := itemMap["name"]; exists {
-						if nameStr, ok := name.(string); ok {
-							contentItem.Name = nameStr
-						}
-					}
-					if input, exists := itemMap["input"]; exists {
-						if inputMap, ok := input.(map[string]interface{}); ok {
-							contentItem.Input = inputMap
-						}
-					}
-					return contentItem, nil
+					return toolUseFromMap(itemMap), nil
 				}
 			}
 		}
@@ -178,18 +198,21 @@ func (r *Reader) ExtractToolUseDetails(message *types.TranscriptMessage) (*types
 
 // GetConversationContext gets the last few messages for context
 func (r *Reader) GetConversationContext(transcriptPath string, maxMessages int) ([]types.TranscriptMessage, error) {
-	messages, err := r.ParseTranscriptFile(transcriptPath)
+	var messages []types.TranscriptMessage
+	err := r.ScanBackward(transcriptPath, func(message *types.TranscriptMessage) bool {
+		if len(messages) >= maxMessages {
+			return false
+		}
+		messages = append(messages, *message)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Return the last N messages
-	start := 0
-	if len(messages) > maxMessages {
-		start = len(messages) - maxMessages
-	}
-
-	return messages[start:], nil
+	// Collected newest first; return them in conversation order
+	slices.Reverse(messages)
+	return messages, nil
 }
 
 // GetMessagesByType filters messages by type (user, assistant)
@@ -207,21 +230,58 @@ func (r *Reader) GetMessagesByType(messages []types.TranscriptMessage, messageTy
 
 // FindToolUseByName finds the last tool use of a specific tool name
 func (r *Reader) FindToolUseByName(transcriptPath string, toolName string) (*types.TranscriptMessage, error) {
-	messages, err := r.ParseTranscriptFile(transcriptPath)
+	message, err := r.findLast(transcriptPath, func(message *types.TranscriptMessage) bool {
+		return message.Type == "assistant" && r.hasToolUseWithName(message, toolName)
+	})
 	if err != nil {
 		return nil, err
 	}
+	if message == nil {
+		return nil, fmt.Errorf("no tool use found for tool: %s", toolName)
+	}
+	return message, nil
+}
 
-	// Search backwards for tool use with specific name
-	for i := len(messages) - 1; i >= 0; i-- {
-		if messages[i].Type == "assistant" {
-			if r.hasToolUseWithName(&messages[i], toolName) {
-				return &messages[i], nil
-			}
+// FindToolResult returns the tool_result answering a tool_use and the message holding it,
+// reading the transcript from the end. It stops at the tool_use itself, since a result is
+// always written after it.
+func (r *Reader) FindToolResult(transcriptPath string, toolUseID string) (*types.ContentItem, *types.TranscriptMessage, error) {
+	var result *types.ContentItem
+	var found *types.TranscriptMessage
+	err := r.ScanBackward(transcriptPath, func(message *types.TranscriptMessage) bool {
+		if item := r.toolResultFor(message, toolUseID); item != nil {
+			result, found = item, message
+			return false
 		}
+		return !r.hasToolUseWithID(message, toolUseID)
+	})
+	if err != nil {
+		return nil, nil, err
 	}
+	if result == nil {
+		return nil, nil, fmt.Errorf("no tool result found for tool use: %s", toolUseID)
+	}
+	return result, found, nil
+}
 
-	return nil, fmt.Errorf("no tool use found for tool: %s", toolName)
+// ToolResultText returns the text of a tool_result, whose content is either a string or a
+// list of text blocks
+func (r *Reader) ToolResultText(result *types.ContentItem) string {
+	switch content := result.Content.(type) {
+	case string:
+		return content
+	case []interface{}:
+		var texts []string
+		for _, item := range content {
+			if itemMap, ok := item.(map[string]interface{}); ok {
+				if text, ok := itemMap["text"].(string); ok {
+					texts = append(texts, text)
+				}
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
 }
 
 // GetSessionInfo extracts session information from any message in the transcript
@@ -321,4 +381,40 @@ func (r *Reader) hasToolUseWithName(message *types.TranscriptMessage, toolName s
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// hasToolUseWithID checks if a message contains the tool_use with a specific ID
+func (r *Reader) hasToolUseWithID(message *types.TranscriptMessage, toolUseID string) bool {
+	if content, ok := message.Message.Content.([]interface{}); ok {
+		for _, item := range content {
+			if itemMap, ok := item.(map[string]interface{}); ok {
+				if itemMap["type"] == "tool_use" && itemMap["id"] == toolUseID {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// toolResultFor returns the tool_result for a tool_use ID in a message, or nil
+func (r *Reader) toolResultFor(message *types.TranscriptMessage, toolUseID string) *types.ContentItem {
+	content, ok := message.Message.Content.([]interface{})
+	if !ok {
+		return nil
+	}
+	for _, item := range content {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok || itemMap["type"] != "tool_result" || itemMap["tool_use_id"] != toolUseID {
+			continue
+		}
+		isError, _ := itemMap["is_error"].(bool)
+		return &types.ContentItem{
+			Type:      "tool_result",
+			ToolUseID: toolUseID,
+			Content:   itemMap["content"],
+			IsError:   isError,
+		}
+	}
+	return nil
+}
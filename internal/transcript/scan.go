@@ -0,0 +1,116 @@
+package transcript
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/jsonl"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// ErrTruncated is returned by ReadNewMessages when a transcript is shorter than the offset,
+// because it was replaced or rewritten
+var ErrTruncated = errors.New("transcript is shorter than the offset")
+
+// ErrTranscriptMissing is returned when a session's transcript file can't be found
+var ErrTranscriptMissing = errors.New("transcript file does not exist")
+
+// ScanBackward calls fn with the messages of a transcript from the newest to the oldest until
+// fn returns false. The file is read from the end in chunks, so finding a recent message
+// doesn't parse the whole transcript. An incomplete last line, which Claude Code may still be
+// writing, and lines over the maximum line size are skipped.
+func (r *Reader) ScanBackward(path string, fn func(*types.TranscriptMessage) bool) error {
+	defer r.timeRead(time.Now())
+	file, err := r.open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read transcript file: %w", err)
+	}
+
+	var parseErr error
+	err = jsonl.ScanBackward(file, info.Size(), func(line []byte, partial bool) bool {
+		var message types.TranscriptMessage
+		if err := json.Unmarshal(line, &message); err != nil {
+			if partial {
+				return true
+			}
+			parseErr = fmt.Errorf("failed to parse line in transcript file %s: %w", path, err)
+			return false
+		}
+		return fn(&message)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read transcript file: %w", err)
+	}
+	return parseErr
+}
+
+// ReadNewMessages parses the complete lines a transcript gained after offset and returns
+// them with the offset to continue from, so a growing transcript is only read once. An
+// incomplete last line is left for the next call. After a line that fails to parse, the
+// messages before it are returned with the offset past it.
+func (r *Reader) ReadNewMessages(path string, offset int64) ([]types.TranscriptMessage, int64, error) {
+	defer r.timeRead(time.Now())
+	file, err := r.open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, offset, fmt.Errorf("failed to read transcript file: %w", err)
+	}
+	if info.Size() < offset {
+		return nil, offset, ErrTruncated
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, fmt.Errorf("failed to read transcript file: %w", err)
+	}
+
+	var messages []types.TranscriptMessage
+	start := offset
+	reader := jsonl.NewReader(file)
+	for {
+		line, err := reader.Next()
+		if err == io.EOF || reader.Partial() {
+			// Incomplete line: read it again once it is finished
+			return messages, offset, nil
+		}
+		offset = start + reader.Offset()
+		// Lines over the maximum line size are skipped
+		if errors.Is(err, jsonl.ErrLineTooLong) {
+			continue
+		}
+		if err != nil {
+			return messages, offset, fmt.Errorf("error reading transcript file: %w", err)
+		}
+
+		var message types.TranscriptMessage
+		if err := json.Unmarshal(line, &message); err != nil {
+			return messages, offset, fmt.Errorf("failed to parse line in transcript file %s: %w", path, err)
+		}
+		messages = append(messages, message)
+	}
+}
+
+// open opens a transcript file
+func (r *Reader) open(path string) (*os.File, error) {
+	if !r.fileExists(path) {
+		return nil, fmt.Errorf("%w: %s", ErrTranscriptMissing, path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript file: %w", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,311 @@
+package transcript
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/riaanpieterse81/ClaudeToGo/internal/session"
+	"github.com/riaanpieterse81/ClaudeToGo/internal/types"
+)
+
+// maxSummaryReply caps the length of Claude's intermediate replies in a summary; the final
+// result is kept in full
+const maxSummaryReply = 500
+
+// SessionSummary is a readable account of a Claude Code session
+type SessionSummary struct {
+	SessionID    string        `json:"session_id"`
+	CWD          string        `json:"cwd,omitempty"`
+	GitBranch    string        `json:"git_branch,omitempty"`
+	Model        string        `json:"model,omitempty"`
+	Started      string        `json:"started,omitempty"`
+	Ended        string        `json:"ended,omitempty"`
+	Turns        []SummaryTurn `json:"turns"`
+	FilesChanged []string      `json:"files_changed,omitempty"`
+	Result       string        `json:"result,omitempty"` // Claude's last reply
+}
+
+// SummaryTurn is one prompt and what Claude did about it
+type SummaryTurn struct {
+	Prompt    string        `json:"prompt"`
+	ToolCalls []SummaryTool `json:"tool_calls,omitempty"`
+	Replies   []string      `json:"replies,omitempty"`
+}
+
+// SummaryTool is one tool call in a summary
+type SummaryTool struct {
+	Name   string `json:"name"`
+	Target string `json:"target,omitempty"` // File, command, URL or pattern the tool worked on
+	Failed bool   `json:"failed,omitempty"`
+}
+
+// FindSessionTranscript returns the transcript of a session in a transcripts directory
+// (<dir>/<project>/<session>.jsonl). A unique prefix of the session ID is enough.
+func FindSessionTranscript(dir, sessionID string) (string, error) {
+	if sessionID == "" {
+		return "", fmt.Errorf("session ID cannot be empty")
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*", sessionID+"*.jsonl"))
+	if err != nil {
+		return "", fmt.Errorf("invalid session ID %q: %w", sessionID, err)
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no transcript for session %s in %s: %w", sessionID, dir, ErrTranscriptMissing)
+	}
+
+	paths := make(map[string]string, len(matches))
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		id := strings.TrimSuffix(filepath.Base(match), ".jsonl")
+		paths[id] = match
+		ids = append(ids, id)
+	}
+	id, err := session.Resolve(sessionID, ids)
+	if err != nil {
+		return "", err
+	}
+	return paths[id], nil
+}
+
+// Summarize walks a transcript and collects its prompts, tool calls, replies and result.
+// Subagent (sidechain) messages and messages Claude Code adds for itself are left out.
+func (r *Reader) Summarize(path string) (*SessionSummary, error) {
+	messages, err := r.ParseTranscriptFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &SessionSummary{SessionID: strings.TrimSuffix(filepath.Base(path), ".jsonl")}
+	toolCalls := make(map[string]int) // Index in the current turn by tool use ID, to mark failed calls
+	changed := make(map[string]bool)
+	var turn *SummaryTurn
+	for i := range messages {
+		message := &messages[i]
+		if message.IsSidechain || message.IsMeta {
+			continue
+		}
+		if message.SessionID != "" {
+			summary.SessionID = message.SessionID
+		}
+		if summary.CWD == "" {
+			summary.CWD = message.CWD
+		}
+		if message.GitBranch != "" {
+			summary.GitBranch = message.GitBranch
+		}
+		if message.Message.Model != "" && !strings.HasPrefix(message.Message.Model, "<") {
+			summary.Model = message.Message.Model
+		}
+		if message.Timestamp != "" {
+			if summary.Started == "" {
+				summary.Started = message.Timestamp
+			}
+			summary.Ended = message.Timestamp
+		}
+
+		switch message.Type {
+		case "user":
+			for _, result := range contentItems(message, "tool_result") {
+				if isError, _ := result["is_error"].(bool); isError {
+					if index, ok := toolCalls[fmt.Sprint(result["tool_use_id"])]; ok {
+						turn.ToolCalls[index].Failed = true
+					}
+				}
+			}
+			if prompt := r.PromptText(message); prompt != "" {
+				summary.Turns = append(summary.Turns, SummaryTurn{Prompt: prompt})
+				turn = &summary.Turns[len(summary.Turns)-1]
+				toolCalls = make(map[string]int)
+			}
+		case "assistant":
+			if turn == nil {
+				// A resumed session may start with Claude
+				summary.Turns = append(summary.Turns, SummaryTurn{})
+				turn = &summary.Turns[len(summary.Turns)-1]
+			}
+			if text := strings.TrimSpace(r.ExtractTextContent(message)); text != "" {
+				turn.Replies = append(turn.Replies, text)
+				summary.Result = text
+			}
+			for _, item := range contentItems(message, "tool_use") {
+				name, _ := item["name"].(string)
+				input, _ := item["input"].(map[string]interface{})
+				turn.ToolCalls = append(turn.ToolCalls, SummaryTool{Name: name, Target: toolTarget(input)})
+				if id, ok := item["id"].(string); ok {
+					toolCalls[id] = len(turn.ToolCalls) - 1
+				}
+				if file, ok := input["file_path"].(string); ok && isFileChange(name) && !changed[file] {
+					changed[file] = true
+					summary.FilesChanged = append(summary.FilesChanged, file)
+				}
+			}
+		}
+	}
+
+	// The result is shown on its own rather than as the last reply
+	if last := len(summary.Turns) - 1; last >= 0 {
+		replies := summary.Turns[last].Replies
+		if len(replies) > 0 && replies[len(replies)-1] == summary.Result {
+			summary.Turns[last].Replies = replies[:len(replies)-1]
+		}
+	}
+	return summary, nil
+}
+
+// Markdown renders the summary for a pull request or ticket
+func (s *SessionSummary) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Claude Code session %s\n\n", s.SessionID)
+	if s.CWD != "" {
+		project := "`" + s.CWD + "`"
+		if s.GitBranch != "" {
+			project += " on branch `" + s.GitBranch + "`"
+		}
+		fmt.Fprintf(&b, "- **Project:** %s\n", project)
+	}
+	if s.Model != "" {
+		fmt.Fprintf(&b, "- **Model:** %s\n", s.Model)
+	}
+	if s.Started != "" {
+		fmt.Fprintf(&b, "- **When:** %s%s\n", s.Started, sessionDuration(s.Started, s.Ended))
+	}
+	toolCalls := 0
+	for _, turn := range s.Turns {
+		toolCalls += len(turn.ToolCalls)
+	}
+	fmt.Fprintf(&b, "- **Prompts:** %d, **tool calls:** %d, **files changed:** %d\n", len(s.Turns), toolCalls, len(s.FilesChanged))
+
+	for i, turn := range s.Turns {
+		fmt.Fprintf(&b, "\n## %d. ", i+1)
+		if turn.Prompt == "" {
+			b.WriteString("(continued session)\n")
+		} else {
+			fmt.Fprintf(&b, "%s\n\n%s\n", firstLine(turn.Prompt), quote(turn.Prompt))
+		}
+		if len(turn.ToolCalls) > 0 {
+			b.WriteString("\n**Tool calls:**\n\n")
+			for _, tool := range turn.ToolCalls {
+				fmt.Fprintf(&b, "- %s", tool.Name)
+				if tool.Target != "" {
+					fmt.Fprintf(&b, " `%s`", strings.ReplaceAll(firstLine(tool.Target), "`", "'"))
+				}
+				if tool.Failed {
+					b.WriteString(" ❌ failed")
+				}
+				b.WriteString("\n")
+			}
+		}
+		for _, reply := range turn.Replies {
+			fmt.Fprintf(&b, "\n**Claude:** %s\n", shorten(reply, maxSummaryReply))
+		}
+	}
+
+	if len(s.FilesChanged) > 0 {
+		b.WriteString("\n## Files changed\n\n")
+		for _, file := range s.FilesChanged {
+			if rel, err := filepath.Rel(s.CWD, file); s.CWD != "" && err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+			fmt.Fprintf(&b, "- `%s`\n", file)
+		}
+	}
+	if s.Result != "" {
+		fmt.Fprintf(&b, "\n## Result\n\n%s\n", s.Result)
+	}
+	return b.String()
+}
+
+// contentItems returns the content blocks of a message that have the given type
+func contentItems(message *types.TranscriptMessage, itemType string) []map[string]interface{} {
+	content, ok := message.Message.Content.([]interface{})
+	if !ok {
+		return nil
+	}
+	var items []map[string]interface{}
+	for _, item := range content {
+		if itemMap, ok := item.(map[string]interface{}); ok && itemMap["type"] == itemType {
+			items = append(items, itemMap)
+		}
+	}
+	return items
+}
+
+// PromptText returns what the user typed in a user message, or "" for tool results and
+// the output of local commands
+func (r *Reader) PromptText(message *types.TranscriptMessage) string {
+	var text string
+	switch content := message.Message.Content.(type) {
+	case string:
+		text = content
+	case []interface{}:
+		var parts []string
+		for _, item := range contentItems(message, "text") {
+			if part, ok := item["text"].(string); ok {
+				parts = append(parts, part)
+			}
+		}
+		text = strings.Join(parts, "\n")
+	}
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "<local-command-stdout>") || strings.HasPrefix(text, "Caveat:") {
+		return ""
+	}
+	return text
+}
+
+// toolTarget returns what a tool call worked on, from its input
+func toolTarget(input map[string]interface{}) string {
+	for _, key := range []string{"file_path", "notebook_path", "command", "url", "path", "pattern", "query", "description"} {
+		if value, ok := input[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// isFileChange reports whether a tool writes the file it names
+func isFileChange(toolName string) bool {
+	switch strings.ToLower(toolName) {
+	case "write", "edit", "multiedit", "notebookedit":
+		return true
+	}
+	return false
+}
+
+// sessionDuration renders how long a session took, or "" if the timestamps can't be read
+func sessionDuration(started, ended string) string {
+	start, err := time.Parse(time.RFC3339, started)
+	if err != nil {
+		return ""
+	}
+	end, err := time.Parse(time.RFC3339, ended)
+	if err != nil || !end.After(start) {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", end.Sub(start).Round(time.Second))
+}
+
+// firstLine returns the first line of a text, shortened for a heading
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return shorten(line, 80)
+}
+
+// shorten cuts text to max bytes, marking the cut
+func shorten(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	return strings.TrimSpace(text[:max]) + "..."
+}
+
+// quote renders text as a Markdown block quote
+func quote(text string) string {
+	return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+}
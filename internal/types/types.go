@@ -7,13 +7,15 @@ import (
 
 // ClaudeHookEvent represents the JSON data received from Claude Code hooks
 type ClaudeHookEvent struct {
-	SessionID      string `json:"session_id"`
-	TranscriptPath string `json:"transcript_path"`
-	CWD            string `json:"cwd"`
-	HookEventName  string `json:"hook_event_name"`
-	ToolName       string `json:"tool_name,omitempty"`
-	Timestamp      string `json:"timestamp"`
-	Message        string `json:"message,omitempty"`
+	SessionID      string                 `json:"session_id"`
+	TranscriptPath string                 `json:"transcript_path"`
+	CWD            string                 `json:"cwd"`
+	HookEventName  string                 `json:"hook_event_name"`
+	ToolName       string                 `json:"tool_name,omitempty"`
+	ToolUseID      string                 `json:"tool_use_id,omitempty"`
+	ToolInput      map[string]interface{} `json:"tool_input,omitempty"`
+	Timestamp      string                 `json:"timestamp"`
+	Message        string                 `json:"message,omitempty"`
 }
 
 // ClaudeHookResponse represents the response sent back to Claude Code
@@ -25,8 +27,14 @@ type ClaudeHookResponse struct {
 // Config holds application configuration
 type Config struct {
 	LogFile      string
+	LogFiles     []string // Log files or globs to monitor; LogFile is used when empty
 	PollInterval time.Duration
 	Verbose      bool
+	UsePolling   bool // Poll instead of using file system notifications
+	NoColor      bool // Disable colored monitor output
+	ShowContext  int       // Transcript excerpts shown under each monitored event
+	FromStart    bool      // Replay the whole log before following new events
+	Since        time.Time // Replay events from this time before following new events
 }
 
 // ConfigFile represents the configuration file structure
@@ -34,6 +42,7 @@ type ConfigFile struct {
 	LogFile      string `json:"logFile"`
 	PollInterval string `json:"pollInterval"`
 	Verbose      bool   `json:"verbose"`
+	UsePolling   bool   `json:"usePolling,omitempty"`
 }
 
 // ClaudeSettingsConfig represents the Claude Code settings.json structure
@@ -41,6 +50,9 @@ type ClaudeSettingsConfig struct {
 	Hooks map[string][]HookMatcher `json:"hooks,omitempty"`
 	// Preserve all other unknown fields in the settings.json
 	Extra map[string]json.RawMessage `json:"-"`
+	// Fingerprint of the file when it was loaded ("" if it didn't exist), so changes made
+	// by another program before saving are detected
+	Loaded string `json:"-"`
 }
 
 // HookMatcher represents a hook matcher configuration
@@ -60,7 +72,8 @@ type HookConfig struct {
 type ConfigLocation struct {
 	Path        string
 	Description string
-	Scope       string // "global", "project", "local"
+	Scope       string // "managed", "local", "project", "global", "legacy"
+	Precedence  int    // Claude Code's order for conflicting settings, 1 = highest
 }
 
 // Transcript processing types
@@ -125,6 +138,30 @@ type ExtractedData struct {
 	CWD       string      `json:"cwd"`
 	Timestamp string      `json:"timestamp"`
 	Data      interface{} `json:"data"` // StopEventData or NotificationEventData
+	Metadata  *SessionMetadata `json:"metadata,omitempty"`
+	Conversation *ConversationContext `json:"conversation,omitempty"` // Set when formatting.context_messages is on
+}
+
+// ConversationContext is the conversation leading up to an event, telling an approver why
+// Claude is asking
+type ConversationContext struct {
+	LastPrompt              string `json:"last_prompt,omitempty"` // The user's most recent prompt
+	RecentExchanges         int    `json:"recent_exchanges"`      // User prompts among the recent messages
+	RecentUserMessages      int    `json:"recent_user_messages"`
+	RecentAssistantMessages int    `json:"recent_assistant_messages"`
+	TotalRecentMessages     int    `json:"total_recent_messages"`
+}
+
+// SessionMetadata holds project and model details gathered from the transcript
+type SessionMetadata struct {
+	RepoName      string `json:"repo_name,omitempty"`
+	GitBranch     string `json:"git_branch,omitempty"`
+	GitAuthor     string `json:"git_author,omitempty"` // git user.email, else user.name, of the repository
+	Model         string `json:"model,omitempty"`
+	ClaudeVersion string `json:"claude_version,omitempty"`
+	InputTokens   int    `json:"input_tokens"`
+	OutputTokens  int    `json:"output_tokens"`
+	CacheTokens   int    `json:"cache_tokens"`
 }
 
 // StopEventData represents data extracted from Stop events
@@ -140,6 +177,20 @@ type NotificationEventData struct {
 	Action      string                 `json:"action"`
 	Details     map[string]interface{} `json:"details"`
 	RequestText string                 `json:"request_text,omitempty"`
+	Result      *ToolResult            `json:"tool_result,omitempty"` // What the tool returned, once it has run
+	Subagent    bool                   `json:"subagent,omitempty"`    // Requested by a subagent, not the main agent
+	Agent       string                 `json:"agent,omitempty"`       // Subagent type, when known
+	AgentTask   string                 `json:"agent_task,omitempty"`  // What the subagent was asked to do, when known
+}
+
+// ToolResult is what a tool call returned, read from its tool_result in the transcript
+type ToolResult struct {
+	Output       string `json:"output,omitempty"` // Text returned to Claude, or the error text
+	Stdout       string `json:"stdout,omitempty"`
+	Stderr       string `json:"stderr,omitempty"`
+	IsError      bool   `json:"is_error,omitempty"`
+	BytesWritten int    `json:"bytes_written,omitempty"` // For tools that write files
+	Truncated    bool   `json:"truncated,omitempty"`
 }
 
 // Messenger formatting types
@@ -154,6 +205,22 @@ type MessengerMessage struct {
 	Context     map[string]interface{} `json:"context"`
 	Timestamp   string                 `json:"timestamp"`
 	Priority    string                 `json:"priority,omitempty"` // "high", "medium", "low"
+	MessageID   string                 `json:"message_id,omitempty"`
+	ThreadID    string                 `json:"thread_id,omitempty"` // Groups all messages from one Claude session
+	ReplyTo     string                 `json:"reply_to,omitempty"`  // MessageID of the previous message in the thread
+	Attachments []Attachment           `json:"attachments,omitempty"`
+
+	ConfirmationCode string `json:"-"` // One-time code approving a high-risk request; only added to notifications sent, never stored
+}
+
+// Attachment is a file stored alongside a message for channels that support uploads
+type Attachment struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"` // Location on disk, set when the message is saved
+	MimeType    string `json:"mime_type"`
+	Description string `json:"description,omitempty"`
+	Size        int    `json:"size"`
+	Content     string `json:"-"` // Held in memory until written to Path
 }
 
 // SuggestedAction represents actions a user can take via messenger
@@ -0,0 +1,93 @@
+// Package version reports which build of ClaudeToGo is running, for bug reports and updates
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// pseudoVersion matches the timestamp and commit of a Go pseudo-version such as
+// v0.0.0-20250801100000-1a2b3c4d5e6f, which says no more than the commit does
+var pseudoVersion = regexp.MustCompile(`\d{14}-[0-9a-f]{12}`)
+
+// Build metadata, set at build time with
+//
+//	go build -ldflags "-X github.com/riaanpieterse81/ClaudeToGo/internal/version.Version=1.2.0 \
+//	  -X github.com/riaanpieterse81/ClaudeToGo/internal/version.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/riaanpieterse81/ClaudeToGo/internal/version.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Commit and Date fall back to the VCS details Go records when building from a git checkout.
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a checkout with uncommitted changes
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the metadata of the running build
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	// `go install module@v1.2.0` and builds of a tagged checkout record the tag
+	if moduleVersion := buildInfo.Main.Version; info.Version == "dev" && moduleVersion != "" && moduleVersion != "(devel)" &&
+		!strings.Contains(moduleVersion, "+dirty") && !pseudoVersion.MatchString(moduleVersion) {
+		info.Version = moduleVersion
+	}
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true" && Commit == ""
+		}
+	}
+	return info
+}
+
+// String renders the build on one line, e.g. "claudetogo 1.2.0 (commit 1a2b3c4d, built
+// 2025-08-01T10:00:00Z, go1.22.2 linux/amd64)"
+func (i Info) String() string {
+	details := ""
+	if i.Commit != "" {
+		commit := i.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if i.Modified {
+			commit += "-dirty"
+		}
+		details += "commit " + commit + ", "
+	}
+	if i.Date != "" {
+		details += "built " + i.Date + ", "
+	}
+	return fmt.Sprintf("claudetogo %s (%s%s %s)", i.Version, details, i.GoVersion, i.Platform)
+}